@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 )
@@ -21,7 +22,7 @@ func (c *customerController) List(ctx context.Context, presenter port.Presenter,
 		return nil, err
 	}
 
-	return presenter.Present(dto.PresenterInput{
+	return present(ctx, presenter, dto.PresenterInput{
 		Total:  total,
 		Page:   input.Page,
 		Limit:  input.Limit,
@@ -35,7 +36,7 @@ func (c *customerController) Create(ctx context.Context, presenter port.Presente
 		return nil, err
 	}
 
-	return presenter.Present(dto.PresenterInput{
+	return present(ctx, presenter, dto.PresenterInput{
 		Result: customer,
 	})
 }
@@ -46,18 +47,29 @@ func (c *customerController) Get(ctx context.Context, presenter port.Presenter,
 		return nil, err
 	}
 
-	return presenter.Present(dto.PresenterInput{
+	return present(ctx, presenter, dto.PresenterInput{
 		Result: customer,
 	})
 }
 
+func (c *customerController) BatchGet(ctx context.Context, presenter port.Presenter, input dto.BatchGetCustomersInput) ([]byte, error) {
+	output, err := c.useCase.BatchGet(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: output,
+	})
+}
+
 func (c *customerController) Update(ctx context.Context, presenter port.Presenter, input dto.UpdateCustomerInput) ([]byte, error) {
 	customer, err := c.useCase.Update(ctx, input)
 	if err != nil {
 		return nil, err
 	}
 
-	return presenter.Present(dto.PresenterInput{
+	return present(ctx, presenter, dto.PresenterInput{
 		Result: customer,
 	})
 }
@@ -68,7 +80,7 @@ func (c *customerController) GetByCPF(ctx context.Context, presenter port.Presen
 		return nil, err
 	}
 
-	return presenter.Present(dto.PresenterInput{
+	return present(ctx, presenter, dto.PresenterInput{
 		Result: customer,
 	})
 }
@@ -79,7 +91,127 @@ func (c *customerController) Delete(ctx context.Context, presenter port.Presente
 		return nil, err
 	}
 
-	return presenter.Present(dto.PresenterInput{
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: customer,
+	})
+}
+
+func (c *customerController) Restore(ctx context.Context, presenter port.Presenter, input dto.RestoreCustomerInput) ([]byte, error) {
+	customer, err := c.useCase.Restore(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: customer,
+	})
+}
+
+func (c *customerController) ChangeStatus(ctx context.Context, presenter port.Presenter, input dto.ChangeCustomerStatusInput) ([]byte, error) {
+	customer, err := c.useCase.ChangeStatus(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
 		Result: customer,
 	})
 }
+
+func (c *customerController) Anonymize(ctx context.Context, presenter port.Presenter, input dto.AnonymizeCustomerInput) ([]byte, error) {
+	customer, err := c.useCase.Anonymize(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: customer,
+	})
+}
+
+func (c *customerController) Authenticate(ctx context.Context, presenter port.Presenter, input dto.AuthenticateCustomerInput) ([]byte, error) {
+	customer, err := c.useCase.Authenticate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: customer,
+	})
+}
+
+func (c *customerController) History(ctx context.Context, presenter port.Presenter, input dto.GetCustomerHistoryInput) ([]byte, error) {
+	entries, err := c.useCase.History(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: entries,
+	})
+}
+
+func (c *customerController) DataExport(ctx context.Context, presenter port.Presenter, input dto.GetCustomerDataExportInput) ([]byte, error) {
+	export, err := c.useCase.DataExport(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: export,
+	})
+}
+
+func (c *customerController) GetConsents(ctx context.Context, presenter port.Presenter, input dto.GetCustomerConsentsInput) ([]byte, error) {
+	consents, err := c.useCase.GetConsents(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: consents,
+	})
+}
+
+func (c *customerController) UpdateConsents(ctx context.Context, presenter port.Presenter, input dto.UpdateCustomerConsentsInput) ([]byte, error) {
+	consents, err := c.useCase.UpdateConsents(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: consents,
+	})
+}
+
+func (c *customerController) Import(ctx context.Context, presenter port.Presenter, input dto.ImportCustomersInput) ([]byte, error) {
+	report, err := c.useCase.Import(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: report,
+	})
+}
+
+func (c *customerController) Export(ctx context.Context, presenter port.Presenter, input dto.ExportCustomersInput, writer port.CustomerExportWriter) ([]byte, error) {
+	output, err := c.useCase.Export(ctx, input, writer)
+	if err != nil {
+		return nil, err
+	}
+
+	return present(ctx, presenter, dto.PresenterInput{
+		Result: output,
+	})
+}
+
+// present writes the result for the caller and route of the request, which decide how much of the personal
+// data of the customers the presenter shows
+func present(ctx context.Context, presenter port.Presenter, input dto.PresenterInput) ([]byte, error) {
+	input.Audience = dto.Audience{
+		Principal: entity.PrincipalFromContext(ctx),
+		Route:     entity.RouteFromContext(ctx),
+	}
+	return presenter.Present(input)
+}
@@ -286,6 +286,71 @@ func TestCustomerController_Get(t *testing.T) {
 	}
 }
 
+func TestCustomerController_BatchGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.BatchGetCustomersInput{IDs: []int{123, 456}}
+
+	mockOutput := &dto.BatchGetCustomersOutput{
+		Customers:  []*entity.Customer{{ID: 123, Name: "Test Customer"}},
+		MissingIDs: []int{456},
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should batch get customers successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					BatchGet(ctx, input).
+					Return(mockOutput, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockOutput,
+					}).
+					Return([]byte(`{"customers":[{"id":"123"}],"missing_ids":[456]}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, string(result), "missing_ids")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					BatchGet(ctx, input).
+					Return(nil, errors.New("invalid ids"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "invalid ids", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.BatchGet(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
 func TestCustomerController_GetByCPF(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -382,10 +447,11 @@ func TestCustomerController_Update(t *testing.T) {
 	customerController := controller.NewCustomerController(mockUseCase)
 
 	ctx := context.Background()
+	name, email := "Updated Customer", "updated@test.com"
 	input := dto.UpdateCustomerInput{
 		ID:    123,
-		Name:  "Updated Customer",
-		Email: "updated@test.com",
+		Name:  &name,
+		Email: &email,
 	}
 
 	mockCustomer := &entity.Customer{
@@ -550,3 +616,849 @@ func TestCustomerController_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomerController_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.RestoreCustomerInput{ID: 123}
+
+	mockCustomer := &entity.Customer{
+		ID:    123,
+		Name:  "Restored Customer",
+		Email: "restored@test.com",
+		CPF:   "123.456.789-01",
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should restore customer successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Restore(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return([]byte(`{"id":"123","name":"Restored Customer"}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "Restored Customer")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Restore(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Restore(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.Restore(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_ChangeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.ChangeCustomerStatusInput{ID: 123, Status: "blocked", Reason: "chargeback fraud"}
+
+	mockCustomer := &entity.Customer{
+		ID:    123,
+		Name:  "Blocked Customer",
+		Email: "blocked@test.com",
+		CPF:   "123.456.789-01",
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should change customer status successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					ChangeStatus(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return([]byte(`{"id":"123","name":"Blocked Customer"}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "Blocked Customer")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					ChangeStatus(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					ChangeStatus(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.ChangeStatus(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_Anonymize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.AnonymizeCustomerInput{ID: 123}
+
+	mockCustomer := &entity.Customer{
+		ID:    123,
+		Name:  "anon-PW3XQ",
+		Email: "anon-pw3xq@anonymized.invalid",
+		CPF:   "anon-KD7RM",
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should anonymize customer successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Anonymize(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return([]byte(`{"id":"123","name":"anon-PW3XQ"}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "anon-PW3XQ")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Anonymize(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Anonymize(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.Anonymize(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.AuthenticateCustomerInput{CPF: "123.456.789-01"}
+
+	mockCustomer := &entity.Customer{
+		ID:    123,
+		Name:  "Active Customer",
+		Email: "active@test.com",
+		CPF:   "123.456.789-01",
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should authenticate customer successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Authenticate(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return([]byte(`{"id":"123","name":"Active Customer"}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "Active Customer")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Authenticate(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Authenticate(ctx, input).
+					Return(mockCustomer, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockCustomer,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.Authenticate(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.ImportCustomersInput{
+		Rows: []dto.ImportCustomerRow{{Line: 2, Name: "Test Customer", Email: "test@example.com", CPF: "12345678900"}},
+		Mode: dto.ImportModeSkip,
+	}
+
+	mockReport := &dto.ImportCustomersReport{
+		Total:   1,
+		Created: 1,
+		Rows:    []dto.ImportRowResult{{Line: 2, CPF: "12345678900", Status: dto.ImportStatusCreated, CustomerID: 123}},
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should import customers successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Import(ctx, input).
+					Return(mockReport, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockReport,
+					}).
+					Return([]byte(`{"total":1,"created":1}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, string(result), "created")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Import(ctx, input).
+					Return(nil, errors.New("invalid mode"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "invalid mode", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.Import(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	mockWriter := mockport.NewMockCustomerExportWriter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.ExportCustomersInput{Fields: []dto.ExportField{dto.ExportFieldID}, MaskPII: true}
+
+	mockOutput := &dto.ExportCustomersOutput{Fields: []dto.ExportField{dto.ExportFieldID}, Count: 2}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should export customers successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Export(ctx, input, mockWriter).
+					Return(mockOutput, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockOutput,
+					}).
+					Return([]byte(`{"fields":["id"],"count":2}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, string(result), "count")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					Export(ctx, input, mockWriter).
+					Return(nil, errors.New("invalid fields"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "invalid fields", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.Export(ctx, mockPresenter, input, mockWriter)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.GetCustomerHistoryInput{ID: 123}
+
+	mockEntries := []*entity.AuditEntry{
+		{ID: "entry-1", CustomerID: 123, Action: entity.AuditActionCreate},
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should return customer history successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					History(ctx, input).
+					Return(mockEntries, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockEntries,
+					}).
+					Return([]byte(`{"entries":[{"id":"entry-1","action":"create"}]}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "entry-1")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					History(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					History(ctx, input).
+					Return(mockEntries, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockEntries,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.History(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_DataExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.GetCustomerDataExportInput{ID: 123}
+
+	mockExport := &dto.CustomerDataExport{
+		Customer: &entity.Customer{ID: 123, Name: "John Doe"},
+		History:  []*entity.AuditEntry{{ID: "entry-1", CustomerID: 123, Action: entity.AuditActionCreate}},
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should return customer data export successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					DataExport(ctx, input).
+					Return(mockExport, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockExport,
+					}).
+					Return([]byte(`{"customer":{"id":123},"history":[{"id":"entry-1"}]}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "entry-1")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					DataExport(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					DataExport(ctx, input).
+					Return(mockExport, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockExport,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.DataExport(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_GetConsents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	input := dto.GetCustomerConsentsInput{ID: 123}
+
+	mockConsents := &dto.CustomerConsents{Customer: &entity.Customer{ID: 123, Consents: []entity.Consent{
+		{Purpose: entity.ConsentPurposeMarketing, Granted: true, PolicyVersion: "1.0", Channel: entity.ConsentChannelWeb},
+	}}}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should return customer consents successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					GetConsents(ctx, input).
+					Return(mockConsents, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockConsents,
+					}).
+					Return([]byte(`{"id":123,"consents":[{"purpose":"marketing"}]}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "marketing")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					GetConsents(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					GetConsents(ctx, input).
+					Return(mockConsents, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockConsents,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.GetConsents(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_UpdateConsents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	ctx := context.Background()
+	granted := true
+	input := dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{
+		{Purpose: "marketing", Granted: &granted, PolicyVersion: "1.0", Channel: "web"},
+	}}
+
+	mockConsents := &dto.CustomerConsents{Customer: &entity.Customer{ID: 123, Consents: []entity.Consent{
+		{Purpose: entity.ConsentPurposeMarketing, Granted: true, PolicyVersion: "1.0", Channel: entity.ConsentChannelWeb},
+	}}}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		checkResult func(*testing.T, []byte, error)
+	}{
+		{
+			name: "should update customer consents successfully",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					UpdateConsents(ctx, input).
+					Return(mockConsents, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockConsents,
+					}).
+					Return([]byte(`{"id":123,"consents":[{"purpose":"marketing"}]}`), nil)
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				assert.Contains(t, string(result), "marketing")
+			},
+		},
+		{
+			name: "should return error when use case fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					UpdateConsents(ctx, input).
+					Return(nil, errors.New("customer not found"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "customer not found", err.Error())
+			},
+		},
+		{
+			name: "should return error when presenter fails",
+			setupMocks: func() {
+				mockUseCase.EXPECT().
+					UpdateConsents(ctx, input).
+					Return(mockConsents, nil)
+
+				mockPresenter.EXPECT().
+					Present(dto.PresenterInput{
+						Result: mockConsents,
+					}).
+					Return(nil, errors.New("presenter error"))
+			},
+			checkResult: func(t *testing.T, result []byte, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, "presenter error", err.Error())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+
+			result, err := customerController.UpdateConsents(ctx, mockPresenter, input)
+
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerController_Audience(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUseCase := mockport.NewMockCustomerUseCase(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+	customerController := controller.NewCustomerController(mockUseCase)
+
+	principal := &entity.Principal{Subject: "admin@example.com", Role: entity.RoleAdmin}
+	ctx := entity.ContextWithRoute(entity.ContextWithPrincipal(context.Background(), principal), "GET /customers/{id}")
+	customer := &entity.Customer{ID: 1, Name: "John Doe"}
+
+	mockUseCase.EXPECT().Get(ctx, dto.GetCustomerInput{ID: 1}).Return(customer, nil)
+	mockPresenter.EXPECT().
+		Present(dto.PresenterInput{
+			Result:   customer,
+			Audience: dto.Audience{Principal: principal, Route: "GET /customers/{id}"},
+		}).
+		Return([]byte(`{"id":1}`), nil)
+
+	output, err := customerController.Get(ctx, mockPresenter, dto.GetCustomerInput{ID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"id":1}`), output)
+}
@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+type auditGateway struct {
+	dataSource port.AuditDataSource
+}
+
+func NewAuditGateway(dataSource port.AuditDataSource) port.AuditGateway {
+	return &auditGateway{dataSource}
+}
+
+func (g *auditGateway) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	return g.dataSource.Append(ctx, entry)
+}
+
+func (g *auditGateway) FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	return g.dataSource.FindByCustomerID(ctx, customerID)
+}
+
+func (g *auditGateway) EraseCustomer(ctx context.Context, customerID int) error {
+	return g.dataSource.EraseCustomer(ctx, customerID)
+}
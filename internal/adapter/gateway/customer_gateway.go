@@ -19,15 +19,28 @@ func (g *customerGateway) FindByID(ctx context.Context, id int) (*entity.Custome
 	return g.dataSource.FindByID(ctx, id)
 }
 
+func (g *customerGateway) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
+	return g.dataSource.FindByIDs(ctx, ids)
+}
+
 func (g *customerGateway) FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error) {
 	return g.dataSource.FindByCPF(ctx, cpf)
 }
 
-func (g *customerGateway) FindAll(ctx context.Context, name string, page, limit int) ([]*entity.Customer, int64, error) {
+func (g *customerGateway) FindAll(ctx context.Context, name, search string, includeDeleted bool, afterID, page, limit int) ([]*entity.Customer, int64, error) {
 	filters := make(map[string]interface{})
 	if name != "" {
 		filters["name"] = name
 	}
+	if search != "" {
+		filters[port.FilterSearch] = search
+	}
+	if includeDeleted {
+		filters[port.FilterIncludeDeleted] = true
+	}
+	if afterID > 0 {
+		filters[port.FilterAfterID] = afterID
+	}
 
 	return g.dataSource.FindAll(ctx, filters, page, limit)
 }
@@ -40,6 +53,14 @@ func (g *customerGateway) Update(ctx context.Context, customer *entity.Customer)
 	return g.dataSource.Update(ctx, customer)
 }
 
-func (g *customerGateway) Delete(ctx context.Context, id int) error {
-	return g.dataSource.Delete(ctx, id)
+func (g *customerGateway) Delete(ctx context.Context, id int, version int) error {
+	return g.dataSource.Delete(ctx, id, version)
+}
+
+func (g *customerGateway) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	return g.dataSource.SaveBatch(ctx, customers)
+}
+
+func (g *customerGateway) Scan(ctx context.Context, segments int, visit func(*entity.Customer) error) error {
+	return g.dataSource.Scan(ctx, segments, visit)
 }
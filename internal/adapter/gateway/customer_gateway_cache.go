@@ -0,0 +1,293 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// CacheStats counts the lookups answered by the cache, the ones that reached the wrapped gateway,
+// and the cache operations that failed
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Errors int64
+}
+
+// CachedCustomerGateway is a read-through cache of FindByID, FindByIDs and FindByCPF. Customers that don't
+// exist are cached for NegativeTTL, so repeated lookups of unknown CPFs don't reach the data source. Writes
+// remove the cached entries of the customer. A failing cache never fails a request, it only counts an error
+type CachedCustomerGateway struct {
+	port.CustomerGateway
+	cache       port.Cache
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// cachedCustomer is the cache entry of a customer. A null entry records that the customer doesn't exist
+type cachedCustomer struct {
+	ID              int                   `json:"id"`
+	Name            string                `json:"name"`
+	Email           string                `json:"email"`
+	CPF             string                `json:"cpf"`
+	Version         int                   `json:"version"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+	DeletedAt       *time.Time            `json:"deleted_at,omitempty"`
+	Status          entity.CustomerStatus `json:"status,omitempty"`
+	StatusReason    string                `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time            `json:"status_changed_at,omitempty"`
+	AnonymizedAt    *time.Time            `json:"anonymized_at,omitempty"`
+	Consents        []entity.Consent      `json:"consents,omitempty"`
+}
+
+func NewCachedCustomerGateway(gateway port.CustomerGateway, cache port.Cache, ttl, negativeTTL time.Duration) *CachedCustomerGateway {
+	return &CachedCustomerGateway{
+		CustomerGateway: gateway,
+		cache:           cache,
+		ttl:             ttl,
+		negativeTTL:     negativeTTL,
+	}
+}
+
+func (g *CachedCustomerGateway) FindByID(ctx context.Context, id int) (*entity.Customer, error) {
+	return g.readThrough(ctx, customerIDKey(id), func() (*entity.Customer, error) {
+		return g.CustomerGateway.FindByID(ctx, id)
+	})
+}
+
+func (g *CachedCustomerGateway) FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error) {
+	return g.readThrough(ctx, customerCPFKey(cpf), func() (*entity.Customer, error) {
+		return g.CustomerGateway.FindByCPF(ctx, cpf)
+	})
+}
+
+// FindByIDs answers the cached IDs from the cache and loads the others with a single call of the wrapped
+// gateway, caching the customers found and negative entries for the missing ones
+func (g *CachedCustomerGateway) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
+	byID := make(map[int]*entity.Customer, len(ids))
+	missed := make([]int, 0, len(ids))
+	for _, id := range ids {
+		customer, found := g.lookup(ctx, customerIDKey(id))
+		if !found {
+			missed = append(missed, id)
+			continue
+		}
+		if customer != nil {
+			byID[id] = customer
+		}
+	}
+
+	if len(missed) > 0 {
+		loaded, err := g.CustomerGateway.FindByIDs(ctx, missed)
+		if err != nil {
+			return nil, err
+		}
+		for _, customer := range loaded {
+			byID[customer.ID] = customer
+		}
+		for _, id := range missed {
+			g.store(ctx, customerIDKey(id), byID[id])
+		}
+	}
+
+	customers := make([]*entity.Customer, 0, len(byID))
+	for _, id := range ids {
+		if customer, ok := byID[id]; ok {
+			customers = append(customers, customer)
+		}
+	}
+	return customers, nil
+}
+
+// Create removes the negative entries of the new customer
+func (g *CachedCustomerGateway) Create(ctx context.Context, customer *entity.Customer) error {
+	err := g.CustomerGateway.Create(ctx, customer)
+	g.invalidate(ctx, customer.ID, customer.CPF)
+	return err
+}
+
+// Update removes the entries of the customer, even when the update fails, since a version mismatch
+// may come from a stale entry. Anonymizing replaces the CPF, so the CPF it had is looked up first and
+// its entry is removed too. The lookup skips the cache, which it would otherwise fill right before the write
+func (g *CachedCustomerGateway) Update(ctx context.Context, customer *entity.Customer) error {
+	keys := customerKeys(customer.ID, customer.CPF)
+	if customer.IsAnonymized() {
+		if stored, err := g.CustomerGateway.FindByID(ctx, customer.ID); err == nil && stored != nil && stored.CPF != customer.CPF {
+			keys = append(keys, customerCPFKey(stored.CPF))
+		}
+	}
+
+	err := g.CustomerGateway.Update(ctx, customer)
+	g.delete(ctx, keys)
+	return err
+}
+
+// Delete removes the entries of the customer. Its CPF is looked up first, skipping the cache, so the CPF
+// entry is removed too
+func (g *CachedCustomerGateway) Delete(ctx context.Context, id int, version int) error {
+	cpf := ""
+	if customer, err := g.CustomerGateway.FindByID(ctx, id); err == nil && customer != nil {
+		cpf = customer.CPF
+	}
+
+	err := g.CustomerGateway.Delete(ctx, id, version)
+	g.invalidate(ctx, id, cpf)
+	return err
+}
+
+// SaveBatch removes the entries of every customer of the batch, even when the batch fails,
+// since it may have been partially written
+func (g *CachedCustomerGateway) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	err := g.CustomerGateway.SaveBatch(ctx, customers)
+
+	keys := make([]string, 0, 2*len(customers))
+	for _, customer := range customers {
+		keys = append(keys, customerKeys(customer.ID, customer.CPF)...)
+	}
+	g.delete(ctx, keys)
+	return err
+}
+
+// Stats returns the counters since the gateway was created
+func (g *CachedCustomerGateway) Stats() CacheStats {
+	return CacheStats{
+		Hits:   g.hits.Load(),
+		Misses: g.misses.Load(),
+		Errors: g.errors.Load(),
+	}
+}
+
+func (g *CachedCustomerGateway) readThrough(ctx context.Context, key string, load func() (*entity.Customer, error)) (*entity.Customer, error) {
+	if customer, found := g.lookup(ctx, key); found {
+		return customer, nil
+	}
+
+	customer, err := load()
+	if err != nil {
+		return nil, err
+	}
+	g.store(ctx, key, customer)
+	return customer, nil
+}
+
+// lookup reads an entry, counting a hit or a miss. A nil customer that was found is a negative entry
+func (g *CachedCustomerGateway) lookup(ctx context.Context, key string) (*entity.Customer, bool) {
+	value, found, err := g.cache.Get(ctx, key)
+	if err != nil {
+		g.errors.Add(1)
+	}
+	if found {
+		if customer, err := decodeCachedCustomer(value); err == nil {
+			g.hits.Add(1)
+			return customer, true
+		}
+		g.errors.Add(1)
+	}
+	g.misses.Add(1)
+	return nil, false
+}
+
+// store caches a customer, or a negative entry when it's nil
+func (g *CachedCustomerGateway) store(ctx context.Context, key string, customer *entity.Customer) {
+	ttl := g.ttl
+	if customer == nil {
+		ttl = g.negativeTTL
+	}
+	if ttl > 0 {
+		if err := g.cache.Set(ctx, key, encodeCachedCustomer(customer), ttl); err != nil {
+			g.errors.Add(1)
+		}
+	}
+}
+
+func (g *CachedCustomerGateway) invalidate(ctx context.Context, id int, cpf string) {
+	g.delete(ctx, customerKeys(id, cpf))
+}
+
+func (g *CachedCustomerGateway) delete(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := g.cache.Delete(ctx, keys...); err != nil {
+		g.errors.Add(1)
+	}
+}
+
+// customerKeys returns the entries of a customer, leaving out the ID or the CPF when they aren't known
+func customerKeys(id int, cpf string) []string {
+	keys := make([]string, 0, 2)
+	if id > 0 {
+		keys = append(keys, customerIDKey(id))
+	}
+	if cpf != "" {
+		keys = append(keys, customerCPFKey(cpf))
+	}
+	return keys
+}
+
+func customerIDKey(id int) string {
+	return fmt.Sprintf("customer:id:%d", id)
+}
+
+func customerCPFKey(cpf string) string {
+	return "customer:cpf:" + cpf
+}
+
+func encodeCachedCustomer(customer *entity.Customer) []byte {
+	if customer == nil {
+		return []byte("null")
+	}
+
+	value, _ := json.Marshal(cachedCustomer{
+		ID:              customer.ID,
+		Name:            customer.Name,
+		Email:           customer.Email,
+		CPF:             customer.CPF,
+		Version:         customer.Version,
+		CreatedAt:       customer.CreatedAt,
+		UpdatedAt:       customer.UpdatedAt,
+		DeletedAt:       customer.DeletedAt,
+		Status:          customer.Status,
+		StatusReason:    customer.StatusReason,
+		StatusChangedAt: customer.StatusChangedAt,
+		AnonymizedAt:    customer.AnonymizedAt,
+		Consents:        customer.Consents,
+	})
+	return value
+}
+
+func decodeCachedCustomer(value []byte) (*entity.Customer, error) {
+	var cached *cachedCustomer
+	if err := json.Unmarshal(value, &cached); err != nil {
+		return nil, err
+	}
+	if cached == nil {
+		return nil, nil
+	}
+
+	return &entity.Customer{
+		ID:              cached.ID,
+		Name:            cached.Name,
+		Email:           cached.Email,
+		CPF:             cached.CPF,
+		Version:         cached.Version,
+		CreatedAt:       cached.CreatedAt,
+		UpdatedAt:       cached.UpdatedAt,
+		DeletedAt:       cached.DeletedAt,
+		Status:          cached.Status,
+		StatusReason:    cached.StatusReason,
+		StatusChangedAt: cached.StatusChangedAt,
+		AnonymizedAt:    cached.AnonymizedAt,
+		Consents:        cached.Consents,
+	}, nil
+}
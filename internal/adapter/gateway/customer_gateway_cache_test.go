@@ -0,0 +1,348 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	mockport "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port/mocks"
+)
+
+// mapCache is a cache without expiration, recording the ttl of each entry
+type mapCache struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (c *mapCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := c.values[key]
+	return value, ok, nil
+}
+
+func (c *mapCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.values[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *mapCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func newTestCustomer() *entity.Customer {
+	return &entity.Customer{
+		ID:        1,
+		Name:      "Test Customer",
+		Email:     "test@example.com",
+		CPF:       "12345678900",
+		Version:   3,
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC),
+		Consents: []entity.Consent{{
+			Purpose:       entity.ConsentPurposeMarketing,
+			Granted:       true,
+			PolicyVersion: "1.0",
+			Channel:       entity.ConsentChannelWeb,
+			RecordedAt:    time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC),
+		}},
+	}
+}
+
+func TestCachedCustomerGateway_FindByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	cache := newMapCache()
+	cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+	customer := newTestCustomer()
+
+	mockGateway.EXPECT().FindByID(ctx, 1).Return(customer, nil).Times(1)
+
+	first, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+	second, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, customer, first)
+	assert.Equal(t, customer, second, "the cached customer must keep every field")
+	assert.Equal(t, time.Minute, cache.ttls["customer:id:1"])
+	assert.Equal(t, gateway.CacheStats{Hits: 1, Misses: 1}, cached.Stats())
+}
+
+func TestCachedCustomerGateway_FindDeletedByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	cached := gateway.NewCachedCustomerGateway(mockGateway, newMapCache(), time.Minute, 5*time.Second)
+	customer := newTestCustomer()
+	deletedAt := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	customer.DeletedAt = &deletedAt
+
+	mockGateway.EXPECT().FindByID(ctx, 1).Return(customer, nil).Times(1)
+
+	_, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+	found, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, customer, found, "the cached customer must stay deleted")
+}
+
+func TestCachedCustomerGateway_NegativeCaching(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	cache := newMapCache()
+	cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+
+	mockGateway.EXPECT().FindByCPF(ctx, "12345678900").Return(nil, nil).Times(1)
+
+	for range 2 {
+		customer, err := cached.FindByCPF(ctx, "12345678900")
+		require.NoError(t, err)
+		assert.Nil(t, customer)
+	}
+	assert.Equal(t, 5*time.Second, cache.ttls["customer:cpf:12345678900"])
+	assert.Equal(t, gateway.CacheStats{Hits: 1, Misses: 1}, cached.Stats())
+
+	// Creating the customer removes the negative entry
+	customer := newTestCustomer()
+	mockGateway.EXPECT().Create(ctx, customer).Return(nil)
+	mockGateway.EXPECT().FindByCPF(ctx, "12345678900").Return(customer, nil)
+
+	require.NoError(t, cached.Create(ctx, customer))
+	found, err := cached.FindByCPF(ctx, "12345678900")
+	require.NoError(t, err)
+	assert.Equal(t, customer, found)
+}
+
+func TestCachedCustomerGateway_DoesNotCacheErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	cache := newMapCache()
+	cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+
+	mockGateway.EXPECT().FindByID(ctx, 1).Return(nil, errors.New("database error"))
+
+	_, err := cached.FindByID(ctx, 1)
+
+	assert.Error(t, err)
+	assert.Empty(t, cache.values)
+}
+
+func TestCachedCustomerGateway_Invalidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error
+	}{
+		{
+			name: "should invalidate on update",
+			write: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error {
+				customer := newTestCustomer()
+				mockGateway.EXPECT().Update(ctx, customer).Return(nil)
+				return cached.Update(ctx, customer)
+			},
+		},
+		{
+			name: "should invalidate on failed update",
+			write: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error {
+				customer := newTestCustomer()
+				mockGateway.EXPECT().Update(ctx, customer).Return(domain.NewPreconditionFailedError(domain.ErrVersionMismatch))
+				_ = cached.Update(ctx, customer)
+				return nil
+			},
+		},
+		{
+			name: "should invalidate the replaced CPF on anonymization",
+			write: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error {
+				customer := newTestCustomer()
+				customer.Anonymize()
+				mockGateway.EXPECT().FindByID(ctx, 1).Return(newTestCustomer(), nil)
+				mockGateway.EXPECT().Update(ctx, customer).Return(nil)
+				return cached.Update(ctx, customer)
+			},
+		},
+		{
+			name: "should invalidate on delete",
+			write: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error {
+				mockGateway.EXPECT().FindByID(ctx, 1).Return(newTestCustomer(), nil)
+				mockGateway.EXPECT().Delete(ctx, 1, 3).Return(nil)
+				return cached.Delete(ctx, 1, 3)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+			mockGateway := mockport.NewMockCustomerGateway(ctrl)
+			cache := newMapCache()
+			cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+
+			mockGateway.EXPECT().FindByID(ctx, 1).Return(newTestCustomer(), nil)
+			mockGateway.EXPECT().FindByCPF(ctx, "12345678900").Return(newTestCustomer(), nil)
+			_, _ = cached.FindByID(ctx, 1)
+			_, _ = cached.FindByCPF(ctx, "12345678900")
+			require.Len(t, cache.values, 2)
+
+			require.NoError(t, tt.write(ctx, mockGateway, cached))
+
+			assert.Empty(t, cache.values)
+		})
+	}
+}
+
+func TestCachedCustomerGateway_WritesDoNotFillTheCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error
+	}{
+		{
+			name: "should not cache the customer looked up by an anonymization",
+			write: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error {
+				customer := newTestCustomer()
+				customer.Anonymize()
+				mockGateway.EXPECT().FindByID(ctx, 1).Return(newTestCustomer(), nil)
+				mockGateway.EXPECT().Update(ctx, customer).Return(nil)
+				return cached.Update(ctx, customer)
+			},
+		},
+		{
+			name: "should not cache the customer looked up by a delete",
+			write: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, cached *gateway.CachedCustomerGateway) error {
+				mockGateway.EXPECT().FindByID(ctx, 1).Return(newTestCustomer(), nil)
+				mockGateway.EXPECT().Delete(ctx, 1, 3).Return(domain.NewPreconditionFailedError(domain.ErrVersionMismatch))
+				_ = cached.Delete(ctx, 1, 3)
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+			mockGateway := mockport.NewMockCustomerGateway(ctrl)
+			cache := newMapCache()
+			cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+
+			require.NoError(t, tt.write(ctx, mockGateway, cached))
+
+			assert.Empty(t, cache.values)
+			assert.Equal(t, gateway.CacheStats{}, cached.Stats())
+		})
+	}
+}
+
+func TestCachedCustomerGateway_CacheFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockCache := mockport.NewMockCache(ctrl)
+	cached := gateway.NewCachedCustomerGateway(mockGateway, mockCache, time.Minute, 5*time.Second)
+	customer := newTestCustomer()
+
+	mockCache.EXPECT().Get(ctx, "customer:id:1").Return(nil, false, errors.New("connection refused"))
+	mockGateway.EXPECT().FindByID(ctx, 1).Return(customer, nil)
+	mockCache.EXPECT().Set(ctx, "customer:id:1", gomock.Any(), time.Minute).Return(errors.New("connection refused"))
+
+	found, err := cached.FindByID(ctx, 1)
+
+	require.NoError(t, err, "a failing cache must not fail the lookup")
+	assert.Equal(t, customer, found)
+	assert.Equal(t, gateway.CacheStats{Misses: 1, Errors: 2}, cached.Stats())
+}
+
+func TestCachedCustomerGateway_FindByIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	cache := newMapCache()
+	cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+	customer := newTestCustomer()
+	other := newTestCustomer()
+	other.ID = 2
+
+	mockGateway.EXPECT().FindByID(ctx, 1).Return(customer, nil)
+	_, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+
+	// Only the IDs that aren't cached reach the wrapped gateway
+	mockGateway.EXPECT().FindByIDs(ctx, []int{2, 3}).Return([]*entity.Customer{other}, nil).Times(1)
+
+	found, err := cached.FindByIDs(ctx, []int{2, 1, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Customer{other, customer}, found)
+	assert.Equal(t, 5*time.Second, cache.ttls["customer:id:3"], "the missing customer must be cached as a negative entry")
+
+	found, err = cached.FindByIDs(ctx, []int{3, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Customer{other}, found)
+	assert.Equal(t, gateway.CacheStats{Hits: 3, Misses: 3}, cached.Stats())
+}
+
+func TestCachedCustomerGateway_SaveBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	cache := newMapCache()
+	cached := gateway.NewCachedCustomerGateway(mockGateway, cache, time.Minute, 5*time.Second)
+	existing := newTestCustomer()
+	created := newTestCustomer()
+	created.ID, created.CPF = 0, "98765432100"
+
+	mockGateway.EXPECT().FindByID(ctx, 1).Return(existing, nil)
+	mockGateway.EXPECT().FindByCPF(ctx, created.CPF).Return(nil, nil)
+	_, err := cached.FindByID(ctx, 1)
+	require.NoError(t, err)
+	_, err = cached.FindByCPF(ctx, created.CPF)
+	require.NoError(t, err)
+	require.Len(t, cache.values, 2)
+
+	// The entries are removed even when the batch fails, since it may have been partially written
+	mockGateway.EXPECT().
+		SaveBatch(ctx, []*entity.Customer{existing, created}).
+		DoAndReturn(func(_ context.Context, customers []*entity.Customer) error {
+			customers[1].ID = 2
+			return assert.AnError
+		})
+
+	err = cached.SaveBatch(ctx, []*entity.Customer{existing, created})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Empty(t, cache.values)
+}
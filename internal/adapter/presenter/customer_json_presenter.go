@@ -10,35 +10,94 @@ import (
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 )
 
-type customerJsonPresenter struct{}
+type customerJsonPresenter struct {
+	policy MaskingPolicy
+}
 
 // CustomerJsonResponse represents the response of a customer
 func NewCustomerJsonPresenter() port.Presenter {
-	return &customerJsonPresenter{}
+	return NewCustomerJsonPresenterWithPolicy(DefaultMaskingPolicy)
+}
+
+// NewCustomerJsonPresenterWithPolicy masks the personal data of the responses with the policy
+func NewCustomerJsonPresenterWithPolicy(policy MaskingPolicy) port.Presenter {
+	return &customerJsonPresenter{policy: policy}
 }
 
 // ToCustomerJsonResponse convert entity.Customer to CustomerJsonResponse
 func ToCustomerJsonResponse(customer *entity.Customer) CustomerJsonResponse {
-	return CustomerJsonResponse{
-		ID:        customer.ID,
-		Name:      customer.Name,
-		Email:     customer.Email,
-		CPF:       customer.CPF,
-		CreatedAt: customer.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: customer.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+	response := CustomerJsonResponse{
+		ID:           customer.ID,
+		Name:         customer.Name,
+		Email:        customer.Email,
+		CPF:          customer.CPF,
+		Version:      customer.Version,
+		CreatedAt:    customer.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:    customer.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		Status:       string(customer.Status),
+		StatusReason: customer.StatusReason,
+	}
+	if customer.StatusChangedAt != nil {
+		response.StatusChangedAt = customer.StatusChangedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	if customer.DeletedAt != nil {
+		response.DeletedAt = customer.DeletedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	if customer.AnonymizedAt != nil {
+		response.AnonymizedAt = customer.AnonymizedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	if consents := customer.CurrentConsents(); len(consents) > 0 {
+		response.Consents = ToCustomerJsonConsentResponses(consents)
+	}
+	return response
+}
+
+// ToCustomerJsonConsentResponses convert []entity.Consent to []CustomerJsonConsentResponse
+func ToCustomerJsonConsentResponses(consents []entity.Consent) []CustomerJsonConsentResponse {
+	responses := make([]CustomerJsonConsentResponse, len(consents))
+	for i, consent := range consents {
+		responses[i] = CustomerJsonConsentResponse{
+			Purpose:       string(consent.Purpose),
+			Granted:       consent.Granted,
+			PolicyVersion: consent.PolicyVersion,
+			Channel:       string(consent.Channel),
+			RecordedAt:    consent.RecordedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+	return responses
+}
+
+// ToCustomerJsonAuditEntryResponse convert entity.AuditEntry to CustomerJsonAuditEntryResponse
+func ToCustomerJsonAuditEntryResponse(entry *entity.AuditEntry) CustomerJsonAuditEntryResponse {
+	response := CustomerJsonAuditEntryResponse{
+		ID:         entry.ID,
+		CustomerID: entry.CustomerID,
+		Action:     string(entry.Action),
+		Actor:      entry.Actor,
+		ActorRole:  string(entry.ActorRole),
+		RequestID:  entry.RequestID,
+		Changes:    make([]CustomerJsonAuditChangeResponse, len(entry.Changes)),
+		OccurredAt: entry.OccurredAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if response.Actor == "" {
+		response.Actor = "anonymous"
 	}
+	for i, change := range entry.Changes {
+		response.Changes[i] = CustomerJsonAuditChangeResponse(change)
+	}
+	return response
 }
 
 // Present write the response to the client
 func (p *customerJsonPresenter) Present(pp dto.PresenterInput) ([]byte, error) {
 	switch v := pp.Result.(type) {
 	case *entity.Customer:
-		output := ToCustomerJsonResponse(v)
+		output := ToCustomerJsonResponse(p.policy.Customer(pp.Audience, v))
 		return json.Marshal(output)
 	case []*entity.Customer:
 		customerOutputs := make([]CustomerJsonResponse, len(v))
 		for i, customer := range v {
-			customerOutputs[i] = ToCustomerJsonResponse(customer)
+			customerOutputs[i] = ToCustomerJsonResponse(p.policy.Customer(pp.Audience, customer))
 		}
 
 		output := &CustomerJsonPaginatedResponse{
@@ -51,6 +110,66 @@ func (p *customerJsonPresenter) Present(pp dto.PresenterInput) ([]byte, error) {
 		}
 
 		return json.Marshal(output)
+	case *dto.BatchGetCustomersOutput:
+		customerOutputs := make([]CustomerJsonResponse, len(v.Customers))
+		for i, customer := range v.Customers {
+			customerOutputs[i] = ToCustomerJsonResponse(p.policy.Customer(pp.Audience, customer))
+		}
+
+		return json.Marshal(&CustomerJsonBatchResponse{
+			Customers:  customerOutputs,
+			MissingIDs: v.MissingIDs,
+		})
+	case []*entity.AuditEntry:
+		entries := make([]CustomerJsonAuditEntryResponse, len(v))
+		for i, entry := range v {
+			entries[i] = ToCustomerJsonAuditEntryResponse(p.policy.AuditEntry(pp.Audience, entry))
+		}
+
+		return json.Marshal(&CustomerJsonHistoryResponse{Entries: entries})
+	case *dto.CustomerDataExport:
+		entries := make([]CustomerJsonAuditEntryResponse, len(v.History))
+		for i, entry := range v.History {
+			entries[i] = ToCustomerJsonAuditEntryResponse(p.policy.AuditEntry(pp.Audience, entry))
+		}
+
+		return json.Marshal(&CustomerJsonDataExportResponse{
+			ExportedAt: v.ExportedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+			Customer:   ToCustomerJsonResponse(p.policy.Customer(pp.Audience, v.Customer)),
+			Consents:   ToCustomerJsonConsentResponses(v.Customer.Consents),
+			History:    entries,
+		})
+	case *dto.CustomerConsents:
+		return json.Marshal(&CustomerJsonConsentsResponse{
+			ID:       v.Customer.ID,
+			Version:  v.Customer.Version,
+			Consents: ToCustomerJsonConsentResponses(v.Customer.CurrentConsents()),
+			History:  ToCustomerJsonConsentResponses(v.Customer.Consents),
+		})
+	case *dto.ImportCustomersReport:
+		rows := make([]CustomerJsonImportRowResponse, len(v.Rows))
+		for i, row := range v.Rows {
+			rows[i] = CustomerJsonImportRowResponse(row)
+		}
+
+		return json.Marshal(&CustomerJsonImportReportResponse{
+			Total:   v.Total,
+			Created: v.Created,
+			Updated: v.Updated,
+			Skipped: v.Skipped,
+			Failed:  v.Failed,
+			Rows:    rows,
+		})
+	case *dto.ExportCustomersOutput:
+		fields := make([]string, len(v.Fields))
+		for i, field := range v.Fields {
+			fields[i] = string(field)
+		}
+
+		return json.Marshal(&CustomerJsonExportResponse{
+			Fields: fields,
+			Count:  v.Count,
+		})
 	default:
 		return nil, domain.NewInternalError(errors.New(domain.ErrInternalError))
 	}
@@ -7,8 +7,19 @@ type CustomerJsonResponse struct {
 	Name      string `json:"name" example:"John Doe"`
 	Email     string `json:"email" example:"john.doe@email.com"`
 	CPF       string `json:"cpf" example:"123.456.789-00"`
+	Version   int    `json:"version" example:"1"`
 	CreatedAt string `json:"created_at" example:"2024-02-09T10:00:00Z"`
 	UpdatedAt string `json:"updated_at" example:"2024-02-09T10:00:00Z"`
+	Status    string `json:"status" example:"active"`
+	// StatusReason and StatusChangedAt are only present once an admin changed the status
+	StatusReason    string `json:"status_reason,omitempty" example:"chargeback under review"`
+	StatusChangedAt string `json:"status_changed_at,omitempty" example:"2024-02-09T10:00:00Z"`
+	// DeletedAt is only present on deleted customers
+	DeletedAt string `json:"deleted_at,omitempty" example:"2024-02-09T10:00:00Z"`
+	// AnonymizedAt is only present on anonymized customers, whose name, email and CPF are tokens
+	AnonymizedAt string `json:"anonymized_at,omitempty" example:"2024-02-09T10:00:00Z"`
+	// Consents is the current consent of each purpose the customer decided on, a missing purpose isn't granted
+	Consents []CustomerJsonConsentResponse `json:"consents,omitempty"`
 }
 
 func (r CustomerJsonResponse) String() string {
@@ -19,6 +30,86 @@ func (r CustomerJsonResponse) String() string {
 	return string(o)
 }
 
+// CustomerJsonBatchResponse has the customers found by a batch get and the requested IDs without a customer
+type CustomerJsonBatchResponse struct {
+	Customers  []CustomerJsonResponse `json:"customers"`
+	MissingIDs []int                  `json:"missing_ids" example:"7,9"`
+}
+
+// CustomerJsonImportReportResponse counts the rows of an import by status and has the result of each row
+type CustomerJsonImportReportResponse struct {
+	Total   int                             `json:"total" example:"3"`
+	Created int                             `json:"created" example:"1"`
+	Updated int                             `json:"updated" example:"0"`
+	Skipped int                             `json:"skipped" example:"1"`
+	Failed  int                             `json:"failed" example:"1"`
+	Rows    []CustomerJsonImportRowResponse `json:"rows"`
+}
+
+type CustomerJsonImportRowResponse struct {
+	Line       int    `json:"line" example:"2"`
+	CPF        string `json:"cpf,omitempty" example:"123.456.789-00"`
+	Status     string `json:"status" example:"created"`
+	CustomerID int    `json:"customer_id,omitempty" example:"1"`
+	Error      string `json:"error,omitempty" example:"invalid email"`
+}
+
+// CustomerJsonExportResponse has the fields written for each customer of an export and how many were written
+type CustomerJsonExportResponse struct {
+	Fields []string `json:"fields" example:"id,name,email"`
+	Count  int      `json:"count" example:"1500"`
+}
+
+// CustomerJsonHistoryResponse has the audit entries of a customer, oldest first
+type CustomerJsonHistoryResponse struct {
+	Entries []CustomerJsonAuditEntryResponse `json:"entries"`
+}
+
+// CustomerJsonAuditEntryResponse is a change made to a customer. Actor is "anonymous" when the request had no token
+type CustomerJsonAuditEntryResponse struct {
+	ID         string                            `json:"id" example:"0b5e8a9c-4f0e-4f3b-9d1a-2c7e6f1d8b3a"`
+	CustomerID int                               `json:"customer_id" example:"1"`
+	Action     string                            `json:"action" example:"update"`
+	Actor      string                            `json:"actor" example:"admin@example.com"`
+	ActorRole  string                            `json:"actor_role,omitempty" example:"admin"`
+	RequestID  string                            `json:"request_id,omitempty" example:"c6af9ac6-7b61-11e6-9a41-93e8deadbeef"`
+	Changes    []CustomerJsonAuditChangeResponse `json:"changes"`
+	OccurredAt string                            `json:"occurred_at" example:"2024-02-09T10:00:00Z"`
+}
+
+// CustomerJsonAuditChangeResponse is the value of a field before and after a change, empty when it had none
+type CustomerJsonAuditChangeResponse struct {
+	Field  string `json:"field" example:"email"`
+	Before string `json:"before" example:"john.doe@email.com"`
+	After  string `json:"after" example:"john@email.com"`
+}
+
+// CustomerJsonDataExportResponse is the package of a data export, with everything the service holds about a customer
+type CustomerJsonDataExportResponse struct {
+	ExportedAt string                           `json:"exported_at" example:"2024-02-09T10:00:00Z"`
+	Customer   CustomerJsonResponse             `json:"customer"`
+	Consents   []CustomerJsonConsentResponse    `json:"consents"`
+	History    []CustomerJsonAuditEntryResponse `json:"history"`
+}
+
+// CustomerJsonConsentResponse is a customer granting or withdrawing a purpose under a version of the privacy policy
+type CustomerJsonConsentResponse struct {
+	Purpose       string `json:"purpose" example:"marketing"`
+	Granted       bool   `json:"granted" example:"true"`
+	PolicyVersion string `json:"policy_version" example:"2024-01"`
+	Channel       string `json:"channel" example:"web"`
+	RecordedAt    string `json:"recorded_at" example:"2024-02-09T10:00:00Z"`
+}
+
+// CustomerJsonConsentsResponse has the current consent of each purpose of a customer and their history, oldest first.
+// ID and Version are the customer's, so the response carries its ETag
+type CustomerJsonConsentsResponse struct {
+	ID       int                           `json:"id" example:"1"`
+	Version  int                           `json:"version" example:"3"`
+	Consents []CustomerJsonConsentResponse `json:"consents"`
+	History  []CustomerJsonConsentResponse `json:"history"`
+}
+
 type CustomerJsonPaginatedResponse struct {
 	JsonPagination
 	Customers []CustomerJsonResponse `json:"customers"`
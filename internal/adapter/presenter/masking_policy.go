@@ -0,0 +1,74 @@
+package presenter
+
+import (
+	"slices"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+// MaskingPolicy decides, by route and role, whether the responses show the CPF and email of the customers in
+// full or masked, as in ***.456.789-** and j***@email.com. A customer always sees its own data in full, and
+// the anonymized customers are shown as they are, since their tokens aren't personal data
+type MaskingPolicy struct {
+	// Routes has the roles that see the full data on a route, keyed by its method and resource as in
+	// "GET /customers". The routes without an entry use DefaultFullAccess
+	Routes            map[string][]entity.Role
+	DefaultFullAccess []entity.Role
+}
+
+// DefaultMaskingPolicy shows the full data to the admins only, the lists included
+var DefaultMaskingPolicy = MaskingPolicy{
+	Routes: map[string][]entity.Role{
+		"GET /customers":           {entity.RoleAdmin},
+		"POST /customers:batchGet": {entity.RoleAdmin},
+	},
+	DefaultFullAccess: []entity.Role{entity.RoleAdmin},
+}
+
+// showsFull tells whether the audience sees the full data of the customer. Outside of a request, like in the
+// maintenance commands, nothing is masked
+func (p MaskingPolicy) showsFull(audience dto.Audience, customerID int) bool {
+	if audience.Route == "" || audience.Principal.IsCustomer(customerID) {
+		return true
+	}
+
+	roles, ok := p.Routes[audience.Route]
+	if !ok {
+		roles = p.DefaultFullAccess
+	}
+	return audience.Principal != nil && slices.Contains(roles, audience.Principal.Role)
+}
+
+// Customer returns the customer as the audience sees it, a masked copy when it can't see the full data
+func (p MaskingPolicy) Customer(audience dto.Audience, customer *entity.Customer) *entity.Customer {
+	if customer.IsAnonymized() || p.showsFull(audience, customer.ID) {
+		return customer
+	}
+
+	masked := *customer
+	masked.Email = entity.MaskEmail(customer.Email)
+	masked.CPF = entity.MaskCPF(customer.CPF)
+	return &masked
+}
+
+// AuditEntry returns the audit entry as the audience sees it, with the CPF and email changes masked when it
+// can't see the full data
+func (p MaskingPolicy) AuditEntry(audience dto.Audience, entry *entity.AuditEntry) *entity.AuditEntry {
+	if p.showsFull(audience, entry.CustomerID) {
+		return entry
+	}
+
+	masked := *entry
+	masked.Changes = make([]entity.AuditChange, len(entry.Changes))
+	for i, change := range entry.Changes {
+		switch change.Field {
+		case "email":
+			change.Before, change.After = entity.MaskEmail(change.Before), entity.MaskEmail(change.After)
+		case "cpf":
+			change.Before, change.After = entity.MaskCPF(change.Before), entity.MaskCPF(change.After)
+		}
+		masked.Changes[i] = change
+	}
+	return &masked
+}
@@ -0,0 +1,111 @@
+package presenter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+func TestCustomerJsonPresenter_Masking(t *testing.T) {
+	customer := &entity.Customer{ID: 1, Name: "John Doe", Email: "john.doe@email.com", CPF: "123.456.789-00"}
+	admin := &entity.Principal{Subject: "admin@example.com", Role: entity.RoleAdmin}
+	self := &entity.Principal{Subject: "1", Role: entity.RoleCustomer}
+	other := &entity.Principal{Subject: "2", Role: entity.RoleCustomer}
+
+	tests := []struct {
+		name       string
+		audience   dto.Audience
+		wantMasked bool
+	}{
+		{name: "should show the customer in full to itself", audience: dto.Audience{Principal: self, Route: "GET /customers/{id}"}},
+		{name: "should show the customer in full to an admin", audience: dto.Audience{Principal: admin, Route: "GET /customers/{id}"}},
+		{name: "should mask the customer to another customer", audience: dto.Audience{Principal: other, Route: "GET /customers/{id}"}, wantMasked: true},
+		{name: "should mask the customer to an anonymous caller", audience: dto.Audience{Route: "GET /customers"}, wantMasked: true},
+		{name: "should show the lists in full to an admin", audience: dto.Audience{Principal: admin, Route: "GET /customers"}},
+		{name: "should mask the lists to another customer", audience: dto.Audience{Principal: other, Route: "GET /customers"}, wantMasked: true},
+		{name: "should show the lists in full to the customer itself", audience: dto.Audience{Principal: self, Route: "GET /customers"}},
+		{name: "should show the customer in full outside of a request", audience: dto.Audience{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := NewCustomerJsonPresenter().Present(dto.PresenterInput{Result: customer, Audience: tt.audience})
+			require.NoError(t, err)
+
+			var response CustomerJsonResponse
+			require.NoError(t, json.Unmarshal(body, &response))
+			assert.Equal(t, "John Doe", response.Name)
+			if tt.wantMasked {
+				assert.Equal(t, "j***@email.com", response.Email)
+				assert.Equal(t, "***.456.789-**", response.CPF)
+			} else {
+				assert.Equal(t, "john.doe@email.com", response.Email)
+				assert.Equal(t, "123.456.789-00", response.CPF)
+			}
+		})
+	}
+
+	t.Run("should mask each customer of a batch get", func(t *testing.T) {
+		body, err := NewCustomerJsonPresenter().Present(dto.PresenterInput{
+			Result:   &dto.BatchGetCustomersOutput{Customers: []*entity.Customer{customer}},
+			Audience: dto.Audience{Principal: other, Route: "POST /customers:batchGet"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"cpf":"***.456.789-**"`)
+		assert.NotContains(t, string(body), "123.456.789-00")
+	})
+
+	t.Run("should show a batch get in full to an admin", func(t *testing.T) {
+		body, err := NewCustomerJsonPresenter().Present(dto.PresenterInput{
+			Result:   &dto.BatchGetCustomersOutput{Customers: []*entity.Customer{customer}},
+			Audience: dto.Audience{Principal: admin, Route: "POST /customers:batchGet"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"cpf":"123.456.789-00"`)
+	})
+
+	t.Run("should mask the CPF and email changes of the history", func(t *testing.T) {
+		policy := MaskingPolicy{DefaultFullAccess: []entity.Role{}}
+		entry := &entity.AuditEntry{CustomerID: 1, Changes: []entity.AuditChange{
+			{Field: "name", Before: "John Doe", After: "John Smith"},
+			{Field: "email", Before: "john.doe@email.com", After: "john.smith@email.com"},
+			{Field: "cpf", Before: "", After: "123.456.789-00"},
+		}}
+
+		body, err := NewCustomerJsonPresenterWithPolicy(policy).Present(dto.PresenterInput{
+			Result:   []*entity.AuditEntry{entry},
+			Audience: dto.Audience{Principal: admin, Route: "GET /customers/{id}/history"},
+		})
+		require.NoError(t, err)
+
+		var response CustomerJsonHistoryResponse
+		require.NoError(t, json.Unmarshal(body, &response))
+		assert.Equal(t, []CustomerJsonAuditChangeResponse{
+			{Field: "name", Before: "John Doe", After: "John Smith"},
+			{Field: "email", Before: "j***@email.com", After: "j***@email.com"},
+			{Field: "cpf", Before: "", After: "***.456.789-**"},
+		}, response.Entries[0].Changes)
+		assert.Equal(t, "john.doe@email.com", entry.Changes[1].Before, "the entry must not be changed")
+	})
+
+	t.Run("should show the tokens of an anonymized customer", func(t *testing.T) {
+		anonymized := *customer
+		anonymized.Anonymize()
+
+		body, err := NewCustomerJsonPresenter().Present(dto.PresenterInput{
+			Result:   &anonymized,
+			Audience: dto.Audience{Principal: other, Route: "GET /customers/{id}"},
+		})
+		require.NoError(t, err)
+
+		var response CustomerJsonResponse
+		require.NoError(t, json.Unmarshal(body, &response))
+		assert.Equal(t, anonymized.Email, response.Email)
+		assert.Equal(t, anonymized.CPF, response.CPF)
+	})
+}
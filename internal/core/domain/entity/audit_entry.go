@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAction is the kind of change recorded by an audit entry
+type AuditAction string
+
+const (
+	AuditActionCreate       AuditAction = "create"
+	AuditActionUpdate       AuditAction = "update"
+	AuditActionDelete       AuditAction = "delete"
+	AuditActionRestore      AuditAction = "restore"
+	AuditActionChangeStatus AuditAction = "change_status"
+	AuditActionImport       AuditAction = "import"
+	AuditActionAnonymize    AuditAction = "anonymize"
+	AuditActionConsents     AuditAction = "consents"
+)
+
+// AuditErasedValue replaces the personal data erased by an anonymization in its audit entry, so the entry
+// doesn't keep what was erased
+const AuditErasedValue = "[erased]"
+
+// AuditChange is a field of a customer changed by an action, with its value before and after it.
+// A field without a value, like the deletion time of a customer that isn't deleted, is empty
+type AuditChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// AuditEntry records a change made to a customer, who made it and in which request. Entries are
+// only appended and never removed. The only change they take is the erasure of the personal data
+// they recorded, when their customer is anonymized
+type AuditEntry struct {
+	ID         string
+	CustomerID int
+	Action     AuditAction
+	// Actor is the subject of the caller, empty when the request was anonymous
+	Actor      string
+	ActorRole  Role
+	RequestID  string
+	Changes    []AuditChange
+	OccurredAt time.Time
+}
+
+// auditedFields are the customer fields compared by the audit entries, in the order they're reported
+var auditedFields = []struct {
+	name  string
+	value func(*Customer) string
+}{
+	{"name", func(c *Customer) string { return c.Name }},
+	{"email", func(c *Customer) string { return c.Email }},
+	{"cpf", func(c *Customer) string { return c.CPF }},
+	{"status", func(c *Customer) string { return string(c.Status) }},
+	{"status_reason", func(c *Customer) string { return c.StatusReason }},
+	{"deleted_at", func(c *Customer) string { return formatAuditTime(c.DeletedAt) }},
+	{"anonymized_at", func(c *Customer) string { return formatAuditTime(c.AnonymizedAt) }},
+	{"consent_marketing", func(c *Customer) string { return formatAuditConsent(c.CurrentConsent(ConsentPurposeMarketing)) }},
+	{"consent_data_sharing", func(c *Customer) string { return formatAuditConsent(c.CurrentConsent(ConsentPurposeDataSharing)) }},
+}
+
+// erasedFields are the audited fields holding personal data, erased by an anonymization
+var erasedFields = map[string]bool{"name": true, "email": true, "cpf": true}
+
+// NewAuditEntry records an action on a customer by the caller of the request in the context. before is
+// nil when the action created the customer. The entry of an anonymization leaves out the erased values
+func NewAuditEntry(ctx context.Context, action AuditAction, before, after *Customer) *AuditEntry {
+	entry := &AuditEntry{
+		CustomerID: after.ID,
+		Action:     action,
+		RequestID:  RequestIDFromContext(ctx),
+		Changes:    DiffCustomers(before, after),
+		OccurredAt: time.Now(),
+	}
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		entry.Actor, entry.ActorRole = principal.Subject, principal.Role
+	}
+	if action == AuditActionAnonymize {
+		for i, change := range entry.Changes {
+			if erasedFields[change.Field] {
+				entry.Changes[i].Before = AuditErasedValue
+			}
+		}
+	}
+	return entry
+}
+
+// ErasePersonalData replaces the name, email and CPF recorded by the entry with AuditErasedValue, so the
+// entries written before an anonymization don't keep what it erased. It tells whether anything was erased
+func (e *AuditEntry) ErasePersonalData() bool {
+	erased := false
+	for i, change := range e.Changes {
+		if !erasedFields[change.Field] {
+			continue
+		}
+		if change.Before != "" && change.Before != AuditErasedValue {
+			e.Changes[i].Before, erased = AuditErasedValue, true
+		}
+		if change.After != "" && change.After != AuditErasedValue && e.Action != AuditActionAnonymize {
+			e.Changes[i].After, erased = AuditErasedValue, true
+		}
+	}
+	return erased
+}
+
+// DiffCustomers returns the audited fields whose value differs between two versions of a customer.
+// A nil customer has no value in any field
+func DiffCustomers(before, after *Customer) []AuditChange {
+	changes := make([]AuditChange, 0)
+	for _, field := range auditedFields {
+		var old, current string
+		if before != nil {
+			old = field.value(before)
+		}
+		if after != nil {
+			current = field.value(after)
+		}
+		if old != current {
+			changes = append(changes, AuditChange{Field: field.name, Before: old, After: current})
+		}
+	}
+	return changes
+}
+
+func formatAuditTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatAuditConsent describes the state of a consent with its policy version, as in "granted (policy 2.1)"
+func formatAuditConsent(consent *Consent) string {
+	if consent == nil {
+		return ""
+	}
+	state := "withdrawn"
+	if consent.Granted {
+		state = "granted"
+	}
+	return state + " (policy " + consent.PolicyVersion + ")"
+}
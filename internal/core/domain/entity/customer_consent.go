@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+)
+
+// ConsentPurpose is a use of the customer data that needs the customer's consent
+type ConsentPurpose string
+
+const (
+	// ConsentPurposeMarketing is sending marketing emails to the customer
+	ConsentPurposeMarketing ConsentPurpose = "marketing"
+	// ConsentPurposeDataSharing is sharing the customer data with partners
+	ConsentPurposeDataSharing ConsentPurpose = "data_sharing"
+)
+
+// consentPurposes are the purposes a consent can be given to, in the order they're reported
+var consentPurposes = []ConsentPurpose{ConsentPurposeMarketing, ConsentPurposeDataSharing}
+
+// ConsentChannel is where the customer gave or withdrew a consent
+type ConsentChannel string
+
+const (
+	ConsentChannelWeb   ConsentChannel = "web"
+	ConsentChannelApp   ConsentChannel = "app"
+	ConsentChannelEmail ConsentChannel = "email"
+	ConsentChannelPhone ConsentChannel = "phone"
+	ConsentChannelStore ConsentChannel = "store"
+)
+
+var consentChannels = []ConsentChannel{
+	ConsentChannelWeb, ConsentChannelApp, ConsentChannelEmail, ConsentChannelPhone, ConsentChannelStore,
+}
+
+// Consent records the customer granting or withdrawing a purpose, under a version of the privacy policy.
+// The consents of a customer are only appended, the latest of each purpose is its current state
+type Consent struct {
+	Purpose       ConsentPurpose
+	Granted       bool
+	PolicyVersion string
+	Channel       ConsentChannel
+	RecordedAt    time.Time
+}
+
+// ParseConsentPurpose reads a purpose, ignoring case
+func ParseConsentPurpose(value string) (ConsentPurpose, error) {
+	purpose := ConsentPurpose(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range consentPurposes {
+		if purpose == known {
+			return purpose, nil
+		}
+	}
+	return "", domain.NewValidationError(errors.New(domain.ErrInvalidConsentPurpose))
+}
+
+// ParseConsentChannel reads a channel, ignoring case
+func ParseConsentChannel(value string) (ConsentChannel, error) {
+	channel := ConsentChannel(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range consentChannels {
+		if channel == known {
+			return channel, nil
+		}
+	}
+	return "", domain.NewValidationError(errors.New(domain.ErrInvalidConsentChannel))
+}
+
+// RecordConsent appends the customer's decision on a purpose to its consents
+func (p *Customer) RecordConsent(purpose ConsentPurpose, granted bool, policyVersion string, channel ConsentChannel) error {
+	policyVersion = strings.TrimSpace(policyVersion)
+	if policyVersion == "" {
+		return domain.NewValidationError(errors.New(domain.ErrPolicyVersionIsMandatory))
+	}
+
+	now := time.Now()
+	p.Consents = append(p.Consents, Consent{
+		Purpose:       purpose,
+		Granted:       granted,
+		PolicyVersion: policyVersion,
+		Channel:       channel,
+		RecordedAt:    now,
+	})
+	p.UpdatedAt = now
+	return nil
+}
+
+// CurrentConsents returns the latest consent of each purpose. A purpose the customer never decided on
+// is left out, and must be handled as not granted
+func (p *Customer) CurrentConsents() []Consent {
+	current := make([]Consent, 0, len(consentPurposes))
+	for _, purpose := range consentPurposes {
+		if consent := p.CurrentConsent(purpose); consent != nil {
+			current = append(current, *consent)
+		}
+	}
+	return current
+}
+
+// CurrentConsent returns the latest consent of a purpose, nil when the customer never decided on it
+func (p *Customer) CurrentConsent(purpose ConsentPurpose) *Consent {
+	for i := len(p.Consents) - 1; i >= 0; i-- {
+		if p.Consents[i].Purpose == purpose {
+			return &p.Consents[i]
+		}
+	}
+	return nil
+}
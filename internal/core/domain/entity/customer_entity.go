@@ -1,7 +1,24 @@
 package entity
 
 import (
+	"crypto/rand"
+	"errors"
+	"net/mail"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+)
+
+const (
+	// anonymizedPrefix starts the tokens of an anonymized customer, so a CPF token is never a valid CPF and
+	// can't take the CPF of another customer
+	anonymizedPrefix = "anon-"
+	// anonymizedEmailDomain is the reserved domain of the email tokens, which can't receive messages
+	anonymizedEmailDomain  = "anonymized.invalid"
+	anonymizedStatusReason = "personal data erased on request"
 )
 
 type Customer struct {
@@ -9,8 +26,21 @@ type Customer struct {
 	Name      string
 	Email     string
 	CPF       string
+	Version   int
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// DeletedAt is when the customer was deleted. A deleted customer is kept, so it can be restored,
+	// until the retention period of the data source purges it
+	DeletedAt *time.Time
+	Status    CustomerStatus
+	// StatusReason is why an admin gave the customer its status, empty for new customers
+	StatusReason    string
+	StatusChangedAt *time.Time
+	// AnonymizedAt is when the personal data of the customer was erased. An anonymized customer keeps
+	// its ID, so the records of other services still reference it, but can't be changed anymore
+	AnonymizedAt *time.Time
+	// Consents are the decisions of the customer on the uses of its data, oldest first
+	Consents []Consent
 }
 
 func (p *Customer) Update(name string, email string) {
@@ -18,3 +48,169 @@ func (p *Customer) Update(name string, email string) {
 	p.Email = email
 	p.UpdatedAt = time.Now()
 }
+
+// IsDeleted tells whether the customer was deleted and not restored
+func (p *Customer) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// Delete marks the customer as deleted
+func (p *Customer) Delete() {
+	now := time.Now()
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+}
+
+// Restore undoes the deletion of the customer
+func (p *Customer) Restore() {
+	p.DeletedAt = nil
+	p.UpdatedAt = time.Now()
+}
+
+// IsAnonymized tells whether the personal data of the customer was erased
+func (p *Customer) IsAnonymized() bool {
+	return p.AnonymizedAt != nil
+}
+
+// Anonymize erases the personal data of the customer for good: its name, email and CPF are replaced by
+// random tokens, which can't be traced back to them, and its account is closed
+func (p *Customer) Anonymize() {
+	now := time.Now()
+	p.Name = anonymizedPrefix + rand.Text()
+	p.Email = anonymizedPrefix + strings.ToLower(rand.Text()) + "@" + anonymizedEmailDomain
+	p.CPF = anonymizedPrefix + rand.Text()
+	p.Status = CustomerStatusClosed
+	p.StatusReason = anonymizedStatusReason
+	p.StatusChangedAt = &now
+	p.AnonymizedAt = &now
+	p.UpdatedAt = now
+}
+
+// Validate checks the customer against the domain rules, normalizing its email and its CPF first, so the same
+// email or CPF is always stored, looked up and kept unique in the same form
+func (p *Customer) Validate() error {
+	p.Email = NormalizeEmail(p.Email)
+	p.CPF = NormalizeCPF(p.CPF)
+
+	if strings.TrimSpace(p.Name) == "" {
+		return domain.NewValidationError(errors.New(domain.ErrNameIsMandatory))
+	}
+
+	if p.Email == "" {
+		return domain.NewValidationError(errors.New(domain.ErrEmailIsMandatory))
+	}
+	if address, err := mail.ParseAddress(p.Email); err != nil || address.Address != p.Email {
+		return domain.NewValidationError(errors.New(domain.ErrInvalidEmail))
+	}
+
+	if p.CPF == "" {
+		return domain.NewValidationError(errors.New(domain.ErrCPFIsMandatory))
+	}
+	if !isValidCPF(p.CPF) {
+		return domain.NewValidationError(errors.New(domain.ErrInvalidCPF))
+	}
+
+	return nil
+}
+
+// NormalizeEmail trims an email and lowercases it, since the emails of the customers are unique regardless of
+// their case
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeCPF returns the digits of a CPF written with or without the usual punctuation (123.456.789-09 or
+// 12345678909). Any other value is returned as it is, trimmed, and fails the validation
+func NormalizeCPF(cpf string) string {
+	cpf = strings.TrimSpace(cpf)
+	digits := make([]rune, 0, 11)
+	for _, r := range cpf {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, r)
+		case r == '.' || r == '-':
+		default:
+			return cpf
+		}
+	}
+	return string(digits)
+}
+
+// isValidCPF checks that a normalized CPF has 11 digits, not all the same, and that its last two digits are
+// the check digits of the others
+func isValidCPF(cpf string) bool {
+	if len(cpf) != 11 || strings.Count(cpf, cpf[:1]) == 11 {
+		return false
+	}
+	for _, r := range cpf {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return cpf[9] == cpfCheckDigit(cpf[:9]) && cpf[10] == cpfCheckDigit(cpf[:10])
+}
+
+// cpfCheckDigit computes the check digit that follows the digits, weighting them from len(digits)+1 down to 2
+func cpfCheckDigit(digits string) byte {
+	sum := 0
+	for i := range len(digits) {
+		sum += int(digits[i]-'0') * (len(digits) + 1 - i)
+	}
+	digit := sum * 10 % 11
+	if digit == 10 {
+		digit = 0
+	}
+	return byte('0' + digit)
+}
+
+// Masked returns a copy of the customer with its name, email and CPF masked, for the uses that
+// don't need the personal data
+func (p *Customer) Masked() *Customer {
+	masked := *p
+	masked.Name = MaskName(p.Name)
+	masked.Email = MaskEmail(p.Email)
+	masked.CPF = MaskCPF(p.CPF)
+	return &masked
+}
+
+// MaskName keeps the first letter of each word of a name, as in J*** D***
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, _ := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + "***"
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskEmail keeps the first letter of an email and its domain, as in j***@email.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return maskAll(email)
+	}
+	first, _ := utf8.DecodeRuneInString(email)
+	return string(first) + "***" + email[at:]
+}
+
+// MaskCPF keeps the middle digits of a CPF, as in ***.456.789-**
+func MaskCPF(cpf string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, cpf)
+	if len(digits) != 11 {
+		return maskAll(cpf)
+	}
+	return "***." + digits[3:6] + "." + digits[6:9] + "-**"
+}
+
+// maskAll hides a value that doesn't have the expected format, keeping only whether it's empty
+func maskAll(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "***"
+}
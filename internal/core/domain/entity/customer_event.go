@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// CustomerEventType is what happened to a customer, as told to the other services
+type CustomerEventType string
+
+const (
+	// CustomerEventAnonymized tells that the personal data of a customer was erased, so the other services
+	// erase their copies too
+	CustomerEventAnonymized CustomerEventType = "customer.anonymized"
+)
+
+// CustomerEvent is a domain event about a customer, published for the services downstream. It only
+// carries the customer ID, never its personal data
+type CustomerEvent struct {
+	Type       CustomerEventType
+	CustomerID int
+	RequestID  string
+	OccurredAt time.Time
+}
+
+// NewCustomerEvent returns an event about a customer, raised by the request in the context
+func NewCustomerEvent(ctx context.Context, eventType CustomerEventType, customerID int) *CustomerEvent {
+	return &CustomerEvent{
+		Type:       eventType,
+		CustomerID: customerID,
+		RequestID:  RequestIDFromContext(ctx),
+		OccurredAt: time.Now(),
+	}
+}
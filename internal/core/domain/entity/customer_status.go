@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+)
+
+// CustomerStatus is the state of a customer account. Only active customers can authenticate
+type CustomerStatus string
+
+const (
+	// CustomerStatusPending is an account waiting to be activated
+	CustomerStatusPending CustomerStatus = "pending"
+	// CustomerStatusActive is an account in good standing, the status of new customers
+	CustomerStatusActive CustomerStatus = "active"
+	// CustomerStatusSuspended is an account put on hold for a while, e.g. during a dispute
+	CustomerStatusSuspended CustomerStatus = "suspended"
+	// CustomerStatusBlocked is an account refused for misuse, e.g. fraud
+	CustomerStatusBlocked CustomerStatus = "blocked"
+	// CustomerStatusClosed is an account closed for good
+	CustomerStatusClosed CustomerStatus = "closed"
+)
+
+// customerStatusTransitions are the statuses each status can change to. A closed account can't be reopened
+var customerStatusTransitions = map[CustomerStatus][]CustomerStatus{
+	CustomerStatusPending:   {CustomerStatusActive, CustomerStatusBlocked, CustomerStatusClosed},
+	CustomerStatusActive:    {CustomerStatusSuspended, CustomerStatusBlocked, CustomerStatusClosed},
+	CustomerStatusSuspended: {CustomerStatusActive, CustomerStatusBlocked, CustomerStatusClosed},
+	CustomerStatusBlocked:   {CustomerStatusActive, CustomerStatusClosed},
+	CustomerStatusClosed:    {},
+}
+
+// ParseCustomerStatus reads a status, ignoring case
+func ParseCustomerStatus(value string) (CustomerStatus, error) {
+	status := CustomerStatus(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := customerStatusTransitions[status]; !ok {
+		return "", domain.NewValidationError(errors.New(domain.ErrInvalidCustomerStatus))
+	}
+	return status, nil
+}
+
+// CanChangeTo tells whether the status may change to next
+func (s CustomerStatus) CanChangeTo(next CustomerStatus) bool {
+	for _, allowed := range customerStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive tells whether the customer can authenticate
+func (p *Customer) IsActive() bool {
+	return p.Status == CustomerStatusActive
+}
+
+// ChangeStatus moves the customer to another status, recording why. It fails when the
+// current status can't change to the new one
+func (p *Customer) ChangeStatus(status CustomerStatus, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return domain.NewValidationError(errors.New(domain.ErrStatusReasonIsMandatory))
+	}
+	if !p.Status.CanChangeTo(status) {
+		return domain.NewConflictError(domain.ErrCustomerInvalidStatusTransition)
+	}
+
+	now := time.Now()
+	p.Status = status
+	p.StatusReason = reason
+	p.StatusChangedAt = &now
+	p.UpdatedAt = now
+	return nil
+}
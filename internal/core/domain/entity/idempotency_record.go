@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// IdempotencyRecord remembers the response given to a request sent with an Idempotency-Key header,
+// so retries of the same request are answered with it instead of running the request again
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// LeaseID identifies the request that reserved the key, so only that request completes or releases the
+	// record, and not one that took the reservation over after it expired
+	LeaseID    string
+	Completed  bool
+	StatusCode int
+	Headers    map[string]string
+	Body       string
+	// CustomerID is the customer presented by the body, so the record is purged when it's anonymized
+	CustomerID int
+	ExpiresAt  time.Time
+}
+
+// Expired reports whether the key can be reused, the store may keep expired records for a while
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// Matches reports whether the record was created by the same request
+func (r *IdempotencyRecord) Matches(requestHash string) bool {
+	return r.RequestHash == requestHash
+}
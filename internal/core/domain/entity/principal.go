@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"context"
+	"strconv"
+)
+
+// Role is what the caller of a request is allowed to do
+type Role string
+
+const (
+	// RoleCustomer is a customer authenticated with its CPF
+	RoleCustomer Role = "customer"
+	// RoleAdmin is an operator of the service, with access to the customers of everyone
+	RoleAdmin Role = "admin"
+)
+
+// Principal is the authenticated caller of a request, read from its access token
+type Principal struct {
+	// Subject identifies the caller, the customer ID for customers
+	Subject string
+	Role    Role
+}
+
+// IsAdmin tells whether the principal is an admin. A nil principal, of an anonymous request, isn't
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Role == RoleAdmin
+}
+
+// IsCustomer tells whether the principal is the customer with the ID, whose tokens carry it as their subject
+func (p *Principal) IsCustomer(customerID int) bool {
+	return p != nil && p.Role == RoleCustomer && p.Subject == strconv.Itoa(customerID)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a context carrying the caller of the request
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller of the request, or nil when the request is anonymous
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
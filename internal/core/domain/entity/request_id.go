@@ -0,0 +1,16 @@
+package entity
+
+import "context"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying the ID given to the request by its integration
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID of the request, or an empty string when it has none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "context"
+
+type routeKey struct{}
+
+// ContextWithRoute returns a context carrying the route called by the request, its method and resource as in
+// "GET /customers/{id}"
+func ContextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext returns the route of the request, or an empty string outside of a request
+func RouteFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
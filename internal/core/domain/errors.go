@@ -11,6 +11,9 @@ var (
 	ErrTokenCreation = "error creating token"
 	ErrExpiredToken  = "access token has expired"
 	ErrInvalidToken  = "access token is invalid"
+	ErrMissingToken  = "an access token is required"
+	ErrAdminOnly     = "only admins can do this"
+	ErrSelfOrAdmin   = "only the customer or an admin can do this"
 
 	ErrOrderInvalidStatusTransition = "invalid status transition"
 	ErrOrderWithoutProducts         = "order without products"
@@ -20,14 +23,63 @@ var (
 	ErrOrderIsNotOpen               = "order is not on status open"
 	ErrRoleInvalid                  = "invalid role"
 
+	ErrNameIsMandatory  = "name is mandatory"
+	ErrEmailIsMandatory = "email is mandatory"
+	ErrInvalidEmail     = "invalid email"
+	ErrCPFIsMandatory   = "cpf is mandatory"
+	ErrInvalidCPF       = "invalid cpf, it must have 11 digits and valid check digits"
+	ErrCPFIsImmutable   = "cpf can't be changed"
+
+	ErrCPFAlreadyExists   = "a customer with this cpf already exists"
+	ErrCustomerNotDeleted = "customer isn't deleted"
+	ErrCustomerAnonymized = "customer was anonymized, its data can't be changed"
+	ErrEmailAlreadyExists = "a customer with this email already exists"
+
+	ErrInvalidCustomerStatus           = "status must be pending, active, suspended, blocked or closed"
+	ErrStatusReasonIsMandatory         = "reason is mandatory"
+	ErrCustomerInvalidStatusTransition = "customer status can't change to this status"
+	ErrCustomerNotActive               = "customer account isn't active"
+
+	ErrConsentsAreMandatory      = "consents must have at least one consent"
+	ErrInvalidConsentPurpose     = "consent purpose must be marketing or data_sharing"
+	ErrInvalidConsentChannel     = "consent channel must be web, app, email, phone or store"
+	ErrConsentGrantedIsMandatory = "consent granted is mandatory"
+	ErrPolicyVersionIsMandatory  = "consent policy_version is mandatory"
+
+	ErrAuditNotRecorded  = "the customer was saved, but its audit entry wasn't recorded"
+	ErrAuditNotErased    = "the audit entries of the customer weren't erased, so it wasn't anonymized"
+	ErrEventNotPublished = "the customer was saved, but its event wasn't published"
+
+	ErrBatchGetIDsCount  = "ids must have between 1 and 100 customer ids"
+	ErrBatchGetInvalidID = "customer ids must be greater than zero"
+
+	ErrImportInvalidMode    = "import mode must be skip or upsert"
+	ErrImportRepeatedCPF    = "cpf repeated in the import file"
+	ErrImportUnknownFormat  = "import files must be .csv, .ndjson or .jsonl"
+	ErrImportMissingColumns = "import csv files must have name, email and cpf columns"
+
+	ErrExportInvalidField    = "export fields must be id, name, email, cpf, version, created_at or updated_at, without repeating them"
+	ErrExportInvalidSegments = "export segments must be between 1 and 64"
+	ErrExportUnknownFormat   = "export files must be .csv, .ndjson, .jsonl or .parquet"
+
+	ErrListScanLimitExceeded = "there are too many customers to list them with this data source, export them instead"
+
 	ErrPageMustBeGreaterThanZero = "page must be greater than zero"
 	ErrLimitMustBeBetween1And100 = "limit must be between 1 and 100"
 
+	ErrServiceUnavailable = "service temporarily unavailable, try again later"
+
 	ErrInternalError      = "internal server error"
 	ErrUnknownError       = "unknown error"
 	ErrValidationError    = "validation error"
 	ErrInvalidInput       = "invalid input"
 	ErrPreconditionFailed = "precondition failed"
+	ErrVersionMismatch    = "customer was modified, its version doesn't match the If-Match header"
+
+	ErrIdempotencyKeyReused     = "idempotency key was already used by a different request"
+	ErrIdempotencyKeyInProgress = "a request with this idempotency key is still being processed"
+	ErrIdempotencyKeyTooLong    = "idempotency key must have at most 255 characters"
+	ErrIdempotencyLeaseLost     = "the idempotency key reservation expired and was taken over by another request"
 
 	ErrFailedToCreatePaymentExternal = "failed to create payment external"
 	ErrFetchingCustomer              = "failed to fetch customer"
@@ -65,6 +117,53 @@ func (e *InternalError) Error() string {
 	return e.Message
 }
 
+type PreconditionFailedError struct {
+	Message string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+type ServiceUnavailableError struct {
+	Message string
+	Err     error
+}
+
+// Error is the message shown to the clients. The cause, with the details of the unavailable dependency, is only
+// logged
+func (e *ServiceUnavailableError) Error() string {
+	return e.Message
+}
+
+func (e *ServiceUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Message
+}
+
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
 type InvalidInputError struct {
 	Message string
 }
@@ -93,8 +192,39 @@ func NewInternalError(err error) *InternalError {
 	}
 }
 
+func NewPreconditionFailedError(message string) *PreconditionFailedError {
+	return &PreconditionFailedError{
+		Message: message,
+	}
+}
+
+func NewConflictError(message string) *ConflictError {
+	return &ConflictError{
+		Message: message,
+	}
+}
+
+func NewServiceUnavailableError(err error) *ServiceUnavailableError {
+	return &ServiceUnavailableError{
+		Message: ErrServiceUnavailable,
+		Err:     err,
+	}
+}
+
 func NewInvalidInputError(message string) *InvalidInputError {
 	return &InvalidInputError{
 		Message: message,
 	}
 }
+
+func NewUnauthorizedError(message string) *UnauthorizedError {
+	return &UnauthorizedError{
+		Message: message,
+	}
+}
+
+func NewForbiddenError(message string) *ForbiddenError {
+	return &ForbiddenError{
+		Message: message,
+	}
+}
@@ -1,33 +1,146 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+// MaxBatchGetCustomers is the maximum number of IDs of a batch get
+const MaxBatchGetCustomers = 100
+
 type CreateCustomerInput struct {
 	Name  string
 	Email string
 	CPF   string
 }
 
+// UpdateCustomerInput holds the fields to change. Nil fields keep their current value.
+// When Version is set, the update only happens if the customer is still at that version
 type UpdateCustomerInput struct {
-	ID    int
-	Name  string
-	Email string
+	ID      int
+	Name    *string
+	Email   *string
+	CPF     *string
+	Version *int
 }
 
+// GetCustomerInput identifies the customer to return. A deleted customer is only returned
+// with IncludeDeleted, which is restricted to admins
 type GetCustomerInput struct {
-	ID int
+	ID             int
+	IncludeDeleted bool
+}
+
+// BatchGetCustomersInput lists the IDs of the customers to return, at most MaxBatchGetCustomers
+type BatchGetCustomersInput struct {
+	IDs []int
+}
+
+// BatchGetCustomersOutput has the customers found, in the order of the requested IDs,
+// and the requested IDs without a customer
+type BatchGetCustomersOutput struct {
+	Customers  []*entity.Customer
+	MissingIDs []int
 }
 
 type GetCustomerByCPFInput struct {
 	CPF string
 }
 
+// DeleteCustomerInput identifies the customer to delete. When Version is set,
+// the deletion only happens if the customer is still at that version
 type DeleteCustomerInput struct {
+	ID      int
+	Version *int
+}
+
+// RestoreCustomerInput identifies the deleted customer to restore. When Version is set,
+// the restoration only happens if the customer is still at that version
+type RestoreCustomerInput struct {
+	ID      int
+	Version *int
+}
+
+// AnonymizeCustomerInput identifies the customer whose personal data is erased, restricted to the customer
+// themself and admins. When Version is set, the erasure only happens if the customer is still at that version
+type AnonymizeCustomerInput struct {
+	ID      int
+	Version *int
+}
+
+// ChangeCustomerStatusInput moves a customer to another status, restricted to admins. When Version
+// is set, the change only happens if the customer is still at that version
+type ChangeCustomerStatusInput struct {
+	ID      int
+	Status  string
+	Reason  string
+	Version *int
+}
+
+// GetCustomerHistoryInput identifies the customer whose audit entries are returned, restricted to admins
+type GetCustomerHistoryInput struct {
 	ID int
 }
 
+// GetCustomerDataExportInput identifies the customer whose data is exported, restricted to the customer
+// themself and admins
+type GetCustomerDataExportInput struct {
+	ID int
+}
+
+// CustomerDataExport is everything the service holds about a customer: its profile, with its consents, and
+// its audit entries
+type CustomerDataExport struct {
+	Customer   *entity.Customer
+	History    []*entity.AuditEntry
+	ExportedAt time.Time
+}
+
+// GetCustomerConsentsInput identifies the customer whose consents are returned, restricted to the customer
+// themself and admins
+type GetCustomerConsentsInput struct {
+	ID int
+}
+
+// UpdateCustomerConsentsInput records the decisions of a customer on the purposes of its data, restricted to
+// the customer themself and admins. When Version is set, they're only recorded if the customer is still at
+// that version
+type UpdateCustomerConsentsInput struct {
+	ID       int
+	Consents []ConsentInput
+	Version  *int
+}
+
+// ConsentInput grants or withdraws a purpose under a version of the privacy policy. Granted is required,
+// so a missing decision isn't taken as a withdrawal
+type ConsentInput struct {
+	Purpose       string
+	Granted       *bool
+	PolicyVersion string
+	Channel       string
+}
+
+// CustomerConsents are the consents of a customer, presented with their current state and history
+type CustomerConsents struct {
+	Customer *entity.Customer
+}
+
+// AuthenticateCustomerInput identifies the customer signing in
+type AuthenticateCustomerInput struct {
+	CPF string
+}
+
+// ListCustomersInput selects a page of customers. Search keeps the customers whose name has
+// all of its words. When AfterID is set, the page starts after that customer (keyset pagination)
+// and Page is ignored. IncludeDeleted, restricted to admins, lists the deleted customers too
 type ListCustomersInput struct {
-	Name  string
-	Page  int
-	Limit int
+	Name           string
+	Search         string
+	IncludeDeleted bool
+	AfterID        int
+	Page           int
+	Limit          int
 }
 
 type FindCustomerByCPFInput struct {
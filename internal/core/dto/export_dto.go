@@ -0,0 +1,45 @@
+package dto
+
+// ExportField is a customer field written by an export
+type ExportField string
+
+const (
+	ExportFieldID        ExportField = "id"
+	ExportFieldName      ExportField = "name"
+	ExportFieldEmail     ExportField = "email"
+	ExportFieldCPF       ExportField = "cpf"
+	ExportFieldVersion   ExportField = "version"
+	ExportFieldCreatedAt ExportField = "created_at"
+	ExportFieldUpdatedAt ExportField = "updated_at"
+)
+
+// ExportFields are the fields of an export that doesn't select them, in the order they are written
+var ExportFields = []ExportField{
+	ExportFieldID,
+	ExportFieldName,
+	ExportFieldEmail,
+	ExportFieldCPF,
+	ExportFieldVersion,
+	ExportFieldCreatedAt,
+	ExportFieldUpdatedAt,
+}
+
+// Bounds of the parallel readers of an export
+const (
+	DefaultExportSegments = 4
+	MaxExportSegments     = 64
+)
+
+// ExportCustomersInput selects the fields written for each customer, in order, every field when empty.
+// MaskPII masks the name, email and CPF, and Segments is the number of parallel readers of the customers
+type ExportCustomersInput struct {
+	Fields   []ExportField
+	MaskPII  bool
+	Segments int
+}
+
+// ExportCustomersOutput has the fields written for each customer and how many customers were exported
+type ExportCustomersOutput struct {
+	Fields []ExportField
+	Count  int
+}
@@ -0,0 +1,53 @@
+package dto
+
+// ImportMode tells what an import does with the rows whose CPF already belongs to a customer
+type ImportMode string
+
+const (
+	// ImportModeSkip keeps the existing customer and reports the row as skipped
+	ImportModeSkip ImportMode = "skip"
+	// ImportModeUpsert replaces the name and email of the existing customer with the ones of the row
+	ImportModeUpsert ImportMode = "upsert"
+)
+
+// Statuses of the rows of an import report
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportCustomerRow is a customer read from an import file. Line is its line in the file,
+// and Error says why the row couldn't be read, when it couldn't
+type ImportCustomerRow struct {
+	Line  int
+	Name  string
+	Email string
+	CPF   string
+	Error string
+}
+
+type ImportCustomersInput struct {
+	Rows []ImportCustomerRow
+	Mode ImportMode
+}
+
+// ImportRowResult is the outcome of a row: the customer it created, updated or skipped, or why it failed
+type ImportRowResult struct {
+	Line       int
+	CPF        string
+	Status     string
+	CustomerID int
+	Error      string
+}
+
+// ImportCustomersReport counts the rows of an import by status, and has the result of each row in file order
+type ImportCustomersReport struct {
+	Total   int
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+	Rows    []ImportRowResult
+}
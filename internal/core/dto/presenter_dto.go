@@ -1,8 +1,19 @@
 package dto
 
+import "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+
 type PresenterInput struct {
 	Result any
 	Total  int64
 	Page   int
 	Limit  int
+	// Audience decides how much of the personal data of the customers the response shows
+	Audience Audience
+}
+
+// Audience is who a response is presented to: the caller of the request and the route it called. It's empty
+// outside of a request, like in the maintenance commands
+type Audience struct {
+	Principal *entity.Principal
+	Route     string
 }
@@ -0,0 +1,24 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+type AuditGateway interface {
+	Append(ctx context.Context, entry *entity.AuditEntry) error
+	FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error)
+	EraseCustomer(ctx context.Context, customerID int) error
+}
+
+// AuditDataSource stores the audit entries of the customers. It only appends, so the entries are never
+// removed, not even when their customer is purged, and are only changed to erase their personal data
+type AuditDataSource interface {
+	// Append stores a new entry, giving it an ID
+	Append(ctx context.Context, entry *entity.AuditEntry) error
+	// FindByCustomerID returns the entries of a customer, oldest first
+	FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error)
+	// EraseCustomer erases the personal data recorded by the entries of an anonymized customer
+	EraseCustomer(ctx context.Context, customerID int) error
+}
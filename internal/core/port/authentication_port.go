@@ -1,5 +1,10 @@
 package port
 
+import "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+
 type IAuthenticationService interface {
 	GenerateToken(userIdentifier string) (string, string, int64, error)
+	// ValidateToken checks the signature, issuer, audience and expiration of an access token
+	// and returns its principal
+	ValidateToken(token string) (*entity.Principal, error)
 }
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+type Cache interface {
+	// Get returns the value stored for the key, and whether it was found and hasn't expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value for the key until the ttl elapses
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the keys, ignoring the ones that aren't stored
+	Delete(ctx context.Context, keys ...string) error
+}
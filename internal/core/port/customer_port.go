@@ -11,34 +11,86 @@ type CustomerController interface {
 	List(ctx context.Context, presenter Presenter, input dto.ListCustomersInput) ([]byte, error)
 	Create(ctx context.Context, presenter Presenter, input dto.CreateCustomerInput) ([]byte, error)
 	Get(ctx context.Context, presenter Presenter, input dto.GetCustomerInput) ([]byte, error)
+	BatchGet(ctx context.Context, presenter Presenter, input dto.BatchGetCustomersInput) ([]byte, error)
 	GetByCPF(ctx context.Context, presenter Presenter, input dto.GetCustomerByCPFInput) ([]byte, error)
 	Update(ctx context.Context, presenter Presenter, input dto.UpdateCustomerInput) ([]byte, error)
 	Delete(ctx context.Context, presenter Presenter, input dto.DeleteCustomerInput) ([]byte, error)
+	Restore(ctx context.Context, presenter Presenter, input dto.RestoreCustomerInput) ([]byte, error)
+	ChangeStatus(ctx context.Context, presenter Presenter, input dto.ChangeCustomerStatusInput) ([]byte, error)
+	Anonymize(ctx context.Context, presenter Presenter, input dto.AnonymizeCustomerInput) ([]byte, error)
+	Authenticate(ctx context.Context, presenter Presenter, input dto.AuthenticateCustomerInput) ([]byte, error)
+	History(ctx context.Context, presenter Presenter, input dto.GetCustomerHistoryInput) ([]byte, error)
+	DataExport(ctx context.Context, presenter Presenter, input dto.GetCustomerDataExportInput) ([]byte, error)
+	GetConsents(ctx context.Context, presenter Presenter, input dto.GetCustomerConsentsInput) ([]byte, error)
+	UpdateConsents(ctx context.Context, presenter Presenter, input dto.UpdateCustomerConsentsInput) ([]byte, error)
+	Import(ctx context.Context, presenter Presenter, input dto.ImportCustomersInput) ([]byte, error)
+	Export(ctx context.Context, presenter Presenter, input dto.ExportCustomersInput, writer CustomerExportWriter) ([]byte, error)
 }
 
 type CustomerUseCase interface {
 	List(ctx context.Context, input dto.ListCustomersInput) ([]*entity.Customer, int64, error)
 	Create(ctx context.Context, input dto.CreateCustomerInput) (*entity.Customer, error)
 	Get(ctx context.Context, input dto.GetCustomerInput) (*entity.Customer, error)
+	BatchGet(ctx context.Context, input dto.BatchGetCustomersInput) (*dto.BatchGetCustomersOutput, error)
 	GetByCPF(ctx context.Context, i dto.GetCustomerByCPFInput) (*entity.Customer, error)
 	Update(ctx context.Context, input dto.UpdateCustomerInput) (*entity.Customer, error)
 	Delete(ctx context.Context, input dto.DeleteCustomerInput) (*entity.Customer, error)
+	Restore(ctx context.Context, input dto.RestoreCustomerInput) (*entity.Customer, error)
+	ChangeStatus(ctx context.Context, input dto.ChangeCustomerStatusInput) (*entity.Customer, error)
+	Anonymize(ctx context.Context, input dto.AnonymizeCustomerInput) (*entity.Customer, error)
+	Authenticate(ctx context.Context, input dto.AuthenticateCustomerInput) (*entity.Customer, error)
+	History(ctx context.Context, input dto.GetCustomerHistoryInput) ([]*entity.AuditEntry, error)
+	DataExport(ctx context.Context, input dto.GetCustomerDataExportInput) (*dto.CustomerDataExport, error)
+	GetConsents(ctx context.Context, input dto.GetCustomerConsentsInput) (*dto.CustomerConsents, error)
+	UpdateConsents(ctx context.Context, input dto.UpdateCustomerConsentsInput) (*dto.CustomerConsents, error)
+	Import(ctx context.Context, input dto.ImportCustomersInput) (*dto.ImportCustomersReport, error)
+	Export(ctx context.Context, input dto.ExportCustomersInput, writer CustomerExportWriter) (*dto.ExportCustomersOutput, error)
 }
 
 type CustomerGateway interface {
 	FindByID(ctx context.Context, id int) (*entity.Customer, error)
+	FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error)
 	FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error)
-	FindAll(ctx context.Context, name string, page, limit int) ([]*entity.Customer, int64, error)
+	FindAll(ctx context.Context, name, search string, includeDeleted bool, afterID, page, limit int) ([]*entity.Customer, int64, error)
 	Create(ctx context.Context, customer *entity.Customer) error
 	Update(ctx context.Context, customer *entity.Customer) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, version int) error
+	SaveBatch(ctx context.Context, customers []*entity.Customer) error
+	Scan(ctx context.Context, segments int, visit func(*entity.Customer) error) error
 }
 
+// FilterAfterID is the CustomerDataSource.FindAll filter that only keeps customers with a greater ID.
+// It makes the pagination start after the last customer of the previous page, and isn't counted in the total
+const FilterAfterID = "after_id"
+
+// FilterSearch is the CustomerDataSource.FindAll filter that keeps the customers whose name has every word
+// of the search, ignoring case
+const FilterSearch = "search"
+
+// FilterIncludeDeleted is the CustomerDataSource.FindAll filter that keeps the deleted customers,
+// which FindAll leaves out by default
+const FilterIncludeDeleted = "include_deleted"
+
+// CustomerDataSource stores the customers. FindByID, FindByIDs, FindByCPF and Scan return the deleted
+// customers too, with their DeletedAt set, and leave it to the caller to tell them apart
 type CustomerDataSource interface {
 	FindByID(ctx context.Context, id int) (*entity.Customer, error)
+	// FindByIDs returns the customers with the given IDs in the order of the IDs, skipping the missing ones
+	FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error)
 	FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error)
 	FindAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*entity.Customer, int64, error)
 	Create(ctx context.Context, product *entity.Customer) error
+	// Update writes the customer, DeletedAt included, so it also deletes and restores customers
 	Update(ctx context.Context, product *entity.Customer) error
-	Delete(ctx context.Context, id int) error
+	// Delete removes the customer for good
+	Delete(ctx context.Context, id int, version int) error
+	// SaveBatch writes customers in bulk. Customers without an ID are created with the next IDs and version 1,
+	// the others replace the stored customer with the version they carry, only while it still has the version
+	// before it. A replaced customer that is gone fails the batch with a NotFoundError, and one changed since
+	// it was read with a PreconditionFailedError
+	SaveBatch(ctx context.Context, customers []*entity.Customer) error
+	// Scan calls visit with every customer, in no particular order, reading them with up to segments
+	// parallel readers where the data source supports it. visit is never called concurrently, and an
+	// error it returns stops the scan
+	Scan(ctx context.Context, segments int, visit func(*entity.Customer) error) error
 }
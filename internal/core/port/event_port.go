@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+// EventPublisher tells the services downstream about the domain events of the customers
+type EventPublisher interface {
+	Publish(ctx context.Context, event *entity.CustomerEvent) error
+}
@@ -0,0 +1,16 @@
+package port
+
+import (
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+// CustomerExportWriter encodes the customers of an export in a file format
+type CustomerExportWriter interface {
+	// Begin is called before the first customer with the fields to write, in order
+	Begin(fields []dto.ExportField) error
+	// Write encodes the fields of a customer
+	Write(customer *entity.Customer) error
+	// Close completes the file after the last customer
+	Close() error
+}
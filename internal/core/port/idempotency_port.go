@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+type IdempotencyDataSource interface {
+	// Reserve stores a pending record for the key. When a record that hasn't expired already exists
+	// for the key, nothing is stored and the existing record is returned
+	Reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error)
+	// Complete stores the response of the request that reserved the key, while the record still has its
+	// lease. It fails with a ConflictError when another request took the reservation over
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+	// Release removes the pending record of the lease, so the request can be retried with the same key
+	Release(ctx context.Context, record *entity.IdempotencyRecord) error
+	// PurgeCustomer removes the records whose stored response presents the customer
+	PurgeCustomer(ctx context.Context, customerID int) error
+}
@@ -0,0 +1,15 @@
+package port
+
+import "context"
+
+// KeyProvider holds the master key that protects the personal data of the customers. The data is encrypted
+// with data keys, which are only stored wrapped by the master key (envelope encryption)
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit data key, in plaintext and wrapped by the master key
+	GenerateDataKey(ctx context.Context) (plaintext, wrapped []byte, err error)
+	// DecryptDataKey unwraps a data key returned by GenerateDataKey
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+	// BlindIndexKey returns the secret key of the blind indexes, which is the same on every call so
+	// equal values always have the same index
+	BlindIndexKey(ctx context.Context) ([]byte, error)
+}
@@ -0,0 +1,152 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/audit_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/audit_port.go -destination=internal/core/port/mocks/audit_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuditGateway is a mock of AuditGateway interface.
+type MockAuditGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditGatewayMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditGatewayMockRecorder is the mock recorder for MockAuditGateway.
+type MockAuditGatewayMockRecorder struct {
+	mock *MockAuditGateway
+}
+
+// NewMockAuditGateway creates a new mock instance.
+func NewMockAuditGateway(ctrl *gomock.Controller) *MockAuditGateway {
+	mock := &MockAuditGateway{ctrl: ctrl}
+	mock.recorder = &MockAuditGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditGateway) EXPECT() *MockAuditGatewayMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditGateway) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditGatewayMockRecorder) Append(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditGateway)(nil).Append), ctx, entry)
+}
+
+// EraseCustomer mocks base method.
+func (m *MockAuditGateway) EraseCustomer(ctx context.Context, customerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseCustomer", ctx, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseCustomer indicates an expected call of EraseCustomer.
+func (mr *MockAuditGatewayMockRecorder) EraseCustomer(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseCustomer", reflect.TypeOf((*MockAuditGateway)(nil).EraseCustomer), ctx, customerID)
+}
+
+// FindByCustomerID mocks base method.
+func (m *MockAuditGateway) FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCustomerID", ctx, customerID)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCustomerID indicates an expected call of FindByCustomerID.
+func (mr *MockAuditGatewayMockRecorder) FindByCustomerID(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCustomerID", reflect.TypeOf((*MockAuditGateway)(nil).FindByCustomerID), ctx, customerID)
+}
+
+// MockAuditDataSource is a mock of AuditDataSource interface.
+type MockAuditDataSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditDataSourceMockRecorder
+	isgomock struct{}
+}
+
+// MockAuditDataSourceMockRecorder is the mock recorder for MockAuditDataSource.
+type MockAuditDataSourceMockRecorder struct {
+	mock *MockAuditDataSource
+}
+
+// NewMockAuditDataSource creates a new mock instance.
+func NewMockAuditDataSource(ctrl *gomock.Controller) *MockAuditDataSource {
+	mock := &MockAuditDataSource{ctrl: ctrl}
+	mock.recorder = &MockAuditDataSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditDataSource) EXPECT() *MockAuditDataSourceMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditDataSource) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditDataSourceMockRecorder) Append(ctx, entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditDataSource)(nil).Append), ctx, entry)
+}
+
+// EraseCustomer mocks base method.
+func (m *MockAuditDataSource) EraseCustomer(ctx context.Context, customerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseCustomer", ctx, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseCustomer indicates an expected call of EraseCustomer.
+func (mr *MockAuditDataSourceMockRecorder) EraseCustomer(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseCustomer", reflect.TypeOf((*MockAuditDataSource)(nil).EraseCustomer), ctx, customerID)
+}
+
+// FindByCustomerID mocks base method.
+func (m *MockAuditDataSource) FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByCustomerID", ctx, customerID)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByCustomerID indicates an expected call of FindByCustomerID.
+func (mr *MockAuditDataSourceMockRecorder) FindByCustomerID(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByCustomerID", reflect.TypeOf((*MockAuditDataSource)(nil).FindByCustomerID), ctx, customerID)
+}
@@ -12,6 +12,7 @@ package mocks
 import (
 	reflect "reflect"
 
+	entity "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,3 +56,18 @@ func (mr *MockIAuthenticationServiceMockRecorder) GenerateToken(userIdentifier a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockIAuthenticationService)(nil).GenerateToken), userIdentifier)
 }
+
+// ValidateToken mocks base method.
+func (m *MockIAuthenticationService) ValidateToken(token string) (*entity.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateToken", token)
+	ret0, _ := ret[0].(*entity.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateToken indicates an expected call of ValidateToken.
+func (mr *MockIAuthenticationServiceMockRecorder) ValidateToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockIAuthenticationService)(nil).ValidateToken), token)
+}
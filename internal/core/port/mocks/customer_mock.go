@@ -43,6 +43,66 @@ func (m *MockCustomerController) EXPECT() *MockCustomerControllerMockRecorder {
 	return m.recorder
 }
 
+// Anonymize mocks base method.
+func (m *MockCustomerController) Anonymize(ctx context.Context, presenter port.Presenter, input dto.AnonymizeCustomerInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockCustomerControllerMockRecorder) Anonymize(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockCustomerController)(nil).Anonymize), ctx, presenter, input)
+}
+
+// Authenticate mocks base method.
+func (m *MockCustomerController) Authenticate(ctx context.Context, presenter port.Presenter, input dto.AuthenticateCustomerInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockCustomerControllerMockRecorder) Authenticate(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockCustomerController)(nil).Authenticate), ctx, presenter, input)
+}
+
+// BatchGet mocks base method.
+func (m *MockCustomerController) BatchGet(ctx context.Context, presenter port.Presenter, input dto.BatchGetCustomersInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGet", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchGet indicates an expected call of BatchGet.
+func (mr *MockCustomerControllerMockRecorder) BatchGet(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGet", reflect.TypeOf((*MockCustomerController)(nil).BatchGet), ctx, presenter, input)
+}
+
+// ChangeStatus mocks base method.
+func (m *MockCustomerController) ChangeStatus(ctx context.Context, presenter port.Presenter, input dto.ChangeCustomerStatusInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockCustomerControllerMockRecorder) ChangeStatus(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockCustomerController)(nil).ChangeStatus), ctx, presenter, input)
+}
+
 // Create mocks base method.
 func (m *MockCustomerController) Create(ctx context.Context, presenter port.Presenter, input dto.CreateCustomerInput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -58,6 +118,21 @@ func (mr *MockCustomerControllerMockRecorder) Create(ctx, presenter, input any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomerController)(nil).Create), ctx, presenter, input)
 }
 
+// DataExport mocks base method.
+func (m *MockCustomerController) DataExport(ctx context.Context, presenter port.Presenter, input dto.GetCustomerDataExportInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DataExport", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DataExport indicates an expected call of DataExport.
+func (mr *MockCustomerControllerMockRecorder) DataExport(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DataExport", reflect.TypeOf((*MockCustomerController)(nil).DataExport), ctx, presenter, input)
+}
+
 // Delete mocks base method.
 func (m *MockCustomerController) Delete(ctx context.Context, presenter port.Presenter, input dto.DeleteCustomerInput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -73,6 +148,21 @@ func (mr *MockCustomerControllerMockRecorder) Delete(ctx, presenter, input any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerController)(nil).Delete), ctx, presenter, input)
 }
 
+// Export mocks base method.
+func (m *MockCustomerController) Export(ctx context.Context, presenter port.Presenter, input dto.ExportCustomersInput, writer port.CustomerExportWriter) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, presenter, input, writer)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockCustomerControllerMockRecorder) Export(ctx, presenter, input, writer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockCustomerController)(nil).Export), ctx, presenter, input, writer)
+}
+
 // Get mocks base method.
 func (m *MockCustomerController) Get(ctx context.Context, presenter port.Presenter, input dto.GetCustomerInput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +193,51 @@ func (mr *MockCustomerControllerMockRecorder) GetByCPF(ctx, presenter, input any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCPF", reflect.TypeOf((*MockCustomerController)(nil).GetByCPF), ctx, presenter, input)
 }
 
+// GetConsents mocks base method.
+func (m *MockCustomerController) GetConsents(ctx context.Context, presenter port.Presenter, input dto.GetCustomerConsentsInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsents", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsents indicates an expected call of GetConsents.
+func (mr *MockCustomerControllerMockRecorder) GetConsents(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsents", reflect.TypeOf((*MockCustomerController)(nil).GetConsents), ctx, presenter, input)
+}
+
+// History mocks base method.
+func (m *MockCustomerController) History(ctx context.Context, presenter port.Presenter, input dto.GetCustomerHistoryInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockCustomerControllerMockRecorder) History(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockCustomerController)(nil).History), ctx, presenter, input)
+}
+
+// Import mocks base method.
+func (m *MockCustomerController) Import(ctx context.Context, presenter port.Presenter, input dto.ImportCustomersInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockCustomerControllerMockRecorder) Import(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockCustomerController)(nil).Import), ctx, presenter, input)
+}
+
 // List mocks base method.
 func (m *MockCustomerController) List(ctx context.Context, presenter port.Presenter, input dto.ListCustomersInput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -118,6 +253,21 @@ func (mr *MockCustomerControllerMockRecorder) List(ctx, presenter, input any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCustomerController)(nil).List), ctx, presenter, input)
 }
 
+// Restore mocks base method.
+func (m *MockCustomerController) Restore(ctx context.Context, presenter port.Presenter, input dto.RestoreCustomerInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockCustomerControllerMockRecorder) Restore(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockCustomerController)(nil).Restore), ctx, presenter, input)
+}
+
 // Update mocks base method.
 func (m *MockCustomerController) Update(ctx context.Context, presenter port.Presenter, input dto.UpdateCustomerInput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -133,6 +283,21 @@ func (mr *MockCustomerControllerMockRecorder) Update(ctx, presenter, input any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomerController)(nil).Update), ctx, presenter, input)
 }
 
+// UpdateConsents mocks base method.
+func (m *MockCustomerController) UpdateConsents(ctx context.Context, presenter port.Presenter, input dto.UpdateCustomerConsentsInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConsents", ctx, presenter, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateConsents indicates an expected call of UpdateConsents.
+func (mr *MockCustomerControllerMockRecorder) UpdateConsents(ctx, presenter, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConsents", reflect.TypeOf((*MockCustomerController)(nil).UpdateConsents), ctx, presenter, input)
+}
+
 // MockCustomerUseCase is a mock of CustomerUseCase interface.
 type MockCustomerUseCase struct {
 	ctrl     *gomock.Controller
@@ -157,6 +322,66 @@ func (m *MockCustomerUseCase) EXPECT() *MockCustomerUseCaseMockRecorder {
 	return m.recorder
 }
 
+// Anonymize mocks base method.
+func (m *MockCustomerUseCase) Anonymize(ctx context.Context, input dto.AnonymizeCustomerInput) (*entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", ctx, input)
+	ret0, _ := ret[0].(*entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Anonymize indicates an expected call of Anonymize.
+func (mr *MockCustomerUseCaseMockRecorder) Anonymize(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockCustomerUseCase)(nil).Anonymize), ctx, input)
+}
+
+// Authenticate mocks base method.
+func (m *MockCustomerUseCase) Authenticate(ctx context.Context, input dto.AuthenticateCustomerInput) (*entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, input)
+	ret0, _ := ret[0].(*entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockCustomerUseCaseMockRecorder) Authenticate(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockCustomerUseCase)(nil).Authenticate), ctx, input)
+}
+
+// BatchGet mocks base method.
+func (m *MockCustomerUseCase) BatchGet(ctx context.Context, input dto.BatchGetCustomersInput) (*dto.BatchGetCustomersOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGet", ctx, input)
+	ret0, _ := ret[0].(*dto.BatchGetCustomersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchGet indicates an expected call of BatchGet.
+func (mr *MockCustomerUseCaseMockRecorder) BatchGet(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGet", reflect.TypeOf((*MockCustomerUseCase)(nil).BatchGet), ctx, input)
+}
+
+// ChangeStatus mocks base method.
+func (m *MockCustomerUseCase) ChangeStatus(ctx context.Context, input dto.ChangeCustomerStatusInput) (*entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, input)
+	ret0, _ := ret[0].(*entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus.
+func (mr *MockCustomerUseCaseMockRecorder) ChangeStatus(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockCustomerUseCase)(nil).ChangeStatus), ctx, input)
+}
+
 // Create mocks base method.
 func (m *MockCustomerUseCase) Create(ctx context.Context, input dto.CreateCustomerInput) (*entity.Customer, error) {
 	m.ctrl.T.Helper()
@@ -172,6 +397,21 @@ func (mr *MockCustomerUseCaseMockRecorder) Create(ctx, input any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomerUseCase)(nil).Create), ctx, input)
 }
 
+// DataExport mocks base method.
+func (m *MockCustomerUseCase) DataExport(ctx context.Context, input dto.GetCustomerDataExportInput) (*dto.CustomerDataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DataExport", ctx, input)
+	ret0, _ := ret[0].(*dto.CustomerDataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DataExport indicates an expected call of DataExport.
+func (mr *MockCustomerUseCaseMockRecorder) DataExport(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DataExport", reflect.TypeOf((*MockCustomerUseCase)(nil).DataExport), ctx, input)
+}
+
 // Delete mocks base method.
 func (m *MockCustomerUseCase) Delete(ctx context.Context, input dto.DeleteCustomerInput) (*entity.Customer, error) {
 	m.ctrl.T.Helper()
@@ -187,6 +427,21 @@ func (mr *MockCustomerUseCaseMockRecorder) Delete(ctx, input any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerUseCase)(nil).Delete), ctx, input)
 }
 
+// Export mocks base method.
+func (m *MockCustomerUseCase) Export(ctx context.Context, input dto.ExportCustomersInput, writer port.CustomerExportWriter) (*dto.ExportCustomersOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, input, writer)
+	ret0, _ := ret[0].(*dto.ExportCustomersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockCustomerUseCaseMockRecorder) Export(ctx, input, writer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockCustomerUseCase)(nil).Export), ctx, input, writer)
+}
+
 // Get mocks base method.
 func (m *MockCustomerUseCase) Get(ctx context.Context, input dto.GetCustomerInput) (*entity.Customer, error) {
 	m.ctrl.T.Helper()
@@ -217,6 +472,51 @@ func (mr *MockCustomerUseCaseMockRecorder) GetByCPF(ctx, i any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCPF", reflect.TypeOf((*MockCustomerUseCase)(nil).GetByCPF), ctx, i)
 }
 
+// GetConsents mocks base method.
+func (m *MockCustomerUseCase) GetConsents(ctx context.Context, input dto.GetCustomerConsentsInput) (*dto.CustomerConsents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsents", ctx, input)
+	ret0, _ := ret[0].(*dto.CustomerConsents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsents indicates an expected call of GetConsents.
+func (mr *MockCustomerUseCaseMockRecorder) GetConsents(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsents", reflect.TypeOf((*MockCustomerUseCase)(nil).GetConsents), ctx, input)
+}
+
+// History mocks base method.
+func (m *MockCustomerUseCase) History(ctx context.Context, input dto.GetCustomerHistoryInput) ([]*entity.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, input)
+	ret0, _ := ret[0].([]*entity.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockCustomerUseCaseMockRecorder) History(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockCustomerUseCase)(nil).History), ctx, input)
+}
+
+// Import mocks base method.
+func (m *MockCustomerUseCase) Import(ctx context.Context, input dto.ImportCustomersInput) (*dto.ImportCustomersReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, input)
+	ret0, _ := ret[0].(*dto.ImportCustomersReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockCustomerUseCaseMockRecorder) Import(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockCustomerUseCase)(nil).Import), ctx, input)
+}
+
 // List mocks base method.
 func (m *MockCustomerUseCase) List(ctx context.Context, input dto.ListCustomersInput) ([]*entity.Customer, int64, error) {
 	m.ctrl.T.Helper()
@@ -233,6 +533,21 @@ func (mr *MockCustomerUseCaseMockRecorder) List(ctx, input any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCustomerUseCase)(nil).List), ctx, input)
 }
 
+// Restore mocks base method.
+func (m *MockCustomerUseCase) Restore(ctx context.Context, input dto.RestoreCustomerInput) (*entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, input)
+	ret0, _ := ret[0].(*entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockCustomerUseCaseMockRecorder) Restore(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockCustomerUseCase)(nil).Restore), ctx, input)
+}
+
 // Update mocks base method.
 func (m *MockCustomerUseCase) Update(ctx context.Context, input dto.UpdateCustomerInput) (*entity.Customer, error) {
 	m.ctrl.T.Helper()
@@ -248,6 +563,21 @@ func (mr *MockCustomerUseCaseMockRecorder) Update(ctx, input any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomerUseCase)(nil).Update), ctx, input)
 }
 
+// UpdateConsents mocks base method.
+func (m *MockCustomerUseCase) UpdateConsents(ctx context.Context, input dto.UpdateCustomerConsentsInput) (*dto.CustomerConsents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConsents", ctx, input)
+	ret0, _ := ret[0].(*dto.CustomerConsents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateConsents indicates an expected call of UpdateConsents.
+func (mr *MockCustomerUseCaseMockRecorder) UpdateConsents(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConsents", reflect.TypeOf((*MockCustomerUseCase)(nil).UpdateConsents), ctx, input)
+}
+
 // MockCustomerGateway is a mock of CustomerGateway interface.
 type MockCustomerGateway struct {
 	ctrl     *gomock.Controller
@@ -287,23 +617,23 @@ func (mr *MockCustomerGatewayMockRecorder) Create(ctx, customer any) *gomock.Cal
 }
 
 // Delete mocks base method.
-func (m *MockCustomerGateway) Delete(ctx context.Context, id int) error {
+func (m *MockCustomerGateway) Delete(ctx context.Context, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCustomerGatewayMockRecorder) Delete(ctx, id any) *gomock.Call {
+func (mr *MockCustomerGatewayMockRecorder) Delete(ctx, id, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerGateway)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerGateway)(nil).Delete), ctx, id, version)
 }
 
 // FindAll mocks base method.
-func (m *MockCustomerGateway) FindAll(ctx context.Context, name string, page, limit int) ([]*entity.Customer, int64, error) {
+func (m *MockCustomerGateway) FindAll(ctx context.Context, name, search string, includeDeleted bool, afterID, page, limit int) ([]*entity.Customer, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", ctx, name, page, limit)
+	ret := m.ctrl.Call(m, "FindAll", ctx, name, search, includeDeleted, afterID, page, limit)
 	ret0, _ := ret[0].([]*entity.Customer)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -311,9 +641,9 @@ func (m *MockCustomerGateway) FindAll(ctx context.Context, name string, page, li
 }
 
 // FindAll indicates an expected call of FindAll.
-func (mr *MockCustomerGatewayMockRecorder) FindAll(ctx, name, page, limit any) *gomock.Call {
+func (mr *MockCustomerGatewayMockRecorder) FindAll(ctx, name, search, includeDeleted, afterID, page, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockCustomerGateway)(nil).FindAll), ctx, name, page, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockCustomerGateway)(nil).FindAll), ctx, name, search, includeDeleted, afterID, page, limit)
 }
 
 // FindByCPF mocks base method.
@@ -346,6 +676,49 @@ func (mr *MockCustomerGatewayMockRecorder) FindByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockCustomerGateway)(nil).FindByID), ctx, id)
 }
 
+// FindByIDs mocks base method.
+func (m *MockCustomerGateway) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockCustomerGatewayMockRecorder) FindByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockCustomerGateway)(nil).FindByIDs), ctx, ids)
+}
+
+// SaveBatch mocks base method.
+func (m *MockCustomerGateway) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, customers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockCustomerGatewayMockRecorder) SaveBatch(ctx, customers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockCustomerGateway)(nil).SaveBatch), ctx, customers)
+}
+
+// Scan mocks base method.
+func (m *MockCustomerGateway) Scan(ctx context.Context, segments int, visit func(*entity.Customer) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", ctx, segments, visit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockCustomerGatewayMockRecorder) Scan(ctx, segments, visit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockCustomerGateway)(nil).Scan), ctx, segments, visit)
+}
+
 // Update mocks base method.
 func (m *MockCustomerGateway) Update(ctx context.Context, customer *entity.Customer) error {
 	m.ctrl.T.Helper()
@@ -399,17 +772,17 @@ func (mr *MockCustomerDataSourceMockRecorder) Create(ctx, product any) *gomock.C
 }
 
 // Delete mocks base method.
-func (m *MockCustomerDataSource) Delete(ctx context.Context, id int) error {
+func (m *MockCustomerDataSource) Delete(ctx context.Context, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCustomerDataSourceMockRecorder) Delete(ctx, id any) *gomock.Call {
+func (mr *MockCustomerDataSourceMockRecorder) Delete(ctx, id, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerDataSource)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCustomerDataSource)(nil).Delete), ctx, id, version)
 }
 
 // FindAll mocks base method.
@@ -458,6 +831,49 @@ func (mr *MockCustomerDataSourceMockRecorder) FindByID(ctx, id any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockCustomerDataSource)(nil).FindByID), ctx, id)
 }
 
+// FindByIDs mocks base method.
+func (m *MockCustomerDataSource) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*entity.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockCustomerDataSourceMockRecorder) FindByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockCustomerDataSource)(nil).FindByIDs), ctx, ids)
+}
+
+// SaveBatch mocks base method.
+func (m *MockCustomerDataSource) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, customers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockCustomerDataSourceMockRecorder) SaveBatch(ctx, customers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockCustomerDataSource)(nil).SaveBatch), ctx, customers)
+}
+
+// Scan mocks base method.
+func (m *MockCustomerDataSource) Scan(ctx context.Context, segments int, visit func(*entity.Customer) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scan", ctx, segments, visit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Scan indicates an expected call of Scan.
+func (mr *MockCustomerDataSourceMockRecorder) Scan(ctx, segments, visit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockCustomerDataSource)(nil).Scan), ctx, segments, visit)
+}
+
 // Update mocks base method.
 func (m *MockCustomerDataSource) Update(ctx context.Context, product *entity.Customer) error {
 	m.ctrl.T.Helper()
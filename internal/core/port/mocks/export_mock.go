@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/export_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/export_port.go -destination=internal/core/port/mocks/export_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	entity "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	dto "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCustomerExportWriter is a mock of CustomerExportWriter interface.
+type MockCustomerExportWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomerExportWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockCustomerExportWriterMockRecorder is the mock recorder for MockCustomerExportWriter.
+type MockCustomerExportWriterMockRecorder struct {
+	mock *MockCustomerExportWriter
+}
+
+// NewMockCustomerExportWriter creates a new mock instance.
+func NewMockCustomerExportWriter(ctrl *gomock.Controller) *MockCustomerExportWriter {
+	mock := &MockCustomerExportWriter{ctrl: ctrl}
+	mock.recorder = &MockCustomerExportWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomerExportWriter) EXPECT() *MockCustomerExportWriterMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockCustomerExportWriter) Begin(fields []dto.ExportField) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockCustomerExportWriterMockRecorder) Begin(fields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockCustomerExportWriter)(nil).Begin), fields)
+}
+
+// Close mocks base method.
+func (m *MockCustomerExportWriter) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockCustomerExportWriterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockCustomerExportWriter)(nil).Close))
+}
+
+// Write mocks base method.
+func (m *MockCustomerExportWriter) Write(customer *entity.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockCustomerExportWriterMockRecorder) Write(customer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockCustomerExportWriter)(nil).Write), customer)
+}
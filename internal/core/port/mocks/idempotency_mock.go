@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/idempotency_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/idempotency_port.go -destination=internal/core/port/mocks/idempotency_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIdempotencyDataSource is a mock of IdempotencyDataSource interface.
+type MockIdempotencyDataSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyDataSourceMockRecorder
+	isgomock struct{}
+}
+
+// MockIdempotencyDataSourceMockRecorder is the mock recorder for MockIdempotencyDataSource.
+type MockIdempotencyDataSourceMockRecorder struct {
+	mock *MockIdempotencyDataSource
+}
+
+// NewMockIdempotencyDataSource creates a new mock instance.
+func NewMockIdempotencyDataSource(ctrl *gomock.Controller) *MockIdempotencyDataSource {
+	mock := &MockIdempotencyDataSource{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyDataSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyDataSource) EXPECT() *MockIdempotencyDataSourceMockRecorder {
+	return m.recorder
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyDataSource) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyDataSourceMockRecorder) Complete(ctx, record any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyDataSource)(nil).Complete), ctx, record)
+}
+
+// PurgeCustomer mocks base method.
+func (m *MockIdempotencyDataSource) PurgeCustomer(ctx context.Context, customerID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeCustomer", ctx, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeCustomer indicates an expected call of PurgeCustomer.
+func (mr *MockIdempotencyDataSourceMockRecorder) PurgeCustomer(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeCustomer", reflect.TypeOf((*MockIdempotencyDataSource)(nil).PurgeCustomer), ctx, customerID)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyDataSource) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyDataSourceMockRecorder) Release(ctx, record any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyDataSource)(nil).Release), ctx, record)
+}
+
+// Reserve mocks base method.
+func (m *MockIdempotencyDataSource) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reserve", ctx, record)
+	ret0, _ := ret[0].(*entity.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockIdempotencyDataSourceMockRecorder) Reserve(ctx, record any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockIdempotencyDataSource)(nil).Reserve), ctx, record)
+}
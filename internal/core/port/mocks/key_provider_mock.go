@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/key_provider_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/key_provider_port.go -destination=internal/core/port/mocks/key_provider_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockKeyProvider is a mock of KeyProvider interface.
+type MockKeyProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyProviderMockRecorder
+	isgomock struct{}
+}
+
+// MockKeyProviderMockRecorder is the mock recorder for MockKeyProvider.
+type MockKeyProviderMockRecorder struct {
+	mock *MockKeyProvider
+}
+
+// NewMockKeyProvider creates a new mock instance.
+func NewMockKeyProvider(ctrl *gomock.Controller) *MockKeyProvider {
+	mock := &MockKeyProvider{ctrl: ctrl}
+	mock.recorder = &MockKeyProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyProvider) EXPECT() *MockKeyProviderMockRecorder {
+	return m.recorder
+}
+
+// BlindIndexKey mocks base method.
+func (m *MockKeyProvider) BlindIndexKey(ctx context.Context) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlindIndexKey", ctx)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlindIndexKey indicates an expected call of BlindIndexKey.
+func (mr *MockKeyProviderMockRecorder) BlindIndexKey(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlindIndexKey", reflect.TypeOf((*MockKeyProvider)(nil).BlindIndexKey), ctx)
+}
+
+// DecryptDataKey mocks base method.
+func (m *MockKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecryptDataKey", ctx, wrapped)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecryptDataKey indicates an expected call of DecryptDataKey.
+func (mr *MockKeyProviderMockRecorder) DecryptDataKey(ctx, wrapped any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecryptDataKey", reflect.TypeOf((*MockKeyProvider)(nil).DecryptDataKey), ctx, wrapped)
+}
+
+// GenerateDataKey mocks base method.
+func (m *MockKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateDataKey", ctx)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GenerateDataKey indicates an expected call of GenerateDataKey.
+func (mr *MockKeyProviderMockRecorder) GenerateDataKey(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateDataKey", reflect.TypeOf((*MockKeyProvider)(nil).GenerateDataKey), ctx)
+}
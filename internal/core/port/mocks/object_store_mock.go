@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/object_store_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/object_store_port.go -destination=internal/core/port/mocks/object_store_mock.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	port "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockObjectStore is a mock of ObjectStore interface.
+type MockObjectStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockObjectStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockObjectStoreMockRecorder is the mock recorder for MockObjectStore.
+type MockObjectStoreMockRecorder struct {
+	mock *MockObjectStore
+}
+
+// NewMockObjectStore creates a new mock instance.
+func NewMockObjectStore(ctrl *gomock.Controller) *MockObjectStore {
+	mock := &MockObjectStore{ctrl: ctrl}
+	mock.recorder = &MockObjectStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockObjectStore) EXPECT() *MockObjectStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockObjectStore) Create(ctx context.Context, bucket, key string) (port.ObjectWriter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, bucket, key)
+	ret0, _ := ret[0].(port.ObjectWriter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockObjectStoreMockRecorder) Create(ctx, bucket, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockObjectStore)(nil).Create), ctx, bucket, key)
+}
+
+// Open mocks base method.
+func (m *MockObjectStore) Open(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, bucket, key)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockObjectStoreMockRecorder) Open(ctx, bucket, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockObjectStore)(nil).Open), ctx, bucket, key)
+}
+
+// Put mocks base method.
+func (m *MockObjectStore) Put(ctx context.Context, bucket, key string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, bucket, key, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockObjectStoreMockRecorder) Put(ctx, bucket, key, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockObjectStore)(nil).Put), ctx, bucket, key, body)
+}
+
+// MockObjectWriter is a mock of ObjectWriter interface.
+type MockObjectWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockObjectWriterMockRecorder
+	isgomock struct{}
+}
+
+// MockObjectWriterMockRecorder is the mock recorder for MockObjectWriter.
+type MockObjectWriterMockRecorder struct {
+	mock *MockObjectWriter
+}
+
+// NewMockObjectWriter creates a new mock instance.
+func NewMockObjectWriter(ctrl *gomock.Controller) *MockObjectWriter {
+	mock := &MockObjectWriter{ctrl: ctrl}
+	mock.recorder = &MockObjectWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockObjectWriter) EXPECT() *MockObjectWriterMockRecorder {
+	return m.recorder
+}
+
+// Abort mocks base method.
+func (m *MockObjectWriter) Abort() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Abort")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Abort indicates an expected call of Abort.
+func (mr *MockObjectWriterMockRecorder) Abort() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abort", reflect.TypeOf((*MockObjectWriter)(nil).Abort))
+}
+
+// Close mocks base method.
+func (m *MockObjectWriter) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockObjectWriterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockObjectWriter)(nil).Close))
+}
+
+// Write mocks base method.
+func (m *MockObjectWriter) Write(p []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockObjectWriterMockRecorder) Write(p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockObjectWriter)(nil).Write), p)
+}
@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+	"io"
+)
+
+// ObjectStore holds the objects named by storage events, like the S3 object created events of the imports,
+// and the files written by the exports
+type ObjectStore interface {
+	// Open returns the content of an object, failing with a NotFoundError when it doesn't exist
+	Open(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// Put stores an object, replacing it when it exists
+	Put(ctx context.Context, bucket, key string, body []byte) error
+	// Create returns a writer of a new object, which is only stored when the writer is closed
+	Create(ctx context.Context, bucket, key string) (ObjectWriter, error)
+}
+
+// ObjectWriter writes an object, like the parts of an S3 multipart upload
+type ObjectWriter interface {
+	io.Writer
+	// Close stores the object
+	Close() error
+	// Abort discards what was written, without storing the object
+	Abort() error
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// Export streams every customer not deleted to the writer, with the selected fields and masking. The customers
+// are read by parallel segments and written as they arrive, so they aren't held in memory nor sorted
+func (uc *customerUseCase) Export(ctx context.Context, i dto.ExportCustomersInput, writer port.CustomerExportWriter) (*dto.ExportCustomersOutput, error) {
+	fields, err := exportFields(i.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := i.Segments
+	if segments == 0 {
+		segments = dto.DefaultExportSegments
+	}
+	if segments < 1 || segments > dto.MaxExportSegments {
+		return nil, domain.NewInvalidInputError(domain.ErrExportInvalidSegments)
+	}
+
+	if err := writer.Begin(fields); err != nil {
+		return nil, domain.NewInternalError(err)
+	}
+
+	output := &dto.ExportCustomersOutput{Fields: fields}
+	err = uc.gateway.Scan(ctx, segments, func(customer *entity.Customer) error {
+		if customer.IsDeleted() {
+			return nil
+		}
+		if i.MaskPII {
+			customer = customer.Masked()
+		}
+		output.Count++
+		return writer.Write(customer)
+	})
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, domain.NewInternalError(err)
+	}
+	return output, nil
+}
+
+// exportFields validates the selected fields, which default to every field
+func exportFields(selected []dto.ExportField) ([]dto.ExportField, error) {
+	if len(selected) == 0 {
+		return dto.ExportFields, nil
+	}
+
+	seen := make(map[dto.ExportField]bool, len(selected))
+	for _, field := range selected {
+		if seen[field] || !slices.Contains(dto.ExportFields, field) {
+			return nil, domain.NewInvalidInputError(domain.ErrExportInvalidField)
+		}
+		seen[field] = true
+	}
+	return selected, nil
+}
@@ -0,0 +1,172 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	mockport "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+)
+
+// scanCustomers mimics a gateway Scan visiting the customers
+func scanCustomers(customers []*entity.Customer) func(context.Context, int, func(*entity.Customer) error) error {
+	return func(_ context.Context, _ int, visit func(*entity.Customer) error) error {
+		for _, customer := range customers {
+			if err := visit(customer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestCustomerUseCase_Export(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockWriter := mockport.NewMockCustomerExportWriter(ctrl)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
+	ctx := context.Background()
+	mockCustomers := createMockCustomers()
+
+	tests := []struct {
+		name        string
+		input       dto.ExportCustomersInput
+		setupMocks  func()
+		checkResult func(*testing.T, *dto.ExportCustomersOutput, error)
+	}{
+		{
+			name:  "should export every field of every customer by default",
+			input: dto.ExportCustomersInput{},
+			setupMocks: func() {
+				gomock.InOrder(
+					mockWriter.EXPECT().Begin(dto.ExportFields).Return(nil),
+					mockGateway.EXPECT().Scan(ctx, dto.DefaultExportSegments, gomock.Any()).DoAndReturn(scanCustomers(mockCustomers)),
+					mockWriter.EXPECT().Close().Return(nil),
+				)
+				mockWriter.EXPECT().Write(mockCustomers[0]).Return(nil)
+				mockWriter.EXPECT().Write(mockCustomers[1]).Return(nil)
+			},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, output.Count)
+				assert.Equal(t, dto.ExportFields, output.Fields)
+			},
+		},
+		{
+			name:  "should leave the deleted customers out",
+			input: dto.ExportCustomersInput{},
+			setupMocks: func() {
+				deletedAt := time.Now()
+				deleted := &entity.Customer{ID: 999, DeletedAt: &deletedAt}
+				mockWriter.EXPECT().Begin(dto.ExportFields).Return(nil)
+				mockGateway.EXPECT().Scan(ctx, dto.DefaultExportSegments, gomock.Any()).
+					DoAndReturn(scanCustomers([]*entity.Customer{mockCustomers[0], deleted}))
+				mockWriter.EXPECT().Write(mockCustomers[0]).Return(nil)
+				mockWriter.EXPECT().Close().Return(nil)
+			},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, output.Count)
+			},
+		},
+		{
+			name: "should export the selected fields of the masked customers",
+			input: dto.ExportCustomersInput{
+				Fields:   []dto.ExportField{dto.ExportFieldCPF, dto.ExportFieldEmail},
+				MaskPII:  true,
+				Segments: 8,
+			},
+			setupMocks: func() {
+				mockWriter.EXPECT().Begin([]dto.ExportField{dto.ExportFieldCPF, dto.ExportFieldEmail}).Return(nil)
+				mockGateway.EXPECT().Scan(ctx, 8, gomock.Any()).DoAndReturn(scanCustomers(mockCustomers[:1]))
+				mockWriter.EXPECT().
+					Write(gomock.Any()).
+					DoAndReturn(func(customer *entity.Customer) error {
+						assert.Equal(t, mockCustomers[0].ID, customer.ID)
+						assert.Equal(t, "T*** C*** 1***", customer.Name)
+						assert.Equal(t, "t***@email.com", customer.Email)
+						assert.Equal(t, "***.456.789-**", customer.CPF)
+						return nil
+					})
+				mockWriter.EXPECT().Close().Return(nil)
+			},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, output.Count)
+				assert.Equal(t, "12345678909", mockCustomers[0].CPF, "the scanned customer must not be changed")
+			},
+		},
+		{
+			name:  "should stop the scan when the writer fails",
+			input: dto.ExportCustomersInput{},
+			setupMocks: func() {
+				mockWriter.EXPECT().Begin(dto.ExportFields).Return(nil)
+				mockGateway.EXPECT().Scan(ctx, dto.DefaultExportSegments, gomock.Any()).DoAndReturn(scanCustomers(mockCustomers))
+				mockWriter.EXPECT().Write(mockCustomers[0]).Return(assert.AnError)
+			},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InternalError{}, err)
+			},
+		},
+		{
+			name:  "should return service unavailable when the scan is unavailable",
+			input: dto.ExportCustomersInput{},
+			setupMocks: func() {
+				mockWriter.EXPECT().Begin(dto.ExportFields).Return(nil)
+				mockGateway.EXPECT().
+					Scan(ctx, dto.DefaultExportSegments, gomock.Any()).
+					Return(domain.NewServiceUnavailableError(assert.AnError))
+			},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.ServiceUnavailableError{}, err)
+			},
+		},
+		{
+			name:       "should return invalid input error when a field is unknown",
+			input:      dto.ExportCustomersInput{Fields: []dto.ExportField{dto.ExportFieldID, "password"}},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+			},
+		},
+		{
+			name:       "should return invalid input error when a field is repeated",
+			input:      dto.ExportCustomersInput{Fields: []dto.ExportField{dto.ExportFieldID, dto.ExportFieldID}},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+			},
+		},
+		{
+			name:       "should return invalid input error when there are too many segments",
+			input:      dto.ExportCustomersInput{Segments: dto.MaxExportSegments + 1},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, output *dto.ExportCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			output, err := useCase.Export(ctx, tt.input, mockWriter)
+			tt.checkResult(t, output, err)
+		})
+	}
+}
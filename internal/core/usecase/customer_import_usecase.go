@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+// importBatchSize is the number of customers written by each SaveBatch call of an import
+const importBatchSize = 100
+
+// importedCustomer is a valid row waiting to be written, with the customer it replaces in upsert mode
+type importedCustomer struct {
+	result   *dto.ImportRowResult
+	customer *entity.Customer
+	existing *entity.Customer
+}
+
+// Import validates each row with the domain rules and writes the valid ones in batches. A row whose CPF
+// belongs to a customer is skipped or replaces it, according to the mode, and a CPF repeated in the rows
+// fails its later rows. An invalid row only fails itself, the report has the outcome of every row
+func (uc *customerUseCase) Import(ctx context.Context, i dto.ImportCustomersInput) (*dto.ImportCustomersReport, error) {
+	if i.Mode != dto.ImportModeSkip && i.Mode != dto.ImportModeUpsert {
+		return nil, domain.NewInvalidInputError(domain.ErrImportInvalidMode)
+	}
+
+	report := &dto.ImportCustomersReport{Total: len(i.Rows), Rows: make([]dto.ImportRowResult, len(i.Rows))}
+	seen := make(map[string]int, len(i.Rows))
+	pending := make([]importedCustomer, 0, importBatchSize)
+
+	for n, row := range i.Rows {
+		result := &report.Rows[n]
+		result.Line = row.Line
+		result.CPF = strings.TrimSpace(row.CPF)
+
+		customer, existing, err := uc.importedCustomer(ctx, row, i.Mode, seen)
+		switch {
+		case err != nil:
+			result.Status, result.Error = dto.ImportStatusFailed, err.Error()
+		case customer.ID > 0 && i.Mode == dto.ImportModeSkip:
+			result.Status, result.CustomerID = dto.ImportStatusSkipped, customer.ID
+		default:
+			pending = append(pending, importedCustomer{result: result, customer: customer, existing: existing})
+		}
+
+		if len(pending) == importBatchSize {
+			uc.saveImported(ctx, pending)
+			pending = pending[:0]
+		}
+	}
+	uc.saveImported(ctx, pending)
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case dto.ImportStatusCreated:
+			report.Created++
+		case dto.ImportStatusUpdated:
+			report.Updated++
+		case dto.ImportStatusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// importedCustomer validates a row and returns its customer. When the CPF belongs to a customer, it's
+// returned too, and the customer of the row has its ID and in upsert mode is ready to replace it
+func (uc *customerUseCase) importedCustomer(ctx context.Context, row dto.ImportCustomerRow, mode dto.ImportMode, seen map[string]int) (*entity.Customer, *entity.Customer, error) {
+	if row.Error != "" {
+		return nil, nil, errors.New(row.Error)
+	}
+
+	now := time.Now()
+	customer := &entity.Customer{
+		Name:      strings.TrimSpace(row.Name),
+		Email:     strings.TrimSpace(row.Email),
+		CPF:       strings.TrimSpace(row.CPF),
+		Status:    entity.CustomerStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := customer.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if line, ok := seen[customer.CPF]; ok {
+		return nil, nil, fmt.Errorf("%s, first seen on line %d", domain.ErrImportRepeatedCPF, line)
+	}
+	seen[customer.CPF] = row.Line
+
+	existing, err := uc.gateway.FindByCPF(ctx, customer.CPF)
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing == nil {
+		return customer, nil, nil
+	}
+	if mode == dto.ImportModeSkip {
+		return existing, existing, nil
+	}
+
+	// The data of an anonymized customer was erased, and an upsert must not write it back
+	if existing.IsAnonymized() {
+		return nil, nil, domain.NewConflictError(domain.ErrCustomerAnonymized)
+	}
+
+	// An upsert doesn't restore a deleted customer nor change its status, and keeps its consent history
+	customer.ID = existing.ID
+	customer.Version = existing.Version + 1
+	customer.CreatedAt = existing.CreatedAt
+	customer.DeletedAt = existing.DeletedAt
+	customer.Status = existing.Status
+	customer.StatusReason = existing.StatusReason
+	customer.StatusChangedAt = existing.StatusChangedAt
+	customer.AnonymizedAt = existing.AnonymizedAt
+	customer.Consents = existing.Consents
+	return customer, existing, nil
+}
+
+// saveImported writes a batch of customers and appends an audit entry for each one. When the batch fails,
+// its customers are written one by one, so a row that conflicts with a stored customer doesn't fail the others
+func (uc *customerUseCase) saveImported(ctx context.Context, pending []importedCustomer) {
+	if len(pending) == 0 {
+		return
+	}
+
+	customers := make([]*entity.Customer, len(pending))
+	created := make([]bool, len(pending))
+	for n, imported := range pending {
+		customers[n] = imported.customer
+		created[n] = imported.customer.ID == 0
+	}
+
+	err := uc.gateway.SaveBatch(ctx, customers)
+	if err != nil && len(pending) > 1 {
+		// The failed batch may have given IDs to the new customers
+		for n, customer := range customers {
+			if created[n] {
+				customer.ID, customer.Version = 0, 0
+			}
+		}
+		for _, imported := range pending {
+			uc.saveImported(ctx, []importedCustomer{imported})
+		}
+		return
+	}
+
+	for n, imported := range pending {
+		switch {
+		case err != nil:
+			imported.result.Status, imported.result.Error = dto.ImportStatusFailed, err.Error()
+			continue
+		case created[n]:
+			imported.result.Status, imported.result.CustomerID = dto.ImportStatusCreated, imported.customer.ID
+		default:
+			imported.result.Status, imported.result.CustomerID = dto.ImportStatusUpdated, imported.customer.ID
+		}
+
+		// The row is saved, so it keeps its status and only reports the missing entry
+		entry := entity.NewAuditEntry(ctx, entity.AuditActionImport, imported.existing, imported.customer)
+		if err := uc.auditGateway.Append(ctx, entry); err != nil {
+			imported.result.Error = fmt.Sprintf("%s: %s", domain.ErrAuditNotRecorded, err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	mockport "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+)
+
+// assignIDs mimics SaveBatch giving IDs to the new customers
+func assignIDs(firstID int) func(context.Context, []*entity.Customer) error {
+	return func(_ context.Context, customers []*entity.Customer) error {
+		for _, customer := range customers {
+			if customer.ID == 0 {
+				customer.ID, customer.Version = firstID, 1
+				firstID++
+			}
+		}
+		return nil
+	}
+}
+
+func TestCustomerUseCase_Import(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	mockAuditGateway.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	ctx := context.Background()
+	existing := createMockCustomers()[0]
+	existing.Version = 3
+
+	newRow := dto.ImportCustomerRow{Line: 2, Name: " New Customer ", Email: "new@email.com", CPF: "98765432100"}
+	existingRow := dto.ImportCustomerRow{Line: 3, Name: "Renamed Customer", Email: "renamed@email.com", CPF: existing.CPF}
+
+	tests := []struct {
+		name        string
+		input       dto.ImportCustomersInput
+		setupMocks  func()
+		checkResult func(*testing.T, *dto.ImportCustomersReport, error)
+	}{
+		{
+			name:  "should create new customers and skip the existing ones in skip mode",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{newRow, existingRow}, Mode: dto.ImportModeSkip},
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByCPF(ctx, newRow.CPF).Return(nil, nil)
+				mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(existing, nil)
+				mockGateway.EXPECT().
+					SaveBatch(ctx, gomock.Len(1)).
+					DoAndReturn(func(ctx context.Context, customers []*entity.Customer) error {
+						assert.Equal(t, "New Customer", customers[0].Name)
+						assert.Equal(t, entity.CustomerStatusActive, customers[0].Status)
+						return assignIDs(1000)(ctx, customers)
+					})
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, report.Total)
+				assert.Equal(t, 1, report.Created)
+				assert.Equal(t, 1, report.Skipped)
+				assert.Equal(t, []dto.ImportRowResult{
+					{Line: 2, CPF: newRow.CPF, Status: dto.ImportStatusCreated, CustomerID: 1000},
+					{Line: 3, CPF: existing.CPF, Status: dto.ImportStatusSkipped, CustomerID: existing.ID},
+				}, report.Rows)
+			},
+		},
+		{
+			name:  "should replace the existing customers in upsert mode",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{existingRow}, Mode: dto.ImportModeUpsert},
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(existing, nil)
+				mockGateway.EXPECT().
+					SaveBatch(ctx, gomock.Len(1)).
+					DoAndReturn(func(_ context.Context, customers []*entity.Customer) error {
+						assert.Equal(t, existing.ID, customers[0].ID)
+						assert.Equal(t, existing.Version+1, customers[0].Version)
+						assert.Equal(t, existing.CreatedAt, customers[0].CreatedAt)
+						assert.Equal(t, "Renamed Customer", customers[0].Name)
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Updated)
+				assert.Equal(t, dto.ImportStatusUpdated, report.Rows[0].Status)
+				assert.Equal(t, existing.ID, report.Rows[0].CustomerID)
+			},
+		},
+		{
+			name:  "should keep a deleted customer deleted in upsert mode",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{existingRow}, Mode: dto.ImportModeUpsert},
+			setupMocks: func() {
+				deleted := *existing
+				deletedAt := time.Now()
+				deleted.DeletedAt = &deletedAt
+				mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(&deleted, nil)
+				mockGateway.EXPECT().
+					SaveBatch(ctx, gomock.Len(1)).
+					DoAndReturn(func(_ context.Context, customers []*entity.Customer) error {
+						assert.Equal(t, &deletedAt, customers[0].DeletedAt)
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Updated)
+			},
+		},
+		{
+			name:  "should keep the status of a blocked customer in upsert mode",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{existingRow}, Mode: dto.ImportModeUpsert},
+			setupMocks: func() {
+				blocked := *existing
+				blocked.Status = entity.CustomerStatusBlocked
+				blocked.StatusReason = "chargeback fraud"
+				mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(&blocked, nil)
+				mockGateway.EXPECT().
+					SaveBatch(ctx, gomock.Len(1)).
+					DoAndReturn(func(_ context.Context, customers []*entity.Customer) error {
+						assert.Equal(t, entity.CustomerStatusBlocked, customers[0].Status)
+						assert.Equal(t, "chargeback fraud", customers[0].StatusReason)
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Updated)
+			},
+		},
+		{
+			name:  "should keep the consent history of the customer in upsert mode",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{existingRow}, Mode: dto.ImportModeUpsert},
+			setupMocks: func() {
+				withConsents := *existing
+				withConsents.Consents = []entity.Consent{{
+					Purpose:       entity.ConsentPurposeMarketing,
+					Granted:       true,
+					PolicyVersion: "2024-01",
+					Channel:       entity.ConsentChannelApp,
+					RecordedAt:    time.Now(),
+				}}
+				mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(&withConsents, nil)
+				mockGateway.EXPECT().
+					SaveBatch(ctx, gomock.Len(1)).
+					DoAndReturn(func(_ context.Context, customers []*entity.Customer) error {
+						assert.Equal(t, withConsents.Consents, customers[0].Consents)
+						assert.Equal(t, "Renamed Customer", customers[0].Name)
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Updated)
+			},
+		},
+		{
+			name:  "should not write the data of a row back onto an anonymized customer",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{existingRow}, Mode: dto.ImportModeUpsert},
+			setupMocks: func() {
+				anonymized := *existing
+				anonymized.Anonymize()
+				mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(&anonymized, nil)
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Failed)
+				assert.Equal(t, domain.ErrCustomerAnonymized, report.Rows[0].Error)
+			},
+		},
+		{
+			name: "should fail the invalid, unreadable and repeated rows only",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{
+				newRow,
+				{Line: 3, Name: "Invalid Email", Email: "invalid", CPF: "11122233396"},
+				{Line: 4, Error: "bare \" in non-quoted field"},
+				{Line: 5, Name: "Repeated", Email: "repeated@email.com", CPF: newRow.CPF},
+			}, Mode: dto.ImportModeSkip},
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByCPF(ctx, newRow.CPF).Return(nil, nil)
+				mockGateway.EXPECT().SaveBatch(ctx, gomock.Len(1)).DoAndReturn(assignIDs(1000))
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Created)
+				assert.Equal(t, 3, report.Failed)
+				assert.Equal(t, domain.ErrInvalidEmail, report.Rows[1].Error)
+				assert.Equal(t, "bare \" in non-quoted field", report.Rows[2].Error)
+				assert.Equal(t, fmt.Sprintf("%s, first seen on line 2", domain.ErrImportRepeatedCPF), report.Rows[3].Error)
+			},
+		},
+		{
+			name: "should write the rows one by one when a batch fails",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{
+				newRow,
+				{Line: 3, Name: "Conflicting", Email: "conflicting@email.com", CPF: "11122233396"},
+			}, Mode: dto.ImportModeSkip},
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByCPF(ctx, gomock.Any()).Return(nil, nil).Times(2)
+				gomock.InOrder(
+					mockGateway.EXPECT().SaveBatch(ctx, gomock.Len(2)).
+						DoAndReturn(func(ctx context.Context, customers []*entity.Customer) error {
+							_ = assignIDs(1000)(ctx, customers)
+							return domain.NewConflictError(domain.ErrCPFAlreadyExists)
+						}),
+					mockGateway.EXPECT().SaveBatch(ctx, gomock.Len(1)).DoAndReturn(assignIDs(1000)),
+					mockGateway.EXPECT().SaveBatch(ctx, gomock.Len(1)).
+						Return(domain.NewConflictError(domain.ErrCPFAlreadyExists)),
+				)
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Created)
+				assert.Equal(t, 1, report.Failed)
+				assert.Equal(t, 1000, report.Rows[0].CustomerID)
+				assert.Equal(t, domain.ErrCPFAlreadyExists, report.Rows[1].Error)
+				assert.Zero(t, report.Rows[1].CustomerID)
+			},
+		},
+		{
+			name:  "should fail the row when the CPF lookup fails",
+			input: dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{newRow}, Mode: dto.ImportModeSkip},
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByCPF(ctx, newRow.CPF).Return(nil, assert.AnError)
+			},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, report.Failed)
+				assert.Equal(t, dto.ImportStatusFailed, report.Rows[0].Status)
+			},
+		},
+		{
+			name:       "should return invalid input error when the mode is unknown",
+			input:      dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{newRow}, Mode: "replace"},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, report *dto.ImportCustomersReport, err error) {
+				assert.Nil(t, report)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			report, err := useCase.Import(ctx, tt.input)
+			tt.checkResult(t, report, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_Import_Audit(t *testing.T) {
+	ctx := entity.ContextWithRequestID(context.Background(), "invocation-1")
+	existing := createMockCustomers()[0]
+	newRow := dto.ImportCustomerRow{Line: 2, Name: "New Customer", Email: "new@email.com", CPF: "98765432100"}
+	existingRow := dto.ImportCustomerRow{Line: 3, Name: "Renamed Customer", Email: existing.Email, CPF: existing.CPF}
+
+	t.Run("should record the saved rows with the request ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockGateway := mockport.NewMockCustomerGateway(ctrl)
+		mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+		useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+
+		mockGateway.EXPECT().FindByCPF(ctx, newRow.CPF).Return(nil, nil)
+		mockGateway.EXPECT().FindByCPF(ctx, existing.CPF).Return(existing, nil)
+		mockGateway.EXPECT().SaveBatch(ctx, gomock.Len(2)).DoAndReturn(assignIDs(1000))
+
+		var entries []*entity.AuditEntry
+		mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, entry *entity.AuditEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+
+		report, err := useCase.Import(ctx, dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{newRow, existingRow}, Mode: dto.ImportModeUpsert})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, 1000, entries[0].CustomerID)
+			assert.Equal(t, entity.AuditActionImport, entries[0].Action)
+			assert.Equal(t, "invocation-1", entries[0].RequestID)
+			assert.Empty(t, entries[0].Actor)
+			assert.Equal(t, existing.ID, entries[1].CustomerID)
+			assert.Equal(t, []entity.AuditChange{{Field: "name", Before: existing.Name, After: "Renamed Customer"}}, entries[1].Changes)
+		}
+	})
+
+	t.Run("should keep the status of a saved row whose entry wasn't recorded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockGateway := mockport.NewMockCustomerGateway(ctrl)
+		mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+		useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+
+		mockGateway.EXPECT().FindByCPF(ctx, newRow.CPF).Return(nil, nil)
+		mockGateway.EXPECT().SaveBatch(ctx, gomock.Len(1)).DoAndReturn(assignIDs(1000))
+		mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Return(assert.AnError)
+
+		report, err := useCase.Import(ctx, dto.ImportCustomersInput{Rows: []dto.ImportCustomerRow{newRow}, Mode: dto.ImportModeSkip})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, dto.ImportStatusCreated, report.Rows[0].Status)
+		assert.Contains(t, report.Rows[0].Error, domain.ErrAuditNotRecorded)
+	})
+}
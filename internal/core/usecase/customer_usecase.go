@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
@@ -11,18 +13,27 @@ import (
 )
 
 type customerUseCase struct {
-	gateway port.CustomerGateway
+	gateway        port.CustomerGateway
+	auditGateway   port.AuditGateway
+	eventPublisher port.EventPublisher
 }
 
-// NewCustomerUseCase creates a new CreateCustomerUseCase
-func NewCustomerUseCase(gateway port.CustomerGateway) port.CustomerUseCase {
-	return &customerUseCase{gateway}
+// NewCustomerUseCase creates a new CreateCustomerUseCase, which records each change of a customer
+// in the audit gateway and publishes the domain events of the customers
+func NewCustomerUseCase(gateway port.CustomerGateway, auditGateway port.AuditGateway, eventPublisher port.EventPublisher) port.CustomerUseCase {
+	return &customerUseCase{gateway, auditGateway, eventPublisher}
 }
 
 func (uc *customerUseCase) List(ctx context.Context, i dto.ListCustomersInput) ([]*entity.Customer, int64, error) {
-	customers, total, err := uc.gateway.FindAll(ctx, i.Name, i.Page, i.Limit)
+	if i.IncludeDeleted {
+		if err := requireAdmin(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	customers, total, err := uc.gateway.FindAll(ctx, i.Name, i.Search, i.IncludeDeleted, i.AfterID, i.Page, i.Limit)
 	if err != nil {
-		return nil, 0, domain.NewInternalError(err)
+		return nil, 0, gatewayError(err)
 	}
 
 	return customers, total, nil
@@ -35,82 +46,474 @@ func (uc *customerUseCase) Create(ctx context.Context, i dto.CreateCustomerInput
 		Name:      i.Name,
 		Email:     i.Email,
 		CPF:       i.CPF,
+		Status:    entity.CustomerStatusActive,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if err := customer.Validate(); err != nil {
+		return nil, err
+	}
 
 	if err := uc.gateway.Create(ctx, customer); err != nil {
-		return nil, domain.NewInternalError(err)
+		return nil, gatewayError(err)
 	}
+	uc.audit(ctx, entity.AuditActionCreate, nil, customer)
 
 	return customer, nil
 }
 
 // Get returns a Customer by ID
 func (uc *customerUseCase) Get(ctx context.Context, i dto.GetCustomerInput) (*entity.Customer, error) {
+	if i.IncludeDeleted {
+		if err := requireAdmin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	customer, err := uc.gateway.FindByID(ctx, i.ID)
 	if err != nil {
-		return nil, domain.NewInternalError(err)
+		return nil, gatewayError(err)
 	}
 
-	if customer == nil {
+	if customer == nil || (customer.IsDeleted() && !i.IncludeDeleted) {
 		return nil, domain.NewNotFoundError(domain.ErrNotFound)
 	}
 
 	return customer, nil
 }
 
+// BatchGet returns the customers of a list of IDs, reporting the IDs without a customer, deleted
+// customers included. Repeated IDs are looked up once
+func (uc *customerUseCase) BatchGet(ctx context.Context, i dto.BatchGetCustomersInput) (*dto.BatchGetCustomersOutput, error) {
+	if len(i.IDs) == 0 || len(i.IDs) > dto.MaxBatchGetCustomers {
+		return nil, domain.NewInvalidInputError(domain.ErrBatchGetIDsCount)
+	}
+
+	ids := make([]int, 0, len(i.IDs))
+	seen := make(map[int]bool, len(i.IDs))
+	for _, id := range i.IDs {
+		if id <= 0 {
+			return nil, domain.NewInvalidInputError(domain.ErrBatchGetInvalidID)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	customers, err := uc.gateway.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+
+	found := make(map[int]bool, len(customers))
+	output := &dto.BatchGetCustomersOutput{Customers: make([]*entity.Customer, 0, len(customers)), MissingIDs: make([]int, 0)}
+	for _, customer := range customers {
+		if !customer.IsDeleted() {
+			found[customer.ID] = true
+			output.Customers = append(output.Customers, customer)
+		}
+	}
+	for _, id := range ids {
+		if !found[id] {
+			output.MissingIDs = append(output.MissingIDs, id)
+		}
+	}
+	return output, nil
+}
+
 // GetByCPF return a customer by his CPF
 func (uc *customerUseCase) GetByCPF(ctx context.Context, i dto.GetCustomerByCPFInput) (*entity.Customer, error) {
-	var cpf = i.CPF
+	var cpf = entity.NormalizeCPF(i.CPF)
 	if cpf == "" {
-		cpf = "000.000.000-00"
+		cpf = "00000000000"
 	}
 
 	customers, err := uc.gateway.FindByCPF(ctx, cpf)
 	if err != nil {
-		return nil, domain.NewInternalError(err)
+		return nil, gatewayError(err)
 	}
 
-	if customers == nil {
+	if customers == nil || customers.IsDeleted() {
 		return nil, domain.NewNotFoundError("customer not found")
 	}
 
 	return customers, nil
 }
 
-// Update updates a Customer
+// Update updates the fields of a Customer present in the input, keeping the others. Only the customer
+// themself or an admin can do it
 func (uc *customerUseCase) Update(ctx context.Context, i dto.UpdateCustomerInput) (*entity.Customer, error) {
+	if err := requireSelfOrAdmin(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
 	customer, err := uc.gateway.FindByID(ctx, i.ID)
 	if err != nil {
-		return nil, domain.NewInternalError(err)
+		return nil, gatewayError(err)
 	}
-	if customer == nil {
+	if customer == nil || customer.IsDeleted() {
 		return nil, domain.NewNotFoundError(domain.ErrNotFound)
 	}
 
-	customer.Update(i.Name, i.Email)
+	if i.Version != nil && *i.Version != customer.Version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+
+	if customer.IsAnonymized() {
+		return nil, domain.NewConflictError(domain.ErrCustomerAnonymized)
+	}
+
+	if i.CPF != nil && entity.NormalizeCPF(*i.CPF) != entity.NormalizeCPF(customer.CPF) {
+		return nil, domain.NewValidationError(errors.New(domain.ErrCPFIsImmutable))
+	}
+
+	name, email := customer.Name, customer.Email
+	if i.Name != nil {
+		name = *i.Name
+	}
+	if i.Email != nil {
+		email = *i.Email
+	}
+
+	before := *customer
+	customer.Update(name, email)
+	if err := customer.Validate(); err != nil {
+		return nil, err
+	}
 
 	if err := uc.gateway.Update(ctx, customer); err != nil {
-		return nil, domain.NewInternalError(err)
+		return nil, gatewayError(err)
 	}
+	uc.audit(ctx, entity.AuditActionUpdate, &before, customer)
 
 	return customer, nil
 }
 
-// Delete deletes a Customer
+// Delete marks a Customer as deleted, on the request of the customer themself or of an admin. It's kept,
+// so it can be restored, until the retention period of the data source purges it
 func (uc *customerUseCase) Delete(ctx context.Context, i dto.DeleteCustomerInput) (*entity.Customer, error) {
+	if err := requireSelfOrAdmin(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil || customer.IsDeleted() {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+
+	if i.Version != nil && *i.Version != customer.Version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+
+	before := *customer
+	customer.Delete()
+	if err := uc.gateway.Update(ctx, customer); err != nil {
+		return nil, gatewayError(err)
+	}
+	uc.audit(ctx, entity.AuditActionDelete, &before, customer)
+
+	return customer, nil
+}
+
+// Restore undoes the deletion of a Customer not purged yet, which only admins can do
+func (uc *customerUseCase) Restore(ctx context.Context, i dto.RestoreCustomerInput) (*entity.Customer, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
 	customer, err := uc.gateway.FindByID(ctx, i.ID)
 	if err != nil {
-		return nil, domain.NewInternalError(err)
+		return nil, gatewayError(err)
 	}
 	if customer == nil {
 		return nil, domain.NewNotFoundError(domain.ErrNotFound)
 	}
+	if !customer.IsDeleted() {
+		return nil, domain.NewConflictError(domain.ErrCustomerNotDeleted)
+	}
 
-	if err := uc.gateway.Delete(ctx, i.ID); err != nil {
-		return nil, domain.NewInternalError(err)
+	if i.Version != nil && *i.Version != customer.Version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
 	}
 
+	before := *customer
+	customer.Restore()
+	if err := uc.gateway.Update(ctx, customer); err != nil {
+		return nil, gatewayError(err)
+	}
+	uc.audit(ctx, entity.AuditActionRestore, &before, customer)
+
 	return customer, nil
 }
+
+// ChangeStatus moves a Customer to another status allowed from its current one, which only admins can do
+func (uc *customerUseCase) ChangeStatus(ctx context.Context, i dto.ChangeCustomerStatusInput) (*entity.Customer, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	status, err := entity.ParseCustomerStatus(i.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil || customer.IsDeleted() {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+
+	if i.Version != nil && *i.Version != customer.Version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+
+	before := *customer
+	if err := customer.ChangeStatus(status, i.Reason); err != nil {
+		return nil, err
+	}
+	if err := uc.gateway.Update(ctx, customer); err != nil {
+		return nil, gatewayError(err)
+	}
+	uc.audit(ctx, entity.AuditActionChangeStatus, &before, customer)
+
+	return customer, nil
+}
+
+// Anonymize erases the personal data of a Customer on the request of the customer themself or of an admin,
+// keeping its ID. The personal data recorded by its earlier audit entries is erased first, so a failure leaves
+// the customer to be anonymized again. The erasure is recorded in the audit trail and published as a domain
+// event, so the services downstream erase their copies. A deleted customer is only anonymized by admins, like
+// its other lookups
+func (uc *customerUseCase) Anonymize(ctx context.Context, i dto.AnonymizeCustomerInput) (*entity.Customer, error) {
+	if err := requireSelfOrAdmin(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil || (customer.IsDeleted() && !entity.PrincipalFromContext(ctx).IsAdmin()) {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	if customer.IsAnonymized() {
+		return nil, domain.NewConflictError(domain.ErrCustomerAnonymized)
+	}
+
+	if i.Version != nil && *i.Version != customer.Version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+
+	if err := uc.auditGateway.EraseCustomer(ctx, customer.ID); err != nil {
+		return nil, domain.NewInternalError(fmt.Errorf("%s: %w", domain.ErrAuditNotErased, err))
+	}
+
+	before := *customer
+	customer.Anonymize()
+	if err := uc.gateway.Update(ctx, customer); err != nil {
+		return nil, gatewayError(err)
+	}
+	uc.audit(ctx, entity.AuditActionAnonymize, &before, customer)
+
+	event := entity.NewCustomerEvent(ctx, entity.CustomerEventAnonymized, customer.ID)
+	if err := uc.eventPublisher.Publish(ctx, event); err != nil {
+		return nil, domain.NewInternalError(fmt.Errorf("%s: %w", domain.ErrEventNotPublished, err))
+	}
+
+	return customer, nil
+}
+
+// Authenticate returns the customer of a CPF when its account is active, so the other statuses can't sign in
+func (uc *customerUseCase) Authenticate(ctx context.Context, i dto.AuthenticateCustomerInput) (*entity.Customer, error) {
+	customer, err := uc.GetByCPF(ctx, dto.GetCustomerByCPFInput{CPF: i.CPF})
+	if err != nil {
+		return nil, err
+	}
+
+	if !customer.IsActive() {
+		return nil, domain.NewForbiddenError(domain.ErrCustomerNotActive)
+	}
+
+	return customer, nil
+}
+
+// History returns the audit entries of a Customer, oldest first, which only admins can read. The entries
+// outlive the customer, so the history of a purged customer is still returned
+func (uc *customerUseCase) History(ctx context.Context, i dto.GetCustomerHistoryInput) ([]*entity.AuditEntry, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	entries, err := uc.auditGateway.FindByCustomerID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	return entries, nil
+}
+
+// DataExport assembles everything the service holds about a customer, for the customer themself or an admin.
+// A deleted customer is only exported to admins, like its other lookups
+func (uc *customerUseCase) DataExport(ctx context.Context, i dto.GetCustomerDataExportInput) (*dto.CustomerDataExport, error) {
+	if err := requireSelfOrAdmin(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil || (customer.IsDeleted() && !entity.PrincipalFromContext(ctx).IsAdmin()) {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+
+	entries, err := uc.auditGateway.FindByCustomerID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+
+	return &dto.CustomerDataExport{
+		Customer:   customer,
+		History:    entries,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+// GetConsents returns the consents of a customer to the customer themself or an admin. A deleted customer is only
+// returned to admins, like its other lookups
+func (uc *customerUseCase) GetConsents(ctx context.Context, i dto.GetCustomerConsentsInput) (*dto.CustomerConsents, error) {
+	if err := requireSelfOrAdmin(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil || (customer.IsDeleted() && !entity.PrincipalFromContext(ctx).IsAdmin()) {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+
+	return &dto.CustomerConsents{Customer: customer}, nil
+}
+
+// UpdateConsents records the decisions of a customer on the purposes of its data, on the request of the customer
+// themself or of an admin. The previous decisions are kept as the history of the consents
+func (uc *customerUseCase) UpdateConsents(ctx context.Context, i dto.UpdateCustomerConsentsInput) (*dto.CustomerConsents, error) {
+	if err := requireSelfOrAdmin(ctx, i.ID); err != nil {
+		return nil, err
+	}
+
+	if len(i.Consents) == 0 {
+		return nil, domain.NewValidationError(errors.New(domain.ErrConsentsAreMandatory))
+	}
+	purposes := make([]entity.ConsentPurpose, len(i.Consents))
+	channels := make([]entity.ConsentChannel, len(i.Consents))
+	for n, consent := range i.Consents {
+		if consent.Granted == nil {
+			return nil, domain.NewValidationError(errors.New(domain.ErrConsentGrantedIsMandatory))
+		}
+		purpose, err := entity.ParseConsentPurpose(consent.Purpose)
+		if err != nil {
+			return nil, err
+		}
+		channel, err := entity.ParseConsentChannel(consent.Channel)
+		if err != nil {
+			return nil, err
+		}
+		purposes[n], channels[n] = purpose, channel
+	}
+
+	customer, err := uc.gateway.FindByID(ctx, i.ID)
+	if err != nil {
+		return nil, gatewayError(err)
+	}
+	if customer == nil || customer.IsDeleted() {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	if customer.IsAnonymized() {
+		return nil, domain.NewConflictError(domain.ErrCustomerAnonymized)
+	}
+
+	if i.Version != nil && *i.Version != customer.Version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+
+	before := *customer
+	for n, consent := range i.Consents {
+		if err := customer.RecordConsent(purposes[n], *consent.Granted, consent.PolicyVersion, channels[n]); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.gateway.Update(ctx, customer); err != nil {
+		return nil, gatewayError(err)
+	}
+	uc.audit(ctx, entity.AuditActionConsents, &before, customer)
+
+	return &dto.CustomerConsents{Customer: customer}, nil
+}
+
+// audit appends the entry of an action on a customer. The customer is already saved when it's called, so
+// an entry that can't be appended doesn't fail the request, which the client would retry against a change
+// already made. The audit data source logs the failed append
+func (uc *customerUseCase) audit(ctx context.Context, action entity.AuditAction, before, after *entity.Customer) {
+	_ = uc.auditGateway.Append(ctx, entity.NewAuditEntry(ctx, action, before, after))
+}
+
+// requireAdmin only lets admins through. An anonymous request is unauthorized, and a request of
+// another role is forbidden
+func requireAdmin(ctx context.Context) error {
+	principal := entity.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.NewUnauthorizedError(domain.ErrMissingToken)
+	}
+	if !principal.IsAdmin() {
+		return domain.NewForbiddenError(domain.ErrAdminOnly)
+	}
+	return nil
+}
+
+// requireSelfOrAdmin only lets through the customer with the ID and the admins. An anonymous request is
+// unauthorized, and a request of another customer is forbidden
+func requireSelfOrAdmin(ctx context.Context, customerID int) error {
+	principal := entity.PrincipalFromContext(ctx)
+	if principal == nil {
+		return domain.NewUnauthorizedError(domain.ErrMissingToken)
+	}
+	if !principal.IsAdmin() && !principal.IsCustomer(customerID) {
+		return domain.NewForbiddenError(domain.ErrSelfOrAdmin)
+	}
+	return nil
+}
+
+// gatewayError keeps the domain errors raised by the gateway, like a concurrent modification detected
+// by a conditional write, an unavailable data source or a listing it can't serve, and wraps any other error
+// as an internal error
+func gatewayError(err error) error {
+	var notFound *domain.NotFoundError
+	var preconditionFailed *domain.PreconditionFailedError
+	var conflict *domain.ConflictError
+	var unavailable *domain.ServiceUnavailableError
+	var invalidInput *domain.InvalidInputError
+	if errors.As(err, &notFound) || errors.As(err, &preconditionFailed) || errors.As(err, &conflict) ||
+		errors.As(err, &unavailable) || errors.As(err, &invalidInput) {
+		return err
+	}
+	return domain.NewInternalError(err)
+}
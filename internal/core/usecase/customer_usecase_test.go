@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 	mockport "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port/mocks"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
 )
@@ -23,7 +25,7 @@ func createMockCustomers() []*entity.Customer {
 			ID:        123,
 			Name:      "Test Customer 1",
 			Email:     "test.customer.1@email.com",
-			CPF:       "12345678901",
+			CPF:       "12345678909",
 			CreatedAt: currentTime,
 			UpdatedAt: currentTime,
 		},
@@ -31,20 +33,32 @@ func createMockCustomers() []*entity.Customer {
 			ID:        321,
 			Name:      "Test Customer 2",
 			Email:     "test.customer.2@email.com",
-			CPF:       "12345678902",
+			CPF:       "52998224725",
 			CreatedAt: currentTime,
 			UpdatedAt: currentTime,
 		},
 	}
 }
 
+func stringPtr(s string) *string {
+	return &s
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
 func TestCustomersUseCase_List(t *testing.T) {
 	// Setup
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockGateway := mockport.NewMockCustomerGateway(ctrl)
-	useCase := usecase.NewCustomerUseCase(mockGateway)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
 	ctx := context.Background()
 	mockCustomers := createMockCustomers()
 
@@ -62,7 +76,7 @@ func TestCustomersUseCase_List(t *testing.T) {
 			},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindAll(ctx, "", 1, 10).
+					FindAll(ctx, "", "", false, 0, 1, 10).
 					Return(mockCustomers, int64(2), nil)
 			},
 			checkResult: func(t *testing.T, customers []*entity.Customer, total int64, err error) {
@@ -80,7 +94,7 @@ func TestCustomersUseCase_List(t *testing.T) {
 			},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindAll(ctx, "", 1, 10).
+					FindAll(ctx, "", "", false, 0, 1, 10).
 					Return(nil, int64(0), assert.AnError)
 			},
 			checkResult: func(t *testing.T, customers []*entity.Customer, total int64, err error) {
@@ -90,6 +104,23 @@ func TestCustomersUseCase_List(t *testing.T) {
 				assert.IsType(t, &domain.InternalError{}, err)
 			},
 		},
+		{
+			name: "should keep the error of a listing the data source can't serve",
+			input: dto.ListCustomersInput{
+				Page:  1,
+				Limit: 10,
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindAll(ctx, "", "", false, 0, 1, 10).
+					Return(nil, int64(0), domain.NewInvalidInputError(domain.ErrListScanLimitExceeded))
+			},
+			checkResult: func(t *testing.T, customers []*entity.Customer, total int64, err error) {
+				assert.Nil(t, customers)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+				assert.EqualError(t, err, domain.ErrListScanLimitExceeded)
+			},
+		},
 		{
 			name: "should filter by name",
 			input: dto.ListCustomersInput{
@@ -99,7 +130,7 @@ func TestCustomersUseCase_List(t *testing.T) {
 			},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindAll(ctx, "Test", 1, 10).
+					FindAll(ctx, "Test", "", false, 0, 1, 10).
 					Return(mockCustomers, int64(2), nil)
 			},
 			checkResult: func(t *testing.T, customers []*entity.Customer, total int64, err error) {
@@ -109,6 +140,24 @@ func TestCustomersUseCase_List(t *testing.T) {
 				assert.Equal(t, int64(2), total)
 			},
 		},
+		{
+			name: "should search by name words",
+			input: dto.ListCustomersInput{
+				Search: "test customer",
+				Page:   1,
+				Limit:  10,
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindAll(ctx, "", "test customer", false, 0, 1, 10).
+					Return(mockCustomers, int64(2), nil)
+			},
+			checkResult: func(t *testing.T, customers []*entity.Customer, total int64, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, len(mockCustomers), len(customers))
+				assert.Equal(t, int64(2), total)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,7 +180,9 @@ func TestCustomerUseCase_Create(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockGateway := mockport.NewMockCustomerGateway(ctrl)
-	useCase := usecase.NewCustomerUseCase(mockGateway)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	mockAuditGateway.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
 	ctx := context.Background()
 	mockCustomers := createMockCustomers()
 
@@ -159,6 +210,7 @@ func TestCustomerUseCase_Create(t *testing.T) {
 				assert.Equal(t, mockCustomers[0].Name, customer.Name)
 				assert.Equal(t, mockCustomers[0].Email, customer.Email)
 				assert.Equal(t, mockCustomers[0].CPF, customer.CPF)
+				assert.Equal(t, entity.CustomerStatusActive, customer.Status)
 			},
 		},
 		{
@@ -179,6 +231,131 @@ func TestCustomerUseCase_Create(t *testing.T) {
 				assert.IsType(t, &domain.InternalError{}, err)
 			},
 		},
+		{
+			name: "should return conflict error when customer already exists",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: mockCustomers[0].Email,
+				CPF:   mockCustomers[0].CPF,
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					Create(ctx, gomock.Any()).
+					Return(domain.NewConflictError(domain.ErrConflict))
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name: "should not create a customer without a name",
+			input: dto.CreateCustomerInput{
+				Name:  " ",
+				Email: mockCustomers[0].Email,
+				CPF:   mockCustomers[0].CPF,
+			},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+				assert.EqualError(t, err, domain.ErrNameIsMandatory)
+			},
+		},
+		{
+			name: "should not create a customer with an invalid email",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: "not-an-email",
+				CPF:   mockCustomers[0].CPF,
+			},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+				assert.EqualError(t, err, domain.ErrInvalidEmail)
+			},
+		},
+		{
+			name: "should not create a customer with an invalid CPF",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: mockCustomers[0].Email,
+				CPF:   "123",
+			},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+				assert.EqualError(t, err, domain.ErrInvalidCPF)
+			},
+		},
+		{
+			name: "should not create a customer with wrong CPF check digits",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: mockCustomers[0].Email,
+				CPF:   "123.456.789-00",
+			},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.EqualError(t, err, domain.ErrInvalidCPF)
+			},
+		},
+		{
+			name: "should not create a customer with a CPF of repeated digits",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: mockCustomers[0].Email,
+				CPF:   "111.111.111-11",
+			},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.EqualError(t, err, domain.ErrInvalidCPF)
+			},
+		},
+		{
+			name: "should store the email trimmed and in lowercase",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: " John.Doe@Email.COM ",
+				CPF:   mockCustomers[0].CPF,
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+						assert.Equal(t, "john.doe@email.com", customer.Email)
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "john.doe@email.com", customer.Email)
+			},
+		},
+		{
+			name: "should store the digits of a punctuated CPF",
+			input: dto.CreateCustomerInput{
+				Name:  mockCustomers[0].Name,
+				Email: mockCustomers[0].Email,
+				CPF:   " 123.456.789-09 ",
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+						assert.Equal(t, "12345678909", customer.CPF)
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "12345678909", customer.CPF)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -201,7 +378,7 @@ func TestCustomerUseCase_Get(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockGateway := mockport.NewMockCustomerGateway(ctrl)
-	useCase := usecase.NewCustomerUseCase(mockGateway)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
 	ctx := context.Background()
 	mockCustomers := createMockCustomers()
 
@@ -243,6 +420,29 @@ func TestCustomerUseCase_Get(t *testing.T) {
 				assert.IsType(t, &domain.NotFoundError{}, err)
 			},
 		},
+		{
+			name:  "should return not found error when customer is deleted",
+			input: dto.GetCustomerInput{ID: 123},
+			setupMocks: func() {
+				deletedAt := time.Now()
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(&entity.Customer{ID: 123, DeletedAt: &deletedAt}, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:       "should return unauthorized error when an anonymous request includes the deleted customers",
+			input:      dto.GetCustomerInput{ID: 123, IncludeDeleted: true},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.UnauthorizedError{}, err)
+			},
+		},
 		{
 			name:  "should return internal error when gateway fails",
 			input: dto.GetCustomerInput{ID: 123},
@@ -257,6 +457,19 @@ func TestCustomerUseCase_Get(t *testing.T) {
 				assert.IsType(t, &domain.InternalError{}, err)
 			},
 		},
+		{
+			name:  "should return service unavailable when the data source is unavailable",
+			input: dto.GetCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(nil, domain.NewServiceUnavailableError(assert.AnError))
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ServiceUnavailableError{}, err)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -273,106 +486,121 @@ func TestCustomerUseCase_Get(t *testing.T) {
 	}
 }
 
-func TestCustomerUseCase_Update(t *testing.T) {
+func TestCustomerUseCase_BatchGet(t *testing.T) {
 	// Setup
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockGateway := mockport.NewMockCustomerGateway(ctrl)
-	useCase := usecase.NewCustomerUseCase(mockGateway)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
 	ctx := context.Background()
 	mockCustomers := createMockCustomers()
 
+	tooMany := make([]int, dto.MaxBatchGetCustomers+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+
 	tests := []struct {
 		name        string
-		input       dto.UpdateCustomerInput
+		input       dto.BatchGetCustomersInput
 		setupMocks  func()
-		checkResult func(*testing.T, *entity.Customer, error)
+		checkResult func(*testing.T, *dto.BatchGetCustomersOutput, error)
 	}{
 		{
-			name: "should update customer successfully",
-			input: dto.UpdateCustomerInput{
-				ID:    123,
-				Name:  "New Name",
-				Email: "new.name@email.com",
-			},
+			name:  "should return the customers found and the missing IDs",
+			input: dto.BatchGetCustomersInput{IDs: []int{123, 999, 321, 123}},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByID(ctx, 123).
-					Return(mockCustomers[0], nil)
-
-				mockGateway.EXPECT().
-					Update(ctx, gomock.Any()).
-					DoAndReturn(func(_ context.Context, p *entity.Customer) error {
-						assert.Equal(t, "New Name", p.Name)
-						assert.Equal(t, "new.name@email.com", p.Email)
-						return nil
-					})
+					FindByIDs(ctx, []int{123, 999, 321}).
+					Return(mockCustomers, nil)
 			},
-			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
 				assert.NoError(t, err)
-				assert.NotNil(t, customer)
-				assert.Equal(t, "New Name", customer.Name)
-				assert.Equal(t, "new.name@email.com", customer.Email)
-				assert.Equal(t, mockCustomers[0].CreatedAt, customer.CreatedAt)
+				assert.Equal(t, mockCustomers, output.Customers)
+				assert.Equal(t, []int{999}, output.MissingIDs)
 			},
 		},
 		{
-			name: "should return error when customer not found",
-			input: dto.UpdateCustomerInput{
-				ID:    123,
-				Name:  "New Name",
-				Email: "new.name@email.com",
+			name:  "should report the deleted customers as missing",
+			input: dto.BatchGetCustomersInput{IDs: []int{123, 321}},
+			setupMocks: func() {
+				deletedAt := time.Now()
+				mockGateway.EXPECT().
+					FindByIDs(ctx, []int{123, 321}).
+					Return([]*entity.Customer{mockCustomers[0], {ID: 321, DeletedAt: &deletedAt}}, nil)
+			},
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, mockCustomers[:1], output.Customers)
+				assert.Equal(t, []int{321}, output.MissingIDs)
 			},
+		},
+		{
+			name:  "should return an empty list of missing IDs when every customer is found",
+			input: dto.BatchGetCustomersInput{IDs: []int{123}},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByID(ctx, 123).
-					Return(nil, nil)
+					FindByIDs(ctx, []int{123}).
+					Return(mockCustomers[:1], nil)
 			},
-			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
-				assert.Error(t, err)
-				assert.Nil(t, customer)
-				assert.IsType(t, &domain.NotFoundError{}, err)
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, output.MissingIDs)
+				assert.Empty(t, output.MissingIDs)
 			},
 		},
 		{
-			name: "should return error when gateway find fails",
-			input: dto.UpdateCustomerInput{
-				ID:    123,
-				Name:  "New Name",
-				Email: "new.name@email.com",
+			name:       "should return invalid input error when no IDs are given",
+			input:      dto.BatchGetCustomersInput{},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+			},
+		},
+		{
+			name:       "should return invalid input error when too many IDs are given",
+			input:      dto.BatchGetCustomersInput{IDs: tooMany},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
 			},
+		},
+		{
+			name:       "should return invalid input error when an ID is invalid",
+			input:      dto.BatchGetCustomersInput{IDs: []int{123, 0}},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+			},
+		},
+		{
+			name:  "should return internal error when gateway fails",
+			input: dto.BatchGetCustomersInput{IDs: []int{123}},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByID(ctx, 123).
+					FindByIDs(ctx, []int{123}).
 					Return(nil, assert.AnError)
 			},
-			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
-				assert.Error(t, err)
-				assert.Nil(t, customer)
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.Nil(t, output)
 				assert.IsType(t, &domain.InternalError{}, err)
 			},
 		},
 		{
-			name: "should return error when gateway update fails",
-			input: dto.UpdateCustomerInput{
-				ID:    123,
-				Name:  "New Name",
-				Email: "new.name@email.com",
-			},
+			name:  "should return service unavailable when the data source is unavailable",
+			input: dto.BatchGetCustomersInput{IDs: []int{123}},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByID(ctx, 123).
-					Return(mockCustomers[0], nil)
-
-				mockGateway.EXPECT().
-					Update(ctx, gomock.Any()).
-					Return(assert.AnError)
+					FindByIDs(ctx, []int{123}).
+					Return(nil, domain.NewServiceUnavailableError(assert.AnError))
 			},
-			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
-				assert.Error(t, err)
-				assert.Nil(t, customer)
-				assert.IsType(t, &domain.InternalError{}, err)
+			checkResult: func(t *testing.T, output *dto.BatchGetCustomersOutput, err error) {
+				assert.Nil(t, output)
+				assert.IsType(t, &domain.ServiceUnavailableError{}, err)
 			},
 		},
 	}
@@ -383,50 +611,68 @@ func TestCustomerUseCase_Update(t *testing.T) {
 			tt.setupMocks()
 
 			// Act
-			customer, err := useCase.Update(ctx, tt.input)
+			output, err := useCase.BatchGet(ctx, tt.input)
 
 			// Assert
-			tt.checkResult(t, customer, err)
+			tt.checkResult(t, output, err)
 		})
 	}
 }
 
-func TestCustomerUseCase_Delete(t *testing.T) {
+func TestCustomerUseCase_Update(t *testing.T) {
 	// Setup
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockGateway := mockport.NewMockCustomerGateway(ctrl)
-	useCase := usecase.NewCustomerUseCase(mockGateway)
-	ctx := context.Background()
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	mockAuditGateway.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer})
+	mockCustomers := createMockCustomers()
 
 	tests := []struct {
 		name        string
-		input       dto.DeleteCustomerInput
+		ctx         context.Context
+		input       dto.UpdateCustomerInput
 		setupMocks  func()
 		checkResult func(*testing.T, *entity.Customer, error)
 	}{
 		{
-			name:  "should delete customer successfully",
-			input: dto.DeleteCustomerInput{ID: 123},
+			name: "should update customer successfully",
+			input: dto.UpdateCustomerInput{
+				ID:    123,
+				Name:  stringPtr("New Name"),
+				Email: stringPtr("new.name@email.com"),
+			},
 			setupMocks: func() {
 				mockGateway.EXPECT().
 					FindByID(ctx, 123).
-					Return(&entity.Customer{ID: 123}, nil)
+					Return(mockCustomers[0], nil)
 
 				mockGateway.EXPECT().
-					Delete(ctx, 123).
-					Return(nil)
+					Update(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, p *entity.Customer) error {
+						assert.Equal(t, "New Name", p.Name)
+						assert.Equal(t, "new.name@email.com", p.Email)
+						return nil
+					})
 			},
 			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
 				assert.NoError(t, err)
 				assert.NotNil(t, customer)
-				assert.Equal(t, 123, customer.ID)
+				assert.Equal(t, "New Name", customer.Name)
+				assert.Equal(t, "new.name@email.com", customer.Email)
+				assert.Equal(t, mockCustomers[0].CreatedAt, customer.CreatedAt)
 			},
 		},
 		{
-			name:  "should return not found error when customer doesn't exist",
-			input: dto.DeleteCustomerInput{ID: 123},
+			name: "should return error when customer not found",
+			input: dto.UpdateCustomerInput{
+				ID:    123,
+				Name:  stringPtr("New Name"),
+				Email: stringPtr("new.name@email.com"),
+			},
 			setupMocks: func() {
 				mockGateway.EXPECT().
 					FindByID(ctx, 123).
@@ -439,8 +685,12 @@ func TestCustomerUseCase_Delete(t *testing.T) {
 			},
 		},
 		{
-			name:  "should return error when gateway fails on find",
-			input: dto.DeleteCustomerInput{ID: 123},
+			name: "should return error when gateway find fails",
+			input: dto.UpdateCustomerInput{
+				ID:    123,
+				Name:  stringPtr("New Name"),
+				Email: stringPtr("new.name@email.com"),
+			},
 			setupMocks: func() {
 				mockGateway.EXPECT().
 					FindByID(ctx, 123).
@@ -453,15 +703,298 @@ func TestCustomerUseCase_Delete(t *testing.T) {
 			},
 		},
 		{
-			name:  "should return error when gateway fails on delete",
-			input: dto.DeleteCustomerInput{ID: 123},
+			name: "should return error when gateway update fails",
+			input: dto.UpdateCustomerInput{
+				ID:    123,
+				Name:  stringPtr("New Name"),
+				Email: stringPtr("new.name@email.com"),
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(mockCustomers[0], nil)
+
+				mockGateway.EXPECT().
+					Update(ctx, gomock.Any()).
+					Return(assert.AnError)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.InternalError{}, err)
+			},
+		},
+		{
+			name: "should keep the fields absent from a partial update of an admin",
+			ctx:  entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin}),
+			input: dto.UpdateCustomerInput{
+				ID:   321,
+				Name: stringPtr("Only Name"),
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(gomock.Any(), 321).
+					Return(mockCustomers[1], nil)
+
+				mockGateway.EXPECT().
+					Update(gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "Only Name", customer.Name)
+				assert.Equal(t, "test.customer.2@email.com", customer.Email)
+			},
+		},
+		{
+			name: "should return validation error when a mandatory field is emptied",
+			input: dto.UpdateCustomerInput{
+				ID:    123,
+				Name:  stringPtr("New Name"),
+				Email: stringPtr(""),
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(createMockCustomers()[0], nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+				assert.EqualError(t, err, domain.ErrEmailIsMandatory)
+			},
+		},
+		{
+			name: "should return validation error when email is invalid",
+			input: dto.UpdateCustomerInput{
+				ID:    123,
+				Email: stringPtr("not an email"),
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(createMockCustomers()[0], nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.EqualError(t, err, domain.ErrInvalidEmail)
+			},
+		},
+		{
+			name: "should return validation error when cpf changes",
+			input: dto.UpdateCustomerInput{
+				ID:  123,
+				CPF: stringPtr("99999999999"),
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(createMockCustomers()[0], nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+				assert.EqualError(t, err, domain.ErrCPFIsImmutable)
+			},
+		},
+		{
+			name: "should accept the same cpf written with punctuation",
+			input: dto.UpdateCustomerInput{
+				ID:   123,
+				Name: stringPtr("New Name"),
+				CPF:  stringPtr("123.456.789-09"),
+			},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(createMockCustomers()[0], nil)
+
+				mockGateway.EXPECT().
+					Update(ctx, gomock.Any()).
+					Return(nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "12345678909", customer.CPF)
+			},
+		},
+		{
+			name: "should return precondition failed when version doesn't match",
+			input: dto.UpdateCustomerInput{
+				ID:      123,
+				Name:    stringPtr("Updated Name"),
+				Version: intPtr(1),
+			},
+			setupMocks: func() {
+				customer := createMockCustomers()[0]
+				customer.Version = 2
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(customer, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+				assert.EqualError(t, err, domain.ErrVersionMismatch)
+			},
+		},
+		{
+			name: "should return precondition failed when customer is modified concurrently",
+			input: dto.UpdateCustomerInput{
+				ID:      123,
+				Name:    stringPtr("Updated Name"),
+				Version: intPtr(2),
+			},
+			setupMocks: func() {
+				customer := createMockCustomers()[0]
+				customer.Version = 2
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(customer, nil)
+
+				mockGateway.EXPECT().
+					Update(ctx, gomock.Any()).
+					Return(domain.NewPreconditionFailedError(domain.ErrVersionMismatch))
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:       "should return unauthorized to an anonymous request",
+			ctx:        context.Background(),
+			input:      dto.UpdateCustomerInput{ID: 123},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.UnauthorizedError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden to another customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "456", Role: entity.RoleCustomer}),
+			input:      dto.UpdateCustomerInput{ID: 123},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.setupMocks()
+
+			ctx := ctx
+			if tt.ctx != nil {
+				ctx = tt.ctx
+			}
+
+			// Act
+			customer, err := useCase.Update(ctx, tt.input)
+
+			// Assert
+			tt.checkResult(t, customer, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_Delete(t *testing.T) {
+	// Setup
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	mockAuditGateway.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer})
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		input       dto.DeleteCustomerInput
+		setupMocks  func()
+		checkResult func(*testing.T, *entity.Customer, error)
+	}{
+		{
+			name:  "should delete customer successfully",
+			input: dto.DeleteCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(&entity.Customer{ID: 123, Version: 2}, nil)
+
+				mockGateway.EXPECT().
+					Update(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+						assert.Equal(t, 2, customer.Version)
+						assert.True(t, customer.IsDeleted())
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.NotNil(t, customer)
+				assert.Equal(t, 123, customer.ID)
+				assert.NotNil(t, customer.DeletedAt)
+			},
+		},
+		{
+			name:  "should return not found error when customer is already deleted",
+			input: dto.DeleteCustomerInput{ID: 123},
+			setupMocks: func() {
+				deletedAt := time.Now()
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(&entity.Customer{ID: 123, Version: 2, DeletedAt: &deletedAt}, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:  "should return not found error when customer doesn't exist",
+			input: dto.DeleteCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(nil, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:  "should return error when gateway fails on find",
+			input: dto.DeleteCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(nil, assert.AnError)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.InternalError{}, err)
+			},
+		},
+		{
+			name:  "should return error when gateway fails on delete",
+			input: dto.DeleteCustomerInput{ID: 123},
 			setupMocks: func() {
 				mockGateway.EXPECT().
 					FindByID(ctx, 123).
 					Return(&entity.Customer{}, nil)
 
 				mockGateway.EXPECT().
-					Delete(ctx, 123).
+					Update(ctx, gomock.Any()).
 					Return(assert.AnError)
 			},
 			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
@@ -470,6 +1003,56 @@ func TestCustomerUseCase_Delete(t *testing.T) {
 				assert.IsType(t, &domain.InternalError{}, err)
 			},
 		},
+		{
+			name:  "should return precondition failed when version doesn't match",
+			input: dto.DeleteCustomerInput{ID: 123, Version: intPtr(1)},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(&entity.Customer{ID: 123, Version: 2}, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:  "should return precondition failed when customer is modified concurrently",
+			input: dto.DeleteCustomerInput{ID: 123, Version: intPtr(2)},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(&entity.Customer{ID: 123, Version: 2}, nil)
+
+				mockGateway.EXPECT().
+					Update(ctx, gomock.Any()).
+					Return(domain.NewPreconditionFailedError(domain.ErrVersionMismatch))
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:       "should return unauthorized to an anonymous request",
+			ctx:        context.Background(),
+			input:      dto.DeleteCustomerInput{ID: 123},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.UnauthorizedError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden to another customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "456", Role: entity.RoleCustomer}),
+			input:      dto.DeleteCustomerInput{ID: 123},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -477,6 +1060,11 @@ func TestCustomerUseCase_Delete(t *testing.T) {
 			// Arrange
 			tt.setupMocks()
 
+			ctx := ctx
+			if tt.ctx != nil {
+				ctx = tt.ctx
+			}
+
 			// Act
 			customer, err := useCase.Delete(ctx, tt.input)
 
@@ -492,7 +1080,7 @@ func TestCustomerUseCase_GetByCPF(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockGateway := mockport.NewMockCustomerGateway(ctrl)
-	useCase := usecase.NewCustomerUseCase(mockGateway)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
 	ctx := context.Background()
 	mockCustomers := createMockCustomers()
 
@@ -502,12 +1090,25 @@ func TestCustomerUseCase_GetByCPF(t *testing.T) {
 		setupMocks  func()
 		checkResult func(*testing.T, *entity.Customer, error)
 	}{
+		{
+			name:  "should look up the digits of a punctuated CPF",
+			input: dto.GetCustomerByCPFInput{CPF: "123.456.789-09"},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByCPF(ctx, "12345678909").
+					Return(mockCustomers[0], nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, mockCustomers[0].ID, customer.ID)
+			},
+		},
 		{
 			name:  "should get customer by CPF successfully",
-			input: dto.GetCustomerByCPFInput{CPF: "12345678901"},
+			input: dto.GetCustomerByCPFInput{CPF: "12345678909"},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByCPF(ctx, "12345678901").
+					FindByCPF(ctx, "12345678909").
 					Return(mockCustomers[0], nil)
 			},
 			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
@@ -537,10 +1138,10 @@ func TestCustomerUseCase_GetByCPF(t *testing.T) {
 		},
 		{
 			name:  "should return internal error when gateway fails",
-			input: dto.GetCustomerByCPFInput{CPF: "12345678901"},
+			input: dto.GetCustomerByCPFInput{CPF: "12345678909"},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByCPF(ctx, "12345678901").
+					FindByCPF(ctx, "12345678909").
 					Return(nil, assert.AnError)
 			},
 			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
@@ -554,7 +1155,7 @@ func TestCustomerUseCase_GetByCPF(t *testing.T) {
 			input: dto.GetCustomerByCPFInput{CPF: ""},
 			setupMocks: func() {
 				mockGateway.EXPECT().
-					FindByCPF(ctx, "000.000.000-00").
+					FindByCPF(ctx, "00000000000").
 					Return(mockCustomers[0], nil)
 			},
 			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
@@ -578,3 +1179,1281 @@ func TestCustomerUseCase_GetByCPF(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomerUseCase_IncludeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
+	deletedAt := time.Now()
+	deleted := &entity.Customer{ID: 123, Version: 2, DeletedAt: &deletedAt}
+
+	tests := []struct {
+		name      string
+		principal *entity.Principal
+		wantErr   error
+	}{
+		{
+			name:      "should include the deleted customers for an admin",
+			principal: &entity.Principal{Subject: "ops", Role: entity.RoleAdmin},
+		},
+		{
+			name:      "should return forbidden error for a customer",
+			principal: &entity.Principal{Subject: "123", Role: entity.RoleCustomer},
+			wantErr:   &domain.ForbiddenError{},
+		},
+		{
+			name:    "should return unauthorized error for an anonymous request",
+			wantErr: &domain.UnauthorizedError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = entity.ContextWithPrincipal(ctx, tt.principal)
+			}
+			if tt.wantErr == nil {
+				mockGateway.EXPECT().
+					FindAll(ctx, "", "", true, 0, 1, 10).
+					Return([]*entity.Customer{deleted}, int64(1), nil)
+				mockGateway.EXPECT().
+					FindByID(ctx, 123).
+					Return(deleted, nil)
+			}
+
+			customers, _, listErr := useCase.List(ctx, dto.ListCustomersInput{IncludeDeleted: true, Page: 1, Limit: 10})
+			customer, getErr := useCase.Get(ctx, dto.GetCustomerInput{ID: 123, IncludeDeleted: true})
+
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, listErr)
+				assert.IsType(t, tt.wantErr, getErr)
+				return
+			}
+			assert.NoError(t, listErr)
+			assert.Equal(t, []*entity.Customer{deleted}, customers)
+			assert.NoError(t, getErr)
+			assert.Equal(t, deleted, customer)
+		})
+	}
+}
+
+func TestCustomerUseCase_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	mockAuditGateway.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	deletedCustomer := func() *entity.Customer {
+		deletedAt := time.Now()
+		return &entity.Customer{ID: 123, Version: 2, DeletedAt: &deletedAt}
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		input       dto.RestoreCustomerInput
+		setupMocks  func()
+		checkResult func(*testing.T, *entity.Customer, error)
+	}{
+		{
+			name:  "should restore a deleted customer",
+			ctx:   admin,
+			input: dto.RestoreCustomerInput{ID: 123, Version: intPtr(2)},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(deletedCustomer(), nil)
+				mockGateway.EXPECT().
+					Update(admin, gomock.Any()).
+					DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+						assert.Equal(t, 2, customer.Version)
+						assert.False(t, customer.IsDeleted())
+						customer.Version++
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, customer.Version)
+				assert.Nil(t, customer.DeletedAt)
+			},
+		},
+		{
+			name:  "should return conflict error when customer isn't deleted",
+			ctx:   admin,
+			input: dto.RestoreCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(&entity.Customer{ID: 123, Version: 2}, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name:  "should return not found error when customer doesn't exist",
+			ctx:   admin,
+			input: dto.RestoreCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(nil, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:  "should return precondition failed when version doesn't match",
+			ctx:   admin,
+			input: dto.RestoreCustomerInput{ID: 123, Version: intPtr(1)},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(deletedCustomer(), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:  "should return internal error when gateway fails on update",
+			ctx:   admin,
+			input: dto.RestoreCustomerInput{ID: 123},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(deletedCustomer(), nil)
+				mockGateway.EXPECT().
+					Update(admin, gomock.Any()).
+					Return(assert.AnError)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.InternalError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden error for a customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer}),
+			input:      dto.RestoreCustomerInput{ID: 123},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.setupMocks()
+
+			// Act
+			customer, err := useCase.Restore(tt.ctx, tt.input)
+
+			// Assert
+			tt.checkResult(t, customer, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_ChangeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	mockAuditGateway.EXPECT().Append(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	customerWithStatus := func(status entity.CustomerStatus) *entity.Customer {
+		return &entity.Customer{ID: 123, Version: 2, Status: status}
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		input       dto.ChangeCustomerStatusInput
+		setupMocks  func()
+		checkResult func(*testing.T, *entity.Customer, error)
+	}{
+		{
+			name:  "should block an active customer",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "Blocked", Reason: " chargeback fraud ", Version: intPtr(2)},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusActive), nil)
+				mockGateway.EXPECT().
+					Update(admin, gomock.Any()).
+					DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+						assert.Equal(t, entity.CustomerStatusBlocked, customer.Status)
+						customer.Version++
+						return nil
+					})
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, entity.CustomerStatusBlocked, customer.Status)
+				assert.Equal(t, "chargeback fraud", customer.StatusReason)
+				assert.NotNil(t, customer.StatusChangedAt)
+				assert.Equal(t, 3, customer.Version)
+			},
+		},
+		{
+			name:  "should activate a pending customer",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "active", Reason: "documents checked"},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusPending), nil)
+				mockGateway.EXPECT().
+					Update(admin, gomock.Any()).
+					Return(nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.True(t, customer.IsActive())
+			},
+		},
+		{
+			name:  "should return conflict error when reopening a closed customer",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "active", Reason: "asked to come back"},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusClosed), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name:  "should return conflict error when suspending a blocked customer",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "suspended", Reason: "dispute"},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusBlocked), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name:  "should return conflict error when the status doesn't change",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "active", Reason: "again"},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusActive), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name:  "should return validation error without a reason",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "suspended", Reason: " "},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusActive), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+				assert.EqualError(t, err, domain.ErrStatusReasonIsMandatory)
+			},
+		},
+		{
+			name:       "should return validation error for an unknown status",
+			ctx:        admin,
+			input:      dto.ChangeCustomerStatusInput{ID: 123, Status: "frozen", Reason: "fraud"},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ValidationError{}, err)
+			},
+		},
+		{
+			name:  "should return not found error for a deleted customer",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "blocked", Reason: "fraud"},
+			setupMocks: func() {
+				deletedAt := time.Now()
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(&entity.Customer{ID: 123, Status: entity.CustomerStatusActive, DeletedAt: &deletedAt}, nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:  "should return precondition failed when version doesn't match",
+			ctx:   admin,
+			input: dto.ChangeCustomerStatusInput{ID: 123, Status: "blocked", Reason: "fraud", Version: intPtr(1)},
+			setupMocks: func() {
+				mockGateway.EXPECT().
+					FindByID(admin, 123).
+					Return(customerWithStatus(entity.CustomerStatusActive), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden error for a customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer}),
+			input:      dto.ChangeCustomerStatusInput{ID: 123, Status: "closed", Reason: "leaving"},
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.setupMocks()
+
+			// Act
+			customer, err := useCase.ChangeStatus(tt.ctx, tt.input)
+
+			// Assert
+			tt.checkResult(t, customer, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
+	ctx := context.Background()
+	input := dto.AuthenticateCustomerInput{CPF: "12345678909"}
+	checkForbidden := func(t *testing.T, customer *entity.Customer, err error) {
+		assert.Nil(t, customer)
+		assert.IsType(t, &domain.ForbiddenError{}, err)
+	}
+
+	tests := []struct {
+		name        string
+		status      entity.CustomerStatus
+		missing     bool
+		checkResult func(*testing.T, *entity.Customer, error)
+	}{
+		{
+			name:   "should authenticate an active customer",
+			status: entity.CustomerStatusActive,
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 123, customer.ID)
+			},
+		},
+		{
+			name:    "should return not found error for an unknown CPF",
+			missing: true,
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:        "should return forbidden error for a pending customer",
+			status:      entity.CustomerStatusPending,
+			checkResult: checkForbidden,
+		},
+		{
+			name:        "should return forbidden error for a suspended customer",
+			status:      entity.CustomerStatusSuspended,
+			checkResult: checkForbidden,
+		},
+		{
+			name:        "should return forbidden error for a blocked customer",
+			status:      entity.CustomerStatusBlocked,
+			checkResult: checkForbidden,
+		},
+		{
+			name:        "should return forbidden error for a closed customer",
+			status:      entity.CustomerStatusClosed,
+			checkResult: checkForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var found *entity.Customer
+			if !tt.missing {
+				found = &entity.Customer{ID: 123, CPF: input.CPF, Status: tt.status}
+			}
+			mockGateway.EXPECT().FindByCPF(ctx, input.CPF).Return(found, nil)
+
+			// Act
+			customer, err := useCase.Authenticate(ctx, input)
+
+			// Assert
+			tt.checkResult(t, customer, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_Audit(t *testing.T) {
+	principal := &entity.Principal{Subject: "ops", Role: entity.RoleAdmin}
+	ctx := entity.ContextWithRequestID(entity.ContextWithPrincipal(context.Background(), principal), "req-1")
+	stored := func() *entity.Customer {
+		return &entity.Customer{ID: 123, Name: "John Doe", Email: "john@example.com", CPF: "12345678909",
+			Status: entity.CustomerStatusActive, Version: 2}
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func(*mockport.MockCustomerGateway)
+		act         func(port.CustomerUseCase) error
+		wantAction  entity.AuditAction
+		wantChanges []entity.AuditChange
+	}{
+		{
+			name: "should record every field of a created customer",
+			setupMocks: func(mockGateway *mockport.MockCustomerGateway) {
+				mockGateway.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+					customer.ID = 123
+					return nil
+				})
+			},
+			act: func(useCase port.CustomerUseCase) error {
+				_, err := useCase.Create(ctx, dto.CreateCustomerInput{Name: "John Doe", Email: "john@example.com", CPF: "12345678909"})
+				return err
+			},
+			wantAction: entity.AuditActionCreate,
+			wantChanges: []entity.AuditChange{
+				{Field: "name", After: "John Doe"},
+				{Field: "email", After: "john@example.com"},
+				{Field: "cpf", After: "12345678909"},
+				{Field: "status", After: "active"},
+			},
+		},
+		{
+			name: "should record the changed fields of an update",
+			setupMocks: func(mockGateway *mockport.MockCustomerGateway) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+			},
+			act: func(useCase port.CustomerUseCase) error {
+				_, err := useCase.Update(ctx, dto.UpdateCustomerInput{ID: 123, Name: stringPtr("John Doe"), Email: stringPtr("john.doe@example.com")})
+				return err
+			},
+			wantAction:  entity.AuditActionUpdate,
+			wantChanges: []entity.AuditChange{{Field: "email", Before: "john@example.com", After: "john.doe@example.com"}},
+		},
+		{
+			name: "should record a status change with its reason",
+			setupMocks: func(mockGateway *mockport.MockCustomerGateway) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+			},
+			act: func(useCase port.CustomerUseCase) error {
+				_, err := useCase.ChangeStatus(ctx, dto.ChangeCustomerStatusInput{ID: 123, Status: "blocked", Reason: "fraud"})
+				return err
+			},
+			wantAction: entity.AuditActionChangeStatus,
+			wantChanges: []entity.AuditChange{
+				{Field: "status", Before: "active", After: "blocked"},
+				{Field: "status_reason", After: "fraud"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockGateway := mockport.NewMockCustomerGateway(ctrl)
+			mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+			useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+			tt.setupMocks(mockGateway)
+
+			var entry *entity.AuditEntry
+			mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e *entity.AuditEntry) error {
+				entry = e
+				return nil
+			})
+
+			assert.NoError(t, tt.act(useCase))
+			assert.Equal(t, 123, entry.CustomerID)
+			assert.Equal(t, tt.wantAction, entry.Action)
+			assert.Equal(t, "ops", entry.Actor)
+			assert.Equal(t, entity.RoleAdmin, entry.ActorRole)
+			assert.Equal(t, "req-1", entry.RequestID)
+			assert.Equal(t, tt.wantChanges, entry.Changes)
+			assert.WithinDuration(t, time.Now(), entry.OccurredAt, time.Second)
+		})
+	}
+
+	t.Run("should record a sign-up without actor for an anonymous request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockGateway := mockport.NewMockCustomerGateway(ctrl)
+		mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+		useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+		anonymous := context.Background()
+
+		mockGateway.EXPECT().Create(anonymous, gomock.Any()).Return(nil)
+		mockAuditGateway.EXPECT().Append(anonymous, gomock.Any()).DoAndReturn(func(_ context.Context, entry *entity.AuditEntry) error {
+			assert.Equal(t, entity.AuditActionCreate, entry.Action)
+			assert.Empty(t, entry.Actor)
+			assert.Empty(t, entry.RequestID)
+			return nil
+		})
+
+		_, err := useCase.Create(anonymous, dto.CreateCustomerInput{Name: "John Doe", Email: "john.doe@email.com", CPF: "123.456.789-09"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return the saved customer when the entry can't be appended", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockGateway := mockport.NewMockCustomerGateway(ctrl)
+		mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+		useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+
+		mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+		mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+		mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Return(assert.AnError)
+
+		customer, err := useCase.Update(ctx, dto.UpdateCustomerInput{ID: 123, Name: stringPtr("Jane Doe")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", customer.Name)
+	})
+
+	t.Run("should return the created customer when the entry can't be appended", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockGateway := mockport.NewMockCustomerGateway(ctrl)
+		mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+		useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+
+		mockGateway.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, customer *entity.Customer) error {
+			customer.ID = 1
+			return nil
+		})
+		mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Return(assert.AnError)
+
+		customer, err := useCase.Create(ctx, dto.CreateCustomerInput{Name: "John Doe", Email: "john.doe@email.com", CPF: "123.456.789-09"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, customer.ID)
+	})
+}
+
+func TestCustomerUseCase_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	entries := []*entity.AuditEntry{
+		{ID: "1", CustomerID: 123, Action: entity.AuditActionCreate},
+		{ID: "2", CustomerID: 123, Action: entity.AuditActionUpdate},
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		setupMocks  func()
+		checkResult func(*testing.T, []*entity.AuditEntry, error)
+	}{
+		{
+			name: "should return the entries of the customer",
+			ctx:  admin,
+			setupMocks: func() {
+				mockAuditGateway.EXPECT().FindByCustomerID(admin, 123).Return(entries, nil)
+			},
+			checkResult: func(t *testing.T, result []*entity.AuditEntry, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, entries, result)
+			},
+		},
+		{
+			name: "should return an empty history of a customer without entries",
+			ctx:  admin,
+			setupMocks: func() {
+				mockAuditGateway.EXPECT().FindByCustomerID(admin, 123).Return([]*entity.AuditEntry{}, nil)
+				mockGateway.EXPECT().FindByID(admin, 123).Return(&entity.Customer{ID: 123}, nil)
+			},
+			checkResult: func(t *testing.T, result []*entity.AuditEntry, err error) {
+				assert.NoError(t, err)
+				assert.Empty(t, result)
+			},
+		},
+		{
+			name: "should return not found error when customer doesn't exist",
+			ctx:  admin,
+			setupMocks: func() {
+				mockAuditGateway.EXPECT().FindByCustomerID(admin, 123).Return([]*entity.AuditEntry{}, nil)
+				mockGateway.EXPECT().FindByID(admin, 123).Return(nil, nil)
+			},
+			checkResult: func(t *testing.T, result []*entity.AuditEntry, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name: "should return internal error when gateway fails",
+			ctx:  admin,
+			setupMocks: func() {
+				mockAuditGateway.EXPECT().FindByCustomerID(admin, 123).Return(nil, assert.AnError)
+			},
+			checkResult: func(t *testing.T, result []*entity.AuditEntry, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.InternalError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden error for a customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer}),
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, result []*entity.AuditEntry, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+		{
+			name:       "should return unauthorized error for an anonymous request",
+			ctx:        context.Background(),
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, result []*entity.AuditEntry, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.UnauthorizedError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.setupMocks()
+
+			// Act
+			result, err := useCase.History(tt.ctx, dto.GetCustomerHistoryInput{ID: 123})
+
+			// Assert
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_DataExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+	self := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer})
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	deletedAt := time.Now()
+	customer := &entity.Customer{ID: 123, Name: "John Doe", Email: "john@example.com", CPF: "12345678909"}
+	deleted := &entity.Customer{ID: 123, Name: "John Doe", DeletedAt: &deletedAt}
+	entries := []*entity.AuditEntry{{ID: "1", CustomerID: 123, Action: entity.AuditActionCreate}}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		setupMocks  func()
+		checkResult func(*testing.T, *dto.CustomerDataExport, error)
+	}{
+		{
+			name: "should export the data of the customer to themself",
+			ctx:  self,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(self, 123).Return(customer, nil)
+				mockAuditGateway.EXPECT().FindByCustomerID(self, 123).Return(entries, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, customer, result.Customer)
+				assert.Equal(t, entries, result.History)
+				assert.WithinDuration(t, time.Now(), result.ExportedAt, time.Second)
+			},
+		},
+		{
+			name: "should export the data of a deleted customer to an admin",
+			ctx:  admin,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(admin, 123).Return(deleted, nil)
+				mockAuditGateway.EXPECT().FindByCustomerID(admin, 123).Return(entries, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, deleted, result.Customer)
+			},
+		},
+		{
+			name: "should return not found error for a deleted customer exported by themself",
+			ctx:  self,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(self, 123).Return(deleted, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name: "should return not found error when customer doesn't exist",
+			ctx:  admin,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(admin, 123).Return(nil, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name: "should return internal error when the history can't be read",
+			ctx:  self,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(self, 123).Return(customer, nil)
+				mockAuditGateway.EXPECT().FindByCustomerID(self, 123).Return(nil, assert.AnError)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.InternalError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden error for another customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "456", Role: entity.RoleCustomer}),
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+		{
+			name:       "should return unauthorized error for an anonymous request",
+			ctx:        context.Background(),
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, result *dto.CustomerDataExport, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.UnauthorizedError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.setupMocks()
+
+			// Act
+			result, err := useCase.DataExport(tt.ctx, dto.GetCustomerDataExportInput{ID: 123})
+
+			// Assert
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_Anonymize(t *testing.T) {
+	self := entity.ContextWithRequestID(
+		entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer}), "req-1")
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	stored := func() *entity.Customer {
+		return &entity.Customer{ID: 123, Name: "John Doe", Email: "john@example.com", CPF: "12345678909",
+			Status: entity.CustomerStatusActive, Version: 2}
+	}
+	deleted := func() *entity.Customer {
+		customer := stored()
+		customer.Delete()
+		return customer
+	}
+	anonymized := func() *entity.Customer {
+		customer := stored()
+		customer.Anonymize()
+		return customer
+	}
+	version := 2
+	staleVersion := 1
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		input       dto.AnonymizeCustomerInput
+		setupMocks  func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway, *mockport.MockEventPublisher)
+		checkResult func(*testing.T, *entity.Customer, error)
+	}{
+		{
+			name:  "should erase the personal data of the customer on their request",
+			ctx:   self,
+			input: dto.AnonymizeCustomerInput{ID: 123, Version: &version},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, mockAuditGateway *mockport.MockAuditGateway, mockPublisher *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockAuditGateway.EXPECT().EraseCustomer(ctx, 123).Return(nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+				mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry *entity.AuditEntry) error {
+					assert.Equal(t, entity.AuditActionAnonymize, entry.Action)
+					assert.Equal(t, "123", entry.Actor)
+					for _, change := range entry.Changes {
+						assert.NotContains(t, []string{"John Doe", "john@example.com", "12345678909"}, change.Before)
+						assert.NotContains(t, []string{"John Doe", "john@example.com", "12345678909"}, change.After)
+						if change.Field == "name" || change.Field == "email" || change.Field == "cpf" {
+							assert.Equal(t, entity.AuditErasedValue, change.Before)
+						}
+					}
+					return nil
+				})
+				mockPublisher.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event *entity.CustomerEvent) error {
+					assert.Equal(t, entity.CustomerEventAnonymized, event.Type)
+					assert.Equal(t, 123, event.CustomerID)
+					assert.Equal(t, "req-1", event.RequestID)
+					return nil
+				})
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 123, customer.ID)
+				assert.True(t, customer.IsAnonymized())
+				assert.NotEqual(t, "John Doe", customer.Name)
+				assert.NotEqual(t, "john@example.com", customer.Email)
+				assert.NotEqual(t, "12345678909", customer.CPF)
+				assert.Equal(t, entity.CustomerStatusClosed, customer.Status)
+			},
+		},
+		{
+			name:  "should anonymize a deleted customer for an admin",
+			ctx:   admin,
+			input: dto.AnonymizeCustomerInput{ID: 123},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, mockAuditGateway *mockport.MockAuditGateway, mockPublisher *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(deleted(), nil)
+				mockAuditGateway.EXPECT().EraseCustomer(ctx, 123).Return(nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+				mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Return(nil)
+				mockPublisher.EXPECT().Publish(ctx, gomock.Any()).Return(nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.NoError(t, err)
+				assert.True(t, customer.IsAnonymized())
+				assert.True(t, customer.IsDeleted())
+			},
+		},
+		{
+			name:  "should return not found error for a deleted customer anonymized by themself",
+			ctx:   self,
+			input: dto.AnonymizeCustomerInput{ID: 123},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway, _ *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(deleted(), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:  "should return conflict error when the customer is already anonymized",
+			ctx:   admin,
+			input: dto.AnonymizeCustomerInput{ID: 123},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway, _ *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(anonymized(), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name:  "should return precondition failed error when the version doesn't match",
+			ctx:   self,
+			input: dto.AnonymizeCustomerInput{ID: 123, Version: &staleVersion},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway, _ *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:  "should return internal error when the event can't be published",
+			ctx:   self,
+			input: dto.AnonymizeCustomerInput{ID: 123},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, mockAuditGateway *mockport.MockAuditGateway, mockPublisher *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockAuditGateway.EXPECT().EraseCustomer(ctx, 123).Return(nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+				mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Return(nil)
+				mockPublisher.EXPECT().Publish(ctx, gomock.Any()).Return(assert.AnError)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.InternalError{}, err)
+				assert.Contains(t, err.Error(), domain.ErrEventNotPublished)
+			},
+		},
+		{
+			name:  "should leave the customer as it was when its audit entries can't be erased",
+			ctx:   self,
+			input: dto.AnonymizeCustomerInput{ID: 123},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, mockAuditGateway *mockport.MockAuditGateway, _ *mockport.MockEventPublisher) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockAuditGateway.EXPECT().EraseCustomer(ctx, 123).Return(assert.AnError)
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.InternalError{}, err)
+				assert.Contains(t, err.Error(), domain.ErrAuditNotErased)
+			},
+		},
+		{
+			name:  "should return forbidden error for another customer",
+			ctx:   entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "456", Role: entity.RoleCustomer}),
+			input: dto.AnonymizeCustomerInput{ID: 123},
+			setupMocks: func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway, *mockport.MockEventPublisher) {
+			},
+			checkResult: func(t *testing.T, customer *entity.Customer, err error) {
+				assert.Nil(t, customer)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockGateway := mockport.NewMockCustomerGateway(ctrl)
+			mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+			mockPublisher := mockport.NewMockEventPublisher(ctrl)
+			useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockPublisher)
+			tt.setupMocks(tt.ctx, mockGateway, mockAuditGateway, mockPublisher)
+
+			// Act
+			customer, err := useCase.Anonymize(tt.ctx, tt.input)
+
+			// Assert
+			tt.checkResult(t, customer, err)
+		})
+	}
+
+	t.Run("should refuse to update an anonymized customer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockGateway := mockport.NewMockCustomerGateway(ctrl)
+		useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
+		mockGateway.EXPECT().FindByID(admin, 123).Return(anonymized(), nil)
+
+		customer, err := useCase.Update(admin, dto.UpdateCustomerInput{ID: 123, Name: stringPtr("John Doe")})
+
+		assert.Nil(t, customer)
+		assert.IsType(t, &domain.ConflictError{}, err)
+	})
+}
+
+func TestCustomerUseCase_GetConsents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGateway := mockport.NewMockCustomerGateway(ctrl)
+	useCase := usecase.NewCustomerUseCase(mockGateway, mockport.NewMockAuditGateway(ctrl), mockport.NewMockEventPublisher(ctrl))
+	self := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer})
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	deletedAt := time.Now()
+	customer := &entity.Customer{ID: 123, Consents: []entity.Consent{
+		{Purpose: entity.ConsentPurposeMarketing, Granted: true, PolicyVersion: "1.0", Channel: entity.ConsentChannelWeb},
+	}}
+	deleted := &entity.Customer{ID: 123, DeletedAt: &deletedAt}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		setupMocks  func()
+		checkResult func(*testing.T, *dto.CustomerConsents, error)
+	}{
+		{
+			name: "should return the consents of the customer to themself",
+			ctx:  self,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(self, 123).Return(customer, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, customer, result.Customer)
+			},
+		},
+		{
+			name: "should return the consents of a deleted customer to an admin",
+			ctx:  admin,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(admin, 123).Return(deleted, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, deleted, result.Customer)
+			},
+		},
+		{
+			name: "should return not found error for a deleted customer read by themself",
+			ctx:  self,
+			setupMocks: func() {
+				mockGateway.EXPECT().FindByID(self, 123).Return(deleted, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden error for another customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "456", Role: entity.RoleCustomer}),
+			setupMocks: func() {},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.setupMocks()
+
+			// Act
+			result, err := useCase.GetConsents(tt.ctx, dto.GetCustomerConsentsInput{ID: 123})
+
+			// Assert
+			tt.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestCustomerUseCase_UpdateConsents(t *testing.T) {
+	self := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "123", Role: entity.RoleCustomer})
+	admin := entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	stored := func() *entity.Customer {
+		return &entity.Customer{ID: 123, Name: "John Doe", Version: 2, Consents: []entity.Consent{
+			{Purpose: entity.ConsentPurposeMarketing, Granted: true, PolicyVersion: "1.0", Channel: entity.ConsentChannelWeb},
+		}}
+	}
+	withdrawMarketing := dto.ConsentInput{Purpose: "Marketing", Granted: boolPtr(false), PolicyVersion: "1.1", Channel: "app"}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		input       dto.UpdateCustomerConsentsInput
+		setupMocks  func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway)
+		checkResult func(*testing.T, *dto.CustomerConsents, error)
+	}{
+		{
+			name:  "should record the consents keeping the previous ones as history",
+			ctx:   self,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Version: intPtr(2), Consents: []dto.ConsentInput{withdrawMarketing}},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, mockAuditGateway *mockport.MockAuditGateway) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+				mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry *entity.AuditEntry) error {
+					assert.Equal(t, entity.AuditActionConsents, entry.Action)
+					assert.Equal(t, []entity.AuditChange{
+						{Field: "consent_marketing", Before: "granted (policy 1.0)", After: "withdrawn (policy 1.1)"},
+					}, entry.Changes)
+					return nil
+				})
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, result.Customer.Consents, 2)
+				current := result.Customer.CurrentConsent(entity.ConsentPurposeMarketing)
+				assert.False(t, current.Granted)
+				assert.Equal(t, "1.1", current.PolicyVersion)
+				assert.Equal(t, entity.ConsentChannelApp, current.Channel)
+				assert.WithinDuration(t, time.Now(), current.RecordedAt, time.Second)
+			},
+		},
+		{
+			name: "should record the consents of a customer for an admin",
+			ctx:  admin,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{
+				{Purpose: "data_sharing", Granted: boolPtr(true), PolicyVersion: "1.1", Channel: "phone"},
+			}},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, mockAuditGateway *mockport.MockAuditGateway) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+				mockGateway.EXPECT().Update(ctx, gomock.Any()).Return(nil)
+				mockAuditGateway.EXPECT().Append(ctx, gomock.Any()).Return(nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, result.Customer.CurrentConsents(), 2)
+			},
+		},
+		{
+			name:       "should return validation error without consents",
+			ctx:        self,
+			input:      dto.UpdateCustomerConsentsInput{ID: 123},
+			setupMocks: func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway) {},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ValidationError{}, err)
+			},
+		},
+		{
+			name: "should return validation error for an unknown purpose",
+			ctx:  self,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{
+				{Purpose: "profiling", Granted: boolPtr(true), PolicyVersion: "1.1", Channel: "web"},
+			}},
+			setupMocks: func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway) {},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ValidationError{}, err)
+			},
+		},
+		{
+			name: "should return validation error for an unknown channel",
+			ctx:  self,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{
+				{Purpose: "marketing", Granted: boolPtr(true), PolicyVersion: "1.1", Channel: "fax"},
+			}},
+			setupMocks: func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway) {},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ValidationError{}, err)
+			},
+		},
+		{
+			name: "should return validation error without the decision",
+			ctx:  self,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{
+				{Purpose: "marketing", PolicyVersion: "1.1", Channel: "web"},
+			}},
+			setupMocks: func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway) {},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ValidationError{}, err)
+			},
+		},
+		{
+			name: "should return validation error without the policy version",
+			ctx:  self,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{
+				{Purpose: "marketing", Granted: boolPtr(true), PolicyVersion: " ", Channel: "web"},
+			}},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ValidationError{}, err)
+			},
+		},
+		{
+			name:  "should return conflict error for an anonymized customer",
+			ctx:   admin,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{withdrawMarketing}},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway) {
+				customer := stored()
+				customer.Anonymize()
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(customer, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ConflictError{}, err)
+			},
+		},
+		{
+			name:  "should return not found error for a deleted customer",
+			ctx:   admin,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{withdrawMarketing}},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway) {
+				customer := stored()
+				customer.Delete()
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(customer, nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.NotFoundError{}, err)
+			},
+		},
+		{
+			name:  "should return precondition failed error when the version doesn't match",
+			ctx:   self,
+			input: dto.UpdateCustomerConsentsInput{ID: 123, Version: intPtr(1), Consents: []dto.ConsentInput{withdrawMarketing}},
+			setupMocks: func(ctx context.Context, mockGateway *mockport.MockCustomerGateway, _ *mockport.MockAuditGateway) {
+				mockGateway.EXPECT().FindByID(ctx, 123).Return(stored(), nil)
+			},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.PreconditionFailedError{}, err)
+			},
+		},
+		{
+			name:       "should return forbidden error for another customer",
+			ctx:        entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: "456", Role: entity.RoleCustomer}),
+			input:      dto.UpdateCustomerConsentsInput{ID: 123, Consents: []dto.ConsentInput{withdrawMarketing}},
+			setupMocks: func(context.Context, *mockport.MockCustomerGateway, *mockport.MockAuditGateway) {},
+			checkResult: func(t *testing.T, result *dto.CustomerConsents, err error) {
+				assert.Nil(t, result)
+				assert.IsType(t, &domain.ForbiddenError{}, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockGateway := mockport.NewMockCustomerGateway(ctrl)
+			mockAuditGateway := mockport.NewMockAuditGateway(ctrl)
+			useCase := usecase.NewCustomerUseCase(mockGateway, mockAuditGateway, mockport.NewMockEventPublisher(ctrl))
+			tt.setupMocks(tt.ctx, mockGateway, mockAuditGateway)
+
+			// Act
+			result, err := useCase.UpdateConsents(tt.ctx, tt.input)
+
+			// Assert
+			tt.checkResult(t, result, err)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package lambda
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/request"
+)
+
+// authenticate puts the principal of the bearer token in the context. A request without a token is
+// anonymous, and is refused by the use cases restricted to a role, while an invalid token is refused
+// right away
+func authenticate(ctx context.Context, req request.HTTPRequest) (context.Context, error) {
+	token, err := req.BearerToken()
+	if err != nil || token == "" {
+		return ctx, err
+	}
+
+	principal, err := authenticationService.ValidateToken(token)
+	if err != nil {
+		return ctx, err
+	}
+	return entity.ContextWithPrincipal(ctx, principal), nil
+}
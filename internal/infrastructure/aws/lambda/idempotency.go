@@ -0,0 +1,114 @@
+package lambda
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/request"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/response"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// defaultIdempotencyLease is how long a reservation stays pending when the request has no deadline
+const defaultIdempotencyLease = 30 * time.Second
+
+// withIdempotency runs the handler at most once per Idempotency-Key header. Retries of the same request
+// are answered with the stored response, while a key reused by a different request is rejected.
+// Requests without the header are handled as usual
+func withIdempotency(ctx context.Context, req request.HTTPRequest, handle func() response.HTTPResponse) response.HTTPResponse {
+	key := strings.TrimSpace(req.Header("Idempotency-Key"))
+	if key == "" || idempotencyDataSource == nil {
+		return handle()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return response.NewHTTPResponseError(domain.NewInvalidInputError(domain.ErrIdempotencyKeyTooLong))
+	}
+
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+
+	// The reservation only lasts as long as the request may run, so the key of a request that never
+	// completed, e.g. because the function timed out, can be reclaimed by a retry
+	now := time.Now()
+	record := &entity.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash(ctx, req, body),
+		LeaseID:     rand.Text(),
+		ExpiresAt:   idempotencyLease(ctx, now),
+	}
+
+	existing, err := idempotencyDataSource.Reserve(ctx, record)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to reserve idempotency key", "error", err)
+		return response.NewHTTPResponseError(domain.NewInternalError(err))
+	}
+	if existing != nil && !existing.Expired(now) {
+		switch {
+		case !existing.Matches(record.RequestHash):
+			return response.NewHTTPResponseError(domain.NewValidationError(errors.New(domain.ErrIdempotencyKeyReused)))
+		case !existing.Completed:
+			return response.NewHTTPResponseError(domain.NewConflictError(domain.ErrIdempotencyKeyInProgress))
+		default:
+			replayed := response.HTTPResponse{StatusCode: existing.StatusCode, Headers: existing.Headers, Body: existing.Body}
+			return replayed.WithHeader("Idempotent-Replayed", "true")
+		}
+	}
+
+	resp := handle()
+
+	// Server errors are transient, so the client must be able to retry them with the same key
+	if resp.StatusCode >= http.StatusInternalServerError {
+		if err := idempotencyDataSource.Release(ctx, record); err != nil {
+			l.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+		}
+		return resp
+	}
+
+	record.Completed = true
+	record.ExpiresAt = time.Now().Add(idempotencyTTL)
+	record.StatusCode = resp.StatusCode
+	record.Headers = resp.Headers
+	record.Body = resp.Body
+	if id, _, ok := presentedCustomer([]byte(resp.Body)); ok {
+		record.CustomerID, _ = strconv.Atoi(id)
+	}
+	if err := idempotencyDataSource.Complete(ctx, record); err != nil {
+		l.ErrorContext(ctx, "Failed to store idempotent response", "error", err)
+	}
+	return resp
+}
+
+// idempotencyLease returns when the reservation of a request expires: at its deadline, which the function
+// timeout sets, or after defaultIdempotencyLease without one
+func idempotencyLease(ctx context.Context, now time.Time) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return now.Add(defaultIdempotencyLease)
+}
+
+// requestHash identifies a request by its caller, method, path and body, so a key reused by another
+// caller is rejected instead of replaying the response given to the first one
+func requestHash(ctx context.Context, req request.HTTPRequest, body []byte) string {
+	caller := "anonymous"
+	if principal := entity.PrincipalFromContext(ctx); principal != nil {
+		caller = string(principal.Role) + " " + principal.Subject
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(caller + "\n" + req.Method + " " + req.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
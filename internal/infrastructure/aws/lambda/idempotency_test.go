@@ -0,0 +1,221 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	mockport "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/request"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/response"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database/dynamotest"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+)
+
+func TestHandleRequest_CreateCustomer_Idempotency(t *testing.T) {
+	body := `{"name":"John Doe","email":"john@example.com","cpf":"12345678901"}`
+	createInput := dto.CreateCustomerInput{Name: "John Doe", Email: "john@example.com", CPF: "12345678901"}
+	created := []byte(`{"id":123,"name":"John Doe","version":1}`)
+
+	event := events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/customers",
+		Path:       "/customers",
+		Headers:    map[string]string{"Content-Type": "application/json", "Idempotency-Key": "key-1"},
+		Body:       body,
+	}
+	hash := requestHash(context.Background(), request.FromAPIGatewayProxyRequest(event), []byte(body))
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		setupMocks     func(*mockport.MockCustomerController, *mockport.MockIdempotencyDataSource)
+		expectedStatus int
+		expectedBody   string
+		expectedHeader map[string]string
+	}{
+		{
+			name: "should create the customer and store the response",
+			setupMocks: func(mockController *mockport.MockCustomerController, mockStore *mockport.MockIdempotencyDataSource) {
+				mockStore.EXPECT().Reserve(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+						assert.WithinDuration(t, time.Now().Add(defaultIdempotencyLease), record.ExpiresAt, time.Second,
+							"a pending reservation must only last as long as the request")
+						return nil, nil
+					})
+				mockController.EXPECT().Create(gomock.Any(), gomock.Any(), createInput).Return(created, nil)
+				mockStore.EXPECT().Complete(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, record *entity.IdempotencyRecord) error {
+						assert.WithinDuration(t, time.Now().Add(time.Hour), record.ExpiresAt, time.Second)
+						assert.Equal(t, "key-1", record.Key)
+						assert.Equal(t, hash, record.RequestHash)
+						assert.True(t, record.Completed)
+						assert.Equal(t, 201, record.StatusCode)
+						assert.Equal(t, string(created), record.Body)
+						assert.Equal(t, 123, record.CustomerID)
+						return nil
+					})
+			},
+			expectedStatus: 201,
+			expectedBody:   string(created),
+			expectedHeader: map[string]string{"Location": "/customers/123"},
+		},
+		{
+			name: "should replay the stored response",
+			setupMocks: func(_ *mockport.MockCustomerController, mockStore *mockport.MockIdempotencyDataSource) {
+				mockStore.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(&entity.IdempotencyRecord{
+					Key:         "key-1",
+					RequestHash: hash,
+					Completed:   true,
+					StatusCode:  201,
+					Headers:     map[string]string{"Location": "/customers/123"},
+					Body:        string(created),
+					ExpiresAt:   future,
+				}, nil)
+			},
+			expectedStatus: 201,
+			expectedBody:   string(created),
+			expectedHeader: map[string]string{"Location": "/customers/123", "Idempotent-Replayed": "true"},
+		},
+		{
+			name: "should reject a key reused with a different body",
+			setupMocks: func(_ *mockport.MockCustomerController, mockStore *mockport.MockIdempotencyDataSource) {
+				mockStore.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(&entity.IdempotencyRecord{
+					Key:         "key-1",
+					RequestHash: "another request",
+					Completed:   true,
+					ExpiresAt:   future,
+				}, nil)
+			},
+			expectedStatus: 422,
+			expectedBody:   domain.ErrIdempotencyKeyReused,
+		},
+		{
+			name: "should reject a key while its request is in progress",
+			setupMocks: func(_ *mockport.MockCustomerController, mockStore *mockport.MockIdempotencyDataSource) {
+				mockStore.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(&entity.IdempotencyRecord{
+					Key:         "key-1",
+					RequestHash: hash,
+					ExpiresAt:   future,
+				}, nil)
+			},
+			expectedStatus: 409,
+			expectedBody:   domain.ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "should release the key when the creation fails",
+			setupMocks: func(mockController *mockport.MockCustomerController, mockStore *mockport.MockIdempotencyDataSource) {
+				mockStore.EXPECT().Reserve(gomock.Any(), gomock.Any()).Return(nil, nil)
+				mockController.EXPECT().Create(gomock.Any(), gomock.Any(), createInput).Return(nil, domain.NewInternalError(assert.AnError))
+				mockStore.EXPECT().Release(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, record *entity.IdempotencyRecord) error {
+						assert.Equal(t, "key-1", record.Key)
+						assert.NotEmpty(t, record.LeaseID)
+						return nil
+					})
+			},
+			expectedStatus: 500,
+		},
+		{
+			name:    "should create without idempotency when the header is missing",
+			headers: map[string]string{"Content-Type": "application/json"},
+			setupMocks: func(mockController *mockport.MockCustomerController, _ *mockport.MockIdempotencyDataSource) {
+				mockController.EXPECT().Create(gomock.Any(), gomock.Any(), createInput).Return(created, nil)
+			},
+			expectedStatus: 201,
+			expectedBody:   string(created),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			mockStore := mockport.NewMockIdempotencyDataSource(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			idempotencyDataSource = mockStore
+			idempotencyTTL = time.Hour
+			defer func() { idempotencyDataSource = nil }()
+			tt.setupMocks(mockController, mockStore)
+
+			req := event
+			if tt.headers != nil {
+				req.Headers = tt.headers
+			}
+
+			resp, err := handleRequest(context.Background(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Contains(t, resp.Body, tt.expectedBody)
+			for key, value := range tt.expectedHeader {
+				assert.Equal(t, value, resp.Headers[key])
+			}
+		})
+	}
+}
+
+func TestWithIdempotency_ReclaimsAbandonedReservation(t *testing.T) {
+	client := dynamotest.NewFakeClient()
+	client.SetHashKey("idempotency-keys", "idempotency_key")
+	db := database.NewDynamoItemDatabase(client, "customers", l)
+	idempotencyDataSource = datasource.NewIdempotencyDynamoDataSource(db, "idempotency-keys")
+	idempotencyTTL = time.Hour
+	defer func() { idempotencyDataSource = nil }()
+
+	req := request.FromAPIGatewayProxyRequest(events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/customers",
+		Path:       "/customers",
+		Headers:    map[string]string{"Idempotency-Key": "key-1"},
+		Body:       `{"name":"John Doe"}`,
+	})
+
+	// The first invocation stops without completing nor releasing the key, like a function that timed out
+	abandoned, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	func() {
+		defer func() { assert.NotNil(t, recover()) }()
+		withIdempotency(abandoned, req, func() response.HTTPResponse { panic("timed out") })
+	}()
+
+	resp := withIdempotency(context.Background(), req, func() response.HTTPResponse {
+		return response.HTTPResponse{StatusCode: 201, Body: `{"id":123}`}
+	})
+
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Empty(t, resp.Headers["Idempotent-Replayed"])
+	items := client.Items("idempotency-keys")
+	require.Len(t, items, 1)
+	assert.Equal(t, &types.AttributeValueMemberBOOL{Value: true}, items[0]["completed"])
+}
+
+func TestRequestHash(t *testing.T) {
+	req := request.FromAPIGatewayProxyRequest(events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/customers"})
+	body := []byte(`{"name":"John Doe"}`)
+	callerContext := func(subject string, role entity.Role) context.Context {
+		return entity.ContextWithPrincipal(context.Background(), &entity.Principal{Subject: subject, Role: role})
+	}
+
+	anonymous := requestHash(context.Background(), req, body)
+	customer := requestHash(callerContext("1", entity.RoleCustomer), req, body)
+
+	assert.Equal(t, customer, requestHash(callerContext("1", entity.RoleCustomer), req, body))
+	assert.NotEqual(t, anonymous, customer)
+	assert.NotEqual(t, customer, requestHash(callerContext("2", entity.RoleCustomer), req, body),
+		"a key reused by another caller must not match")
+	assert.NotEqual(t, customer, requestHash(callerContext("1", entity.RoleAdmin), req, body))
+}
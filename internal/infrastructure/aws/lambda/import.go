@@ -0,0 +1,69 @@
+package lambda
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/importer"
+)
+
+// importReportSuffix is appended to the key of an imported object to name its report
+const importReportSuffix = ".report.json"
+
+// handleS3Event imports the customers of each object in an S3 event and stores the report of each one
+// next to it. The reports trigger events too, so their keys are ignored
+func handleS3Event(ctx context.Context, event events.S3Event) error {
+	if objectStore == nil {
+		return errors.New("the object store is not configured")
+	}
+
+	var errs []error
+	for _, record := range event.Records {
+		bucket := record.S3.Bucket.Name
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if strings.HasSuffix(key, importReportSuffix) {
+			continue
+		}
+
+		report, err := importObject(ctx, bucket, key)
+		if err != nil {
+			l.ErrorContext(ctx, "Failed to import customers", "bucket", bucket, "key", key, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		l.InfoContext(ctx, "Imported customers", "bucket", bucket, "key", key, "report", key+importReportSuffix)
+		if err := objectStore.Put(ctx, bucket, key+importReportSuffix, report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// importObject imports the customers of an object and returns the JSON report
+func importObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	format, err := importer.FormatOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := objectStore.Open(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	rows, err := importer.ReadRows(object, format)
+	if err != nil {
+		return nil, err
+	}
+	return customerController.Import(ctx, jsonPresenter, dto.ImportCustomersInput{Rows: rows, Mode: importMode})
+}
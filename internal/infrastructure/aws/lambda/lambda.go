@@ -2,26 +2,34 @@ package lambda
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"mime"
+	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/presenter"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/request"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda/response"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/cache"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
-	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/objectstore"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/service"
 
 	"github.com/aws/aws-lambda-go/lambda"
@@ -30,12 +38,20 @@ import (
 )
 
 var customerDataSource port.CustomerDataSource
+var auditDataSource port.AuditDataSource
 var customerGateway port.CustomerGateway
 var customerUseCase port.CustomerUseCase
 var customerController port.CustomerController
 var jsonPresenter port.Presenter
 var jwtPresenter port.Presenter
+var authenticationService port.IAuthenticationService
 var l *logger.Logger
+var ifMatchRequired bool
+var idempotencyDataSource port.IdempotencyDataSource
+var idempotencyTTL time.Duration
+var cachedCustomerGateway *gateway.CachedCustomerGateway
+var objectStore port.ObjectStore
+var importMode dto.ImportMode
 
 // init function is called in a lambda cold start. So, at this moment is initialized
 // all structures and also the database connection
@@ -48,63 +64,219 @@ func init() {
 		return
 	}
 
-	// Skip initialization during test execution
-	if testing.Testing() {
+	// Skip initialization during test execution, and when the binary runs a maintenance command
+	if testing.Testing() || len(os.Args) > 1 {
 		return
 	}
 
-	db, err := database.NewDynamoConnection(cfg, l)
+	dataSources, err := datasource.NewDataSources(context.Background(), cfg, l, "")
 	if err != nil {
 		panic(err)
 	}
+	customerDataSource = dataSources.Customer
+	auditDataSource = dataSources.Audit
+	idempotencyDataSource = dataSources.Idempotency
+
 	jwtService := service.NewJWTService(cfg)
-	customerDataSource = datasource.NewCustomerDynamoDataSource(db)
-	customerGateway = gateway.NewCustomerGateway(customerDataSource)
-	customerUseCase = usecase.NewCustomerUseCase(customerGateway)
+	customerGateway = newCustomerGateway(cfg)
+	customerUseCase = usecase.NewCustomerUseCase(customerGateway, gateway.NewAuditGateway(auditDataSource), eventpublisher.NewLogEventPublisher(l))
 	customerController = controller.NewCustomerController(customerUseCase)
 	jsonPresenter = presenter.NewCustomerJsonPresenter()
 	jwtPresenter = presenter.NewCustomerJwtTokenPresenter(jwtService)
+	authenticationService = jwtService
+	ifMatchRequired = cfg.IfMatchRequired
+	idempotencyTTL = cfg.IdempotencyTTL
+	objectStore = objectstore.NewFileObjectStore(cfg.ImportObjectRoot)
+	importMode = dto.ImportMode(cfg.ImportMode)
+}
+
+// newCustomerGateway wraps the customer gateway with the cache selected by CACHE_BACKEND
+func newCustomerGateway(cfg *config.Config) port.CustomerGateway {
+	customerGateway := gateway.NewCustomerGateway(customerDataSource)
+
+	var customerCache port.Cache
+	switch cfg.CacheBackend {
+	case config.CacheBackendNone:
+		return customerGateway
+	case config.CacheBackendMemory:
+		lruCache, err := cache.NewLRUCache(cfg.CacheSize)
+		if err != nil {
+			panic(err)
+		}
+		customerCache = lruCache
+	case config.CacheBackendRedis:
+		// The entries hold the customers in plaintext, keyed by their CPFs, on a server shared by every
+		// instance, which would undo the field-level encryption
+		if cfg.PIIKeyProvider != "" && cfg.PIIKeyProvider != config.KeyProviderNone {
+			panic(fmt.Sprintf("CACHE_BACKEND %q can't be used with PII_KEY_PROVIDER %q, it stores the customers in plaintext",
+				cfg.CacheBackend, cfg.PIIKeyProvider))
+		}
+		client, err := cache.NewRedisClient(cfg.RedisURL)
+		if err != nil {
+			panic(err)
+		}
+		customerCache = cache.NewRedisCache(client, cfg.CacheKeyPrefix)
+	default:
+		panic(fmt.Sprintf("unsupported CACHE_BACKEND %q", cfg.CacheBackend))
+	}
+
+	cachedCustomerGateway = gateway.NewCachedCustomerGateway(customerGateway, customerCache, cfg.CacheTTL, cfg.CacheNegativeTTL)
+	return cachedCustomerGateway
 }
 
 // StartLambda is the function that tells lambda which function should be call to start lambda.
 func StartLambda() {
 	fmt.Println("🟢 Lambda is ready to receive requests!")
-	lambda.Start(handleRequest)
+	lambda.Start(handleEvent)
+}
+
+// handleEvent detects which integration invoked the lambda (API Gateway REST API, HTTP API, ALB or an
+// S3 object event) and answers with the matching response type
+func handleEvent(ctx context.Context, event json.RawMessage) (any, error) {
+	defer logCacheStats(ctx)
+
+	// The invocation ID identifies the changes of the events without a request ID, like the S3 imports
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		ctx = entity.ContextWithRequestID(ctx, lc.AwsRequestID)
+	}
+
+	eventType, err := request.DetectEventType(event)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to detect event type", "error", err)
+		return nil, err
+	}
+
+	switch eventType {
+	case request.EventTypeS3:
+		var s3Event events.S3Event
+		if err := json.Unmarshal(event, &s3Event); err != nil {
+			return nil, err
+		}
+		return nil, handleS3Event(ctx, s3Event)
+	case request.EventTypeAPIGatewayV2HTTP:
+		var req events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			return nil, err
+		}
+		return handleHTTPAPIRequest(ctx, req)
+	case request.EventTypeALB:
+		var req events.ALBTargetGroupRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			return nil, err
+		}
+		return handleALBRequest(ctx, req)
+	default:
+		var req events.APIGatewayProxyRequest
+		if err := json.Unmarshal(event, &req); err != nil {
+			return nil, err
+		}
+		return handleRequest(ctx, req)
+	}
 }
 
-// handleRequest responsible to handle lambda events
+// handleRequest responsible to handle API Gateway REST API (payload v1) events
 func handleRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return route(ctx, request.FromAPIGatewayProxyRequest(req)).ToAPIGatewayProxyResponse(), nil
+}
+
+// handleHTTPAPIRequest responsible to handle API Gateway HTTP API (payload v2) events
+func handleHTTPAPIRequest(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return route(ctx, request.FromAPIGatewayV2HTTPRequest(req)).ToAPIGatewayV2HTTPResponse(), nil
+}
+
+// handleALBRequest responsible to handle Application Load Balancer target group events
+func handleALBRequest(ctx context.Context, req events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+	httpRequest := request.FromALBTargetGroupRequest(req)
+	return route(ctx, httpRequest).ToALBTargetGroupResponse(httpRequest.MultiValueHeaders), nil
+}
+
+// logCacheStats logs the customer cache counters of this lambda instance
+func logCacheStats(ctx context.Context) {
+	if cachedCustomerGateway == nil {
+		return
+	}
+	stats := cachedCustomerGateway.Stats()
+	l.DebugContext(ctx, "Customer cache stats", "hits", stats.Hits, "misses", stats.Misses, "errors", stats.Errors)
+}
+
+// route dispatches the normalized request to the handler of its method and resource
+func route(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
 	l.InfoContext(ctx, "Starting lambda handler",
-		"httpMethod", req.HTTPMethod,
+		"eventType", req.EventType,
+		"httpMethod", req.Method,
 		"resource", req.Resource,
 		"pathParameters", req.PathParameters,
 		"queryStringParameters", req.QueryStringParameters,
 		"isBase64Encoded", req.IsBase64Encoded,
 		"body", req.Body)
 
+	if req.RequestID != "" {
+		ctx = entity.ContextWithRequestID(ctx, req.RequestID)
+	}
+	// The presenter masks the personal data of the customers by the route and caller
+	ctx = entity.ContextWithRoute(ctx, req.Method+" "+req.Resource)
+
 	// Check if it's an authentication request
-	if req.Resource == "/auth" && req.HTTPMethod == "POST" {
+	if req.Resource == "/auth" && req.Method == "POST" {
 		return handleAuthRequest(ctx, req)
 	}
 
-	switch req.HTTPMethod {
+	ctx, err := authenticate(ctx, req)
+	if err != nil {
+		l.InfoContext(ctx, "Rejected access token", "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	// A batch get only reads, so it isn't subject to idempotency keys
+	if req.Resource == "/customers:batchGet" && req.Method == "POST" {
+		return handleBatchGetRequest(ctx, req)
+	}
+
+	// Restoring an already restored customer fails, so it doesn't need idempotency keys either
+	if req.Resource == "/customers/{id}:restore" && req.Method == "POST" {
+		return handleRestoreRequest(ctx, req)
+	}
+	// Anonymizing an already anonymized customer fails too
+	if req.Resource == "/customers/{id}:anonymize" && req.Method == "POST" {
+		return handleAnonymizeRequest(ctx, req)
+	}
+
+	if req.Resource == "/customers/{id}/status" && req.Method == "PUT" {
+		return handleStatusRequest(ctx, req)
+	}
+
+	if req.Resource == "/customers/{id}/history" && req.Method == "GET" {
+		return handleHistoryRequest(ctx, req)
+	}
+	if req.Resource == "/customers/{id}/data-export" && req.Method == "GET" {
+		return handleDataExportRequest(ctx, req)
+	}
+	if req.Resource == "/customers/{id}/consents" && (req.Method == "GET" || req.Method == "PUT") {
+		return handleConsentsRequest(ctx, req)
+	}
+
+	switch req.Method {
 	case "GET":
 		return handleGetRequest(ctx, req)
 	case "POST":
-		return handlePostRequest(ctx, req)
+		return withIdempotency(ctx, req, func() response.HTTPResponse {
+			return handlePostRequest(ctx, req)
+		})
 	case "PUT":
 		return handlePutRequest(ctx, req)
+	case "PATCH":
+		return handlePatchRequest(ctx, req)
 	case "DELETE":
 		return handleDeleteRequest(ctx, req)
 	default:
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{
-			Message: fmt.Sprintf("HTTP method %s not supported", req.HTTPMethod),
-		}), nil
+		return response.NewHTTPResponseError(&domain.InvalidInputError{
+			Message: fmt.Sprintf("HTTP method %s not supported", req.Method),
+		})
 	}
 }
 
 // handleGetRequest handles GET requests for customers
-func handleGetRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handleGetRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
 	// Check if it's a list request (no ID in path) or get by ID/CPF
 	customerID, hasID := req.PathParameters["id"]
 	cpf := req.QueryStringParameters["cpf"]
@@ -124,17 +296,28 @@ func handleGetRequest(ctx context.Context, req events.APIGatewayProxyRequest) (e
 			}
 		}
 
+		// after_id switches to keyset pagination, starting after the last customer of the previous page
+		afterID := 0
+		if afterIDStr := req.QueryStringParameters["after_id"]; afterIDStr != "" {
+			if a, err := strconv.Atoi(afterIDStr); err == nil && a > 0 {
+				afterID = a
+			}
+		}
+
 		input := dto.ListCustomersInput{
-			Page:  page,
-			Limit: limit,
+			Search:         strings.TrimSpace(req.QueryStringParameters["search"]),
+			IncludeDeleted: includeDeleted(req),
+			AfterID:        afterID,
+			Page:           page,
+			Limit:          limit,
 		}
 
 		resp, err := customerController.List(ctx, jsonPresenter, input)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to list customers", "error", err)
-			return response.NewAPIGatewayProxyResponseError(err), nil
+			return response.NewHTTPResponseError(err)
 		}
-		return response.NewAPIGatewayProxyResponse(resp), nil
+		return response.NewHTTPResponse(http.StatusOK, resp)
 	}
 
 	// Get by CPF
@@ -143,153 +326,456 @@ func handleGetRequest(ctx context.Context, req events.APIGatewayProxyRequest) (e
 		resp, err := customerController.GetByCPF(ctx, jsonPresenter, input)
 		if err != nil {
 			l.ErrorContext(ctx, "Failed to get customer by CPF", "cpf", cpf, "error", err)
-			return response.NewAPIGatewayProxyResponseError(err), nil
+			return response.NewHTTPResponseError(err)
 		}
-		return response.NewAPIGatewayProxyResponse(resp), nil
+		return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
 	}
 
 	// Get by ID
 	id, err := strconv.Atoi(customerID)
 	if err != nil {
 		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
 	}
-	input := dto.GetCustomerInput{ID: id}
+	input := dto.GetCustomerInput{ID: id, IncludeDeleted: includeDeleted(req)}
 	resp, err := customerController.Get(ctx, jsonPresenter, input)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to get customer", "id", customerID, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
 	}
-	return response.NewAPIGatewayProxyResponse(resp), nil
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
 }
 
 // handlePostRequest handles POST requests to create customers
-func handlePostRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handlePostRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
 	var customerRequest request.CustomerRequest
-	var body = []byte(req.Body)
-	var err error
-
-	if req.IsBase64Encoded {
-		body, err = base64.StdEncoding.DecodeString(req.Body)
-		if err != nil {
-			return response.NewAPIGatewayProxyResponseError(err), nil
-		}
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
 	}
 
 	err = json.Unmarshal(body, &customerRequest)
 	if err != nil {
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{Message: err.Error()}), nil
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: err.Error()})
 	}
 
 	input := customerRequest.ToCreateCustomerInput()
 	resp, err := customerController.Create(ctx, jsonPresenter, input)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to create customer", "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
+	}
+
+	created := withETag(response.NewHTTPResponse(http.StatusCreated, resp), resp)
+	if id, _, ok := presentedCustomer(resp); ok {
+		created = created.WithHeader("Location", "/customers/"+id)
+	}
+	return created
+}
+
+// handleBatchGetRequest handles POST /customers:batchGet, which returns the customers with the given IDs
+// and the IDs that weren't found
+func handleBatchGetRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	var batchGetRequest request.BatchGetCustomersRequest
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
 	}
 
-	return response.NewAPIGatewayProxyResponse(resp), nil
+	err = json.Unmarshal(body, &batchGetRequest)
+	if err != nil {
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: err.Error()})
+	}
+
+	resp, err := customerController.BatchGet(ctx, jsonPresenter, batchGetRequest.ToBatchGetCustomersInput())
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to batch get customers", "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+	return response.NewHTTPResponse(http.StatusOK, resp)
 }
 
 // handlePutRequest handles PUT requests to update customers
-func handlePutRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handlePutRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
 	customerID, hasID := req.PathParameters["id"]
 	if !hasID {
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{
+		return response.NewHTTPResponseError(&domain.InvalidInputError{
 			Message: "Customer ID is required for update",
-		}), nil
+		})
 	}
 
-	var customerRequest request.CustomerRequest
-	var body = []byte(req.Body)
-	var err error
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
+	}
 
-	if req.IsBase64Encoded {
-		body, err = base64.StdEncoding.DecodeString(req.Body)
-		if err != nil {
-			return response.NewAPIGatewayProxyResponseError(err), nil
-		}
+	var customerRequest request.CustomerRequest
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
 	}
 
 	err = json.Unmarshal(body, &customerRequest)
 	if err != nil {
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{Message: err.Error()}), nil
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: err.Error()})
 	}
 
 	id, err := strconv.Atoi(customerID)
 	if err != nil {
 		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
 	}
 
 	input := customerRequest.ToUpdateCustomerInput()
 	input.ID = id
+	input.Version = version
 
 	resp, err := customerController.Update(ctx, jsonPresenter, input)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to update customer", "id", customerID, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
+	}
+
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
+}
+
+// handlePatchRequest handles PATCH requests, which apply a JSON Merge Patch to a customer
+func handlePatchRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID, hasID := req.PathParameters["id"]
+	if !hasID {
+		return response.NewHTTPResponseError(&domain.InvalidInputError{
+			Message: "Customer ID is required for update",
+		})
+	}
+
+	contentType := req.Header("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType != request.MergePatchContentType {
+		return response.NewUnsupportedMediaTypeResponse(contentType, request.MergePatchContentType)
+	}
+
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
+	}
+
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+
+	patch, err := request.NewCustomerMergePatchRequest(body)
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	input, err := patch.ToUpdateCustomerInput()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
 	}
+	input.ID = id
+	input.Version = version
 
-	return response.NewAPIGatewayProxyResponse(resp), nil
+	resp, err := customerController.Update(ctx, jsonPresenter, input)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to patch customer", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
 }
 
 // handleDeleteRequest handles DELETE requests to delete customers
-func handleDeleteRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+func handleDeleteRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
 	customerID, hasID := req.PathParameters["id"]
 	if !hasID {
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{
+		return response.NewHTTPResponseError(&domain.InvalidInputError{
 			Message: "Customer ID is required for deletion",
-		}), nil
+		})
+	}
+
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
 	}
 
 	id, err := strconv.Atoi(customerID)
 	if err != nil {
 		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
 	}
 
-	input := dto.DeleteCustomerInput{ID: id}
+	input := dto.DeleteCustomerInput{ID: id, Version: version}
 	resp, err := customerController.Delete(ctx, jsonPresenter, input)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to delete customer", "id", customerID, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
 	}
 
-	return response.NewAPIGatewayProxyResponse(resp), nil
+	// The deleted customer is only returned when the client asks for it
+	if req.Prefers("return=representation") {
+		return response.NewHTTPResponse(http.StatusOK, resp).WithHeader("Preference-Applied", "return=representation")
+	}
+	return response.NewNoContentResponse()
 }
 
-// handleAuthRequest handles authentication requests that return JWT tokens
-func handleAuthRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	var customerRequest request.CustomerRequest
-	var body = []byte(req.Body)
-	var err error
+// handleRestoreRequest handles POST /customers/{id}:restore, which undoes the deletion of a customer
+func handleRestoreRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID := req.PathParameters["id"]
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: domain.ErrInvalidParam})
+	}
+
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
+	}
+
+	input := dto.RestoreCustomerInput{ID: id, Version: version}
+	resp, err := customerController.Restore(ctx, jsonPresenter, input)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to restore customer", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
+}
+
+// handleAnonymizeRequest handles POST /customers/{id}:anonymize, which erases the personal data of a customer
+func handleAnonymizeRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID := req.PathParameters["id"]
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: domain.ErrInvalidParam})
+	}
+
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
+	}
+
+	input := dto.AnonymizeCustomerInput{ID: id, Version: version}
+	resp, err := customerController.Anonymize(ctx, jsonPresenter, input)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to anonymize customer", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	// The responses stored for the idempotency keys of the customer present its personal data. They expire
+	// with the keys anyway, so a failure to purge them doesn't fail the anonymization
+	if idempotencyDataSource != nil {
+		if err := idempotencyDataSource.PurgeCustomer(ctx, id); err != nil {
+			l.ErrorContext(ctx, "Failed to purge the idempotency keys of the anonymized customer", "id", customerID, "error", err)
+		}
+	}
+
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
+}
+
+// handleStatusRequest handles PUT /customers/{id}/status, which admins use to move a customer to another status
+func handleStatusRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID := req.PathParameters["id"]
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: domain.ErrInvalidParam})
+	}
+
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
+	}
+
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+
+	var statusRequest request.CustomerStatusRequest
+	if err := json.Unmarshal(body, &statusRequest); err != nil {
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: err.Error()})
+	}
+
+	input := statusRequest.ToChangeCustomerStatusInput()
+	input.ID = id
+	input.Version = version
+
+	resp, err := customerController.ChangeStatus(ctx, jsonPresenter, input)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to change customer status", "id", customerID, "status", input.Status, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
+}
 
-	if req.IsBase64Encoded {
-		body, err = base64.StdEncoding.DecodeString(req.Body)
+// handleHistoryRequest handles GET /customers/{id}/history, which returns the audit entries of a customer to admins
+func handleHistoryRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID := req.PathParameters["id"]
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: domain.ErrInvalidParam})
+	}
+
+	resp, err := customerController.History(ctx, jsonPresenter, dto.GetCustomerHistoryInput{ID: id})
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to get customer history", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	return response.NewHTTPResponse(http.StatusOK, resp)
+}
+
+// handleDataExportRequest handles GET /customers/{id}/data-export, which returns everything the service holds
+// about a customer to the customer themself or an admin, as a JSON file to download
+func handleDataExportRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID := req.PathParameters["id"]
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: domain.ErrInvalidParam})
+	}
+
+	resp, err := customerController.DataExport(ctx, jsonPresenter, dto.GetCustomerDataExportInput{ID: id})
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to export customer data", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	return response.NewHTTPResponse(http.StatusOK, resp).
+		WithHeader("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%d-data-export.json"`, id))
+}
+
+// handleConsentsRequest handles GET /customers/{id}/consents, which returns the consents of a customer, and
+// PUT /customers/{id}/consents, which records new decisions on them
+func handleConsentsRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	customerID := req.PathParameters["id"]
+	id, err := strconv.Atoi(customerID)
+	if err != nil {
+		l.ErrorContext(ctx, "Invalid customer ID", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: domain.ErrInvalidParam})
+	}
+
+	if req.Method == "GET" {
+		resp, err := customerController.GetConsents(ctx, jsonPresenter, dto.GetCustomerConsentsInput{ID: id})
 		if err != nil {
-			return response.NewAPIGatewayProxyResponseError(err), nil
+			l.ErrorContext(ctx, "Failed to get customer consents", "id", customerID, "error", err)
+			return response.NewHTTPResponseError(err)
 		}
+		return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
+	}
+
+	version, present, err := req.IfMatch()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+	if !present && ifMatchRequired {
+		return response.NewPreconditionRequiredResponse()
+	}
+
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
+	}
+
+	var consentsRequest request.CustomerConsentsRequest
+	if err := json.Unmarshal(body, &consentsRequest); err != nil {
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: err.Error()})
+	}
+
+	input := consentsRequest.ToUpdateCustomerConsentsInput()
+	input.ID = id
+	input.Version = version
+
+	resp, err := customerController.UpdateConsents(ctx, jsonPresenter, input)
+	if err != nil {
+		l.ErrorContext(ctx, "Failed to update customer consents", "id", customerID, "error", err)
+		return response.NewHTTPResponseError(err)
+	}
+
+	return withETag(response.NewHTTPResponse(http.StatusOK, resp), resp)
+}
+
+// includeDeleted reads the include_deleted flag of the admins, which also returns the deleted customers
+func includeDeleted(req request.HTTPRequest) bool {
+	include, _ := strconv.ParseBool(req.QueryStringParameters["include_deleted"])
+	return include
+}
+
+// handleAuthRequest handles authentication requests that return JWT tokens
+func handleAuthRequest(ctx context.Context, req request.HTTPRequest) response.HTTPResponse {
+	var customerRequest request.CustomerRequest
+	body, err := req.DecodedBody()
+	if err != nil {
+		return response.NewHTTPResponseError(err)
 	}
 
 	err = json.Unmarshal(body, &customerRequest)
 	if err != nil {
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{Message: err.Error()}), nil
+		return response.NewHTTPResponseError(&domain.InvalidInputError{Message: err.Error()})
 	}
 
 	// Authentication typically uses CPF lookup
 	if customerRequest.CPF == "" {
-		return response.NewAPIGatewayProxyResponseError(&domain.InvalidInputError{
+		return response.NewHTTPResponseError(&domain.InvalidInputError{
 			Message: "CPF is required for authentication",
-		}), nil
+		})
 	}
 
-	input := dto.GetCustomerByCPFInput{CPF: customerRequest.CPF}
-	resp, err := customerController.GetByCPF(ctx, jwtPresenter, input)
+	input := dto.AuthenticateCustomerInput{CPF: customerRequest.CPF}
+	resp, err := customerController.Authenticate(ctx, jwtPresenter, input)
 	if err != nil {
 		l.ErrorContext(ctx, "Failed to authenticate customer", "cpf", customerRequest.CPF, "error", err)
-		return response.NewAPIGatewayProxyResponseError(err), nil
+		return response.NewHTTPResponseError(err)
 	}
 
-	return response.NewAPIGatewayProxyResponse(resp), nil
+	return response.NewHTTPResponse(http.StatusOK, resp)
+}
+
+// presentedCustomer reads the id and version of a customer from its presented representation
+func presentedCustomer(body []byte) (string, int, bool) {
+	var customer struct {
+		ID      json.RawMessage `json:"id"`
+		Version int             `json:"version"`
+	}
+	if err := json.Unmarshal(body, &customer); err != nil || len(customer.ID) == 0 {
+		return "", 0, false
+	}
+	return strings.Trim(string(customer.ID), `"`), customer.Version, true
+}
+
+// withETag sets the ETag header with the version of the presented customer, which clients send
+// back in the If-Match header of updates and deletions
+func withETag(resp response.HTTPResponse, body []byte) response.HTTPResponse {
+	if _, version, ok := presentedCustomer(body); ok && version > 0 {
+		return resp.WithHeader("ETag", response.ETag(version))
+	}
+	return resp
 }
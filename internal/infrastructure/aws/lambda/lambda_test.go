@@ -4,7 +4,10 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"testing"
 
 	"go.uber.org/mock/gomock"
@@ -12,8 +15,19 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 	mockport "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/objectstore"
 )
 
 //go:embed golden/success_response.golden
@@ -67,6 +81,7 @@ func TestHandleRequest_InvalidJSON(t *testing.T) {
 
 	resp, _ := handleRequest(context.Background(), req)
 	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
 	assert.Contains(t, resp.Body, "invalid character")
 }
 
@@ -92,6 +107,31 @@ func TestHandleRequest_ControllerError(t *testing.T) {
 	assert.Contains(t, resp.Body, "invalid syntax")
 }
 
+func TestHandleRequest_ServiceUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+
+	cause := errors.New("operation error DynamoDB: GetItem, https response error StatusCode: 500, RequestID: ABC123, " +
+		"arn:aws:dynamodb:us-east-1:123456789012:table/tc4-customer-service-prod-customers")
+	mockController.
+		EXPECT().
+		Get(gomock.Any(), gomock.Any(), dto.GetCustomerInput{ID: 123}).
+		Return(nil, domain.NewServiceUnavailableError(cause))
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": "123"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Contains(t, resp.Body, domain.ErrServiceUnavailable)
+	assert.NotContains(t, resp.Body, "arn:aws")
+	assert.NotContains(t, resp.Body, "ABC123")
+}
+
 func TestHandleRequest_CreateCustomer_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -128,18 +168,68 @@ func TestHandleRequest_CreateCustomer_Success(t *testing.T) {
 
 	resp, err := handleRequest(context.Background(), lambdaReq)
 	assert.NoError(t, err)
-	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "/customers/123", resp.Headers["Location"])
 	assert.Equal(t, string(expectedResp), resp.Body)
 }
 
+func TestHandleRequest_DeleteCustomer(t *testing.T) {
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "should return no content by default",
+			expectedStatus: 204,
+			expectedBody:   "",
+		},
+		{
+			name:           "should return the deleted customer when representation is preferred",
+			headers:        map[string]string{"Prefer": "return=representation"},
+			expectedStatus: 200,
+			expectedBody:   `{"id":123,"name":"John Doe"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			mockPresenter := mockport.NewMockPresenter(ctrl)
+
+			customerController = mockController
+			jsonPresenter = mockPresenter
+
+			mockController.
+				EXPECT().
+				Delete(gomock.Any(), jsonPresenter, dto.DeleteCustomerInput{ID: 123}).
+				Return([]byte(`{"id":123,"name":"John Doe"}`), nil).
+				Times(1)
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     "DELETE",
+				PathParameters: map[string]string{"id": "123"},
+				Headers:        tt.headers,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedBody, resp.Body)
+		})
+	}
+}
+
 func TestHandleRequest_UnsupportedMethod(t *testing.T) {
 	lambdaReq := events.APIGatewayProxyRequest{
-		HTTPMethod: "PATCH",
+		HTTPMethod: "OPTIONS",
 	}
 
 	resp, _ := handleRequest(context.Background(), lambdaReq)
 	assert.Equal(t, 400, resp.StatusCode)
-	assert.Contains(t, resp.Body, "HTTP method PATCH not supported")
+	assert.Contains(t, resp.Body, "HTTP method OPTIONS not supported")
 }
 
 func TestHandleRequest_Auth_Success(t *testing.T) {
@@ -169,7 +259,7 @@ func TestHandleRequest_Auth_Success(t *testing.T) {
 
 	mockController.
 		EXPECT().
-		GetByCPF(gomock.Any(), jwtPresenter, gomock.Any()).
+		Authenticate(gomock.Any(), jwtPresenter, dto.AuthenticateCustomerInput{CPF: "12345678900"}).
 		Return(expectedResp, nil).
 		Times(1)
 
@@ -197,3 +287,1071 @@ func TestHandleRequest_Auth_MissingCPF(t *testing.T) {
 	assert.Equal(t, 400, resp.StatusCode)
 	assert.Contains(t, resp.Body, "CPF is required for authentication")
 }
+
+func TestHandleRequest_Auth_InactiveCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jwtPresenter = mockport.NewMockPresenter(ctrl)
+
+	mockController.
+		EXPECT().
+		Authenticate(gomock.Any(), jwtPresenter, dto.AuthenticateCustomerInput{CPF: "12345678900"}).
+		Return(nil, domain.NewForbiddenError(domain.ErrCustomerNotActive))
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/auth",
+		Body:       `{"cpf":"12345678900"}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+	assert.Contains(t, resp.Body, domain.ErrCustomerNotActive)
+}
+
+func TestHandleEvent_HTTPAPIv2_GetByID_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+
+	customerController = mockController
+	jsonPresenter = mockPresenter
+
+	event, err := json.Marshal(events.APIGatewayV2HTTPRequest{
+		Version:        "2.0",
+		RouteKey:       "GET /customers/{id}",
+		RawPath:        "/customers/123",
+		PathParameters: map[string]string{"id": "123"},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "GET", Path: "/customers/123"},
+		},
+	})
+	assert.NoError(t, err)
+
+	expectedResp := []byte(`{"name":"Test User"}`)
+
+	mockController.
+		EXPECT().
+		Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123}).
+		Return(expectedResp, nil).
+		Times(1)
+
+	got, err := handleEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	resp, ok := got.(events.APIGatewayV2HTTPResponse)
+	assert.True(t, ok)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+	assert.JSONEq(t, string(expectedGolden), resp.Body)
+}
+
+func TestHandleEvent_ALB_GetByID_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+
+	customerController = mockController
+	jsonPresenter = mockPresenter
+
+	event, err := json.Marshal(events.ALBTargetGroupRequest{
+		HTTPMethod: "GET",
+		Path:       "/customers/123",
+		Headers:    map[string]string{"accept": "application/json"},
+		RequestContext: events.ALBTargetGroupRequestContext{
+			ELB: events.ELBContext{TargetGroupArn: "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/customers/abc"},
+		},
+	})
+	assert.NoError(t, err)
+
+	expectedResp := []byte(`{"name":"Test User"}`)
+
+	mockController.
+		EXPECT().
+		Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123}).
+		Return(expectedResp, nil).
+		Times(1)
+
+	got, err := handleEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	resp, ok := got.(events.ALBTargetGroupResponse)
+	assert.True(t, ok)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "200 OK", resp.StatusDescription)
+	assert.Equal(t, "application/json", resp.Headers["Content-Type"])
+	assert.JSONEq(t, string(expectedGolden), resp.Body)
+}
+
+func TestHandleEvent_ALB_ListWithEncodedQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	mockPresenter := mockport.NewMockPresenter(ctrl)
+
+	customerController = mockController
+	jsonPresenter = mockPresenter
+
+	event, err := json.Marshal(events.ALBTargetGroupRequest{
+		HTTPMethod:                      "GET",
+		Path:                            "/customers",
+		MultiValueQueryStringParameters: map[string][]string{"cpf": {"123.456.789%2D00"}},
+		MultiValueHeaders:               map[string][]string{"accept": {"application/json"}},
+		RequestContext: events.ALBTargetGroupRequestContext{
+			ELB: events.ELBContext{TargetGroupArn: "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/customers/abc"},
+		},
+	})
+	assert.NoError(t, err)
+
+	expectedResp := []byte(`{"name":"Test User"}`)
+
+	mockController.
+		EXPECT().
+		GetByCPF(gomock.Any(), jsonPresenter, dto.GetCustomerByCPFInput{CPF: "123.456.789-00"}).
+		Return(expectedResp, nil).
+		Times(1)
+
+	got, err := handleEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	resp, ok := got.(events.ALBTargetGroupResponse)
+	assert.True(t, ok)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Empty(t, resp.Headers)
+	assert.Equal(t, []string{"application/json"}, resp.MultiValueHeaders["Content-Type"])
+}
+
+func TestHandleEvent_RESTAPIv1_Auth(t *testing.T) {
+	event := []byte(`{"resource":"/auth","path":"/auth","httpMethod":"POST","body":"{}","requestContext":{"requestId":"abc"}}`)
+
+	got, err := handleEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	resp, ok := got.(events.APIGatewayProxyResponse)
+	assert.True(t, ok)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Contains(t, resp.Body, "CPF is required for authentication")
+}
+
+func TestHandleEvent_InvalidPayload(t *testing.T) {
+	_, err := handleEvent(context.Background(), []byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestHandleRequest_PatchCustomer(t *testing.T) {
+	name := "John Patched"
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		setupMocks     func(*mockport.MockCustomerController)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "should apply the merge patch",
+			contentType: "application/merge-patch+json; charset=utf-8",
+			body:        `{"name":"John Patched"}`,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					Update(gomock.Any(), jsonPresenter, dto.UpdateCustomerInput{ID: 123, Name: &name}).
+					Return([]byte(`{"id":123,"name":"John Patched"}`), nil)
+			},
+			expectedStatus: 200,
+			expectedBody:   `{"id":123,"name":"John Patched"}`,
+		},
+		{
+			name:           "should reject other content types",
+			contentType:    "application/json",
+			body:           `{"name":"John Patched"}`,
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 415,
+			expectedBody:   `application/merge-patch+json`,
+		},
+		{
+			name:           "should reject removing a mandatory field",
+			contentType:    "application/merge-patch+json",
+			body:           `{"email":null}`,
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+			expectedBody:   `field email can't be removed`,
+		},
+		{
+			name:           "should reject documents that aren't objects",
+			contentType:    "application/merge-patch+json",
+			body:           `["name"]`,
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+			expectedBody:   `merge patch document must be a JSON object`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockController)
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     "PATCH",
+				PathParameters: map[string]string{"id": "123"},
+				Headers:        map[string]string{"Content-Type": tt.contentType},
+				Body:           tt.body,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Contains(t, resp.Body, tt.expectedBody)
+		})
+	}
+}
+
+func TestHandleRequest_ETag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+
+	mockController.
+		EXPECT().
+		Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123}).
+		Return([]byte(`{"id":123,"name":"John Doe","version":3}`), nil)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		PathParameters: map[string]string{"id": "123"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, `"3"`, resp.Headers["ETag"])
+}
+
+func TestHandleRequest_IfMatch(t *testing.T) {
+	name := "John Doe"
+	email := "john@example.com"
+	version := 3
+
+	tests := []struct {
+		name            string
+		method          string
+		ifMatch         string
+		ifMatchRequired bool
+		setupMocks      func(*mockport.MockCustomerController)
+		expectedStatus  int
+		expectedETag    string
+	}{
+		{
+			name:    "should update the customer at the version of the ETag",
+			method:  "PUT",
+			ifMatch: `"3"`,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					Update(gomock.Any(), jsonPresenter, dto.UpdateCustomerInput{ID: 123, Name: &name, Email: &email, Version: &version}).
+					Return([]byte(`{"id":123,"name":"John Doe","version":4}`), nil)
+			},
+			expectedStatus: 200,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:            "should update any version with the wildcard",
+			method:          "PUT",
+			ifMatch:         "*",
+			ifMatchRequired: true,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					Update(gomock.Any(), jsonPresenter, dto.UpdateCustomerInput{ID: 123, Name: &name, Email: &email}).
+					Return([]byte(`{"id":123,"name":"John Doe","version":4}`), nil)
+			},
+			expectedStatus: 200,
+			expectedETag:   `"4"`,
+		},
+		{
+			name:    "should return precondition failed when the version doesn't match",
+			method:  "DELETE",
+			ifMatch: `"3"`,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					Delete(gomock.Any(), jsonPresenter, dto.DeleteCustomerInput{ID: 123, Version: &version}).
+					Return(nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch))
+			},
+			expectedStatus: 412,
+		},
+		{
+			name:            "should require If-Match when configured",
+			method:          "DELETE",
+			ifMatchRequired: true,
+			setupMocks:      func(*mockport.MockCustomerController) {},
+			expectedStatus:  428,
+		},
+		{
+			name:            "should require If-Match on merge patches",
+			method:          "PATCH",
+			ifMatchRequired: true,
+			setupMocks:      func(*mockport.MockCustomerController) {},
+			expectedStatus:  428,
+		},
+		{
+			name:           "should reject malformed If-Match headers",
+			method:         "PUT",
+			ifMatch:        "3",
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockController)
+
+			ifMatchRequired = tt.ifMatchRequired
+			defer func() { ifMatchRequired = false }()
+
+			headers := map[string]string{"Content-Type": "application/json"}
+			body := `{"name":"John Doe","email":"john@example.com"}`
+			if tt.method == "PATCH" {
+				headers["Content-Type"] = "application/merge-patch+json"
+				body = `{"name":"John Doe"}`
+			}
+			if tt.ifMatch != "" {
+				headers["If-Match"] = tt.ifMatch
+			}
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     tt.method,
+				PathParameters: map[string]string{"id": "123"},
+				Headers:        headers,
+				Body:           body,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedETag, resp.Headers["ETag"])
+		})
+	}
+}
+
+func TestHandleRequest_ListCustomers_KeysetPagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+
+	mockController.
+		EXPECT().
+		List(gomock.Any(), jsonPresenter, dto.ListCustomersInput{AfterID: 20, Page: 1, Limit: 5}).
+		Return([]byte(`{"customers":[]}`), nil)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		Resource:              "/customers",
+		QueryStringParameters: map[string]string{"after_id": "20", "limit": "5"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestHandleRequest_ListCustomers_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+
+	mockController.
+		EXPECT().
+		List(gomock.Any(), jsonPresenter, dto.ListCustomersInput{Search: "maria silva", Page: 1, Limit: 10}).
+		Return([]byte(`{"customers":[]}`), nil)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		Resource:              "/customers",
+		QueryStringParameters: map[string]string{"search": " maria silva "},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestHandleRequest_BatchGetCustomers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+
+	expectedResp := []byte(`{"customers":[],"missing_ids":[1,2]}`)
+	mockController.
+		EXPECT().
+		BatchGet(gomock.Any(), jsonPresenter, dto.BatchGetCustomersInput{IDs: []int{1, 2}}).
+		Return(expectedResp, nil).
+		Times(2)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/customers:batchGet",
+		Body:       `{"ids":[1,2]}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.JSONEq(t, string(expectedResp), resp.Body)
+
+	// The resource of an ALB event is resolved from its path
+	albResp, err := handleALBRequest(context.Background(), events.ALBTargetGroupRequest{
+		HTTPMethod: "POST",
+		Path:       "/customers:batchGet",
+		Body:       `{"ids":[1,2]}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, albResp.StatusCode)
+
+	resp, err = handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: "POST",
+		Resource:   "/customers:batchGet",
+		Body:       `{"ids":"1"}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestNewCustomerGateway(t *testing.T) {
+	defer func() { cachedCustomerGateway = nil }()
+
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		wantCached bool
+		wantPanic  bool
+	}{
+		{name: "should not cache with the none backend", cfg: &config.Config{CacheBackend: config.CacheBackendNone}},
+		{name: "should cache in memory", cfg: &config.Config{CacheBackend: config.CacheBackendMemory, CacheSize: 10}, wantCached: true},
+		{name: "should cache in redis", cfg: &config.Config{CacheBackend: config.CacheBackendRedis, RedisURL: "redis://localhost:6379/0"}, wantCached: true},
+		{name: "should reject redis with the field-level encryption", cfg: &config.Config{CacheBackend: config.CacheBackendRedis,
+			RedisURL: "redis://localhost:6379/0", PIIKeyProvider: config.KeyProviderKMS}, wantPanic: true},
+		{name: "should reject an unknown backend", cfg: &config.Config{CacheBackend: "memcached"}, wantPanic: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantPanic {
+				assert.Panics(t, func() { newCustomerGateway(tt.cfg) })
+				return
+			}
+
+			_, cached := newCustomerGateway(tt.cfg).(*gateway.CachedCustomerGateway)
+			assert.Equal(t, tt.wantCached, cached)
+		})
+	}
+}
+
+func TestHandleEvent_S3Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func() { objectStore, importMode = nil, "" }()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+	objectStore = objectstore.NewFileObjectStore(t.TempDir())
+	importMode = dto.ImportModeUpsert
+
+	ctx := context.Background()
+	assert.NoError(t, objectStore.Put(ctx, "imports", "new customers.csv", []byte("name,email,cpf\nJohn Doe,john@example.com,52998224725\n")))
+
+	expectedReport := []byte(`{"total":1,"created":1,"updated":0,"skipped":0,"failed":0,"rows":[]}`)
+	mockController.
+		EXPECT().
+		Import(gomock.Any(), jsonPresenter, dto.ImportCustomersInput{
+			Rows: []dto.ImportCustomerRow{{Line: 2, Name: "John Doe", Email: "john@example.com", CPF: "52998224725"}},
+			Mode: dto.ImportModeUpsert,
+		}).
+		Return(expectedReport, nil).
+		Times(1)
+
+	// The keys of S3 events are URL encoded, and the events of the reports are ignored
+	event := `{"Records":[
+		{"eventSource":"aws:s3","s3":{"bucket":{"name":"imports"},"object":{"key":"new+customers.csv"}}},
+		{"eventSource":"aws:s3","s3":{"bucket":{"name":"imports"},"object":{"key":"new+customers.csv.report.json"}}}
+	]}`
+	got, err := handleEvent(ctx, json.RawMessage(event))
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	report, err := objectStore.Open(ctx, "imports", "new customers.csv.report.json")
+	assert.NoError(t, err)
+	defer report.Close()
+	body, err := io.ReadAll(report)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expectedReport), string(body))
+
+	// A missing object fails the event, so it can be retried
+	_, err = handleEvent(ctx, json.RawMessage(`{"Records":[{"eventSource":"aws:s3","s3":{"bucket":{"name":"imports"},"object":{"key":"missing.csv"}}}]}`))
+	assert.Error(t, err)
+}
+
+func TestHandleRequest_RestoreCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+	version := 2
+
+	mockController.
+		EXPECT().
+		Restore(gomock.Any(), jsonPresenter, dto.RestoreCustomerInput{ID: 123, Version: &version}).
+		Return([]byte(`{"id":123,"name":"John Doe","version":3}`), nil)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Resource:       "/customers/{id}:restore",
+		PathParameters: map[string]string{"id": "123"},
+		Headers:        map[string]string{"If-Match": `"2"`},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, `"3"`, resp.Headers["ETag"])
+}
+
+func TestHandleRequest_AnonymizeCustomer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+	version := 2
+
+	mockController.
+		EXPECT().
+		Anonymize(gomock.Any(), jsonPresenter, dto.AnonymizeCustomerInput{ID: 123, Version: &version}).
+		Return([]byte(`{"id":123,"name":"anon-PW3XQ","version":3}`), nil)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Resource:       "/customers/{id}:anonymize",
+		PathParameters: map[string]string{"id": "123"},
+		Headers:        map[string]string{"If-Match": `"2"`},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, `"3"`, resp.Headers["ETag"])
+}
+
+func TestHandleRequest_AnonymizeErasesPersonalData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mockport.NewMockIAuthenticationService(ctrl)
+	mockStore := mockport.NewMockIdempotencyDataSource(ctrl)
+	previousAuth := authenticationService
+	authenticationService = mockAuth
+	idempotencyDataSource = mockStore
+	defer func() { authenticationService, idempotencyDataSource = previousAuth, nil }()
+
+	customerController = controller.NewCustomerController(usecase.NewCustomerUseCase(
+		gateway.NewCustomerGateway(datasource.NewCustomerMemoryDataSource()),
+		gateway.NewAuditGateway(datasource.NewAuditMemoryDataSource()),
+		eventpublisher.NewLogEventPublisher(l)))
+	jsonPresenter = presenter.NewCustomerJsonPresenter()
+	admin := &entity.Principal{Subject: "ops", Role: entity.RoleAdmin}
+	mockAuth.EXPECT().ValidateToken("admin-token").Return(admin, nil).AnyTimes()
+
+	send := func(method, resource, id, body string, headers map[string]string) events.APIGatewayProxyResponse {
+		requestHeaders := map[string]string{"Authorization": "Bearer admin-token", "Content-Type": "application/json"}
+		for name, value := range headers {
+			requestHeaders[name] = value
+		}
+		resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:     method,
+			Resource:       resource,
+			Path:           resource,
+			PathParameters: map[string]string{"id": id},
+			Headers:        requestHeaders,
+			Body:           body,
+		})
+		assert.NoError(t, err)
+		return resp
+	}
+
+	created := send("POST", "/customers", "", `{"name":"John Doe","email":"john@example.com","cpf":"12345678909"}`, nil)
+	assert.Equal(t, 201, created.StatusCode)
+	id, _, _ := presentedCustomer([]byte(created.Body))
+
+	patched := send("PATCH", "/customers/{id}", id, `{"email":"john.doe@example.com"}`,
+		map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": created.Headers["ETag"]})
+	assert.Equal(t, 200, patched.StatusCode)
+
+	customerID, _ := strconv.Atoi(id)
+	mockStore.EXPECT().PurgeCustomer(gomock.Any(), customerID).Return(nil)
+	anonymized := send("POST", "/customers/{id}:anonymize", id, "", map[string]string{"If-Match": patched.Headers["ETag"]})
+	assert.Equal(t, 200, anonymized.StatusCode)
+
+	history := send("GET", "/customers/{id}/history", id, "", nil)
+	assert.Equal(t, 200, history.StatusCode)
+	assert.Contains(t, history.Body, entity.AuditErasedValue)
+	for _, personalData := range []string{"John Doe", "john@example.com", "john.doe@example.com", "12345678909"} {
+		assert.NotContains(t, history.Body, personalData)
+	}
+}
+
+func TestHandleRequest_IncludeDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+
+	mockController.
+		EXPECT().
+		List(gomock.Any(), jsonPresenter, dto.ListCustomersInput{IncludeDeleted: true, Page: 1, Limit: 10}).
+		Return([]byte(`{"customers":[]}`), nil)
+	mockController.
+		EXPECT().
+		Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123, IncludeDeleted: true}).
+		Return([]byte(`{"id":123}`), nil)
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		Resource:              "/customers",
+		QueryStringParameters: map[string]string{"include_deleted": "true"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, err = handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:            "GET",
+		PathParameters:        map[string]string{"id": "123"},
+		QueryStringParameters: map[string]string{"include_deleted": "true"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestHandleRequest_Authentication(t *testing.T) {
+	admin := &entity.Principal{Subject: "ops", Role: entity.RoleAdmin}
+
+	tests := []struct {
+		name            string
+		authorization   string
+		setupMocks      func(*mockport.MockIAuthenticationService, *mockport.MockCustomerController)
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			name:          "should put the principal of the token in the context",
+			authorization: "Bearer admin-token",
+			setupMocks: func(mockAuth *mockport.MockIAuthenticationService, mockController *mockport.MockCustomerController) {
+				mockAuth.EXPECT().ValidateToken("admin-token").Return(admin, nil)
+				mockController.
+					EXPECT().
+					Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123}).
+					DoAndReturn(func(ctx context.Context, _ port.Presenter, _ dto.GetCustomerInput) ([]byte, error) {
+						assert.Equal(t, admin, entity.PrincipalFromContext(ctx))
+						return []byte(`{"id":123}`), nil
+					})
+			},
+			expectedStatus: 200,
+		},
+		{
+			name: "should handle a request without a token as anonymous",
+			setupMocks: func(_ *mockport.MockIAuthenticationService, mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123}).
+					DoAndReturn(func(ctx context.Context, _ port.Presenter, _ dto.GetCustomerInput) ([]byte, error) {
+						assert.Nil(t, entity.PrincipalFromContext(ctx))
+						return []byte(`{"id":123}`), nil
+					})
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:          "should refuse an invalid token",
+			authorization: "Bearer expired-token",
+			setupMocks: func(mockAuth *mockport.MockIAuthenticationService, _ *mockport.MockCustomerController) {
+				mockAuth.EXPECT().ValidateToken("expired-token").Return(nil, domain.NewUnauthorizedError(domain.ErrExpiredToken))
+			},
+			expectedStatus:  401,
+			expectedHeaders: map[string]string{"WWW-Authenticate": "Bearer"},
+		},
+		{
+			name:           "should refuse an authorization that isn't a bearer token",
+			authorization:  "Basic dXNlcjpwYXNz",
+			setupMocks:     func(*mockport.MockIAuthenticationService, *mockport.MockCustomerController) {},
+			expectedStatus: 401,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuth := mockport.NewMockIAuthenticationService(ctrl)
+			mockController := mockport.NewMockCustomerController(ctrl)
+			previous := authenticationService
+			authenticationService = mockAuth
+			defer func() { authenticationService = previous }()
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockAuth, mockController)
+
+			headers := map[string]string{}
+			if tt.authorization != "" {
+				headers["Authorization"] = tt.authorization
+			}
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     "GET",
+				PathParameters: map[string]string{"id": "123"},
+				Headers:        headers,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			for name, value := range tt.expectedHeaders {
+				assert.Equal(t, value, resp.Headers[name])
+			}
+		})
+	}
+}
+
+func TestHandleRequest_ChangeStatus(t *testing.T) {
+	version := 2
+
+	tests := []struct {
+		name           string
+		pathID         string
+		body           string
+		setupMocks     func(*mockport.MockCustomerController)
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name:   "should change the status of the customer",
+			pathID: "123",
+			body:   `{"status":"blocked","reason":"chargeback fraud"}`,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					ChangeStatus(gomock.Any(), jsonPresenter, dto.ChangeCustomerStatusInput{
+						ID: 123, Status: "blocked", Reason: "chargeback fraud", Version: &version,
+					}).
+					Return([]byte(`{"id":123,"status":"blocked","version":3}`), nil)
+			},
+			expectedStatus: 200,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:   "should return conflict when the transition isn't allowed",
+			pathID: "123",
+			body:   `{"status":"active","reason":"reopen"}`,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					ChangeStatus(gomock.Any(), jsonPresenter, gomock.Any()).
+					Return(nil, domain.NewConflictError(domain.ErrCustomerInvalidStatusTransition))
+			},
+			expectedStatus: 409,
+		},
+		{
+			name:           "should reject an invalid body",
+			pathID:         "123",
+			body:           `{"status":`,
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+		},
+		{
+			name:           "should reject an invalid customer ID",
+			pathID:         "abc",
+			body:           `{"status":"blocked","reason":"fraud"}`,
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockController)
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     "PUT",
+				Resource:       "/customers/{id}/status",
+				PathParameters: map[string]string{"id": tt.pathID},
+				Headers:        map[string]string{"If-Match": `"2"`},
+				Body:           tt.body,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedETag, resp.Headers["ETag"])
+		})
+	}
+}
+
+func TestHandleRequest_History(t *testing.T) {
+	tests := []struct {
+		name           string
+		pathID         string
+		setupMocks     func(*mockport.MockCustomerController)
+		expectedStatus int
+	}{
+		{
+			name:   "should return the history of the customer",
+			pathID: "123",
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					History(gomock.Any(), jsonPresenter, dto.GetCustomerHistoryInput{ID: 123}).
+					DoAndReturn(func(ctx context.Context, _ port.Presenter, _ dto.GetCustomerHistoryInput) ([]byte, error) {
+						assert.Equal(t, "req-1", entity.RequestIDFromContext(ctx))
+						return []byte(`{"entries":[]}`), nil
+					})
+			},
+			expectedStatus: 200,
+		},
+		{
+			name:   "should return forbidden for a caller that isn't an admin",
+			pathID: "123",
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					History(gomock.Any(), jsonPresenter, dto.GetCustomerHistoryInput{ID: 123}).
+					Return(nil, domain.NewForbiddenError(domain.ErrAdminOnly))
+			},
+			expectedStatus: 403,
+		},
+		{
+			name:           "should reject an invalid customer ID",
+			pathID:         "abc",
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockController)
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     "GET",
+				Resource:       "/customers/{id}/history",
+				PathParameters: map[string]string{"id": tt.pathID},
+				RequestContext: events.APIGatewayProxyRequestContext{RequestID: "req-1"},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestHandleRequest_DataExport(t *testing.T) {
+	tests := []struct {
+		name                       string
+		pathID                     string
+		setupMocks                 func(*mockport.MockCustomerController)
+		expectedStatus             int
+		expectedContentDisposition string
+	}{
+		{
+			name:   "should return the data of the customer as a file",
+			pathID: "123",
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					DataExport(gomock.Any(), jsonPresenter, dto.GetCustomerDataExportInput{ID: 123}).
+					Return([]byte(`{"customer":{"id":123},"history":[]}`), nil)
+			},
+			expectedStatus:             200,
+			expectedContentDisposition: `attachment; filename="customer-123-data-export.json"`,
+		},
+		{
+			name:   "should return forbidden for another customer",
+			pathID: "123",
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					DataExport(gomock.Any(), jsonPresenter, dto.GetCustomerDataExportInput{ID: 123}).
+					Return(nil, domain.NewForbiddenError(domain.ErrSelfOrAdmin))
+			},
+			expectedStatus: 403,
+		},
+		{
+			name:           "should reject an invalid customer ID",
+			pathID:         "abc",
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockController)
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     "GET",
+				Resource:       "/customers/{id}/data-export",
+				PathParameters: map[string]string{"id": tt.pathID},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedContentDisposition, resp.Headers["Content-Disposition"])
+		})
+	}
+}
+
+func TestHandleRequest_Consents(t *testing.T) {
+	granted := true
+	version := 2
+	tests := []struct {
+		name           string
+		method         string
+		pathID         string
+		headers        map[string]string
+		body           string
+		setupMocks     func(*mockport.MockCustomerController)
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name:   "should return the consents of the customer",
+			method: "GET",
+			pathID: "123",
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					GetConsents(gomock.Any(), jsonPresenter, dto.GetCustomerConsentsInput{ID: 123}).
+					Return([]byte(`{"id":123,"version":2,"consents":[],"history":[]}`), nil)
+			},
+			expectedStatus: 200,
+			expectedETag:   `"2"`,
+		},
+		{
+			name:    "should record the consents of the customer",
+			method:  "PUT",
+			pathID:  "123",
+			headers: map[string]string{"If-Match": `"2"`},
+			body:    `{"consents":[{"purpose":"marketing","granted":true,"policy_version":"1.0","channel":"web"}]}`,
+			setupMocks: func(mockController *mockport.MockCustomerController) {
+				mockController.
+					EXPECT().
+					UpdateConsents(gomock.Any(), jsonPresenter, dto.UpdateCustomerConsentsInput{
+						ID:       123,
+						Version:  &version,
+						Consents: []dto.ConsentInput{{Purpose: "marketing", Granted: &granted, PolicyVersion: "1.0", Channel: "web"}},
+					}).
+					Return([]byte(`{"id":123,"version":3,"consents":[],"history":[]}`), nil)
+			},
+			expectedStatus: 200,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:    "should reject an invalid body",
+			method:  "PUT",
+			pathID:  "123",
+			headers: map[string]string{"If-Match": "*"},
+			body:    `{"consents":`,
+			setupMocks: func(*mockport.MockCustomerController) {
+			},
+			expectedStatus: 400,
+		},
+		{
+			name:           "should reject an invalid customer ID",
+			method:         "GET",
+			pathID:         "abc",
+			setupMocks:     func(*mockport.MockCustomerController) {},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockController := mockport.NewMockCustomerController(ctrl)
+			customerController = mockController
+			jsonPresenter = mockport.NewMockPresenter(ctrl)
+			tt.setupMocks(mockController)
+
+			resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				HTTPMethod:     tt.method,
+				Resource:       "/customers/{id}/consents",
+				PathParameters: map[string]string{"id": tt.pathID},
+				Headers:        tt.headers,
+				Body:           tt.body,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedETag, resp.Headers["ETag"])
+		})
+	}
+}
+
+func TestHandleRequest_Route(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockController := mockport.NewMockCustomerController(ctrl)
+	customerController = mockController
+	jsonPresenter = mockport.NewMockPresenter(ctrl)
+
+	// The presenter masks the customers by the route of the request
+	mockController.
+		EXPECT().
+		Get(gomock.Any(), jsonPresenter, dto.GetCustomerInput{ID: 123}).
+		DoAndReturn(func(ctx context.Context, _ port.Presenter, _ dto.GetCustomerInput) ([]byte, error) {
+			assert.Equal(t, "GET /customers/{id}", entity.RouteFromContext(ctx))
+			return []byte(`{"id":123}`), nil
+		})
+
+	resp, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod:     "GET",
+		Resource:       "/customers/{id}",
+		PathParameters: map[string]string{"id": "123"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
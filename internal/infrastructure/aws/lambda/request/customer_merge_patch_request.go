@@ -0,0 +1,62 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents (RFC 7396)
+const MergePatchContentType = "application/merge-patch+json"
+
+// CustomerMergePatchRequest is a JSON Merge Patch document changing some fields of a customer.
+// Members absent from the document keep their current value
+type CustomerMergePatchRequest map[string]json.RawMessage
+
+// NewCustomerMergePatchRequest parses a merge patch document, which must be a JSON object
+func NewCustomerMergePatchRequest(body []byte) (CustomerMergePatchRequest, error) {
+	var patch CustomerMergePatchRequest
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, domain.NewInvalidInputError("merge patch document must be a JSON object")
+	}
+	return patch, nil
+}
+
+func (c CustomerMergePatchRequest) ToUpdateCustomerInput() (dto.UpdateCustomerInput, error) {
+	var input dto.UpdateCustomerInput
+	var err error
+
+	for field, value := range c {
+		switch field {
+		case "name":
+			input.Name, err = patchString(field, value)
+		case "email":
+			input.Email, err = patchString(field, value)
+		case "cpf":
+			input.CPF, err = patchString(field, value)
+		default:
+			err = domain.NewInvalidInputError(fmt.Sprintf("field %s can't be patched", field))
+		}
+		if err != nil {
+			return dto.UpdateCustomerInput{}, err
+		}
+	}
+
+	return input, nil
+}
+
+// patchString reads a string member of the patch. A null member would remove the field,
+// which isn't allowed because every patchable field of a customer is mandatory
+func patchString(field string, value json.RawMessage) (*string, error) {
+	if string(value) == "null" {
+		return nil, domain.NewInvalidInputError(fmt.Sprintf("field %s can't be removed", field))
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, domain.NewInvalidInputError(fmt.Sprintf("field %s must be a string", field))
+	}
+	return &s, nil
+}
@@ -0,0 +1,72 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerMergePatchRequest_ToUpdateCustomerInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantName  *string
+		wantEmail *string
+		wantErr   string
+	}{
+		{
+			name:      "should only set the members present in the document",
+			body:      `{"email":"new@example.com"}`,
+			wantEmail: stringPtr("new@example.com"),
+		},
+		{
+			name:     "should accept an empty document",
+			body:     `{}`,
+			wantName: nil,
+		},
+		{
+			name:    "should reject null members",
+			body:    `{"name":null}`,
+			wantErr: "field name can't be removed",
+		},
+		{
+			name:    "should reject non string members",
+			body:    `{"name":42}`,
+			wantErr: "field name must be a string",
+		},
+		{
+			name:    "should reject read only members",
+			body:    `{"id":2}`,
+			wantErr: "field id can't be patched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := NewCustomerMergePatchRequest([]byte(tt.body))
+			require.NoError(t, err)
+
+			input, err := patch.ToUpdateCustomerInput()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, input.Name)
+			assert.Equal(t, tt.wantEmail, input.Email)
+			assert.Nil(t, input.CPF)
+		})
+	}
+}
+
+func TestNewCustomerMergePatchRequest_InvalidDocument(t *testing.T) {
+	for _, body := range []string{`null`, `"name"`, `[]`, `{`} {
+		_, err := NewCustomerMergePatchRequest([]byte(body))
+		assert.EqualError(t, err, "merge patch document must be a JSON object", body)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
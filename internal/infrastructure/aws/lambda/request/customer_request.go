@@ -28,11 +28,56 @@ func (c CustomerRequest) ToCreateCustomerInput() dto.CreateCustomerInput {
 	}
 }
 
+// ToUpdateCustomerInput maps a full replacement (PUT) of the customer, so absent fields are replaced by empty values
 func (c CustomerRequest) ToUpdateCustomerInput() dto.UpdateCustomerInput {
 	id, _ := strconv.Atoi(c.ID)
-	return dto.UpdateCustomerInput{
+	input := dto.UpdateCustomerInput{
 		ID:    id,
-		Name:  c.Name,
-		Email: c.Email,
+		Name:  &c.Name,
+		Email: &c.Email,
+	}
+	if c.CPF != "" {
+		input.CPF = &c.CPF
+	}
+	return input
+}
+
+// BatchGetCustomersRequest is the body of POST /customers:batchGet
+type BatchGetCustomersRequest struct {
+	IDs []int `json:"ids"`
+}
+
+func (r BatchGetCustomersRequest) ToBatchGetCustomersInput() dto.BatchGetCustomersInput {
+	return dto.BatchGetCustomersInput{IDs: r.IDs}
+}
+
+// CustomerStatusRequest is the body of PUT /customers/{id}/status
+type CustomerStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (r CustomerStatusRequest) ToChangeCustomerStatusInput() dto.ChangeCustomerStatusInput {
+	return dto.ChangeCustomerStatusInput{Status: r.Status, Reason: r.Reason}
+}
+
+// CustomerConsentsRequest is the body of PUT /customers/{id}/consents
+type CustomerConsentsRequest struct {
+	Consents []ConsentRequest `json:"consents"`
+}
+
+// ConsentRequest is a decision on a purpose. Granted is a pointer, so a missing decision is rejected
+type ConsentRequest struct {
+	Purpose       string `json:"purpose"`
+	Granted       *bool  `json:"granted"`
+	PolicyVersion string `json:"policy_version"`
+	Channel       string `json:"channel"`
+}
+
+func (r CustomerConsentsRequest) ToUpdateCustomerConsentsInput() dto.UpdateCustomerConsentsInput {
+	consents := make([]dto.ConsentInput, len(r.Consents))
+	for i, consent := range r.Consents {
+		consents[i] = dto.ConsentInput(consent)
 	}
+	return dto.UpdateCustomerConsentsInput{Consents: consents}
 }
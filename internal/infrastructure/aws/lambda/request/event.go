@@ -0,0 +1,50 @@
+package request
+
+import (
+	"encoding/json"
+)
+
+// EventType identifies the integration that delivered an event to the lambda
+type EventType string
+
+const (
+	EventTypeAPIGatewayProxy  EventType = "apigateway-rest-v1"
+	EventTypeAPIGatewayV2HTTP EventType = "apigateway-http-v2"
+	EventTypeALB              EventType = "alb"
+	EventTypeS3               EventType = "s3"
+)
+
+// s3EventSource is the event source of the records of an S3 object event
+const s3EventSource = "aws:s3"
+
+// eventProbe holds the fields that tell apart the supported event payloads
+type eventProbe struct {
+	Version        string `json:"version"`
+	RequestContext struct {
+		ELB  json.RawMessage `json:"elb"`
+		HTTP json.RawMessage `json:"http"`
+	} `json:"requestContext"`
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+}
+
+// DetectEventType inspects a raw lambda payload and returns which integration sent it.
+// Payloads that are neither S3, HTTP API v2 nor ALB events are treated as REST API v1 events
+func DetectEventType(payload []byte) (EventType, error) {
+	var probe eventProbe
+	if err := json.Unmarshal(payload, &probe); err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(probe.Records) > 0 && probe.Records[0].EventSource == s3EventSource:
+		return EventTypeS3, nil
+	case len(probe.RequestContext.ELB) > 0:
+		return EventTypeALB, nil
+	case probe.Version == "2.0" && len(probe.RequestContext.HTTP) > 0:
+		return EventTypeAPIGatewayV2HTTP, nil
+	default:
+		return EventTypeAPIGatewayProxy, nil
+	}
+}
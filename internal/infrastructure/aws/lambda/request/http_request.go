@@ -0,0 +1,287 @@
+package request
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+)
+
+// resources are the route templates served by the lambda. They are used to resolve the
+// resource and path parameters of events that don't carry them (ALB and HTTP API $default routes).
+// A parameter may be followed by a custom method, which must come before the bare parameter
+var resources = []string{
+	"/auth",
+	"/customers",
+	"/customers:batchGet",
+	"/customers/{id}:restore",
+	"/customers/{id}:anonymize",
+	"/customers/{id}",
+	"/customers/{id}/status",
+	"/customers/{id}/history",
+	"/customers/{id}/data-export",
+	"/customers/{id}/consents",
+}
+
+// HTTPRequest is the event agnostic representation of an HTTP request received by the lambda
+type HTTPRequest struct {
+	EventType             EventType
+	Method                string
+	Path                  string
+	Resource              string
+	PathParameters        map[string]string
+	QueryStringParameters map[string]string
+	Headers               map[string]string
+	Body                  string
+	IsBase64Encoded       bool
+	RequestID             string
+	MultiValueHeaders     bool
+}
+
+// Header returns the value of the header with the given name, ignoring its case
+func (r HTTPRequest) Header(name string) string {
+	return r.Headers[strings.ToLower(name)]
+}
+
+// Prefers reports whether the client asked for the given preference (RFC 7240), e.g. "return=representation"
+func (r HTTPRequest) Prefers(preference string) bool {
+	for _, value := range strings.Split(r.Header("Prefer"), ",") {
+		for _, token := range strings.Split(value, ";") {
+			if strings.EqualFold(strings.TrimSpace(token), preference) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IfMatch returns the customer version required by the If-Match header and whether the header was sent.
+// The wildcard "*" matches any version, so it is returned as a nil version. Customer ETags are strong
+// validators holding the version, e.g. "3"
+func (r HTTPRequest) IfMatch() (*int, bool, error) {
+	value := strings.TrimSpace(r.Header("If-Match"))
+	if value == "" {
+		return nil, false, nil
+	}
+	if value == "*" {
+		return nil, true, nil
+	}
+
+	invalid := domain.NewInvalidInputError(fmt.Sprintf("invalid If-Match header %q, it must be a customer ETag or *", value))
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return nil, true, invalid
+	}
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil {
+		return nil, true, invalid
+	}
+	return &version, true, nil
+}
+
+// BearerToken returns the token of the Authorization header, or an empty token when the header wasn't sent.
+// Credentials of other schemes are rejected
+func (r HTTPRequest) BearerToken() (string, error) {
+	value := strings.TrimSpace(r.Header("Authorization"))
+	if value == "" {
+		return "", nil
+	}
+
+	scheme, token, found := strings.Cut(value, " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", domain.NewUnauthorizedError(domain.ErrInvalidToken)
+	}
+	return token, nil
+}
+
+// DecodedBody returns the request body, decoding it when it is base64 encoded
+func (r HTTPRequest) DecodedBody() ([]byte, error) {
+	if r.IsBase64Encoded {
+		return base64.StdEncoding.DecodeString(r.Body)
+	}
+	return []byte(r.Body), nil
+}
+
+// FromAPIGatewayProxyRequest normalizes an API Gateway REST API (payload v1) event
+func FromAPIGatewayProxyRequest(req events.APIGatewayProxyRequest) HTTPRequest {
+	r := HTTPRequest{
+		EventType:             EventTypeAPIGatewayProxy,
+		Method:                req.HTTPMethod,
+		Path:                  req.Path,
+		Resource:              req.Resource,
+		PathParameters:        copyMap(req.PathParameters),
+		QueryStringParameters: mergeMultiValue(req.QueryStringParameters, req.MultiValueQueryStringParameters, false),
+		Headers:               lowerKeys(mergeMultiValue(req.Headers, req.MultiValueHeaders, true)),
+		Body:                  req.Body,
+		IsBase64Encoded:       req.IsBase64Encoded,
+		RequestID:             req.RequestContext.RequestID,
+	}
+	r.resolveResource()
+	return r
+}
+
+// FromAPIGatewayV2HTTPRequest normalizes an API Gateway HTTP API (payload v2) event
+func FromAPIGatewayV2HTTPRequest(req events.APIGatewayV2HTTPRequest) HTTPRequest {
+	// The raw path of named stages is prefixed with the stage name
+	path := req.RawPath
+	if stage := req.RequestContext.Stage; stage != "" && stage != "$default" {
+		path = strings.TrimPrefix(path, "/"+stage)
+	}
+
+	r := HTTPRequest{
+		EventType:             EventTypeAPIGatewayV2HTTP,
+		Method:                req.RequestContext.HTTP.Method,
+		Path:                  path,
+		PathParameters:        copyMap(req.PathParameters),
+		QueryStringParameters: copyMap(req.QueryStringParameters),
+		Headers:               lowerKeys(req.Headers),
+		Body:                  req.Body,
+		IsBase64Encoded:       req.IsBase64Encoded,
+		RequestID:             req.RequestContext.RequestID,
+	}
+
+	// The route key has the format "METHOD /resource", or "$default" for the catch-all route
+	if _, resource, found := strings.Cut(req.RouteKey, " "); found {
+		r.Resource = resource
+	}
+	r.resolveResource()
+	return r
+}
+
+// FromALBTargetGroupRequest normalizes an Application Load Balancer target group event
+func FromALBTargetGroupRequest(req events.ALBTargetGroupRequest) HTTPRequest {
+	multiValue := len(req.MultiValueHeaders) > 0 || len(req.MultiValueQueryStringParameters) > 0
+
+	// ALB forwards query string parameters as they were sent by the client, without decoding them
+	query := make(map[string]string)
+	for key, value := range mergeMultiValue(req.QueryStringParameters, req.MultiValueQueryStringParameters, false) {
+		query[unescape(key)] = unescape(value)
+	}
+
+	headers := lowerKeys(mergeMultiValue(req.Headers, req.MultiValueHeaders, true))
+	r := HTTPRequest{
+		EventType:             EventTypeALB,
+		Method:                req.HTTPMethod,
+		Path:                  req.Path,
+		PathParameters:        make(map[string]string),
+		QueryStringParameters: query,
+		Headers:               headers,
+		Body:                  req.Body,
+		IsBase64Encoded:       req.IsBase64Encoded,
+		RequestID:             headers["x-amzn-trace-id"],
+		MultiValueHeaders:     multiValue,
+	}
+	r.resolveResource()
+	return r
+}
+
+// resolveResource fills the resource and path parameters from the request path when the event didn't provide them
+func (r *HTTPRequest) resolveResource() {
+	if r.PathParameters == nil {
+		r.PathParameters = make(map[string]string)
+	}
+	if r.Resource != "" || r.Path == "" {
+		return
+	}
+
+	resource, params, ok := matchResource(r.Path)
+	if !ok {
+		return
+	}
+	r.Resource = resource
+	for key, value := range params {
+		if _, exists := r.PathParameters[key]; !exists {
+			r.PathParameters[key] = value
+		}
+	}
+}
+
+// matchResource finds the route template matching the path and extracts its parameters
+func matchResource(path string) (string, map[string]string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, resource := range resources {
+		templateSegments := strings.Split(strings.Trim(resource, "/"), "/")
+		if len(templateSegments) != len(segments) {
+			continue
+		}
+
+		params := make(map[string]string)
+		matched := true
+		for i, segment := range templateSegments {
+			if strings.HasPrefix(segment, "{") {
+				name, method, _ := strings.Cut(segment[1:], "}")
+				value, hasMethod := strings.CutSuffix(segments[i], method)
+				if !hasMethod || value == "" {
+					matched = false
+					break
+				}
+				params[name] = unescapePath(value)
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return resource, params, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// mergeMultiValue combines single and multi value maps. Multi value entries are joined with a comma
+// for headers, while for query string parameters only the first value is kept
+func mergeMultiValue(single map[string]string, multi map[string][]string, join bool) map[string]string {
+	merged := copyMap(single)
+	for key, values := range multi {
+		if len(values) == 0 {
+			continue
+		}
+		if join {
+			merged[key] = strings.Join(values, ",")
+			continue
+		}
+		if _, exists := merged[key]; !exists {
+			merged[key] = values[0]
+		}
+	}
+	return merged
+}
+
+func lowerKeys(m map[string]string) map[string]string {
+	lowered := make(map[string]string, len(m))
+	for key, value := range m {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
+
+func copyMap(m map[string]string) map[string]string {
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+func unescape(value string) string {
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
+
+func unescapePath(value string) string {
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
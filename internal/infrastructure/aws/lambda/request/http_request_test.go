@@ -0,0 +1,348 @@
+package request
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+)
+
+func TestDetectEventType(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    EventType
+		wantErr bool
+	}{
+		{
+			name:    "should detect REST API v1 events",
+			payload: `{"resource":"/customers","httpMethod":"GET","requestContext":{"requestId":"abc"}}`,
+			want:    EventTypeAPIGatewayProxy,
+		},
+		{
+			name:    "should detect HTTP API v2 events",
+			payload: `{"version":"2.0","routeKey":"GET /customers","requestContext":{"http":{"method":"GET"}}}`,
+			want:    EventTypeAPIGatewayV2HTTP,
+		},
+		{
+			name:    "should detect ALB events",
+			payload: `{"httpMethod":"GET","path":"/customers","requestContext":{"elb":{"targetGroupArn":"arn"}}}`,
+			want:    EventTypeALB,
+		},
+		{
+			name:    "should detect S3 object events",
+			payload: `{"Records":[{"eventSource":"aws:s3","s3":{"bucket":{"name":"imports"},"object":{"key":"customers.csv"}}}]}`,
+			want:    EventTypeS3,
+		},
+		{
+			name:    "should fail on invalid payloads",
+			payload: `[`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectEventType([]byte(tt.payload))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromAPIGatewayProxyRequest(t *testing.T) {
+	req := FromAPIGatewayProxyRequest(events.APIGatewayProxyRequest{
+		HTTPMethod:        "POST",
+		Resource:          "/customers",
+		Path:              "/customers",
+		Headers:           map[string]string{"Content-Type": "application/json"},
+		MultiValueHeaders: map[string][]string{"Accept": {"application/json", "text/plain"}},
+		Body:              base64.StdEncoding.EncodeToString([]byte(`{"cpf":"123"}`)),
+		IsBase64Encoded:   true,
+		RequestContext:    events.APIGatewayProxyRequestContext{RequestID: "req-1"},
+	})
+
+	assert.Equal(t, EventTypeAPIGatewayProxy, req.EventType)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "/customers", req.Resource)
+	assert.Equal(t, "application/json", req.Header("content-type"))
+	assert.Equal(t, "application/json,text/plain", req.Header("Accept"))
+	assert.Equal(t, "req-1", req.RequestID)
+
+	body, err := req.DecodedBody()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cpf":"123"}`, string(body))
+}
+
+func TestFromAPIGatewayV2HTTPRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      events.APIGatewayV2HTTPRequest
+		resource   string
+		customerID string
+	}{
+		{
+			name: "should use the route key and path parameters",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey:       "PUT /customers/{id}",
+				RawPath:        "/customers/7",
+				PathParameters: map[string]string{"id": "7"},
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "PUT"},
+				},
+			},
+			resource:   "/customers/{id}",
+			customerID: "7",
+		},
+		{
+			name: "should resolve the resource of the default route from a staged path",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/prod/customers/42",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "prod",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "GET"},
+				},
+			},
+			resource:   "/customers/{id}",
+			customerID: "42",
+		},
+		{
+			name: "should resolve the custom method of a customer",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/customers/42:restore",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "POST"},
+				},
+			},
+			resource:   "/customers/{id}:restore",
+			customerID: "42",
+		},
+		{
+			name: "should resolve the sub-resource of a customer",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/customers/42/status",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "PUT"},
+				},
+			},
+			resource:   "/customers/{id}/status",
+			customerID: "42",
+		},
+		{
+			name: "should resolve the history of a customer",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/customers/42/history",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "GET"},
+				},
+			},
+			resource:   "/customers/{id}/history",
+			customerID: "42",
+		},
+		{
+			name: "should resolve the data export of a customer",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/customers/42/data-export",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "GET"},
+				},
+			},
+			resource:   "/customers/{id}/data-export",
+			customerID: "42",
+		},
+		{
+			name: "should resolve the anonymization of a customer",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/customers/42:anonymize",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "POST"},
+				},
+			},
+			resource:   "/customers/{id}:anonymize",
+			customerID: "42",
+		},
+		{
+			name: "should resolve the consents of a customer",
+			event: events.APIGatewayV2HTTPRequest{
+				RouteKey: "$default",
+				RawPath:  "/customers/42/consents",
+				RequestContext: events.APIGatewayV2HTTPRequestContext{
+					Stage: "$default",
+					HTTP:  events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "PUT"},
+				},
+			},
+			resource:   "/customers/{id}/consents",
+			customerID: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := FromAPIGatewayV2HTTPRequest(tt.event)
+			assert.Equal(t, EventTypeAPIGatewayV2HTTP, req.EventType)
+			assert.Equal(t, tt.event.RequestContext.HTTP.Method, req.Method)
+			assert.Equal(t, tt.resource, req.Resource)
+			assert.Equal(t, tt.customerID, req.PathParameters["id"])
+		})
+	}
+}
+
+func TestFromALBTargetGroupRequest(t *testing.T) {
+	req := FromALBTargetGroupRequest(events.ALBTargetGroupRequest{
+		HTTPMethod:            "GET",
+		Path:                  "/customers",
+		QueryStringParameters: map[string]string{"name": "Jos%C3%A9+Silva"},
+		Headers:               map[string]string{"X-Amzn-Trace-Id": "Root=1-abc"},
+	})
+
+	assert.Equal(t, EventTypeALB, req.EventType)
+	assert.Equal(t, "/customers", req.Resource)
+	assert.NotContains(t, req.PathParameters, "id")
+	assert.Equal(t, "José Silva", req.QueryStringParameters["name"])
+	assert.Equal(t, "Root=1-abc", req.RequestID)
+	assert.False(t, req.MultiValueHeaders)
+}
+
+func TestHTTPRequest_Prefers(t *testing.T) {
+	req := HTTPRequest{Headers: map[string]string{"prefer": "respond-async, Return=Representation; wait=10"}}
+
+	assert.True(t, req.Prefers("return=representation"))
+	assert.True(t, req.Prefers("respond-async"))
+	assert.False(t, req.Prefers("return=minimal"))
+	assert.False(t, HTTPRequest{}.Prefers("return=representation"))
+}
+
+func TestHTTPRequest_BearerToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		wantToken     string
+		wantErr       bool
+	}{
+		{
+			name: "should report a missing header",
+		},
+		{
+			name:          "should read the bearer token",
+			authorization: "Bearer abc.def.ghi",
+			wantToken:     "abc.def.ghi",
+		},
+		{
+			name:          "should ignore the case of the scheme",
+			authorization: "bearer abc.def.ghi",
+			wantToken:     "abc.def.ghi",
+		},
+		{
+			name:          "should reject other schemes",
+			authorization: "Basic dXNlcjpwYXNz",
+			wantErr:       true,
+		},
+		{
+			name:          "should reject a scheme without token",
+			authorization: "Bearer",
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := HTTPRequest{Headers: map[string]string{"authorization": tt.authorization}}
+
+			token, err := req.BearerToken()
+
+			if tt.wantErr {
+				var unauthorized *domain.UnauthorizedError
+				assert.ErrorAs(t, err, &unauthorized)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantToken, token)
+		})
+	}
+}
+
+func TestHTTPRequest_IfMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifMatch     string
+		wantVersion *int
+		wantPresent bool
+		wantErr     bool
+	}{
+		{
+			name: "should report a missing header",
+		},
+		{
+			name:        "should match any version with the wildcard",
+			ifMatch:     "*",
+			wantPresent: true,
+		},
+		{
+			name:        "should parse the version of a customer ETag",
+			ifMatch:     `"3"`,
+			wantVersion: intPtr(3),
+			wantPresent: true,
+		},
+		{
+			name:        "should reject weak ETags",
+			ifMatch:     `W/"3"`,
+			wantPresent: true,
+			wantErr:     true,
+		},
+		{
+			name:        "should reject unquoted values",
+			ifMatch:     "3",
+			wantPresent: true,
+			wantErr:     true,
+		},
+		{
+			name:        "should reject ETags that aren't versions",
+			ifMatch:     `"abc"`,
+			wantPresent: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := HTTPRequest{Headers: map[string]string{}}
+			if tt.ifMatch != "" {
+				req.Headers["if-match"] = tt.ifMatch
+			}
+
+			version, present, err := req.IfMatch()
+
+			assert.Equal(t, tt.wantPresent, present)
+			if tt.wantErr {
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
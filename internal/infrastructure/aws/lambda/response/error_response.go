@@ -3,10 +3,9 @@ package response
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 
-	"github.com/aws/aws-lambda-go/events"
-
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
 )
 
@@ -24,26 +23,46 @@ func NewErrorResponse(title, status, message string) errorResponse {
 	}
 }
 
-func NewAPIGatewayProxyResponseError(err error) events.APIGatewayProxyResponse {
+func NewHTTPResponseError(err error) HTTPResponse {
 	var title string
 	var status int
 	var internal *domain.InternalError
 	var validation *domain.ValidationError
 	var notfound *domain.NotFoundError
 	var invalidInput *domain.InvalidInputError
+	var preconditionFailed *domain.PreconditionFailedError
+	var conflict *domain.ConflictError
+	var unavailable *domain.ServiceUnavailableError
+	var unauthorized *domain.UnauthorizedError
+	var forbidden *domain.ForbiddenError
 	switch {
 	case errors.As(err, &internal):
 		title = internal.Message
 		status = http.StatusInternalServerError
 	case errors.As(err, &validation):
 		title = validation.Message
-		status = http.StatusPreconditionFailed
+		status = http.StatusUnprocessableEntity
 	case errors.As(err, &notfound):
 		title = notfound.Message
 		status = http.StatusNotFound
 	case errors.As(err, &invalidInput):
 		title = invalidInput.Message
 		status = http.StatusBadRequest
+	case errors.As(err, &preconditionFailed):
+		title = domain.ErrPreconditionFailed
+		status = http.StatusPreconditionFailed
+	case errors.As(err, &conflict):
+		title = domain.ErrConflict
+		status = http.StatusConflict
+	case errors.As(err, &unavailable):
+		title = unavailable.Message
+		status = http.StatusServiceUnavailable
+	case errors.As(err, &unauthorized):
+		title = http.StatusText(http.StatusUnauthorized)
+		status = http.StatusUnauthorized
+	case errors.As(err, &forbidden):
+		title = http.StatusText(http.StatusForbidden)
+		status = http.StatusForbidden
 	default:
 		title = "Unknown error"
 		status = http.StatusInternalServerError
@@ -51,8 +70,26 @@ func NewAPIGatewayProxyResponseError(err error) events.APIGatewayProxyResponse {
 	}
 	errorResponse := NewErrorResponse(title, http.StatusText(status), err.Error())
 	jsn, _ := json.Marshal(errorResponse)
-	return events.APIGatewayProxyResponse{
-		StatusCode: status,
-		Body:       string(jsn),
+	if status == http.StatusUnauthorized {
+		return NewHTTPResponse(status, jsn).WithHeader("WWW-Authenticate", "Bearer")
 	}
+	return NewHTTPResponse(status, jsn)
+}
+
+// NewUnsupportedMediaTypeResponse answers requests whose body has a media type the resource doesn't accept
+func NewUnsupportedMediaTypeResponse(contentType, acceptedContentType string) HTTPResponse {
+	status := http.StatusUnsupportedMediaType
+	message := fmt.Sprintf("content type %q is not supported, use %q", contentType, acceptedContentType)
+	errorResponse := NewErrorResponse(http.StatusText(status), http.StatusText(status), message)
+	jsn, _ := json.Marshal(errorResponse)
+	return NewHTTPResponse(status, jsn).WithHeader("Accept-Patch", acceptedContentType)
+}
+
+// NewPreconditionRequiredResponse answers conditional requests sent without an If-Match header
+func NewPreconditionRequiredResponse() HTTPResponse {
+	status := http.StatusPreconditionRequired
+	message := "the If-Match header is required, send the customer ETag or *"
+	errorResponse := NewErrorResponse(http.StatusText(status), http.StatusText(status), message)
+	jsn, _ := json.Marshal(errorResponse)
+	return NewHTTPResponse(status, jsn)
 }
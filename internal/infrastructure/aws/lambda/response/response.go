@@ -1,16 +1,94 @@
 package response
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/aws/aws-lambda-go/events"
 )
 
-func NewAPIGatewayProxyResponse(data []byte) events.APIGatewayProxyResponse {
-	return events.APIGatewayProxyResponse{
-		StatusCode:      http.StatusOK,
+// HTTPResponse is the event agnostic representation of the response returned by the lambda.
+// It is converted to the response type expected by the integration that invoked the lambda
+type HTTPResponse struct {
+	StatusCode      int
+	Headers         map[string]string
+	Body            string
+	IsBase64Encoded bool
+}
+
+// NewHTTPResponse creates a JSON response with the status code chosen by the handler
+func NewHTTPResponse(statusCode int, data []byte) HTTPResponse {
+	return HTTPResponse{
+		StatusCode:      statusCode,
 		Body:            string(data),
 		Headers:         map[string]string{"Content-Type": "application/json"},
 		IsBase64Encoded: false,
 	}
 }
+
+// NewNoContentResponse creates a 204 response, which must not have a body
+func NewNoContentResponse() HTTPResponse {
+	return HTTPResponse{
+		StatusCode: http.StatusNoContent,
+		Headers:    map[string]string{},
+	}
+}
+
+// WithHeader returns a copy of the response with the header set
+func (r HTTPResponse) WithHeader(key, value string) HTTPResponse {
+	headers := make(map[string]string, len(r.Headers)+1)
+	for k, v := range r.Headers {
+		headers[k] = v
+	}
+	headers[key] = value
+	r.Headers = headers
+	return r
+}
+
+// ETag formats a customer version as a strong entity tag, e.g. "3"
+func ETag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
+
+// ToAPIGatewayProxyResponse converts the response to an API Gateway REST API (payload v1) response
+func (r HTTPResponse) ToAPIGatewayProxyResponse() events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode:      r.StatusCode,
+		Headers:         r.Headers,
+		Body:            r.Body,
+		IsBase64Encoded: r.IsBase64Encoded,
+	}
+}
+
+// ToAPIGatewayV2HTTPResponse converts the response to an API Gateway HTTP API (payload v2) response
+func (r HTTPResponse) ToAPIGatewayV2HTTPResponse() events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:      r.StatusCode,
+		Headers:         r.Headers,
+		Body:            r.Body,
+		IsBase64Encoded: r.IsBase64Encoded,
+	}
+}
+
+// ToALBTargetGroupResponse converts the response to an ALB target group response. When the target group
+// has multi value headers enabled, ALB ignores the single value headers, so they must be sent as multi value
+func (r HTTPResponse) ToALBTargetGroupResponse(multiValueHeaders bool) events.ALBTargetGroupResponse {
+	resp := events.ALBTargetGroupResponse{
+		StatusCode:        r.StatusCode,
+		StatusDescription: fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		Body:              r.Body,
+		IsBase64Encoded:   r.IsBase64Encoded,
+	}
+
+	if !multiValueHeaders {
+		resp.Headers = r.Headers
+		return resp
+	}
+
+	resp.MultiValueHeaders = make(map[string][]string, len(r.Headers))
+	for key, value := range r.Headers {
+		resp.MultiValueHeaders[key] = []string{value}
+	}
+	return resp
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+type lruEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// lruCache keeps the most recently used entries in the process memory. In a Lambda it survives
+// between the invocations of a warm instance, but every instance has its own entries
+type lruCache struct {
+	entries *lru.Cache[string, lruEntry]
+	now     func() time.Time
+}
+
+func NewLRUCache(size int) (port.Cache, error) {
+	entries, err := lru.New[string, lruEntry](size)
+	if err != nil {
+		return nil, err
+	}
+	return &lruCache{entries: entries, now: time.Now}, nil
+}
+
+func (c *lruCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if !c.now().Before(entry.expiresAt) {
+		c.entries.Remove(key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (c *lruCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.entries.Add(key, lruEntry{value: value, expiresAt: c.now().Add(ttl)})
+	return nil
+}
+
+func (c *lruCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.entries.Remove(key)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	c, err := NewLRUCache(2)
+	require.NoError(t, err)
+	lru := c.(*lruCache)
+	lru.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	value, found, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+
+	// Expired entries aren't returned
+	lru.now = func() time.Time { return now.Add(time.Minute) }
+	_, found, _ = c.Get(ctx, "a")
+	assert.False(t, found)
+
+	// The least recently used entry is evicted
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+	_, _, _ = c.Get(ctx, "b")
+	require.NoError(t, c.Set(ctx, "d", []byte("4"), time.Minute))
+	_, found, _ = c.Get(ctx, "c")
+	assert.False(t, found)
+	_, found, _ = c.Get(ctx, "b")
+	assert.True(t, found)
+
+	require.NoError(t, c.Delete(ctx, "b", "missing"))
+	_, found, _ = c.Get(ctx, "b")
+	assert.False(t, found)
+}
+
+func TestNewLRUCache_InvalidSize(t *testing.T) {
+	_, err := NewLRUCache(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// redisCache keeps the entries in a server speaking the Redis protocol (Redis, Valkey, ElastiCache),
+// shared by every Lambda instance. Keys are prefixed, so several services can share the server
+type redisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+func NewRedisCache(client redis.UniversalClient, prefix string) port.Cache {
+	return &redisCache{client: client, prefix: prefix}
+}
+
+// NewRedisClient connects to the server of a redis:// or rediss:// URL
+func NewRedisClient(url string) (redis.UniversalClient, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(options), nil
+}
+
+func (c *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+func (c *redisCache) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
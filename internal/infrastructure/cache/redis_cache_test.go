@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+
+	client, err := NewRedisClient("redis://" + server.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+	c := NewRedisCache(client, "tc4:")
+
+	_, found, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	value, found, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("1"), value)
+	assert.True(t, server.Exists("tc4:a"), "keys must be prefixed")
+	assert.Equal(t, time.Minute, server.TTL("tc4:a"))
+
+	server.FastForward(time.Minute)
+	_, found, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+	require.NoError(t, c.Delete(ctx, "b", "missing"))
+	_, found, err = c.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRedisCache_ServerDown(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := NewRedisClient("redis://" + server.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+	server.Close()
+
+	_, _, err = NewRedisCache(client, "").Get(context.Background(), "a")
+	assert.Error(t, err)
+}
+
+func TestNewRedisClient_InvalidURL(t *testing.T) {
+	_, err := NewRedisClient("http://localhost")
+	assert.Error(t, err)
+}
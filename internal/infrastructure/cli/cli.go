@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// command is a maintenance task run from the command line instead of the lambda handler
+type command struct {
+	description string
+	run         func(ctx context.Context, flags *flag.FlagSet, args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"ensure-tables": {
+		description: "Create or verify the DynamoDB tables and indexes",
+		run:         runEnsureTables,
+	},
+	"export": {
+		description: "Export every customer to a CSV, NDJSON or Parquet file",
+		run:         runExport,
+	},
+	"import": {
+		description: "Import customers from a CSV or NDJSON file",
+		run:         runImport,
+	},
+	"migrate": {
+		description: "Apply the pending migrations of the DynamoDB customer items",
+		run:         runMigrate,
+	},
+	"purge-deleted": {
+		description: "Purge the PostgreSQL customers deleted longer than the retention ago and the expired idempotency keys",
+		run:         runPurgeDeleted,
+	},
+	"token": {
+		description: "Issue an access token, like the admin tokens of the operators",
+		run:         runToken,
+	},
+}
+
+// Run executes the command named by the first argument and returns the process exit code
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	err := cmd.run(ctx, flags, args[1:], stdout)
+	switch {
+	case err == flag.ErrHelp:
+		return 0
+	case err != nil:
+		fmt.Fprintf(stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: bootstrap <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].description)
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/service"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantOutput string
+	}{
+		{name: "should print the usage without a command", args: nil, wantCode: 2, wantOutput: "ensure-tables"},
+		{name: "should reject an unknown command", args: []string{"unknown"}, wantCode: 2, wantOutput: `unknown command "unknown"`},
+		{name: "should print the flags of a command", args: []string{"ensure-tables", "-h"}, wantCode: 0, wantOutput: "-endpoint"},
+		{name: "should reject an unknown flag", args: []string{"ensure-tables", "-unknown"}, wantCode: 1, wantOutput: "flag provided but not defined"},
+		{name: "should print the flags of migrate", args: []string{"migrate", "-h"}, wantCode: 0, wantOutput: "-dry-run"},
+		{name: "should print the flags of import", args: []string{"import", "-h"}, wantCode: 0, wantOutput: "-mode"},
+		{name: "should require the file to import", args: []string{"import"}, wantCode: 1, wantOutput: "the file to import is required"},
+		{name: "should print the flags of export", args: []string{"export", "-h"}, wantCode: 0, wantOutput: "-mask-pii"},
+		{name: "should require the export destination", args: []string{"export"}, wantCode: 1, wantOutput: "the export destination is required"},
+		{name: "should print the flags of purge-deleted", args: []string{"purge-deleted", "-h"}, wantCode: 0, wantOutput: "-timeout"},
+		{name: "should require the token subject", args: []string{"token"}, wantCode: 1, wantOutput: "the token subject is required"},
+		{name: "should reject an unknown role", args: []string{"token", "-subject", "ops", "-role", "root"}, wantCode: 1, wantOutput: `unknown role "root"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := Run(context.Background(), tt.args, &stdout, &stderr)
+
+			assert.Equal(t, tt.wantCode, code)
+			assert.Contains(t, stderr.String(), tt.wantOutput)
+		})
+	}
+}
+
+func TestRun_PurgeDeleted(t *testing.T) {
+	t.Setenv("DATASOURCE", config.DataSourceDynamoDB)
+	var stdout, stderr bytes.Buffer
+
+	code := Run(context.Background(), []string{"purge-deleted"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), `only "postgres" needs this command`)
+}
+
+func TestRun_Token(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := Run(context.Background(), []string{"token", "-subject", "ops@fastfood.com"}, &stdout, &stderr)
+
+	assert.Equal(t, 0, code, stderr.String())
+	principal, err := service.NewJWTService(config.LoadConfig()).ValidateToken(strings.TrimSpace(stdout.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.Principal{Subject: "ops@fastfood.com", Role: entity.RoleAdmin}, principal)
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// runEnsureTables provisions the customers, uniqueness, idempotency and audit tables named by the configuration
+func runEnsureTables(ctx context.Context, flags *flag.FlagSet, args []string, _ io.Writer) error {
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local")
+	timeout := flags.Duration("timeout", 5*time.Minute, "how long to wait for the tables to be active")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	db, err := newDynamoConnection(cfg, *endpoint)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	for _, schema := range []database.TableSchema{
+		database.CustomersTableSchema(cfg.DynamoTableName),
+		database.UniquenessTableSchema(cfg.UniquenessTableName),
+		database.IdempotencyTableSchema(cfg.IdempotencyTableName),
+		database.AuditTableSchema(cfg.AuditTableName),
+	} {
+		if err := db.EnsureTable(ctx, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newDynamoConnection connects to the DynamoDB of the AWS account, or to the given endpoint with dummy credentials
+func newDynamoConnection(cfg *config.Config, endpoint string) (*database.DynamoDatabase, error) {
+	l := logger.NewLogger(cfg)
+	if endpoint != "" {
+		return database.NewDynamoTestConnection(cfg, l, endpoint)
+	}
+	return database.NewDynamoConnection(cfg, l)
+}
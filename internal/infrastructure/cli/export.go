@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/exporter"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/objectstore"
+)
+
+// objectURLScheme prefixes the export destinations stored in the object store instead of a local file
+const objectURLScheme = "s3://"
+
+// runExport writes every customer of the data source to a local file or an object, in CSV, NDJSON
+// or Parquet
+func runExport(ctx context.Context, flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local, with DATASOURCE=dynamodb")
+	format := flags.String("format", "", "file format, csv, ndjson or parquet, detected from the file extension by default")
+	fields := flags.String("fields", "", "comma separated fields to export, every field by default")
+	maskPII := flags.Bool("mask-pii", false, "mask the name, email and CPF of the customers")
+	segments := flags.Int("segments", dto.DefaultExportSegments, "parallel scans of the customers, where the data source supports them")
+	timeout := flags.Duration("timeout", time.Hour, "how long the export may run")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bootstrap export [flags] <file or s3://bucket/key>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("the export destination is required")
+	}
+
+	cfg := config.LoadConfig()
+	l := logger.NewLogger(cfg)
+	dataSources, err := datasource.NewDataSources(ctx, cfg, l, *endpoint)
+	if err != nil {
+		return err
+	}
+	customerController := controller.NewCustomerController(
+		usecase.NewCustomerUseCase(gateway.NewCustomerGateway(dataSources.Customer),
+			gateway.NewAuditGateway(dataSources.Audit), eventpublisher.NewLogEventPublisher(l)))
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	input := dto.ExportCustomersInput{Fields: exportFields(*fields), MaskPII: *maskPII, Segments: *segments}
+	summary, err := exportTo(ctx, customerController, objectstore.NewFileObjectStore(cfg.ExportObjectRoot),
+		flags.Arg(0), exporter.Format(*format), input)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%d customers exported to %s\n", summary.Count, flags.Arg(0))
+	return nil
+}
+
+// exportTo exports the customers to a local file or, for s3://bucket/key destinations, an object of the
+// store. The destination is only written when the export succeeds. Without a format, it's detected from
+// the destination extension
+func exportTo(ctx context.Context, customerController port.CustomerController, store port.ObjectStore, destination string, format exporter.Format, input dto.ExportCustomersInput) (*presenter.CustomerJsonExportResponse, error) {
+	if format == "" {
+		detected, err := exporter.FormatOf(destination)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	output, err := createDestination(ctx, store, destination)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := exporter.NewWriter(output, format)
+	if err != nil {
+		_ = output.Abort()
+		return nil, err
+	}
+
+	body, err := customerController.Export(ctx, presenter.NewCustomerJsonPresenter(), input, writer)
+	if err != nil {
+		_ = output.Abort()
+		return nil, err
+	}
+	if err := output.Close(); err != nil {
+		return nil, err
+	}
+
+	var summary presenter.CustomerJsonExportResponse
+	if err := json.Unmarshal(body, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+func createDestination(ctx context.Context, store port.ObjectStore, destination string) (port.ObjectWriter, error) {
+	object, ok := strings.CutPrefix(destination, objectURLScheme)
+	if !ok {
+		return objectstore.CreateFile(destination)
+	}
+
+	bucket, key, _ := strings.Cut(object, "/")
+	return store.Create(ctx, bucket, key)
+}
+
+// exportFields splits the -fields flag
+func exportFields(value string) []dto.ExportField {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	names := strings.Split(value, ",")
+	fields := make([]dto.ExportField, len(names))
+	for i, name := range names {
+		fields[i] = dto.ExportField(strings.TrimSpace(name))
+	}
+	return fields
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/objectstore"
+)
+
+func newExportController(t *testing.T) port.CustomerController {
+	ctx := context.Background()
+	dataSource := datasource.NewCustomerMemoryDataSource()
+	require.NoError(t, dataSource.Create(ctx, &entity.Customer{Name: "Maria Silva", Email: "maria@example.com", CPF: "11122233344"}))
+	require.NoError(t, dataSource.Create(ctx, &entity.Customer{Name: "John Doe", Email: "john@example.com", CPF: "12345678900"}))
+	return controller.NewCustomerController(usecase.NewCustomerUseCase(gateway.NewCustomerGateway(dataSource),
+		gateway.NewAuditGateway(datasource.NewAuditMemoryDataSource()),
+		eventpublisher.NewLogEventPublisher(logger.NewLogger(&config.Config{Environment: "test"}))))
+}
+
+func TestExportTo(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := objectstore.NewFileObjectStore(root)
+
+	t.Run("should export to a local file", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "customers.csv")
+		input := dto.ExportCustomersInput{Fields: exportFields("id, cpf"), MaskPII: true}
+
+		summary, err := exportTo(ctx, newExportController(t), store, name, "", input)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Count)
+		assert.Equal(t, []string{"id", "cpf"}, summary.Fields)
+		content, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, "id,cpf\n1,***.222.333-**\n2,***.456.789-**\n", string(content))
+	})
+
+	t.Run("should export to an object of the store", func(t *testing.T) {
+		summary, err := exportTo(ctx, newExportController(t), store, "s3://exports/2026/customers.ndjson", "", dto.ExportCustomersInput{})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Count)
+		content, err := os.ReadFile(filepath.Join(root, "exports", "2026", "customers.ndjson"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `"email":"maria@example.com"`)
+	})
+
+	t.Run("should not write the destination when the export fails", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "customers.parquet")
+		input := dto.ExportCustomersInput{Fields: exportFields("id,password")}
+
+		_, err := exportTo(ctx, newExportController(t), store, name, "", input)
+
+		assert.Error(t, err)
+		files, err := os.ReadDir(filepath.Dir(name))
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("should reject unknown formats", func(t *testing.T) {
+		_, err := exportTo(ctx, nil, store, "customers.xlsx", "", dto.ExportCustomersInput{})
+
+		assert.Error(t, err)
+	})
+}
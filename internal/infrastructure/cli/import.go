@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/importer"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// runImport imports the customers of a CSV or NDJSON file into the data source and writes the
+// JSON report of the rows. It fails when a row failed, after importing the others
+func runImport(ctx context.Context, flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local, with DATASOURCE=dynamodb")
+	mode := flags.String("mode", string(dto.ImportModeSkip), "what to do with the rows of an existing CPF: skip or upsert")
+	format := flags.String("format", "", "file format, csv or ndjson, detected from the file extension by default")
+	reportPath := flags.String("report", "", "file to write the JSON report to, instead of the standard output")
+	timeout := flags.Duration("timeout", time.Hour, "how long the import may run")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: bootstrap import [flags] <file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("the file to import is required")
+	}
+
+	cfg := config.LoadConfig()
+	l := logger.NewLogger(cfg)
+	dataSources, err := datasource.NewDataSources(ctx, cfg, l, *endpoint)
+	if err != nil {
+		return err
+	}
+	customerController := controller.NewCustomerController(
+		usecase.NewCustomerUseCase(gateway.NewCustomerGateway(dataSources.Customer),
+			gateway.NewAuditGateway(dataSources.Audit), eventpublisher.NewLogEventPublisher(l)))
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	report := stdout
+	if *reportPath != "" {
+		file, err := os.Create(*reportPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		report = file
+	}
+
+	summary, err := importFile(ctx, customerController, flags.Arg(0), importer.Format(*format), dto.ImportMode(*mode), report)
+	if err != nil {
+		return err
+	}
+	if *reportPath != "" {
+		fmt.Fprintf(stdout, "%d rows: %d created, %d updated, %d skipped, %d failed\n",
+			summary.Total, summary.Created, summary.Updated, summary.Skipped, summary.Failed)
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed, see the report", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+// importFile imports a file with the controller and writes its report. Without a format, it's detected
+// from the file extension
+func importFile(ctx context.Context, customerController port.CustomerController, name string, format importer.Format, mode dto.ImportMode, report io.Writer) (*presenter.CustomerJsonImportReportResponse, error) {
+	if format == "" {
+		detected, err := importer.FormatOf(name)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := importer.ReadRows(file, format)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := customerController.Import(ctx, presenter.NewCustomerJsonPresenter(), dto.ImportCustomersInput{Rows: rows, Mode: mode})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintln(report, string(body)); err != nil {
+		return nil, err
+	}
+
+	var summary presenter.CustomerJsonImportReportResponse
+	if err := json.Unmarshal(body, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+func TestImportFile(t *testing.T) {
+	ctx := context.Background()
+	dataSource := datasource.NewCustomerMemoryDataSource()
+	require.NoError(t, dataSource.Create(ctx, &entity.Customer{Name: "Maria", Email: "maria@example.com", CPF: "11122233396"}))
+	customerController := controller.NewCustomerController(usecase.NewCustomerUseCase(gateway.NewCustomerGateway(dataSource),
+		gateway.NewAuditGateway(datasource.NewAuditMemoryDataSource()),
+		eventpublisher.NewLogEventPublisher(logger.NewLogger(&config.Config{Environment: "test"}))))
+
+	name := filepath.Join(t.TempDir(), "customers.csv")
+	require.NoError(t, os.WriteFile(name, []byte("name,email,cpf\n"+
+		"John Doe,john@example.com,12345678909\n"+
+		"Maria Silva,maria.silva@example.com,11122233396\n"+
+		"No Email,,98765432100\n"), 0o600))
+
+	var report bytes.Buffer
+	summary, err := importFile(ctx, customerController, name, "", dto.ImportModeSkip, &report)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 1, summary.Created)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Contains(t, report.String(), `"status":"skipped"`)
+
+	found, err := dataSource.FindByCPF(ctx, "12345678909")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "John Doe", found.Name)
+}
+
+func TestImportFile_UnknownFormat(t *testing.T) {
+	_, err := importFile(context.Background(), nil, "customers.xlsx", "", dto.ImportModeSkip, &bytes.Buffer{})
+
+	assert.Error(t, err)
+}
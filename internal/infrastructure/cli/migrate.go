@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+// runMigrate applies the pending migrations of the customers table and prints a summary of each one
+func runMigrate(ctx context.Context, flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	endpoint := flags.String("endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB Local")
+	dryRun := flags.Bool("dry-run", false, "report the items that would be updated without writing them")
+	pageSize := flags.Int("page-size", 100, "items read per scan page")
+	writesPerSecond := flags.Float64("rate", 0, "maximum item updates per second, 0 for no limit")
+	timeout := flags.Duration("timeout", time.Hour, "how long the migrations may run")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	db, err := newDynamoConnection(cfg, *endpoint)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	runner := database.NewMigrationRunner(db, database.CustomerItemMigrations, database.MigrationOptions{
+		DryRun:          *dryRun,
+		PageSize:        int32(*pageSize),
+		WritesPerSecond: *writesPerSecond,
+	})
+	reports, err := runner.Run(ctx)
+
+	updated := "updated"
+	if *dryRun {
+		updated = "to update (dry run)"
+	}
+	for _, report := range reports {
+		fmt.Fprintf(stdout, "migration %d (%s): %d scanned, %d %s\n",
+			report.Version, report.Description, report.Scanned, report.Updated, updated)
+	}
+	if err == nil && len(reports) == 0 {
+		fmt.Fprintln(stdout, "no pending migrations")
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// runPurgeDeleted removes the PostgreSQL customers deleted longer than DELETED_CUSTOMER_RETENTION ago and the
+// expired idempotency keys. It's meant to be scheduled, since PostgreSQL has no TTL like DynamoDB and MongoDB
+func runPurgeDeleted(ctx context.Context, flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	timeout := flags.Duration("timeout", 10*time.Minute, "how long the purge may run")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	if cfg.DataSource != config.DataSourcePostgres {
+		return fmt.Errorf("DATASOURCE %q purges the deleted customers with a TTL, only %q needs this command",
+			cfg.DataSource, config.DataSourcePostgres)
+	}
+
+	l := logger.NewLogger(cfg)
+	db, err := database.NewPostgresConnection(cfg, l)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close(ctx) }()
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	purged, err := db.PurgeDeleted(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%d deleted customers purged\n", purged)
+
+	expired, err := db.PurgeExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%d expired idempotency keys purged\n", expired)
+	return nil
+}
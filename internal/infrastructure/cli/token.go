@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/service"
+)
+
+// runToken issues an access token signed with the JWT settings of the configuration, like the admin
+// tokens of the operators, which the /auth route doesn't issue
+func runToken(_ context.Context, flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	subject := flags.String("subject", "", "who the token identifies, e.g. the operator email")
+	role := flags.String("role", string(entity.RoleAdmin), "role of the token: admin or customer")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *subject == "" {
+		flags.Usage()
+		return errors.New("the token subject is required")
+	}
+	if entity.Role(*role) != entity.RoleAdmin && entity.Role(*role) != entity.RoleCustomer {
+		return fmt.Errorf("unknown role %q", *role)
+	}
+
+	jwtService := service.NewJWTService(config.LoadConfig())
+	_, token, _, err := jwtService.IssueToken(entity.Principal{Subject: *subject, Role: entity.Role(*role)})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, token)
+	return err
+}
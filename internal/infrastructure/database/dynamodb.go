@@ -2,10 +2,14 @@ package database
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/keyprovider"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,9 +19,26 @@ import (
 )
 
 type DynamoDatabase struct {
-	Client    *dynamodb.Client
-	TableName string
-	logger    *logger.Logger
+	// Client is the SDK client, used to administer the tables
+	Client *dynamodb.Client
+	// ItemClient reads and writes the items, and is the only client used by the data sources
+	ItemClient DynamoClient
+	TableName  string
+	// UniquenessTableName is the table of the items reserving the CPF and email of each customer. Empty
+	// doesn't enforce their uniqueness
+	UniquenessTableName string
+	Resilience          ResilienceOptions
+	// DeletedRetention is how long the deleted customers are kept before the DynamoDB TTL purges them.
+	// Zero keeps them
+	DeletedRetention time.Duration
+	// KeyProvider encrypts the personal data of the customers with field-level encryption. Nil stores
+	// it in plaintext
+	KeyProvider port.KeyProvider
+	// DataKeyTTL is how long a data key encrypts the writes before another one is generated
+	DataKeyTTL time.Duration
+	// ListScanLimit is the most items a listing of the customers may scan. Zero doesn't limit them
+	ListScanLimit int
+	logger        *logger.Logger
 }
 
 func NewDynamoConnection(cfg *config.Config, l *logger.Logger) (*DynamoDatabase, error) {
@@ -30,6 +51,11 @@ func NewDynamoConnection(cfg *config.Config, l *logger.Logger) (*DynamoDatabase,
 		return nil, err
 	}
 
+	keyProvider, err := keyprovider.NewKeyProvider(cfg, awsCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create DynamoDB client
 	client := dynamodb.NewFromConfig(awsCfg)
 
@@ -38,9 +64,16 @@ func NewDynamoConnection(cfg *config.Config, l *logger.Logger) (*DynamoDatabase,
 		"region", cfg.DynamoRegion)
 
 	return &DynamoDatabase{
-		Client:    client,
-		TableName: cfg.DynamoTableName,
-		logger:    l,
+		Client:              client,
+		ItemClient:          client,
+		TableName:           cfg.DynamoTableName,
+		UniquenessTableName: cfg.UniquenessTableName,
+		Resilience:          NewResilienceOptions(cfg),
+		DeletedRetention:    cfg.DeletedCustomerRetention,
+		KeyProvider:         keyProvider,
+		DataKeyTTL:          cfg.PIIDataKeyTTL,
+		ListScanLimit:       cfg.DynamoListScanLimit,
+		logger:              l,
 	}, nil
 }
 
@@ -64,6 +97,11 @@ func NewDynamoTestConnection(cfg *config.Config, l *logger.Logger, endpoint stri
 		return nil, err
 	}
 
+	keyProvider, err := keyprovider.NewKeyProvider(cfg, awsCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create DynamoDB client with custom endpoint for local testing
 	client := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
 		o.BaseEndpoint = aws.String(endpoint)
@@ -75,12 +113,35 @@ func NewDynamoTestConnection(cfg *config.Config, l *logger.Logger, endpoint stri
 		"endpoint", endpoint)
 
 	return &DynamoDatabase{
-		Client:    client,
-		TableName: cfg.DynamoTableName,
-		logger:    l,
+		Client:              client,
+		ItemClient:          client,
+		TableName:           cfg.DynamoTableName,
+		UniquenessTableName: cfg.UniquenessTableName,
+		Resilience:          NewResilienceOptions(cfg),
+		DeletedRetention:    cfg.DeletedCustomerRetention,
+		KeyProvider:         keyProvider,
+		DataKeyTTL:          cfg.PIIDataKeyTTL,
+		ListScanLimit:       cfg.DynamoListScanLimit,
+		logger:              l,
 	}, nil
 }
 
+// NewDynamoItemDatabase creates a DynamoDatabase on top of an item client, like the fake of the dynamotest
+// package. It has no SDK client, so the tables can't be administered
+func NewDynamoItemDatabase(client DynamoClient, tableName string, l *logger.Logger) *DynamoDatabase {
+	return &DynamoDatabase{
+		ItemClient: client,
+		TableName:  tableName,
+		logger:     l,
+	}
+}
+
+// ResilientClient returns the item client wrapped with the timeouts, retries and circuit breaker of
+// the configuration. Each call returns a client with its own breaker
+func (d *DynamoDatabase) ResilientClient() DynamoClient {
+	return NewResilientDynamoClient(d.ItemClient, d.Resilience, d.logger)
+}
+
 func (d *DynamoDatabase) Close(ctx context.Context) error {
 	d.logger.InfoContext(ctx, "Closing DynamoDB connection")
 	return nil
@@ -97,12 +158,18 @@ func (d *DynamoDatabase) Ping(ctx context.Context) error {
 // LogOperation logs DynamoDB operations for debugging
 func (d *DynamoDatabase) LogOperation(ctx context.Context, operation, table string, duration time.Duration, err error) {
 	if err != nil {
-		d.logger.ErrorContext(ctx, "DynamoDB operation failed",
+		attrs := []any{
 			slog.String("operation", operation),
 			slog.String("table", table),
 			slog.Duration("duration", duration),
 			slog.String("error", err.Error()),
-		)
+		}
+		// The clients only see the message of an unavailable table, its cause is logged
+		var unavailable *domain.ServiceUnavailableError
+		if errors.As(err, &unavailable) && unavailable.Err != nil {
+			attrs = append(attrs, slog.String("cause", unavailable.Err.Error()))
+		}
+		d.logger.ErrorContext(ctx, "DynamoDB operation failed", attrs...)
 		return
 	}
 
@@ -0,0 +1,345 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/time/rate"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// MigrationsMetadataID is the key of the customers table item that records the applied migrations.
+// Customer IDs start at 1, so it never collides with a customer
+const MigrationsMetadataID = 0
+
+const defaultMigrationPageSize = 100
+
+// ItemMigration changes every item of the customers table that needs it. Update returns the change of an
+// item, or nil when the item is already migrated. An interrupted migration resumes from its last
+// checkpoint, which may visit some items twice, so Update must return nil for the items it already changed
+type ItemMigration struct {
+	Version     int
+	Description string
+	Update      func(item map[string]types.AttributeValue) *ItemUpdate
+}
+
+// ItemUpdate is an update expression with its attribute names and values
+type ItemUpdate struct {
+	Expression string
+	Names      map[string]string
+	Values     map[string]types.AttributeValue
+}
+
+// MigrationOptions tune how the migrations go through the table. A PageSize of 0 scans 100 items per page,
+// and a WritesPerSecond of 0 doesn't limit the updates. A dry run scans the items and reports how many
+// would be updated, without writing anything
+type MigrationOptions struct {
+	DryRun          bool
+	PageSize        int32
+	WritesPerSecond float64
+}
+
+// MigrationReport summarizes a migration run
+type MigrationReport struct {
+	Version     int
+	Description string
+	Scanned     int
+	Updated     int
+}
+
+// migrationClient is the part of the DynamoDB client used by the migrations
+type migrationClient interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// appliedMigration is an entry of the migration history kept in the metadata item
+type appliedMigration struct {
+	Version     int    `dynamodbav:"version"`
+	Description string `dynamodbav:"description"`
+	Updated     int    `dynamodbav:"updated"`
+	AppliedAt   string `dynamodbav:"applied_at"`
+}
+
+// migrationState is the metadata item: the version of the last applied migration, and the
+// last key scanned by the migration in progress
+type migrationState struct {
+	schemaVersion     int
+	checkpointVersion int
+	checkpointKey     map[string]types.AttributeValue
+}
+
+type MigrationRunner struct {
+	client     migrationClient
+	tableName  string
+	migrations []ItemMigration
+	options    MigrationOptions
+	limiter    *rate.Limiter
+	logger     *logger.Logger
+}
+
+func NewMigrationRunner(db *DynamoDatabase, migrations []ItemMigration, options MigrationOptions) *MigrationRunner {
+	return newMigrationRunner(db.Client, db.TableName, db.logger, migrations, options)
+}
+
+func newMigrationRunner(client migrationClient, tableName string, l *logger.Logger, migrations []ItemMigration, options MigrationOptions) *MigrationRunner {
+	if options.PageSize <= 0 {
+		options.PageSize = defaultMigrationPageSize
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if options.WritesPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(options.WritesPerSecond), max(1, int(options.WritesPerSecond)))
+	}
+
+	sorted := append([]ItemMigration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &MigrationRunner{
+		client:     client,
+		tableName:  tableName,
+		migrations: sorted,
+		options:    options,
+		limiter:    limiter,
+		logger:     l,
+	}
+}
+
+// Run applies, in version order, the migrations newer than the one recorded in the metadata item
+func (r *MigrationRunner) Run(ctx context.Context) ([]MigrationReport, error) {
+	for i, migration := range r.migrations {
+		if migration.Version <= 0 || (i > 0 && migration.Version == r.migrations[i-1].Version) {
+			return nil, fmt.Errorf("invalid migration version %d: versions must be positive and unique", migration.Version)
+		}
+	}
+
+	state, err := r.loadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]MigrationReport, 0)
+	for _, migration := range r.migrations {
+		if migration.Version <= state.schemaVersion {
+			continue
+		}
+
+		var startKey map[string]types.AttributeValue
+		if state.checkpointVersion == migration.Version {
+			startKey = state.checkpointKey
+		}
+
+		report, err := r.apply(ctx, migration, startKey)
+		reports = append(reports, report)
+		if err != nil {
+			return reports, fmt.Errorf("migration %d: %w", migration.Version, err)
+		}
+
+		if !r.options.DryRun {
+			if err := r.markApplied(ctx, migration, state.schemaVersion, report.Updated); err != nil {
+				return reports, fmt.Errorf("migration %d: %w", migration.Version, err)
+			}
+			state.schemaVersion = migration.Version
+		}
+	}
+	return reports, nil
+}
+
+// apply scans the table page by page from startKey, updating the items returned by the migration.
+// The last key of each page is saved as a checkpoint, so an interrupted run resumes after it
+func (r *MigrationRunner) apply(ctx context.Context, migration ItemMigration, startKey map[string]types.AttributeValue) (MigrationReport, error) {
+	report := MigrationReport{Version: migration.Version, Description: migration.Description}
+	r.logger.InfoContext(ctx, "Applying DynamoDB migration",
+		slog.Int("version", migration.Version),
+		slog.String("description", migration.Description),
+		slog.Bool("dry_run", r.options.DryRun),
+		slog.Bool("resumed", startKey != nil),
+	)
+
+	for {
+		output, err := r.client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(r.tableName),
+			Limit:             aws.Int32(r.options.PageSize),
+			ExclusiveStartKey: startKey,
+			ConsistentRead:    aws.Bool(true),
+		})
+		if err != nil {
+			return report, err
+		}
+
+		for _, item := range output.Items {
+			if isMigrationsMetadata(item) {
+				continue
+			}
+			report.Scanned++
+
+			update := migration.Update(item)
+			if update == nil {
+				continue
+			}
+			if r.options.DryRun {
+				report.Updated++
+				continue
+			}
+
+			updated, err := r.updateItem(ctx, item, update)
+			if err != nil {
+				return report, err
+			}
+			if updated {
+				report.Updated++
+			}
+		}
+
+		startKey = output.LastEvaluatedKey
+		if len(startKey) == 0 {
+			break
+		}
+		if !r.options.DryRun {
+			if err := r.saveCheckpoint(ctx, migration.Version, startKey); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	r.logger.InfoContext(ctx, "Applied DynamoDB migration",
+		slog.Int("version", migration.Version),
+		slog.Int("scanned", report.Scanned),
+		slog.Int("updated", report.Updated),
+		slog.Bool("dry_run", r.options.DryRun),
+	)
+	return report, nil
+}
+
+// updateItem applies the migration to an item, unless it was deleted since it was scanned
+func (r *MigrationRunner) updateItem(ctx context.Context, item map[string]types.AttributeValue, update *ItemUpdate) (bool, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return false, err
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 map[string]types.AttributeValue{"id": item["id"]},
+		UpdateExpression:    aws.String(update.Expression),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	}
+	if len(update.Names) > 0 {
+		input.ExpressionAttributeNames = update.Names
+	}
+	if len(update.Values) > 0 {
+		input.ExpressionAttributeValues = update.Values
+	}
+
+	_, err := r.client.UpdateItem(ctx, input)
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *MigrationRunner) loadState(ctx context.Context) (migrationState, error) {
+	output, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            migrationsMetadataKey(),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return migrationState{}, err
+	}
+
+	var state migrationState
+	if state.schemaVersion, err = numberAttribute(output.Item, "schema_version"); err != nil {
+		return migrationState{}, err
+	}
+	if state.checkpointVersion, err = numberAttribute(output.Item, "checkpoint_version"); err != nil {
+		return migrationState{}, err
+	}
+	if key, ok := output.Item["checkpoint_key"].(*types.AttributeValueMemberM); ok {
+		state.checkpointKey = key.Value
+	}
+	return state, nil
+}
+
+func (r *MigrationRunner) saveCheckpoint(ctx context.Context, version int, key map[string]types.AttributeValue) error {
+	_, err := r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              migrationsMetadataKey(),
+		UpdateExpression: aws.String("SET checkpoint_version = :version, checkpoint_key = :key"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberN{Value: strconv.Itoa(version)},
+			":key":     &types.AttributeValueMemberM{Value: key},
+		},
+	})
+	return err
+}
+
+// markApplied records the migration in the metadata item and clears its checkpoint. The write only
+// succeeds while the recorded version is still the previous one, so concurrent runs can't apply it twice
+func (r *MigrationRunner) markApplied(ctx context.Context, migration ItemMigration, previousVersion, updated int) error {
+	entry, err := attributevalue.MarshalMap(appliedMigration{
+		Version:     migration.Version,
+		Description: migration.Description,
+		Updated:     updated,
+		AppliedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       migrationsMetadataKey(),
+		UpdateExpression: aws.String("SET schema_version = :version, applied = list_append(if_not_exists(applied, :empty), :entry) " +
+			"REMOVE checkpoint_version, checkpoint_key"),
+		ConditionExpression: aws.String("attribute_not_exists(schema_version) OR schema_version = :previous"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version":  &types.AttributeValueMemberN{Value: strconv.Itoa(migration.Version)},
+			":previous": &types.AttributeValueMemberN{Value: strconv.Itoa(previousVersion)},
+			":empty":    &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+			":entry":    &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberM{Value: entry}}},
+		},
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return errors.New("another run changed the schema version")
+	}
+	return err
+}
+
+func migrationsMetadataKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberN{Value: strconv.Itoa(MigrationsMetadataID)},
+	}
+}
+
+func isMigrationsMetadata(item map[string]types.AttributeValue) bool {
+	id, ok := item["id"].(*types.AttributeValueMemberN)
+	return ok && id.Value == strconv.Itoa(MigrationsMetadataID)
+}
+
+// numberAttribute reads a number attribute of an item, which is 0 when absent
+func numberAttribute(item map[string]types.AttributeValue, name string) (int, error) {
+	value, ok := item[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value.Value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value.Value, err)
+	}
+	return number, nil
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// fakeMigrationClient keeps the items of a table keyed by their numeric id, and understands
+// the SET and REMOVE update expressions used by the migrations
+type fakeMigrationClient struct {
+	items    map[int]map[string]types.AttributeValue
+	scans    []*dynamodb.ScanInput
+	updates  []*dynamodb.UpdateItemInput
+	failScan int
+	deleted  map[int]bool
+}
+
+func newFakeMigrationClient(customers int) *fakeMigrationClient {
+	client := &fakeMigrationClient{items: make(map[int]map[string]types.AttributeValue)}
+	for id := 1; id <= customers; id++ {
+		client.items[id] = map[string]types.AttributeValue{
+			"id":   &types.AttributeValueMemberN{Value: strconv.Itoa(id)},
+			"name": &types.AttributeValueMemberS{Value: "Customer " + strconv.Itoa(id)},
+		}
+	}
+	return client
+}
+
+func (c *fakeMigrationClient) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: c.items[keyID(params.Key)]}, nil
+}
+
+func (c *fakeMigrationClient) Scan(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	c.scans = append(c.scans, params)
+	if c.failScan > 0 && len(c.scans) == c.failScan {
+		return nil, errors.New("scan failed")
+	}
+
+	ids := make([]int, 0, len(c.items))
+	for id := range c.items {
+		if params.ExclusiveStartKey == nil || id > keyID(params.ExclusiveStartKey) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	limit := int(aws.ToInt32(params.Limit))
+	output := &dynamodb.ScanOutput{}
+	for _, id := range ids[:min(limit, len(ids))] {
+		output.Items = append(output.Items, c.items[id])
+	}
+	if len(ids) > limit {
+		output.LastEvaluatedKey = map[string]types.AttributeValue{"id": output.Items[limit-1]["id"]}
+	}
+	return output, nil
+}
+
+func (c *fakeMigrationClient) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	c.updates = append(c.updates, params)
+	id := keyID(params.Key)
+	if c.deleted[id] {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+
+	item, ok := c.items[id]
+	if !ok {
+		item = map[string]types.AttributeValue{"id": params.Key["id"]}
+		c.items[id] = item
+	}
+
+	expression := aws.ToString(params.UpdateExpression)
+	remove := ""
+	if i := strings.Index(expression, " REMOVE "); i >= 0 {
+		expression, remove = expression[:i], expression[i+len(" REMOVE "):]
+	}
+	for _, assignment := range strings.Split(strings.TrimPrefix(expression, "SET "), ", ") {
+		name, value, _ := strings.Cut(assignment, " = ")
+		if alias, ok := params.ExpressionAttributeNames[name]; ok {
+			name = alias
+		}
+		if strings.HasPrefix(value, "list_append") {
+			entries := []types.AttributeValue{}
+			if applied, ok := item[name].(*types.AttributeValueMemberL); ok {
+				entries = applied.Value
+			}
+			entry := params.ExpressionAttributeValues[":entry"].(*types.AttributeValueMemberL)
+			item[name] = &types.AttributeValueMemberL{Value: append(entries, entry.Value...)}
+			continue
+		}
+		item[name] = params.ExpressionAttributeValues[value]
+	}
+	for _, name := range strings.Split(remove, ", ") {
+		delete(item, name)
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func keyID(key map[string]types.AttributeValue) int {
+	id, _ := strconv.Atoi(key["id"].(*types.AttributeValueMemberN).Value)
+	return id
+}
+
+// newTestMigrationRunner runs the first customer migration, so the runner tests don't change with the
+// migrations appended later
+func newTestMigrationRunner(client *fakeMigrationClient, options MigrationOptions) *MigrationRunner {
+	cfg := &config.Config{Environment: "test"}
+	return newMigrationRunner(client, "customers", logger.NewLogger(cfg), CustomerItemMigrations[:1], options)
+}
+
+func TestMigrationRunner_Run(t *testing.T) {
+	client := newFakeMigrationClient(5)
+	client.items[2]["version"] = &types.AttributeValueMemberN{Value: "3"}
+
+	reports, err := newTestMigrationRunner(client, MigrationOptions{PageSize: 2}).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, MigrationReport{Version: 1, Description: CustomerItemMigrations[0].Description, Scanned: 5, Updated: 4}, reports[0])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, client.items[1]["version"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "3"}, client.items[2]["version"], "migrated items must be kept")
+
+	metadata := client.items[MigrationsMetadataID]
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, metadata["schema_version"])
+	assert.NotContains(t, metadata, "checkpoint_key")
+	assert.Len(t, metadata["applied"].(*types.AttributeValueMemberL).Value, 1)
+
+	// A second run finds every migration applied
+	updates := len(client.updates)
+	reports, err = newTestMigrationRunner(client, MigrationOptions{PageSize: 2}).Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+	assert.Len(t, client.updates, updates)
+}
+
+func TestMigrationRunner_DryRun(t *testing.T) {
+	client := newFakeMigrationClient(3)
+
+	reports, err := newTestMigrationRunner(client, MigrationOptions{DryRun: true}).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, 3, reports[0].Updated)
+	assert.Empty(t, client.updates)
+	assert.NotContains(t, client.items, MigrationsMetadataID)
+}
+
+func TestMigrationRunner_ResumesFromCheckpoint(t *testing.T) {
+	client := newFakeMigrationClient(5)
+	client.failScan = 2
+
+	_, err := newTestMigrationRunner(client, MigrationOptions{PageSize: 2}).Run(context.Background())
+	require.Error(t, err)
+	checkpoint := client.items[MigrationsMetadataID]["checkpoint_key"].(*types.AttributeValueMemberM)
+	assert.Equal(t, 2, keyID(checkpoint.Value))
+
+	client.failScan = 0
+	client.scans = nil
+	reports, err := newTestMigrationRunner(client, MigrationOptions{PageSize: 2}).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, keyID(client.scans[0].ExclusiveStartKey), "the scan must resume after the checkpoint")
+	assert.Equal(t, 3, reports[0].Scanned)
+	for id := 1; id <= 5; id++ {
+		assert.Contains(t, client.items[id], "version", "customer %d", id)
+	}
+}
+
+func TestMigrationRunner_SkipsDeletedItems(t *testing.T) {
+	client := newFakeMigrationClient(3)
+	client.deleted = map[int]bool{2: true}
+
+	reports, err := newTestMigrationRunner(client, MigrationOptions{}).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, reports[0].Updated)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, client.items[MigrationsMetadataID]["schema_version"])
+}
+
+func TestMigrationRunner_RejectsInvalidVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []ItemMigration
+	}{
+		{name: "should reject a version below 1", migrations: []ItemMigration{{Version: 0}}},
+		{name: "should reject duplicated versions", migrations: []ItemMigration{{Version: 1}, {Version: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeMigrationClient(1)
+			cfg := &config.Config{Environment: "test"}
+			runner := newMigrationRunner(client, "customers", logger.NewLogger(cfg), tt.migrations, MigrationOptions{})
+
+			_, err := runner.Run(context.Background())
+
+			assert.Error(t, err)
+			assert.Empty(t, client.scans)
+		})
+	}
+}
+
+func TestCustomerItemMigrations(t *testing.T) {
+	client := newFakeMigrationClient(3)
+	client.items[2]["status"] = &types.AttributeValueMemberS{Value: "blocked"}
+	cfg := &config.Config{Environment: "test"}
+	runner := newMigrationRunner(client, "customers", logger.NewLogger(cfg), CustomerItemMigrations, MigrationOptions{})
+
+	reports, err := runner.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, reports, len(CustomerItemMigrations))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "active"}, client.items[1]["status"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "blocked"}, client.items[2]["status"], "a status must be kept")
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, client.items[3]["version"])
+}
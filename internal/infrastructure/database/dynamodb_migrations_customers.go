@@ -0,0 +1,38 @@
+package database
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CustomerItemMigrations are the migrations of the customers table items, in version order.
+// Append new migrations with the next version, and never change the applied ones
+var CustomerItemMigrations = []ItemMigration{
+	{
+		Version:     1,
+		Description: "set version 1 on customers created before optimistic concurrency",
+		Update: func(item map[string]types.AttributeValue) *ItemUpdate {
+			if _, ok := item["version"]; ok {
+				return nil
+			}
+			return &ItemUpdate{
+				Expression: "SET #version = :version",
+				Names:      map[string]string{"#version": "version"},
+				Values:     map[string]types.AttributeValue{":version": &types.AttributeValueMemberN{Value: "1"}},
+			}
+		},
+	},
+	{
+		Version:     2,
+		Description: "set the active status on customers created before the account statuses",
+		Update: func(item map[string]types.AttributeValue) *ItemUpdate {
+			if _, ok := item["status"]; ok {
+				return nil
+			}
+			return &ItemUpdate{
+				Expression: "SET #status = :status",
+				Names:      map[string]string{"#status": "status"},
+				Values:     map[string]types.AttributeValue{":status": &types.AttributeValueMemberS{Value: "active"}},
+			}
+		},
+	},
+}
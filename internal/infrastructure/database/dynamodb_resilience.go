@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// ErrCircuitOpen is the cause of the errors returned while the circuit breaker rejects the calls
+var ErrCircuitOpen = errors.New("DynamoDB circuit breaker is open")
+
+// retryableErrorCodes are the DynamoDB error codes of throttled requests and server failures
+var retryableErrorCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"RequestLimitExceeded":                   true,
+	"ThrottlingException":                    true,
+	"Throttling":                             true,
+	"InternalServerError":                    true,
+	"ServiceUnavailable":                     true,
+}
+
+// ResilienceOptions configure the resilient client. Each attempt of an operation has OperationTimeout,
+// and throttled or failed attempts are retried up to MaxAttempts, waiting a jittered exponential backoff
+// between RetryBaseDelay and RetryMaxDelay. After BreakerThreshold operations in a row fail, the circuit
+// breaker opens and rejects the operations, letting a single one through every BreakerCooldown
+type ResilienceOptions struct {
+	OperationTimeout time.Duration
+	MaxAttempts      int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+func NewResilienceOptions(cfg *config.Config) ResilienceOptions {
+	return ResilienceOptions{
+		OperationTimeout: cfg.DynamoOperationTimeout,
+		MaxAttempts:      cfg.DynamoMaxAttempts,
+		RetryBaseDelay:   cfg.DynamoRetryBaseDelay,
+		RetryMaxDelay:    cfg.DynamoRetryMaxDelay,
+		BreakerThreshold: cfg.DynamoBreakerThreshold,
+		BreakerCooldown:  cfg.DynamoBreakerCooldown,
+	}
+}
+
+type resilientDynamoClient struct {
+	client  DynamoClient
+	options ResilienceOptions
+	breaker *circuitBreaker
+	logger  *logger.Logger
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewResilientDynamoClient wraps a client with timeouts, retries and a circuit breaker. Operations that
+// still fail after every attempt, or are rejected by the open breaker, return a ServiceUnavailableError.
+// The retries of the SDK are disabled on the wrapped calls, so they aren't multiplied
+func NewResilientDynamoClient(client DynamoClient, options ResilienceOptions, l *logger.Logger) DynamoClient {
+	return newResilientDynamoClient(client, options, l, time.Now)
+}
+
+func newResilientDynamoClient(client DynamoClient, options ResilienceOptions, l *logger.Logger, now func() time.Time) *resilientDynamoClient {
+	options.MaxAttempts = max(1, options.MaxAttempts)
+	return &resilientDynamoClient{
+		client:  client,
+		options: options,
+		breaker: &circuitBreaker{threshold: options.BreakerThreshold, cooldown: options.BreakerCooldown, now: now},
+		logger:  l,
+		sleep:   Sleep,
+	}
+}
+
+func (c *resilientDynamoClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return call(ctx, c, "GetItem", func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return c.client.GetItem(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return call(ctx, c, "BatchGetItem", func(ctx context.Context) (*dynamodb.BatchGetItemOutput, error) {
+		return c.client.BatchGetItem(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return call(ctx, c, "Query", func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return c.client.Query(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return call(ctx, c, "Scan", func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+		return c.client.Scan(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return call(ctx, c, "PutItem", func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return c.client.PutItem(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return call(ctx, c, "UpdateItem", func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return c.client.UpdateItem(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return call(ctx, c, "DeleteItem", func(ctx context.Context) (*dynamodb.DeleteItemOutput, error) {
+		return c.client.DeleteItem(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return call(ctx, c, "BatchWriteItem", func(ctx context.Context) (*dynamodb.BatchWriteItemOutput, error) {
+		return c.client.BatchWriteItem(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+func (c *resilientDynamoClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return call(ctx, c, "TransactWriteItems", func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return c.client.TransactWriteItems(ctx, params, withoutSDKRetries(optFns)...)
+	})
+}
+
+// call runs an operation through the breaker, retrying the attempts that failed with a retryable error.
+// Errors the table answered with, like a failed condition, count as a success for the breaker, and
+// a context canceled by the caller isn't counted at all
+func call[T any](ctx context.Context, c *resilientDynamoClient, operation string, attempt func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if !c.breaker.allow() {
+		return zero, domain.NewServiceUnavailableError(ErrCircuitOpen)
+	}
+
+	for n := 1; ; n++ {
+		output, err := withTimeout(ctx, c.options.OperationTimeout, attempt)
+		if err == nil {
+			c.record(ctx, operation, true)
+			return output, nil
+		}
+		if ctx.Err() != nil {
+			return zero, err
+		}
+		if !isRetryable(err) {
+			c.record(ctx, operation, true)
+			return zero, err
+		}
+		if n >= c.options.MaxAttempts {
+			c.record(ctx, operation, false)
+			return zero, domain.NewServiceUnavailableError(err)
+		}
+
+		c.logger.DebugContext(ctx, "Retrying DynamoDB operation",
+			slog.String("operation", operation),
+			slog.Int("attempt", n),
+			slog.String("error", err.Error()),
+		)
+		if err := c.sleep(ctx, c.backoff(n)); err != nil {
+			return zero, err
+		}
+	}
+}
+
+// withTimeout runs an attempt with its own deadline, so a slow attempt can be retried
+// while the caller still has time
+func withTimeout[T any](ctx context.Context, timeout time.Duration, attempt func(ctx context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return attempt(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return attempt(ctx)
+}
+
+func (c *resilientDynamoClient) record(ctx context.Context, operation string, success bool) {
+	if c.breaker.record(success) {
+		c.logger.WarnContext(ctx, "DynamoDB circuit breaker opened",
+			slog.String("operation", operation),
+			slog.Duration("cooldown", c.options.BreakerCooldown),
+		)
+	}
+}
+
+func (c *resilientDynamoClient) backoff(attempt int) time.Duration {
+	return c.options.Backoff(attempt)
+}
+
+// Backoff is the wait before the attempt after the given one: half of the exponential delay plus a random
+// part of the other half, so the instances throttled together don't retry together
+func (o ResilienceOptions) Backoff(attempt int) time.Duration {
+	delay := o.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || (o.RetryMaxDelay > 0 && delay > o.RetryMaxDelay) {
+		delay = o.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Sleep waits for d, returning early with the error of the context when it's done
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func withoutSDKRetries(optFns []func(*dynamodb.Options)) []func(*dynamodb.Options) {
+	return append(optFns, func(o *dynamodb.Options) { o.RetryMaxAttempts = 1 })
+}
+
+// isRetryable tells whether an attempt failed because the table throttled it or couldn't answer it:
+// a throttling or server error code, a 5xx response, or an attempt that timed out
+func isRetryable(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && retryableErrorCodes[apiErr.ErrorCode()] {
+		return true
+	}
+
+	var responseErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() >= 500 {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// circuitBreaker opens after threshold failures in a row. While open, it lets a single call through
+// every cooldown, and closes again when one of them succeeds. A threshold of 0 never opens it
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	openedAt time.Time
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return true
+	}
+	if b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	// Let this call probe the table, and reject the others until the next cooldown
+	b.openedAt = b.now()
+	return true
+}
+
+// record counts the outcome of a call, returning true when it opened the breaker
+func (b *circuitBreaker) record(success bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.failures = 0
+		b.open = false
+		return false
+	}
+
+	b.failures++
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return false
+	}
+	opened := !b.open
+	b.open = true
+	b.openedAt = b.now()
+	return opened
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// fakeDynamoClient answers GetItem with the next error of errs, succeeding once they run out.
+// The other operations aren't used by the tests
+type fakeDynamoClient struct {
+	DynamoClient
+	errs    []error
+	calls   int
+	options dynamodb.Options
+	block   bool
+}
+
+func (c *fakeDynamoClient) GetItem(ctx context.Context, _ *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	c.calls++
+	for _, fn := range optFns {
+		fn(&c.options)
+	}
+	if c.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+// fakeClock is a clock moved by the tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func throttlingError() error {
+	return &smithy.OperationError{
+		ServiceID:     "DynamoDB",
+		OperationName: "GetItem",
+		Err:           &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException", Message: "rate exceeded"},
+	}
+}
+
+func newTestResilientClient(client DynamoClient, options ResilienceOptions) (*resilientDynamoClient, *fakeClock, *[]time.Duration) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	resilient := newResilientDynamoClient(client, options, logger.NewLogger(&config.Config{Environment: "test"}), clock.Now)
+
+	sleeps := &[]time.Duration{}
+	resilient.sleep = func(_ context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+	return resilient, clock, sleeps
+}
+
+func TestResilientDynamoClient_Retries(t *testing.T) {
+	conditionFailed := &types.ConditionalCheckFailedException{Message: new(string)}
+
+	tests := []struct {
+		name            string
+		errs            []error
+		wantCalls       int
+		wantErr         error
+		wantUnavailable bool
+		wantSleeps      int
+	}{
+		{
+			name:      "should succeed at the first attempt",
+			wantCalls: 1,
+		},
+		{
+			name:       "should retry a throttled attempt",
+			errs:       []error{throttlingError()},
+			wantCalls:  2,
+			wantSleeps: 1,
+		},
+		{
+			name:            "should return service unavailable after every attempt failed",
+			errs:            []error{throttlingError(), throttlingError(), throttlingError()},
+			wantCalls:       3,
+			wantUnavailable: true,
+			wantSleeps:      2,
+		},
+		{
+			name:      "should not retry a failed condition",
+			errs:      []error{conditionFailed},
+			wantCalls: 1,
+			wantErr:   conditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeDynamoClient{errs: tt.errs}
+			resilient, _, sleeps := newTestResilientClient(client, ResilienceOptions{
+				MaxAttempts:    3,
+				RetryBaseDelay: 100 * time.Millisecond,
+				RetryMaxDelay:  time.Second,
+			})
+
+			_, err := resilient.GetItem(context.Background(), &dynamodb.GetItemInput{})
+
+			assert.Equal(t, tt.wantCalls, client.calls)
+			assert.Len(t, *sleeps, tt.wantSleeps)
+			assert.Equal(t, 1, client.options.RetryMaxAttempts, "the SDK retries must be disabled")
+			switch {
+			case tt.wantUnavailable:
+				var unavailable *domain.ServiceUnavailableError
+				require.ErrorAs(t, err, &unavailable)
+				var apiErr smithy.APIError
+				assert.ErrorAs(t, err, &apiErr, "the cause must be kept")
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResilientDynamoClient_Backoff(t *testing.T) {
+	resilient, _, _ := newTestResilientClient(&fakeDynamoClient{}, ResilienceOptions{
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  300 * time.Millisecond,
+	})
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		t.Run(fmt.Sprintf("attempt %d", attempt), func(t *testing.T) {
+			for range 20 {
+				delay := resilient.backoff(attempt)
+				assert.GreaterOrEqual(t, delay, want/2)
+				assert.LessOrEqual(t, delay, want)
+			}
+		})
+	}
+}
+
+func TestResilientDynamoClient_OperationTimeout(t *testing.T) {
+	client := &fakeDynamoClient{block: true}
+	resilient, _, _ := newTestResilientClient(client, ResilienceOptions{
+		OperationTimeout: 10 * time.Millisecond,
+		MaxAttempts:      2,
+	})
+
+	_, err := resilient.GetItem(context.Background(), &dynamodb.GetItemInput{})
+
+	var unavailable *domain.ServiceUnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, 2, client.calls, "a timed out attempt must be retried")
+}
+
+func TestResilientDynamoClient_CanceledByCaller(t *testing.T) {
+	client := &fakeDynamoClient{block: true}
+	resilient, _, _ := newTestResilientClient(client, ResilienceOptions{MaxAttempts: 3, BreakerThreshold: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, client.calls)
+	assert.True(t, resilient.breaker.allow(), "a canceled call must not open the breaker")
+}
+
+func TestResilientDynamoClient_CircuitBreaker(t *testing.T) {
+	client := &fakeDynamoClient{errs: []error{throttlingError(), throttlingError()}}
+	resilient, clock, _ := newTestResilientClient(client, ResilienceOptions{
+		MaxAttempts:      1,
+		BreakerThreshold: 2,
+		BreakerCooldown:  30 * time.Second,
+	})
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+		require.Error(t, err)
+	}
+
+	_, err := resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var unavailable *domain.ServiceUnavailableError
+	assert.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, 2, client.calls, "the open breaker must fail fast")
+
+	// After the cooldown a single call probes the table, and its success closes the breaker
+	clock.now = clock.now.Add(30 * time.Second)
+	_, err = resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+	require.NoError(t, err)
+	_, err = resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.calls)
+}
+
+func TestResilientDynamoClient_FailedProbe(t *testing.T) {
+	client := &fakeDynamoClient{errs: []error{throttlingError(), throttlingError()}}
+	resilient, clock, _ := newTestResilientClient(client, ResilienceOptions{
+		MaxAttempts:      1,
+		BreakerThreshold: 1,
+		BreakerCooldown:  30 * time.Second,
+	})
+	ctx := context.Background()
+
+	_, _ = resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+	clock.now = clock.now.Add(30 * time.Second)
+	_, err := resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCircuitOpen, "the probe must reach the table")
+
+	_, err = resilient.GetItem(ctx, &dynamodb.GetItemInput{})
+	assert.ErrorIs(t, err, ErrCircuitOpen, "a failed probe must open the breaker for another cooldown")
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestIsRetryable(t *testing.T) {
+	responseError := func(status int) error {
+		return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      errors.New("response error"),
+		}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "should retry provisioned throughput exceeded", err: throttlingError(), want: true},
+		{name: "should retry throttling", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, want: true},
+		{name: "should retry an internal server error", err: &types.InternalServerError{}, want: true},
+		{name: "should retry a 5xx response", err: responseError(http.StatusBadGateway), want: true},
+		{name: "should retry a timed out attempt", err: fmt.Errorf("get item: %w", context.DeadlineExceeded), want: true},
+		{name: "should not retry a 4xx response", err: responseError(http.StatusBadRequest), want: false},
+		{name: "should not retry a failed condition", err: &types.ConditionalCheckFailedException{}, want: false},
+		{name: "should not retry a validation error", err: &smithy.GenericAPIError{Code: "ValidationException"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryable(tt.err))
+		})
+	}
+}
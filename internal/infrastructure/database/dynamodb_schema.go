@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CustomersCPFIndex is the global secondary index of the customers table keyed by CPF
+const CustomersCPFIndex = "cpf-index"
+
+// CustomersPurgeAtAttribute is the TTL attribute of the customers table, the epoch second after which
+// DynamoDB removes a deleted customer
+const CustomersPurgeAtAttribute = "purge_at"
+
+// UniquenessKeyAttribute is the key of the uniqueness table, the attribute and value a customer reserves,
+// like cpf#12345678900
+const UniquenessKeyAttribute = "unique_key"
+
+// AuditCustomerIndex is the global secondary index of the audit table keyed by customer ID
+const AuditCustomerIndex = "customer-index"
+
+// IdempotencyCustomerIndex is the global secondary index of the idempotency table keyed by the ID of the
+// customer a stored response presents. Records without a customer stay out of it
+const IdempotencyCustomerIndex = "customer-index"
+
+// tablePollInterval is how often EnsureTable checks whether a table and its indexes are ACTIVE
+var tablePollInterval = 2 * time.Second
+
+// KeyAttribute is a key attribute of a table or index
+type KeyAttribute struct {
+	Name string
+	Type types.ScalarAttributeType
+}
+
+// IndexSchema is a global secondary index projecting every attribute
+type IndexSchema struct {
+	Name    string
+	HashKey KeyAttribute
+}
+
+// TableSchema is a DynamoDB table with on-demand capacity. When TTLAttribute is set,
+// the DynamoDB TTL is enabled on it
+type TableSchema struct {
+	Name         string
+	HashKey      KeyAttribute
+	Indexes      []IndexSchema
+	TTLAttribute string
+}
+
+// CustomersTableSchema is the table of the customer DynamoDB data source. The TTL purges the deleted
+// customers once their retention period is over
+func CustomersTableSchema(tableName string) TableSchema {
+	return TableSchema{
+		Name:    tableName,
+		HashKey: KeyAttribute{Name: "id", Type: types.ScalarAttributeTypeN},
+		Indexes: []IndexSchema{
+			{Name: CustomersCPFIndex, HashKey: KeyAttribute{Name: "cpf", Type: types.ScalarAttributeTypeS}},
+		},
+		TTLAttribute: CustomersPurgeAtAttribute,
+	}
+}
+
+// UniquenessTableSchema is the table of the items reserving the CPF and email of each customer, keyed by
+// the attribute and its stored value
+func UniquenessTableSchema(tableName string) TableSchema {
+	return TableSchema{
+		Name:    tableName,
+		HashKey: KeyAttribute{Name: UniquenessKeyAttribute, Type: types.ScalarAttributeTypeS},
+	}
+}
+
+// IdempotencyTableSchema is the table of the idempotency DynamoDB data source
+func IdempotencyTableSchema(tableName string) TableSchema {
+	return TableSchema{
+		Name:    tableName,
+		HashKey: KeyAttribute{Name: "idempotency_key", Type: types.ScalarAttributeTypeS},
+		Indexes: []IndexSchema{
+			{Name: IdempotencyCustomerIndex, HashKey: KeyAttribute{Name: "customer_id", Type: types.ScalarAttributeTypeN}},
+		},
+		TTLAttribute: "expires_at",
+	}
+}
+
+// AuditTableSchema is the table of the audit DynamoDB data source. The entries are kept for good,
+// so it has no TTL
+func AuditTableSchema(tableName string) TableSchema {
+	return TableSchema{
+		Name:    tableName,
+		HashKey: KeyAttribute{Name: "entry_id", Type: types.ScalarAttributeTypeS},
+		Indexes: []IndexSchema{
+			{Name: AuditCustomerIndex, HashKey: KeyAttribute{Name: "customer_id", Type: types.ScalarAttributeTypeN}},
+		},
+	}
+}
+
+func (s TableSchema) createTableInput() *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(s.Name),
+		BillingMode:          types.BillingModePayPerRequest,
+		KeySchema:            s.HashKey.keySchema(),
+		AttributeDefinitions: []types.AttributeDefinition{s.HashKey.definition()},
+	}
+
+	for _, index := range s.Indexes {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, index.globalSecondaryIndex())
+		if !hasAttributeDefinition(input.AttributeDefinitions, index.HashKey.Name) {
+			input.AttributeDefinitions = append(input.AttributeDefinitions, index.HashKey.definition())
+		}
+	}
+	return input
+}
+
+func (a KeyAttribute) keySchema() []types.KeySchemaElement {
+	return []types.KeySchemaElement{{AttributeName: aws.String(a.Name), KeyType: types.KeyTypeHash}}
+}
+
+func (a KeyAttribute) definition() types.AttributeDefinition {
+	return types.AttributeDefinition{AttributeName: aws.String(a.Name), AttributeType: a.Type}
+}
+
+func (i IndexSchema) globalSecondaryIndex() types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName:  aws.String(i.Name),
+		KeySchema:  i.HashKey.keySchema(),
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+}
+
+// EnsureTable creates the table of the schema when it doesn't exist, or verifies the key of an existing one
+// and adds the indexes it lacks. It returns once the table and all of its indexes are ACTIVE, or when the
+// context is done
+func (d *DynamoDatabase) EnsureTable(ctx context.Context, schema TableSchema) error {
+	description, err := d.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(schema.Name)})
+
+	var notFound *types.ResourceNotFoundException
+	switch {
+	case errors.As(err, &notFound):
+		d.logger.InfoContext(ctx, "Creating DynamoDB table", slog.String("table", schema.Name))
+		if _, err := d.Client.CreateTable(ctx, schema.createTableInput()); err != nil {
+			return fmt.Errorf("create table %s: %w", schema.Name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("describe table %s: %w", schema.Name, err)
+	default:
+		if err := d.updateTable(ctx, schema, description.Table); err != nil {
+			return err
+		}
+	}
+
+	if err := d.waitForActive(ctx, schema.Name); err != nil {
+		return err
+	}
+	return d.ensureTTL(ctx, schema)
+}
+
+// updateTable verifies the key of an existing table and creates its missing indexes,
+// one at a time as DynamoDB only builds one index per update
+func (d *DynamoDatabase) updateTable(ctx context.Context, schema TableSchema, table *types.TableDescription) error {
+	if err := verifyKey(schema.Name, schema.HashKey, table.KeySchema, table.AttributeDefinitions); err != nil {
+		return err
+	}
+
+	existing := make(map[string]types.GlobalSecondaryIndexDescription, len(table.GlobalSecondaryIndexes))
+	for _, index := range table.GlobalSecondaryIndexes {
+		existing[aws.ToString(index.IndexName)] = index
+	}
+
+	for _, index := range schema.Indexes {
+		if description, ok := existing[index.Name]; ok {
+			if err := verifyKey(schema.Name+"/"+index.Name, index.HashKey, description.KeySchema, table.AttributeDefinitions); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := d.waitForActive(ctx, schema.Name); err != nil {
+			return err
+		}
+
+		d.logger.InfoContext(ctx, "Creating DynamoDB index", slog.String("table", schema.Name), slog.String("index", index.Name))
+		gsi := index.globalSecondaryIndex()
+		_, err := d.Client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+			TableName:            aws.String(schema.Name),
+			AttributeDefinitions: []types.AttributeDefinition{index.HashKey.definition()},
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+				{Create: &types.CreateGlobalSecondaryIndexAction{
+					IndexName:  gsi.IndexName,
+					KeySchema:  gsi.KeySchema,
+					Projection: gsi.Projection,
+				}},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("create index %s of table %s: %w", index.Name, schema.Name, err)
+		}
+	}
+	return nil
+}
+
+// waitForActive polls the table until it and all of its indexes are ACTIVE
+func (d *DynamoDatabase) waitForActive(ctx context.Context, tableName string) error {
+	for {
+		description, err := d.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			return fmt.Errorf("describe table %s: %w", tableName, err)
+		}
+		if tableActive(description.Table) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for table %s to be active: %w", tableName, ctx.Err())
+		case <-time.After(tablePollInterval):
+		}
+	}
+}
+
+// ensureTTL enables the DynamoDB TTL on the TTL attribute of the schema, unless it is already enabled
+func (d *DynamoDatabase) ensureTTL(ctx context.Context, schema TableSchema) error {
+	if schema.TTLAttribute == "" {
+		return nil
+	}
+
+	description, err := d.Client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(schema.Name)})
+	if err != nil {
+		return fmt.Errorf("describe time to live of table %s: %w", schema.Name, err)
+	}
+	if ttl := description.TimeToLiveDescription; ttl != nil &&
+		(ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabled || ttl.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+		if attribute := aws.ToString(ttl.AttributeName); attribute != schema.TTLAttribute {
+			return fmt.Errorf("table %s has TTL on %s, expected %s", schema.Name, attribute, schema.TTLAttribute)
+		}
+		return nil
+	}
+
+	d.logger.InfoContext(ctx, "Enabling DynamoDB TTL", slog.String("table", schema.Name), slog.String("attribute", schema.TTLAttribute))
+	_, err = d.Client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(schema.Name),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(schema.TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("enable time to live of table %s: %w", schema.Name, err)
+	}
+	return nil
+}
+
+func tableActive(table *types.TableDescription) bool {
+	if table == nil || table.TableStatus != types.TableStatusActive {
+		return false
+	}
+	for _, index := range table.GlobalSecondaryIndexes {
+		if index.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyKey checks that a table or index is keyed by the expected hash key only
+func verifyKey(name string, want KeyAttribute, keySchema []types.KeySchemaElement, definitions []types.AttributeDefinition) error {
+	if len(keySchema) != 1 || aws.ToString(keySchema[0].AttributeName) != want.Name || keySchema[0].KeyType != types.KeyTypeHash {
+		return fmt.Errorf("%s must have only the hash key %s", name, want.Name)
+	}
+	for _, definition := range definitions {
+		if aws.ToString(definition.AttributeName) == want.Name && definition.AttributeType != want.Type {
+			return fmt.Errorf("%s key %s has type %s, expected %s", name, want.Name, definition.AttributeType, want.Type)
+		}
+	}
+	return nil
+}
+
+func hasAttributeDefinition(definitions []types.AttributeDefinition, name string) bool {
+	for _, definition := range definitions {
+		if aws.ToString(definition.AttributeName) == name {
+			return true
+		}
+	}
+	return false
+}
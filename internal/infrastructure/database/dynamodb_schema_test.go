@@ -0,0 +1,97 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomersTableSchema_CreateTableInput(t *testing.T) {
+	input := CustomersTableSchema("customers").createTableInput()
+
+	assert.Equal(t, "customers", aws.ToString(input.TableName))
+	assert.Equal(t, types.BillingModePayPerRequest, input.BillingMode)
+	assert.Equal(t, []types.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash}}, input.KeySchema)
+	assert.ElementsMatch(t, []types.AttributeDefinition{
+		{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeN},
+		{AttributeName: aws.String("cpf"), AttributeType: types.ScalarAttributeTypeS},
+	}, input.AttributeDefinitions)
+	require.Len(t, input.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, CustomersCPFIndex, aws.ToString(input.GlobalSecondaryIndexes[0].IndexName))
+	assert.Equal(t, types.ProjectionTypeAll, input.GlobalSecondaryIndexes[0].Projection.ProjectionType)
+	assert.Equal(t, CustomersPurgeAtAttribute, CustomersTableSchema("customers").TTLAttribute)
+}
+
+func TestVerifyKey(t *testing.T) {
+	want := KeyAttribute{Name: "id", Type: types.ScalarAttributeTypeN}
+	hashKey := func(name string) []types.KeySchemaElement {
+		return []types.KeySchemaElement{{AttributeName: aws.String(name), KeyType: types.KeyTypeHash}}
+	}
+	definitions := func(attributeType types.ScalarAttributeType) []types.AttributeDefinition {
+		return []types.AttributeDefinition{{AttributeName: aws.String("id"), AttributeType: attributeType}}
+	}
+
+	tests := []struct {
+		name        string
+		keySchema   []types.KeySchemaElement
+		definitions []types.AttributeDefinition
+		wantErr     bool
+	}{
+		{name: "should accept the expected key", keySchema: hashKey("id"), definitions: definitions(types.ScalarAttributeTypeN)},
+		{name: "should reject another hash key", keySchema: hashKey("uuid"), definitions: definitions(types.ScalarAttributeTypeN), wantErr: true},
+		{name: "should reject another key type", keySchema: hashKey("id"), definitions: definitions(types.ScalarAttributeTypeS), wantErr: true},
+		{
+			name:        "should reject a range key",
+			keySchema:   append(hashKey("id"), types.KeySchemaElement{AttributeName: aws.String("cpf"), KeyType: types.KeyTypeRange}),
+			definitions: definitions(types.ScalarAttributeTypeN),
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyKey("customers", want, tt.keySchema, tt.definitions)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTableActive(t *testing.T) {
+	tests := []struct {
+		name  string
+		table *types.TableDescription
+		want  bool
+	}{
+		{name: "should not be active without a description", table: nil, want: false},
+		{name: "should not be active while creating", table: &types.TableDescription{TableStatus: types.TableStatusCreating}, want: false},
+		{
+			name: "should not be active while an index is building",
+			table: &types.TableDescription{
+				TableStatus:            types.TableStatusActive,
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{IndexStatus: types.IndexStatusCreating}},
+			},
+			want: false,
+		},
+		{
+			name: "should be active with active indexes",
+			table: &types.TableDescription{
+				TableStatus:            types.TableStatusActive,
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{{IndexStatus: types.IndexStatusActive}},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tableActive(tt.table))
+		})
+	}
+}
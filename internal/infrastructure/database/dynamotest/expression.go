@@ -0,0 +1,412 @@
+package dynamotest
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// expression evaluates condition and update expressions against an item. It understands the subset of
+// the DynamoDB expression syntax used by the data sources: comparisons, AND, OR, NOT, parentheses,
+// attribute_exists, attribute_not_exists and begins_with in conditions, and SET and REMOVE clauses with
+// if_not_exists, list_append, + and - in updates
+type expression struct {
+	names  map[string]string
+	values map[string]types.AttributeValue
+	tokens []string
+	pos    int
+}
+
+func newExpression(text string, names map[string]string, values map[string]types.AttributeValue) *expression {
+	return &expression{names: names, values: values, tokens: tokenize(text)}
+}
+
+// evaluateCondition tells whether an item, nil when absent, matches a condition. An empty condition always matches
+func evaluateCondition(text *string, names map[string]string, values map[string]types.AttributeValue, item map[string]types.AttributeValue) (bool, error) {
+	if text == nil || strings.TrimSpace(*text) == "" {
+		return true, nil
+	}
+
+	e := newExpression(*text, names, values)
+	matched, err := e.or(item)
+	if err != nil {
+		return false, err
+	}
+	if e.pos < len(e.tokens) {
+		return false, fmt.Errorf("dynamotest: unexpected %q in condition %q", e.tokens[e.pos], *text)
+	}
+	return matched, nil
+}
+
+// applyUpdate changes an item with the SET and REMOVE clauses of an update expression
+func applyUpdate(text string, names map[string]string, values map[string]types.AttributeValue, item map[string]types.AttributeValue) error {
+	e := newExpression(text, names, values)
+	for e.pos < len(e.tokens) {
+		switch clause := strings.ToUpper(e.next()); clause {
+		case "SET":
+			if err := e.set(item); err != nil {
+				return err
+			}
+		case "REMOVE":
+			for {
+				delete(item, e.name(e.next()))
+				if e.peek() != "," {
+					break
+				}
+				e.next()
+			}
+		default:
+			return fmt.Errorf("dynamotest: unsupported update clause %q in %q", clause, text)
+		}
+	}
+	return nil
+}
+
+func (e *expression) set(item map[string]types.AttributeValue) error {
+	for {
+		path := e.name(e.next())
+		if err := e.expect("="); err != nil {
+			return err
+		}
+		value, err := e.setValue(item)
+		if err != nil {
+			return err
+		}
+		item[path] = value
+
+		if e.peek() != "," {
+			return nil
+		}
+		e.next()
+	}
+}
+
+func (e *expression) setValue(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	value, err := e.setOperand(item)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operator := e.peek(); operator {
+	case "+", "-":
+		e.next()
+		other, err := e.setOperand(item)
+		if err != nil {
+			return nil, err
+		}
+		return arithmetic(value, other, operator)
+	default:
+		return value, nil
+	}
+}
+
+func (e *expression) setOperand(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	token := e.next()
+	switch strings.ToLower(token) {
+	case "if_not_exists":
+		if err := e.expect("("); err != nil {
+			return nil, err
+		}
+		path := e.name(e.next())
+		if err := e.expect(","); err != nil {
+			return nil, err
+		}
+		fallback, err := e.setOperand(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		if current, ok := item[path]; ok {
+			return current, nil
+		}
+		return fallback, nil
+	case "list_append":
+		if err := e.expect("("); err != nil {
+			return nil, err
+		}
+		first, err := e.setOperand(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(","); err != nil {
+			return nil, err
+		}
+		second, err := e.setOperand(item)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		firstList, ok1 := first.(*types.AttributeValueMemberL)
+		secondList, ok2 := second.(*types.AttributeValueMemberL)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("dynamotest: list_append of a value that isn't a list")
+		}
+		return &types.AttributeValueMemberL{Value: append(append([]types.AttributeValue{}, firstList.Value...), secondList.Value...)}, nil
+	default:
+		return e.operand(token, item)
+	}
+}
+
+func (e *expression) or(item map[string]types.AttributeValue) (bool, error) {
+	matched, err := e.and(item)
+	if err != nil {
+		return false, err
+	}
+	for strings.EqualFold(e.peek(), "OR") {
+		e.next()
+		other, err := e.and(item)
+		if err != nil {
+			return false, err
+		}
+		matched = matched || other
+	}
+	return matched, nil
+}
+
+func (e *expression) and(item map[string]types.AttributeValue) (bool, error) {
+	matched, err := e.not(item)
+	if err != nil {
+		return false, err
+	}
+	for strings.EqualFold(e.peek(), "AND") {
+		e.next()
+		other, err := e.not(item)
+		if err != nil {
+			return false, err
+		}
+		matched = matched && other
+	}
+	return matched, nil
+}
+
+func (e *expression) not(item map[string]types.AttributeValue) (bool, error) {
+	if strings.EqualFold(e.peek(), "NOT") {
+		e.next()
+		matched, err := e.not(item)
+		return !matched, err
+	}
+	return e.primary(item)
+}
+
+func (e *expression) primary(item map[string]types.AttributeValue) (bool, error) {
+	token := e.next()
+	switch strings.ToLower(token) {
+	case "(":
+		matched, err := e.or(item)
+		if err != nil {
+			return false, err
+		}
+		return matched, e.expect(")")
+	case "attribute_exists", "attribute_not_exists":
+		args, err := e.arguments(1)
+		if err != nil {
+			return false, err
+		}
+		_, exists := item[e.name(args[0])]
+		return exists == (strings.ToLower(token) == "attribute_exists"), nil
+	case "begins_with":
+		args, err := e.arguments(2)
+		if err != nil {
+			return false, err
+		}
+		value, _ := item[e.name(args[0])].(*types.AttributeValueMemberS)
+		prefix, err := e.operand(args[1], item)
+		if err != nil {
+			return false, err
+		}
+		prefixValue, _ := prefix.(*types.AttributeValueMemberS)
+		return value != nil && prefixValue != nil && strings.HasPrefix(value.Value, prefixValue.Value), nil
+	}
+
+	left, err := e.operand(token, item)
+	if err != nil {
+		return false, err
+	}
+	operator := e.next()
+	right, err := e.operand(e.next(), item)
+	if err != nil {
+		return false, err
+	}
+	return compare(left, right, operator)
+}
+
+// operand resolves a value placeholder or an attribute path, which is nil when the item doesn't have it
+func (e *expression) operand(token string, item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	if strings.HasPrefix(token, ":") {
+		value, ok := e.values[token]
+		if !ok {
+			return nil, fmt.Errorf("dynamotest: missing expression attribute value %q", token)
+		}
+		return value, nil
+	}
+	if token == "" || !isNameToken(token) {
+		return nil, fmt.Errorf("dynamotest: unexpected %q in expression", token)
+	}
+	return item[e.name(token)], nil
+}
+
+func (e *expression) name(token string) string {
+	if alias, ok := e.names[token]; ok {
+		return alias
+	}
+	return token
+}
+
+func (e *expression) arguments(n int) ([]string, error) {
+	if err := e.expect("("); err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for i := range n {
+		if i > 0 {
+			if err := e.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		args = append(args, e.next())
+	}
+	return args, e.expect(")")
+}
+
+func (e *expression) expect(token string) error {
+	if next := e.next(); next != token {
+		return fmt.Errorf("dynamotest: expected %q, found %q", token, next)
+	}
+	return nil
+}
+
+func (e *expression) peek() string {
+	if e.pos >= len(e.tokens) {
+		return ""
+	}
+	return e.tokens[e.pos]
+}
+
+func (e *expression) next() string {
+	token := e.peek()
+	e.pos++
+	return token
+}
+
+func compare(left, right types.AttributeValue, operator string) (bool, error) {
+	if left == nil || right == nil {
+		// A comparison with a missing attribute is false, whatever the operator
+		return false, nil
+	}
+
+	order, comparable := compareValues(left, right)
+	switch operator {
+	case "=":
+		return comparable && order == 0 || !comparable && reflect.DeepEqual(left, right), nil
+	case "<>":
+		return !(comparable && order == 0 || !comparable && reflect.DeepEqual(left, right)), nil
+	case "<":
+		return comparable && order < 0, nil
+	case "<=":
+		return comparable && order <= 0, nil
+	case ">":
+		return comparable && order > 0, nil
+	case ">=":
+		return comparable && order >= 0, nil
+	default:
+		return false, fmt.Errorf("dynamotest: unsupported comparator %q", operator)
+	}
+}
+
+// compareValues orders two numbers or two strings
+func compareValues(left, right types.AttributeValue) (int, bool) {
+	switch l := left.(type) {
+	case *types.AttributeValueMemberN:
+		r, ok := right.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		lNumber, lOk := new(big.Float).SetString(l.Value)
+		rNumber, rOk := new(big.Float).SetString(r.Value)
+		if !lOk || !rOk {
+			return 0, false
+		}
+		return lNumber.Cmp(rNumber), true
+	case *types.AttributeValueMemberS:
+		r, ok := right.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(l.Value, r.Value), true
+	default:
+		return 0, false
+	}
+}
+
+func arithmetic(left, right types.AttributeValue, operator string) (types.AttributeValue, error) {
+	l, lOk := left.(*types.AttributeValueMemberN)
+	r, rOk := right.(*types.AttributeValueMemberN)
+	if !lOk || !rOk {
+		return nil, fmt.Errorf("dynamotest: %s of a value that isn't a number", operator)
+	}
+	lNumber, _ := new(big.Float).SetString(l.Value)
+	rNumber, _ := new(big.Float).SetString(r.Value)
+	if lNumber == nil || rNumber == nil {
+		return nil, fmt.Errorf("dynamotest: invalid number in %s", operator)
+	}
+	if operator == "-" {
+		rNumber.Neg(rNumber)
+	}
+	return &types.AttributeValueMemberN{Value: new(big.Float).Add(lNumber, rNumber).Text('f', -1)}, nil
+}
+
+func tokenize(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("(),=+-", r):
+			tokens = append(tokens, string(r))
+			i++
+		case r == '<' || r == '>':
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+				continue
+			}
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			start := i
+			for i < len(runes) && isNameRune(runes[i]) {
+				i++
+			}
+			if i == start {
+				tokens = append(tokens, string(r))
+				i++
+				continue
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	return tokens
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '#' || r == ':' || r == '.'
+}
+
+func isNameToken(token string) bool {
+	for _, r := range token {
+		if !isNameRune(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package dynamotest
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":        &types.AttributeValueMemberN{Value: "10"},
+		"name":      &types.AttributeValueMemberS{Value: "John Doe"},
+		"version":   &types.AttributeValueMemberN{Value: "2"},
+		"completed": &types.AttributeValueMemberBOOL{Value: false},
+	}
+	names := map[string]string{"#name": "name", "#version": "version"}
+	values := map[string]types.AttributeValue{
+		":version": &types.AttributeValueMemberN{Value: "2"},
+		":nine":    &types.AttributeValueMemberN{Value: "9"},
+		":prefix":  &types.AttributeValueMemberS{Value: "John"},
+		":false":   &types.AttributeValueMemberBOOL{Value: false},
+	}
+
+	tests := []struct {
+		condition string
+		item      map[string]types.AttributeValue
+		want      bool
+	}{
+		{condition: "attribute_exists(id) AND #version = :version", item: item, want: true},
+		{condition: "attribute_exists(id) AND #version = :version", item: nil, want: false},
+		{condition: "attribute_not_exists(id)", item: nil, want: true},
+		{condition: "attribute_exists(id) AND (attribute_not_exists(#version) OR #version = :nine)", item: item, want: false},
+		{condition: "id > :nine AND begins_with(#name, :prefix)", item: item, want: true},
+		{condition: "id <= :nine", item: item, want: false},
+		{condition: "NOT #version <> :version", item: item, want: true},
+		{condition: "completed = :false", item: item, want: true},
+		{condition: "missing = :false", item: item, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			got, err := evaluateCondition(aws.String(tt.condition), names, values, tt.item)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluateCondition_Invalid(t *testing.T) {
+	for _, condition := range []string{"id = :missing", "id = ", "attribute_exists(id"} {
+		t.Run(condition, func(t *testing.T) {
+			_, err := evaluateCondition(aws.String(condition), nil, nil, map[string]types.AttributeValue{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberN{Value: "1"},
+		"version": &types.AttributeValueMemberN{Value: "1"},
+		"note":    &types.AttributeValueMemberS{Value: "remove me"},
+	}
+	values := map[string]types.AttributeValue{
+		":one":   &types.AttributeValueMemberN{Value: "1"},
+		":name":  &types.AttributeValueMemberS{Value: "Jane"},
+		":empty": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		":entry": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+	}
+
+	err := applyUpdate("SET #name = if_not_exists(#name, :name), #version = #version + :one, "+
+		"history = list_append(if_not_exists(history, :empty), :entry) REMOVE note",
+		map[string]string{"#name": "name", "#version": "version"}, values, item)
+
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "Jane"}, item["name"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, item["version"])
+	assert.Equal(t, values[":entry"], item["history"])
+	assert.NotContains(t, item, "note")
+}
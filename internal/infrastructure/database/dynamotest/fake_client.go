@@ -0,0 +1,570 @@
+// Package dynamotest provides an in-memory DynamoDB item client for unit tests
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+// Operations of the client, used to inject faults and read the recorded calls
+const (
+	OperationGetItem            = "GetItem"
+	OperationBatchGetItem       = "BatchGetItem"
+	OperationQuery              = "Query"
+	OperationScan               = "Scan"
+	OperationPutItem            = "PutItem"
+	OperationUpdateItem         = "UpdateItem"
+	OperationDeleteItem         = "DeleteItem"
+	OperationBatchWriteItem     = "BatchWriteItem"
+	OperationTransactWriteItems = "TransactWriteItems"
+)
+
+const defaultHashKey = "id"
+
+// Most requests DynamoDB accepts in a BatchGetItem and a BatchWriteItem call
+const (
+	maxBatchGetKeys       = 100
+	maxBatchWriteRequests = 25
+)
+
+var _ database.DynamoClient = (*FakeClient)(nil)
+
+// Call is an operation received by the client, with its input
+type Call struct {
+	Operation string
+	Input     any
+}
+
+// FakeClient keeps the items of each table in memory, in insertion order, and evaluates the condition,
+// filter, key condition and update expressions of the requests. Queries on an index are answered by
+// evaluating the key condition on every item of the table. Every call is recorded, and the injected
+// faults are returned before the request is looked at
+type FakeClient struct {
+	mu       sync.Mutex
+	hashKeys map[string]string
+	tables   map[string][]map[string]types.AttributeValue
+	calls    []Call
+	next     map[string][]error
+	always   map[string]error
+	batch    int
+}
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		hashKeys: make(map[string]string),
+		tables:   make(map[string][]map[string]types.AttributeValue),
+		next:     make(map[string][]error),
+		always:   make(map[string]error),
+	}
+}
+
+// SetHashKey sets the hash key attribute of a table, which is id by default
+func (c *FakeClient) SetHashKey(table, attribute string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hashKeys[table] = attribute
+}
+
+// FailNext makes the next calls of an operation fail with errs, one call per error
+func (c *FakeClient) FailNext(operation string, errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next[operation] = append(c.next[operation], errs...)
+}
+
+// FailAlways makes every call of an operation fail with err, until it's called with a nil err
+func (c *FakeClient) FailAlways(operation string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.always, operation)
+		return
+	}
+	c.always[operation] = err
+}
+
+// LimitBatch makes each BatchGetItem and BatchWriteItem call process at most n requests, returning the others
+// as unprocessed like DynamoDB does when a batch exceeds the response size or the provisioned throughput.
+// A limit of 0 or less removes the limit
+func (c *FakeClient) LimitBatch(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batch = n
+}
+
+// Calls returns the calls received so far, in order
+func (c *FakeClient) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallsOf returns the calls of an operation received so far, in order
+func (c *FakeClient) CallsOf(operation string) []Call {
+	calls := make([]Call, 0)
+	for _, call := range c.Calls() {
+		if call.Operation == operation {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Put stores an item as is, without recording a call, to seed the tables
+func (c *FakeClient) Put(table string, item map[string]types.AttributeValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(table, item)
+}
+
+// Items returns a copy of the items of a table, in insertion order
+func (c *FakeClient) Items(table string) []map[string]types.AttributeValue {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items := make([]map[string]types.AttributeValue, 0, len(c.tables[table]))
+	for _, item := range c.tables[table] {
+		items = append(items, copyItem(item))
+	}
+	return items
+}
+
+func (c *FakeClient) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationGetItem, params); err != nil {
+		return nil, err
+	}
+
+	table := aws.ToString(params.TableName)
+	_, item := c.find(table, params.Key)
+	return &dynamodb.GetItemOutput{Item: copyItem(item)}, nil
+}
+
+// BatchGetItem reads the keys of each table, in request order, and rejects more than 100 keys like DynamoDB
+func (c *FakeClient) BatchGetItem(_ context.Context, params *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationBatchGetItem, params); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, keys := range params.RequestItems {
+		total += len(keys.Keys)
+	}
+	if total == 0 || total > maxBatchGetKeys {
+		return nil, &smithy.GenericAPIError{Code: "ValidationException", Message: "Too many items requested for the BatchGetItem call"}
+	}
+
+	output := &dynamodb.BatchGetItemOutput{
+		Responses:       make(map[string][]map[string]types.AttributeValue),
+		UnprocessedKeys: make(map[string]types.KeysAndAttributes),
+	}
+	read := 0
+	for table, keys := range params.RequestItems {
+		for i, key := range keys.Keys {
+			if c.batch > 0 && read >= c.batch {
+				unprocessed := keys
+				unprocessed.Keys = keys.Keys[i:]
+				output.UnprocessedKeys[table] = unprocessed
+				break
+			}
+			read++
+			if _, item := c.find(table, key); item != nil {
+				output.Responses[table] = append(output.Responses[table], copyItem(item))
+			}
+		}
+	}
+	return output, nil
+}
+
+func (c *FakeClient) Query(_ context.Context, params *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationQuery, params); err != nil {
+		return nil, err
+	}
+
+	items, lastKey, scanned, err := c.read(aws.ToString(params.TableName), params.ExclusiveStartKey, params.Limit,
+		func(item map[string]types.AttributeValue) (bool, error) {
+			return evaluateCondition(params.KeyConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues, item)
+		},
+		func(item map[string]types.AttributeValue) (bool, error) {
+			return evaluateCondition(params.FilterExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues, item)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.QueryOutput{Items: items, Count: int32(len(items)), ScannedCount: int32(scanned), LastEvaluatedKey: lastKey}, nil
+}
+
+func (c *FakeClient) Scan(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationScan, params); err != nil {
+		return nil, err
+	}
+
+	table := aws.ToString(params.TableName)
+	var selects func(map[string]types.AttributeValue) (bool, error)
+	if params.TotalSegments != nil {
+		segment, total := aws.ToInt32(params.Segment), aws.ToInt32(params.TotalSegments)
+		if params.Segment == nil || total < 1 || segment < 0 || segment >= total {
+			return nil, &smithy.GenericAPIError{Code: "ValidationException", Message: "Segment must be less than TotalSegments"}
+		}
+		selects = func(item map[string]types.AttributeValue) (bool, error) {
+			return segmentOf(keyString(item[c.hashKey(table)]), total) == segment, nil
+		}
+	}
+
+	items, lastKey, scanned, err := c.read(table, params.ExclusiveStartKey, params.Limit, selects,
+		func(item map[string]types.AttributeValue) (bool, error) {
+			return evaluateCondition(params.FilterExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues, item)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.ScanOutput{Items: items, Count: int32(len(items)), ScannedCount: int32(scanned), LastEvaluatedKey: lastKey}, nil
+}
+
+func (c *FakeClient) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationPutItem, params); err != nil {
+		return nil, err
+	}
+
+	table := aws.ToString(params.TableName)
+	_, current := c.find(table, c.keyOf(table, params.Item))
+	if err := c.check(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues,
+		current, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	c.put(table, params.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *FakeClient) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationUpdateItem, params); err != nil {
+		return nil, err
+	}
+
+	table := aws.ToString(params.TableName)
+	_, current := c.find(table, params.Key)
+	if err := c.check(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues,
+		current, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	updated, err := c.update(table, params.Key, current, aws.ToString(params.UpdateExpression), params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.UpdateItemOutput{}
+	switch params.ReturnValues {
+	case types.ReturnValueAllNew:
+		output.Attributes = copyItem(updated)
+	case types.ReturnValueAllOld:
+		output.Attributes = copyItem(current)
+	}
+	return output, nil
+}
+
+func (c *FakeClient) DeleteItem(_ context.Context, params *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationDeleteItem, params); err != nil {
+		return nil, err
+	}
+
+	table := aws.ToString(params.TableName)
+	_, current := c.find(table, params.Key)
+	if err := c.check(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues,
+		current, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	c.delete(table, params.Key)
+	output := &dynamodb.DeleteItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		output.Attributes = copyItem(current)
+	}
+	return output, nil
+}
+
+// BatchWriteItem puts and deletes the items of each table, in request order, and rejects more than 25 requests
+// like DynamoDB
+func (c *FakeClient) BatchWriteItem(_ context.Context, params *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationBatchWriteItem, params); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, requests := range params.RequestItems {
+		total += len(requests)
+	}
+	if total == 0 || total > maxBatchWriteRequests {
+		return nil, &smithy.GenericAPIError{Code: "ValidationException", Message: "Too many items requested for the BatchWriteItem call"}
+	}
+
+	output := &dynamodb.BatchWriteItemOutput{UnprocessedItems: make(map[string][]types.WriteRequest)}
+	written := 0
+	for table, requests := range params.RequestItems {
+		for i, request := range requests {
+			if c.batch > 0 && written >= c.batch {
+				output.UnprocessedItems[table] = requests[i:]
+				break
+			}
+			written++
+			switch {
+			case request.PutRequest != nil:
+				c.put(table, request.PutRequest.Item)
+			case request.DeleteRequest != nil:
+				c.delete(table, request.DeleteRequest.Key)
+			}
+		}
+	}
+	return output, nil
+}
+
+// TransactWriteItems checks the conditions of every action before applying any of them. When a condition
+// fails, nothing is written and a TransactionCanceledException gives the reason of each action
+func (c *FakeClient) TransactWriteItems(_ context.Context, params *dynamodb.TransactWriteItemsInput, _ ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.record(OperationTransactWriteItems, params); err != nil {
+		return nil, err
+	}
+
+	reasons := make([]types.CancellationReason, len(params.TransactItems))
+	canceled := false
+	for i, action := range params.TransactItems {
+		reasons[i] = types.CancellationReason{Code: aws.String("None")}
+
+		table, key, condition, names, values, returnValues, err := c.describe(action)
+		if err != nil {
+			return nil, err
+		}
+		_, current := c.find(table, key)
+		if err := c.check(condition, names, values, current, returnValues); err != nil {
+			var conditionFailed *types.ConditionalCheckFailedException
+			if !errors.As(err, &conditionFailed) {
+				return nil, err
+			}
+			reasons[i] = types.CancellationReason{Code: aws.String("ConditionalCheckFailed"), Item: conditionFailed.Item}
+			canceled = true
+		}
+	}
+	if canceled {
+		return nil, &types.TransactionCanceledException{
+			Message:             aws.String("Transaction cancelled, please refer cancellation reasons for specific reasons"),
+			CancellationReasons: reasons,
+		}
+	}
+
+	for _, action := range params.TransactItems {
+		switch {
+		case action.Put != nil:
+			c.put(aws.ToString(action.Put.TableName), action.Put.Item)
+		case action.Update != nil:
+			table := aws.ToString(action.Update.TableName)
+			_, current := c.find(table, action.Update.Key)
+			if _, err := c.update(table, action.Update.Key, current, aws.ToString(action.Update.UpdateExpression),
+				action.Update.ExpressionAttributeNames, action.Update.ExpressionAttributeValues); err != nil {
+				return nil, err
+			}
+		case action.Delete != nil:
+			c.delete(aws.ToString(action.Delete.TableName), action.Delete.Key)
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// record keeps the call and returns the fault injected for it, if any
+func (c *FakeClient) record(operation string, input any) error {
+	c.calls = append(c.calls, Call{Operation: operation, Input: input})
+
+	if errs := c.next[operation]; len(errs) > 0 {
+		c.next[operation] = errs[1:]
+		return errs[0]
+	}
+	return c.always[operation]
+}
+
+// describe returns the table, key and condition of a transaction action
+func (c *FakeClient) describe(action types.TransactWriteItem) (string, map[string]types.AttributeValue, *string, map[string]string, map[string]types.AttributeValue, types.ReturnValuesOnConditionCheckFailure, error) {
+	switch {
+	case action.Put != nil:
+		table := aws.ToString(action.Put.TableName)
+		return table, c.keyOf(table, action.Put.Item), action.Put.ConditionExpression, action.Put.ExpressionAttributeNames,
+			action.Put.ExpressionAttributeValues, action.Put.ReturnValuesOnConditionCheckFailure, nil
+	case action.Update != nil:
+		return aws.ToString(action.Update.TableName), action.Update.Key, action.Update.ConditionExpression, action.Update.ExpressionAttributeNames,
+			action.Update.ExpressionAttributeValues, action.Update.ReturnValuesOnConditionCheckFailure, nil
+	case action.Delete != nil:
+		return aws.ToString(action.Delete.TableName), action.Delete.Key, action.Delete.ConditionExpression, action.Delete.ExpressionAttributeNames,
+			action.Delete.ExpressionAttributeValues, action.Delete.ReturnValuesOnConditionCheckFailure, nil
+	case action.ConditionCheck != nil:
+		return aws.ToString(action.ConditionCheck.TableName), action.ConditionCheck.Key, action.ConditionCheck.ConditionExpression,
+			action.ConditionCheck.ExpressionAttributeNames, action.ConditionCheck.ExpressionAttributeValues,
+			action.ConditionCheck.ReturnValuesOnConditionCheckFailure, nil
+	default:
+		return "", nil, nil, nil, nil, "", errors.New("dynamotest: empty transaction action")
+	}
+}
+
+// check evaluates a condition on the current item, failing like DynamoDB does
+func (c *FakeClient) check(condition *string, names map[string]string, values map[string]types.AttributeValue, current map[string]types.AttributeValue, returnValues types.ReturnValuesOnConditionCheckFailure) error {
+	matched, err := evaluateCondition(condition, names, values, current)
+	if err != nil {
+		return err
+	}
+	if matched {
+		return nil
+	}
+
+	conditionFailed := &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	if returnValues == types.ReturnValuesOnConditionCheckFailureAllOld && current != nil {
+		conditionFailed.Item = copyItem(current)
+	}
+	return conditionFailed
+}
+
+// read returns the items of a table selected by a key condition, nil for every item, and matching a filter.
+// Like DynamoDB, it starts after the start key and evaluates up to limit selected items, returning the key
+// of the last one evaluated when more items remain
+func (c *FakeClient) read(table string, startKey map[string]types.AttributeValue, limit *int32, selects, matches func(map[string]types.AttributeValue) (bool, error)) ([]map[string]types.AttributeValue, map[string]types.AttributeValue, int, error) {
+	stored := c.tables[table]
+	start := 0
+	if startKey != nil {
+		index, _ := c.find(table, startKey)
+		start = index + 1
+	}
+
+	items := make([]map[string]types.AttributeValue, 0)
+	evaluated := 0
+	var last map[string]types.AttributeValue
+	for i := start; i < len(stored); i++ {
+		if selects != nil {
+			selected, err := selects(stored[i])
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if !selected {
+				continue
+			}
+		}
+		if limit != nil && evaluated >= int(*limit) {
+			return items, c.keyOf(table, last), evaluated, nil
+		}
+		evaluated++
+		last = stored[i]
+
+		matched, err := matches(stored[i])
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if matched {
+			items = append(items, copyItem(stored[i]))
+		}
+	}
+	return items, nil, evaluated, nil
+}
+
+func (c *FakeClient) update(table string, key, current map[string]types.AttributeValue, expression string, names map[string]string, values map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	updated := copyItem(current)
+	if updated == nil {
+		updated = copyItem(key)
+	}
+	if err := applyUpdate(expression, names, values, updated); err != nil {
+		return nil, err
+	}
+	c.put(table, updated)
+	return updated, nil
+}
+
+func (c *FakeClient) put(table string, item map[string]types.AttributeValue) {
+	index, _ := c.find(table, c.keyOf(table, item))
+	if index >= 0 {
+		c.tables[table][index] = copyItem(item)
+		return
+	}
+	c.tables[table] = append(c.tables[table], copyItem(item))
+}
+
+func (c *FakeClient) delete(table string, key map[string]types.AttributeValue) {
+	index, _ := c.find(table, key)
+	if index >= 0 {
+		c.tables[table] = append(c.tables[table][:index], c.tables[table][index+1:]...)
+	}
+}
+
+// find returns the position and the item of a table with a key, or -1 and nil
+func (c *FakeClient) find(table string, key map[string]types.AttributeValue) (int, map[string]types.AttributeValue) {
+	want := keyString(key[c.hashKey(table)])
+	for i, item := range c.tables[table] {
+		if keyString(item[c.hashKey(table)]) == want {
+			return i, item
+		}
+	}
+	return -1, nil
+}
+
+func (c *FakeClient) keyOf(table string, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{c.hashKey(table): item[c.hashKey(table)]}
+}
+
+func (c *FakeClient) hashKey(table string) string {
+	if key, ok := c.hashKeys[table]; ok {
+		return key
+	}
+	return defaultHashKey
+}
+
+func keyString(value types.AttributeValue) string {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberN:
+		return "N:" + v.Value
+	case *types.AttributeValueMemberS:
+		return "S:" + v.Value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+// segmentOf spreads the keys over the segments of a parallel scan, as DynamoDB does with the hash of
+// the partition key
+func segmentOf(key string, total int32) int32 {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return int32(hash.Sum32() % uint32(total))
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	copied := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		copied[name] = value
+	}
+	return copied
+}
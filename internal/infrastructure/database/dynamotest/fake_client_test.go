@@ -0,0 +1,158 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testItem(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"id": &types.AttributeValueMemberN{Value: id}}
+}
+
+func TestFakeClient_Faults(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeClient()
+	first, second := errors.New("first"), errors.New("second")
+	client.FailNext(OperationGetItem, first, second)
+
+	input := &dynamodb.GetItemInput{TableName: aws.String("customers"), Key: testItem("1")}
+	_, err := client.GetItem(ctx, input)
+	assert.ErrorIs(t, err, first)
+	_, err = client.GetItem(ctx, input)
+	assert.ErrorIs(t, err, second)
+	_, err = client.GetItem(ctx, input)
+	assert.NoError(t, err)
+
+	require.Len(t, client.Calls(), 3)
+	assert.Same(t, input, client.Calls()[0].Input)
+}
+
+func TestFakeClient_ScanPages(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeClient()
+	for _, id := range []string{"1", "2", "3"} {
+		client.Put("customers", testItem(id))
+	}
+
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{TableName: aws.String("customers"), Limit: aws.Int32(2)})
+	var ids []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		require.NoError(t, err)
+		for _, item := range page.Items {
+			ids = append(ids, item["id"].(*types.AttributeValueMemberN).Value)
+		}
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.Len(t, client.CallsOf(OperationScan), 2)
+}
+
+func TestFakeClient_ScanSegments(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeClient()
+	for id := 1; id <= 20; id++ {
+		client.Put("customers", testItem(strconv.Itoa(id)))
+	}
+
+	// Every item is returned by exactly one segment
+	seen := make(map[string]int)
+	for segment := int32(0); segment < 3; segment++ {
+		output, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("customers"), Segment: aws.Int32(segment), TotalSegments: aws.Int32(3)})
+		require.NoError(t, err)
+		assert.Less(t, len(output.Items), 20, "a segment must not return every item")
+		for _, item := range output.Items {
+			seen[item["id"].(*types.AttributeValueMemberN).Value]++
+		}
+	}
+	assert.Len(t, seen, 20)
+	for id, count := range seen {
+		assert.Equal(t, 1, count, "item %s", id)
+	}
+
+	_, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("customers"), Segment: aws.Int32(3), TotalSegments: aws.Int32(3)})
+	assert.Error(t, err)
+}
+
+func TestFakeClient_BatchGetItem(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeClient()
+	for _, id := range []string{"1", "2", "3"} {
+		client.Put("customers", testItem(id))
+	}
+	client.LimitBatch(2)
+
+	output, err := client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]types.KeysAndAttributes{
+			"customers": {Keys: []map[string]types.AttributeValue{testItem("3"), testItem("9"), testItem("1")}},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]types.AttributeValue{testItem("3")}, output.Responses["customers"])
+	assert.Equal(t, []map[string]types.AttributeValue{testItem("1")}, output.UnprocessedKeys["customers"].Keys)
+
+	keys := make([]map[string]types.AttributeValue, 101)
+	for i := range keys {
+		keys[i] = testItem("1")
+	}
+	_, err = client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]types.KeysAndAttributes{"customers": {Keys: keys}},
+	})
+	assert.Error(t, err, "a batch of more than 100 keys must be rejected")
+}
+
+func TestFakeClient_BatchWriteItem(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeClient()
+	client.Put("customers", testItem("9"))
+	client.LimitBatch(2)
+
+	output, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{
+			"customers": {
+				{PutRequest: &types.PutRequest{Item: testItem("1")}},
+				{DeleteRequest: &types.DeleteRequest{Key: testItem("9")}},
+				{PutRequest: &types.PutRequest{Item: testItem("2")}},
+			},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]types.AttributeValue{testItem("1")}, client.Items("customers"))
+	require.Len(t, output.UnprocessedItems["customers"], 1)
+	assert.Equal(t, testItem("2"), output.UnprocessedItems["customers"][0].PutRequest.Item)
+}
+
+func TestFakeClient_TransactWriteItems(t *testing.T) {
+	ctx := context.Background()
+	client := NewFakeClient()
+	client.Put("customers", testItem("1"))
+
+	_, err := client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String("customers"), Item: testItem("2")}},
+		{Put: &types.Put{TableName: aws.String("customers"), Item: testItem("1"), ConditionExpression: aws.String("attribute_not_exists(id)")}},
+	}})
+
+	var canceled *types.TransactionCanceledException
+	require.ErrorAs(t, err, &canceled)
+	assert.Equal(t, "None", aws.ToString(canceled.CancellationReasons[0].Code))
+	assert.Equal(t, "ConditionalCheckFailed", aws.ToString(canceled.CancellationReasons[1].Code))
+	assert.Len(t, client.Items("customers"), 1, "a canceled transaction must not write anything")
+
+	_, err = client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String("customers"), Item: testItem("2")}},
+		{Delete: &types.Delete{TableName: aws.String("customers"), Key: testItem("1")}},
+	}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]types.AttributeValue{testItem("2")}, client.Items("customers"))
+}
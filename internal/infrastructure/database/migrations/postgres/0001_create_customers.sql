@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS customers (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    email      TEXT        NOT NULL,
+    cpf        TEXT        NOT NULL,
+    version    INTEGER     NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT customers_cpf_key UNIQUE (cpf),
+    CONSTRAINT customers_email_key UNIQUE (email)
+);
+
+CREATE INDEX IF NOT EXISTS customers_name_idx ON customers (name);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS customers_name_search_idx ON customers USING GIN (to_tsvector('simple', name));
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS customer_audit (
+    id          UUID PRIMARY KEY,
+    customer_id BIGINT      NOT NULL,
+    action      TEXT        NOT NULL,
+    actor       TEXT        NOT NULL DEFAULT '',
+    actor_role  TEXT        NOT NULL DEFAULT '',
+    request_id  TEXT        NOT NULL DEFAULT '',
+    changes     JSONB       NOT NULL DEFAULT '[]',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_audit_customer_id_idx ON customer_audit (customer_id, occurred_at);
+
+-- The audit entries are only appended, they can't be changed nor removed
+CREATE OR REPLACE FUNCTION customer_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'customer_audit entries can''t be changed nor removed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER customer_audit_append_only
+    BEFORE UPDATE OR DELETE ON customer_audit
+    FOR EACH ROW EXECUTE FUNCTION customer_audit_append_only();
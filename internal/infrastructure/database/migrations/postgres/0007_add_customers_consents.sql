@@ -0,0 +1 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS consents JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,11 @@
+-- The audit entries still can't be removed, but their changes can be rewritten to erase the personal
+-- data they recorded when their customer is anonymized. Every other column stays as it was appended
+CREATE OR REPLACE FUNCTION customer_audit_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND (NEW.id, NEW.customer_id, NEW.action, NEW.actor, NEW.actor_role, NEW.request_id, NEW.occurred_at)
+        IS NOT DISTINCT FROM (OLD.id, OLD.customer_id, OLD.action, OLD.actor, OLD.actor_role, OLD.request_id, OLD.occurred_at) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'customer_audit entries can''t be changed nor removed';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+-- Lets the purge of the deleted customers find them without reading the whole table
+CREATE INDEX IF NOT EXISTS customers_deleted_at_idx ON customers (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Keeps the emails unique regardless of their case. The service stores them in lowercase, and the emails stored
+-- before are lowercased first. The index keeps the name of the constraint it replaces, which the conflicts report
+UPDATE customers SET email = lower(trim(email)) WHERE email <> lower(trim(email));
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_key ON customers (lower(email));
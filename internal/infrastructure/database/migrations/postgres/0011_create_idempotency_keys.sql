@@ -0,0 +1,16 @@
+-- The responses stored for the Idempotency-Key header. PostgreSQL has no TTL, so an expired key is taken over
+-- by the next reservation, and the scheduled purge-deleted command removes the others
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) PRIMARY KEY,
+    request_hash    TEXT        NOT NULL,
+    lease_id        TEXT        NOT NULL DEFAULT '',
+    completed       BOOLEAN     NOT NULL DEFAULT FALSE,
+    status_code     INTEGER     NOT NULL DEFAULT 0,
+    headers         JSONB       NOT NULL DEFAULT '{}',
+    body            TEXT        NOT NULL DEFAULT '',
+    customer_id     BIGINT      NOT NULL DEFAULT 0,
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_customer_id_idx ON idempotency_keys (customer_id);
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// MongoDB collections and indexes of the customer service
+const (
+	MongoCustomersCollection   = "customers"
+	MongoCountersCollection    = "counters"
+	MongoAuditCollection       = "customer_audit"
+	MongoIdempotencyCollection = "idempotency_keys"
+
+	MongoCustomersCPFIndex          = "customers_cpf_key"
+	MongoCustomersEmailIndex        = "customers_email_key"
+	MongoCustomersNameSearchIndex   = "customers_name_text"
+	MongoCustomersDeletedTTLIndex   = "customers_deleted_at_ttl"
+	MongoAuditCustomerIndex         = "customer_audit_customer_id"
+	MongoIdempotencyCustomerIndex   = "idempotency_keys_customer_id"
+	MongoIdempotencyExpiresTTLIndex = "idempotency_keys_expires_at_ttl"
+)
+
+type MongoDatabase struct {
+	Client   *mongo.Client
+	Database *mongo.Database
+	// DeletedRetention is how long the deleted customers are kept before the TTL index purges them.
+	// Zero keeps them
+	DeletedRetention time.Duration
+	logger           *logger.Logger
+}
+
+func NewMongoConnection(cfg *config.Config, l *logger.Logger) (*MongoDatabase, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MongoTimeout)
+	defer cancel()
+
+	clientOptions := options.Client().
+		ApplyURI(cfg.MongoURI).
+		SetMaxPoolSize(uint64(cfg.MongoMaxPoolSize)).
+		SetMinPoolSize(uint64(cfg.MongoMinPoolSize)).
+		SetTimeout(cfg.MongoTimeout)
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "Successfully connected to MongoDB", slog.String("database", cfg.MongoDatabase))
+
+	return &MongoDatabase{
+		Client:           client,
+		Database:         client.Database(cfg.MongoDatabase),
+		DeletedRetention: cfg.DeletedCustomerRetention,
+		logger:           l,
+	}, nil
+}
+
+// EnsureIndexes creates the customer indexes: unique CPF and email, a text index on the name
+// without stemming or stop words, so searches match whole words in any language, and a TTL index
+// purging the deleted customers after the retention period, the index of the audit entries by customer, and
+// the indexes of the idempotency keys by customer and purging them once expired.
+// Creating an index that already exists with the same definition is a no-op
+func (d *MongoDatabase) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "cpf", Value: 1}},
+			Options: options.Index().SetName(MongoCustomersCPFIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName(MongoCustomersEmailIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: "text"}},
+			Options: options.Index().SetName(MongoCustomersNameSearchIndex).SetDefaultLanguage("none"),
+		},
+	}
+
+	if _, err := d.Database.Collection(MongoCustomersCollection).Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+	if err := d.ensureDeletedTTLIndex(ctx); err != nil {
+		return err
+	}
+
+	_, err := d.Database.Collection(MongoAuditCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "customer_id", Value: 1}, {Key: "occurred_at", Value: 1}},
+		Options: options.Index().SetName(MongoAuditCustomerIndex),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = d.Database.Collection(MongoIdempotencyCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "customer_id", Value: 1}},
+			Options: options.Index().SetName(MongoIdempotencyCustomerIndex),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName(MongoIdempotencyExpiresTTLIndex).SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+// ensureDeletedTTLIndex makes the TTL index of the deleted customers follow the retention: it's created,
+// changed in place with collMod when the retention changed, or dropped when the retention is zero
+func (d *MongoDatabase) ensureDeletedTTLIndex(ctx context.Context) error {
+	indexes := d.Database.Collection(MongoCustomersCollection).Indexes()
+	specifications, err := indexes.ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	var existing *mongo.IndexSpecification
+	for _, specification := range specifications {
+		if specification.Name == MongoCustomersDeletedTTLIndex {
+			existing = specification
+		}
+	}
+
+	seconds := int32(d.DeletedRetention / time.Second)
+	switch {
+	case existing == nil && seconds <= 0:
+		return nil
+	case existing == nil:
+		_, err = indexes.CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetName(MongoCustomersDeletedTTLIndex).SetExpireAfterSeconds(seconds),
+		})
+		return err
+	case seconds <= 0:
+		_, err = indexes.DropOne(ctx, MongoCustomersDeletedTTLIndex)
+		return err
+	case existing.ExpireAfterSeconds == nil || *existing.ExpireAfterSeconds != seconds:
+		return d.Database.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: MongoCustomersCollection},
+			{Key: "index", Value: bson.D{
+				{Key: "name", Value: MongoCustomersDeletedTTLIndex},
+				{Key: "expireAfterSeconds", Value: seconds},
+			}},
+		}).Err()
+	default:
+		return nil
+	}
+}
+
+func (d *MongoDatabase) Close(ctx context.Context) error {
+	d.logger.InfoContext(ctx, "Closing MongoDB connection")
+	return d.Client.Disconnect(ctx)
+}
+
+func (d *MongoDatabase) Ping(ctx context.Context) error {
+	return d.Client.Ping(ctx, nil)
+}
+
+// LogOperation logs MongoDB operations for debugging
+func (d *MongoDatabase) LogOperation(ctx context.Context, operation, collection string, duration time.Duration, err error) {
+	if err != nil {
+		d.logger.ErrorContext(ctx, "MongoDB operation failed",
+			slog.String("operation", operation),
+			slog.String("collection", collection),
+			slog.Duration("duration", duration),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	d.logger.DebugContext(ctx, "MongoDB operation completed",
+		slog.String("operation", operation),
+		slog.String("collection", collection),
+		slog.Duration("duration", duration),
+	)
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx database/sql driver
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+// postgresMigrationLock is the advisory lock held while migrating, so concurrent cold starts
+// don't apply the same migration twice
+const postgresMigrationLock = 7410432
+
+type PostgresDatabase struct {
+	DB *sql.DB
+	// DeletedRetention is how long the deleted customers are kept before PurgeDeleted removes them.
+	// Zero keeps them
+	DeletedRetention time.Duration
+	logger           *logger.Logger
+}
+
+func NewPostgresConnection(cfg *config.Config, l *logger.Logger) (*PostgresDatabase, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("pgx", cfg.PostgresURL)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.PostgresMaxOpenConns)
+	db.SetMaxIdleConns(cfg.PostgresMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.PostgresConnMaxLifetime)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	l.InfoContext(ctx, "Successfully connected to PostgreSQL")
+
+	return &PostgresDatabase{
+		DB:               db,
+		DeletedRetention: cfg.DeletedCustomerRetention,
+		logger:           l,
+	}, nil
+}
+
+// Migrate applies the embedded SQL migrations that weren't applied yet, in file name order.
+// Each migration runs in its own transaction and is recorded in the schema_migrations table
+func (d *PostgresDatabase) Migrate(ctx context.Context) error {
+	conn, err := d.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", postgresMigrationLock); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", postgresMigrationLock)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return err
+	}
+
+	files, err := fs.Glob(postgresMigrations, "migrations/postgres/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := file[len("migrations/postgres/"):]
+
+		var applied bool
+		err := conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		script, err := postgresMigrations.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := applyPostgresMigration(ctx, conn, version, string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", version, err)
+		}
+		d.logger.InfoContext(ctx, "Applied PostgreSQL migration", slog.String("version", version))
+	}
+
+	return nil
+}
+
+func applyPostgresMigration(ctx context.Context, conn *sql.Conn, version, script string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PurgeDeleted removes the customers deleted more than the retention period ago, standing in for the TTL
+// of DynamoDB and MongoDB, and returns how many were removed. It's run by the purge-deleted command
+func (d *PostgresDatabase) PurgeDeleted(ctx context.Context) (int64, error) {
+	if d.DeletedRetention <= 0 {
+		return 0, nil
+	}
+
+	result, err := d.DB.ExecContext(ctx,
+		"DELETE FROM customers WHERE deleted_at < now() - make_interval(secs => $1)",
+		d.DeletedRetention.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		d.logger.InfoContext(ctx, "Purged deleted PostgreSQL customers", slog.Int64("customers", purged))
+	}
+	return purged, nil
+}
+
+// PurgeExpiredIdempotencyKeys removes the expired idempotency keys, standing in for the TTL of DynamoDB and
+// MongoDB, and returns how many were removed. It's run by the purge-deleted command
+func (d *PostgresDatabase) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := d.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= now()")
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		d.logger.InfoContext(ctx, "Purged expired PostgreSQL idempotency keys", slog.Int64("keys", purged))
+	}
+	return purged, nil
+}
+
+func (d *PostgresDatabase) Close(ctx context.Context) error {
+	d.logger.InfoContext(ctx, "Closing PostgreSQL connection")
+	return d.DB.Close()
+}
+
+func (d *PostgresDatabase) Ping(ctx context.Context) error {
+	return d.DB.PingContext(ctx)
+}
+
+// LogOperation logs PostgreSQL operations for debugging
+func (d *PostgresDatabase) LogOperation(ctx context.Context, operation, table string, duration time.Duration, err error) {
+	if err != nil {
+		d.logger.ErrorContext(ctx, "PostgreSQL operation failed",
+			slog.String("operation", operation),
+			slog.String("table", table),
+			slog.Duration("duration", duration),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	d.logger.DebugContext(ctx, "PostgreSQL operation completed",
+		slog.String("operation", operation),
+		slog.String("table", table),
+		slog.Duration("duration", duration),
+	)
+}
@@ -0,0 +1,41 @@
+package datasource
+
+import (
+	"sort"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+// AuditChangeModel is a change of an audit entry, as stored by every audit data source
+type AuditChangeModel struct {
+	Field  string `json:"field" bson:"field" dynamodbav:"field"`
+	Before string `json:"before" bson:"before" dynamodbav:"before"`
+	After  string `json:"after" bson:"after" dynamodbav:"after"`
+}
+
+func toAuditChangeModels(changes []entity.AuditChange) []AuditChangeModel {
+	models := make([]AuditChangeModel, len(changes))
+	for i, change := range changes {
+		models[i] = AuditChangeModel(change)
+	}
+	return models
+}
+
+func toAuditChanges(models []AuditChangeModel) []entity.AuditChange {
+	changes := make([]entity.AuditChange, len(models))
+	for i, model := range models {
+		changes[i] = entity.AuditChange(model)
+	}
+	return changes
+}
+
+// sortAuditEntries puts the entries of a customer oldest first. Entries appended at the same time keep
+// an arbitrary but stable order
+func sortAuditEntries(entries []*entity.AuditEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].OccurredAt.Equal(entries[j].OccurredAt) {
+			return entries[i].OccurredAt.Before(entries[j].OccurredAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
@@ -0,0 +1,144 @@
+package datasource_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// AuditDataSourceConformanceTestSuite describes the behavior every port.AuditDataSource
+// implementation must have. newDataSource must return an empty data source for each test
+type AuditDataSourceConformanceTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	newDataSource func() port.AuditDataSource
+	dataSource    port.AuditDataSource
+}
+
+func NewAuditDataSourceConformanceTestSuite(newDataSource func() port.AuditDataSource) *AuditDataSourceConformanceTestSuite {
+	return &AuditDataSourceConformanceTestSuite{newDataSource: newDataSource}
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.dataSource = suite.newDataSource()
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) TestAppendAssignsIDAndKeepsFields() {
+	entry := &entity.AuditEntry{
+		CustomerID: 1,
+		Action:     entity.AuditActionUpdate,
+		Actor:      "ops",
+		ActorRole:  entity.RoleAdmin,
+		RequestID:  "req-1",
+		Changes:    []entity.AuditChange{{Field: "email", Before: "old@example.com", After: "new@example.com"}},
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	require.NoError(suite.T(), suite.dataSource.Append(suite.ctx, entry))
+	assert.NotEmpty(suite.T(), entry.ID)
+
+	found, err := suite.dataSource.FindByCustomerID(suite.ctx, 1)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 1)
+	assert.Equal(suite.T(), entry.ID, found[0].ID)
+	assert.Equal(suite.T(), entry.Action, found[0].Action)
+	assert.Equal(suite.T(), entry.Actor, found[0].Actor)
+	assert.Equal(suite.T(), entry.ActorRole, found[0].ActorRole)
+	assert.Equal(suite.T(), entry.RequestID, found[0].RequestID)
+	assert.Equal(suite.T(), entry.Changes, found[0].Changes)
+	assert.True(suite.T(), entry.OccurredAt.Equal(found[0].OccurredAt))
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) TestAppendKeepsAnonymousEntries() {
+	entry := &entity.AuditEntry{CustomerID: 1, Action: entity.AuditActionCreate, Changes: []entity.AuditChange{}, OccurredAt: time.Now()}
+
+	require.NoError(suite.T(), suite.dataSource.Append(suite.ctx, entry))
+
+	found, err := suite.dataSource.FindByCustomerID(suite.ctx, 1)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 1)
+	assert.Empty(suite.T(), found[0].Actor)
+	assert.Empty(suite.T(), found[0].RequestID)
+	assert.Empty(suite.T(), found[0].Changes)
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) TestFindByCustomerIDReturnsOldestFirst() {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	entries := []*entity.AuditEntry{
+		{CustomerID: 1, Action: entity.AuditActionUpdate, OccurredAt: now.Add(time.Minute)},
+		{CustomerID: 2, Action: entity.AuditActionCreate, OccurredAt: now},
+		{CustomerID: 1, Action: entity.AuditActionCreate, OccurredAt: now},
+		{CustomerID: 1, Action: entity.AuditActionDelete, OccurredAt: now.Add(2 * time.Minute)},
+	}
+	for _, entry := range entries {
+		require.NoError(suite.T(), suite.dataSource.Append(suite.ctx, entry))
+	}
+
+	found, err := suite.dataSource.FindByCustomerID(suite.ctx, 1)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 3)
+	assert.Equal(suite.T(), entity.AuditActionCreate, found[0].Action)
+	assert.Equal(suite.T(), entity.AuditActionUpdate, found[1].Action)
+	assert.Equal(suite.T(), entity.AuditActionDelete, found[2].Action)
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) TestFindByCustomerIDWithoutEntries() {
+	found, err := suite.dataSource.FindByCustomerID(suite.ctx, 404)
+
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) TestEraseCustomerErasesPersonalData() {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	personalData := []string{"John Doe", "john@example.com", "new@example.com", "12345678900"}
+	entries := []*entity.AuditEntry{
+		{CustomerID: 1, Action: entity.AuditActionCreate, OccurredAt: now, Changes: []entity.AuditChange{
+			{Field: "name", After: "John Doe"},
+			{Field: "email", After: "john@example.com"},
+			{Field: "cpf", After: "12345678900"},
+			{Field: "status", After: "active"},
+		}},
+		{CustomerID: 1, Action: entity.AuditActionUpdate, OccurredAt: now.Add(time.Minute), Changes: []entity.AuditChange{
+			{Field: "email", Before: "john@example.com", After: "new@example.com"},
+		}},
+		{CustomerID: 2, Action: entity.AuditActionCreate, OccurredAt: now, Changes: []entity.AuditChange{
+			{Field: "name", After: "Jane Doe"},
+		}},
+	}
+	for _, entry := range entries {
+		require.NoError(suite.T(), suite.dataSource.Append(suite.ctx, entry))
+	}
+
+	require.NoError(suite.T(), suite.dataSource.EraseCustomer(suite.ctx, 1))
+
+	found, err := suite.dataSource.FindByCustomerID(suite.ctx, 1)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 2)
+	for _, entry := range found {
+		for _, change := range entry.Changes {
+			assert.NotContains(suite.T(), personalData, change.Before)
+			assert.NotContains(suite.T(), personalData, change.After)
+		}
+	}
+	assert.Equal(suite.T(), entity.AuditChange{Field: "name", After: entity.AuditErasedValue}, found[0].Changes[0])
+	assert.Equal(suite.T(), entity.AuditChange{Field: "status", After: "active"}, found[0].Changes[3])
+	assert.Equal(suite.T(), entries[0].ID, found[0].ID)
+	assert.True(suite.T(), entries[1].OccurredAt.Equal(found[1].OccurredAt))
+
+	other, err := suite.dataSource.FindByCustomerID(suite.ctx, 2)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), other, 1)
+	assert.Equal(suite.T(), "Jane Doe", other[0].Changes[0].After)
+}
+
+func (suite *AuditDataSourceConformanceTestSuite) TestEraseCustomerWithoutEntries() {
+	assert.NoError(suite.T(), suite.dataSource.EraseCustomer(suite.ctx, 404))
+}
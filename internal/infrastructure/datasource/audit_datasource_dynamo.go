@@ -0,0 +1,243 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type auditDynamoDataSource struct {
+	db        *database.DynamoDatabase
+	client    database.DynamoClient
+	tableName string
+	// cipher encrypts the changes of the items, nil when they're stored in plaintext
+	cipher *customerCipher
+}
+
+// AuditDynamoModel is an item of the audit table, keyed by entry_id and indexed by customer_id. With the
+// field-level encryption the changes, which copy the personal data of the customer, are stored as the
+// ciphertext of their JSON in changes_enc, next to their wrapped data key
+type AuditDynamoModel struct {
+	ID               string             `dynamodbav:"entry_id"`
+	CustomerID       int                `dynamodbav:"customer_id"`
+	Action           string             `dynamodbav:"action"`
+	Actor            string             `dynamodbav:"actor,omitempty"`
+	ActorRole        string             `dynamodbav:"actor_role,omitempty"`
+	RequestID        string             `dynamodbav:"request_id,omitempty"`
+	Changes          []AuditChangeModel `dynamodbav:"changes,omitempty"`
+	EncryptedChanges []byte             `dynamodbav:"changes_enc,omitempty"`
+	DataKey          []byte             `dynamodbav:"data_key,omitempty"`
+	OccurredAt       time.Time          `dynamodbav:"occurred_at"`
+}
+
+func (m AuditDynamoModel) toEntity() *entity.AuditEntry {
+	return &entity.AuditEntry{
+		ID:         m.ID,
+		CustomerID: m.CustomerID,
+		Action:     entity.AuditAction(m.Action),
+		Actor:      m.Actor,
+		ActorRole:  entity.Role(m.ActorRole),
+		RequestID:  m.RequestID,
+		Changes:    toAuditChanges(m.Changes),
+		OccurredAt: m.OccurredAt,
+	}
+}
+
+func toAuditDynamoModel(entry *entity.AuditEntry) AuditDynamoModel {
+	return AuditDynamoModel{
+		ID:         entry.ID,
+		CustomerID: entry.CustomerID,
+		Action:     string(entry.Action),
+		Actor:      entry.Actor,
+		ActorRole:  string(entry.ActorRole),
+		RequestID:  entry.RequestID,
+		Changes:    toAuditChangeModels(entry.Changes),
+		OccurredAt: entry.OccurredAt,
+	}
+}
+
+// NewAuditDynamoDataSource calls DynamoDB through the resilient client, like the customer data source.
+// With a key provider in the database the changes of the entries are encrypted
+func NewAuditDynamoDataSource(db *database.DynamoDatabase, tableName string) port.AuditDataSource {
+	ds := &auditDynamoDataSource{
+		db:        db,
+		client:    db.ResilientClient(),
+		tableName: tableName,
+	}
+	if db.KeyProvider != nil {
+		ds.cipher = newCustomerCipher(db.KeyProvider, db.DataKeyTTL)
+	}
+	return ds
+}
+
+// changesScope binds the encrypted changes to their entry
+func changesScope(entryID string) string {
+	return "audit/" + entryID + "/changes"
+}
+
+// marshalEntry returns the item of an entry, with its changes encrypted when the field-level encryption
+// is enabled
+func (ds *auditDynamoDataSource) marshalEntry(ctx context.Context, entry *entity.AuditEntry) (map[string]types.AttributeValue, error) {
+	model := toAuditDynamoModel(entry)
+	if ds.cipher != nil {
+		changes, err := json.Marshal(model.Changes)
+		if err != nil {
+			return nil, err
+		}
+		if model.EncryptedChanges, model.DataKey, err = ds.cipher.sealField(ctx, changesScope(model.ID), string(changes)); err != nil {
+			return nil, err
+		}
+		model.Changes = nil
+	}
+	return attributevalue.MarshalMap(model)
+}
+
+// unmarshalEntry reads an item, decrypting its changes. Items written before the encryption was enabled
+// have no data key, and are read as they are
+func (ds *auditDynamoDataSource) unmarshalEntry(ctx context.Context, item map[string]types.AttributeValue) (*entity.AuditEntry, error) {
+	var model AuditDynamoModel
+	if err := attributevalue.UnmarshalMap(item, &model); err != nil {
+		return nil, err
+	}
+	if ds.cipher != nil && len(model.DataKey) > 0 {
+		changes, err := ds.cipher.openField(ctx, changesScope(model.ID), model.DataKey, model.EncryptedChanges)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &model.Changes); err != nil {
+			return nil, err
+		}
+	}
+	return model.toEntity(), nil
+}
+
+func (ds *auditDynamoDataSource) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	startTime := time.Now()
+
+	stored := *entry
+	stored.ID = uuid.NewString()
+	item, err := ds.marshalEntry(ctx, &stored)
+	if err != nil {
+		return err
+	}
+
+	// The condition keeps an entry from ever being replaced
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(ds.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(entry_id)"),
+	}
+
+	_, err = ds.client.PutItem(ctx, input)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "AppendAuditEntry", ds.tableName, duration, err)
+
+	if err != nil {
+		return err
+	}
+	entry.ID = stored.ID
+	return nil
+}
+
+func (ds *auditDynamoDataSource) FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	startTime := time.Now()
+
+	entries, err := ds.query(ctx, customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindAuditEntriesByCustomerID", ds.tableName, duration, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The index has no sort key, so the entries come in no particular order
+	sortAuditEntries(entries)
+	return entries, nil
+}
+
+// EraseCustomer rewrites the entries that recorded personal data. The condition keeps an entry removed
+// in between from being written back
+func (ds *auditDynamoDataSource) EraseCustomer(ctx context.Context, customerID int) error {
+	startTime := time.Now()
+
+	err := ds.erase(ctx, customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "EraseAuditEntries", ds.tableName, duration, err)
+
+	return err
+}
+
+func (ds *auditDynamoDataSource) erase(ctx context.Context, customerID int) error {
+	entries, err := ds.query(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.ErasePersonalData() {
+			continue
+		}
+
+		item, err := ds.marshalEntry(ctx, entry)
+		if err != nil {
+			return err
+		}
+		_, err = ds.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(ds.tableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_exists(entry_id)"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// query reads every entry of a customer from the customer index
+func (ds *auditDynamoDataSource) query(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ds.tableName),
+		IndexName:              aws.String(database.AuditCustomerIndex),
+		KeyConditionExpression: aws.String("customer_id = :customer_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":customer_id": &types.AttributeValueMemberN{Value: strconv.Itoa(customerID)},
+		},
+	}
+
+	entries := make([]*entity.AuditEntry, 0)
+	for {
+		output, err := ds.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range output.Items {
+			entry, err := ds.unmarshalEntry(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return entries, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,144 @@
+package datasource_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database/dynamotest"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+const fakeAuditTable = "customer-audit"
+
+func newFakeAuditDataSource() (port.AuditDataSource, *dynamotest.FakeClient) {
+	client := dynamotest.NewFakeClient()
+	client.SetHashKey(fakeAuditTable, "entry_id")
+	db := database.NewDynamoItemDatabase(client, fakeCustomersTable, logger.NewLogger(&config.Config{Environment: "test"}))
+	return datasource.NewAuditDynamoDataSource(db, fakeAuditTable), client
+}
+
+func newFakeEncryptedAuditDataSource(client *dynamotest.FakeClient, keys port.KeyProvider) port.AuditDataSource {
+	client.SetHashKey(fakeAuditTable, "entry_id")
+	db := database.NewDynamoItemDatabase(client, fakeCustomersTable, logger.NewLogger(&config.Config{Environment: "test"}))
+	db.KeyProvider = keys
+	db.DataKeyTTL = time.Minute
+	return datasource.NewAuditDynamoDataSource(db, fakeAuditTable)
+}
+
+func TestAuditDynamoDataSourceFakeConformance(t *testing.T) {
+	suite.Run(t, NewAuditDataSourceConformanceTestSuite(func() port.AuditDataSource {
+		ds, _ := newFakeAuditDataSource()
+		return ds
+	}))
+}
+
+func TestAuditDynamoDataSource_Fake(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should leave the entry without ID when it can't be appended", func(t *testing.T) {
+		ds, client := newFakeAuditDataSource()
+		client.FailNext(dynamotest.OperationPutItem, errors.New("access denied"))
+		entry := &entity.AuditEntry{CustomerID: 1, Action: entity.AuditActionCreate, OccurredAt: time.Now()}
+
+		err := ds.Append(ctx, entry)
+
+		assert.Error(t, err)
+		assert.Empty(t, entry.ID)
+		assert.Empty(t, client.Items(fakeAuditTable))
+	})
+
+	t.Run("should query the customer index", func(t *testing.T) {
+		ds, client := newFakeAuditDataSource()
+
+		_, err := ds.FindByCustomerID(ctx, 1)
+
+		assert.NoError(t, err)
+		calls := client.CallsOf(dynamotest.OperationQuery)
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, database.AuditCustomerIndex, aws.ToString(calls[0].Input.(*dynamodb.QueryInput).IndexName))
+		}
+	})
+}
+
+func TestAuditDynamoDataSourceEncryptedFakeConformance(t *testing.T) {
+	keys := newFakeKeyProvider(t)
+	suite.Run(t, NewAuditDataSourceConformanceTestSuite(func() port.AuditDataSource {
+		return newFakeEncryptedAuditDataSource(dynamotest.NewFakeClient(), keys)
+	}))
+}
+
+func TestAuditDynamoDataSource_Encryption(t *testing.T) {
+	ctx := context.Background()
+	changes := []entity.AuditChange{
+		{Field: "name", After: "John Doe"},
+		{Field: "email", Before: "john@example.com", After: "john.doe@example.com"},
+		{Field: "cpf", After: "12345678900"},
+	}
+
+	t.Run("should store no personal data in plaintext", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeEncryptedAuditDataSource(client, newFakeKeyProvider(t))
+		entry := &entity.AuditEntry{CustomerID: 1, Action: entity.AuditActionUpdate, Changes: changes, OccurredAt: time.Now()}
+		require.NoError(t, ds.Append(ctx, entry))
+
+		items := client.Items(fakeAuditTable)
+		require.Len(t, items, 1)
+		assert.NotContains(t, items[0], "changes")
+		assert.Contains(t, items[0], "changes_enc")
+		assert.Contains(t, items[0], "data_key")
+		for attribute, value := range items[0] {
+			for _, plaintext := range []string{"John", "john@example.com", "john.doe@example.com", "12345678900"} {
+				assert.NotContains(t, fmt.Sprint(value), plaintext, "attribute %s", attribute)
+			}
+		}
+
+		found, err := ds.FindByCustomerID(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, changes, found[0].Changes)
+	})
+
+	t.Run("should read the entries written in plaintext", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		require.NoError(t, newFakeEncryptedAuditDataSource(client, nil).Append(ctx,
+			&entity.AuditEntry{CustomerID: 1, Action: entity.AuditActionUpdate, Changes: changes, OccurredAt: time.Now()}))
+		ds := newFakeEncryptedAuditDataSource(client, newFakeKeyProvider(t))
+
+		found, err := ds.FindByCustomerID(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, changes, found[0].Changes)
+	})
+
+	t.Run("should not decrypt the changes copied to another entry", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeEncryptedAuditDataSource(client, newFakeKeyProvider(t))
+		for _, customerID := range []int{1, 2} {
+			require.NoError(t, ds.Append(ctx,
+				&entity.AuditEntry{CustomerID: customerID, Action: entity.AuditActionCreate, Changes: changes, OccurredAt: time.Now()}))
+		}
+
+		items := client.Items(fakeAuditTable)
+		require.Len(t, items, 2)
+		item := items[1]
+		item["changes_enc"] = items[0]["changes_enc"]
+		client.Put(fakeAuditTable, item)
+
+		_, err := ds.FindByCustomerID(ctx, 2)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package datasource
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// auditMemoryDataSource keeps the audit entries in memory, next to the customers of the memory data
+// source. The entries are lost when the process exits
+type auditMemoryDataSource struct {
+	mu      sync.RWMutex
+	entries []entity.AuditEntry
+}
+
+func NewAuditMemoryDataSource() port.AuditDataSource {
+	return &auditMemoryDataSource{}
+}
+
+func (ds *auditMemoryDataSource) Append(_ context.Context, entry *entity.AuditEntry) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	entry.ID = uuid.NewString()
+	stored := *entry
+	stored.Changes = append([]entity.AuditChange(nil), entry.Changes...)
+	ds.entries = append(ds.entries, stored)
+	return nil
+}
+
+func (ds *auditMemoryDataSource) FindByCustomerID(_ context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	entries := make([]*entity.AuditEntry, 0)
+	for _, stored := range ds.entries {
+		if stored.CustomerID == customerID {
+			entry := stored
+			entry.Changes = append([]entity.AuditChange(nil), stored.Changes...)
+			entries = append(entries, &entry)
+		}
+	}
+	sortAuditEntries(entries)
+	return entries, nil
+}
+
+func (ds *auditMemoryDataSource) EraseCustomer(_ context.Context, customerID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	for i := range ds.entries {
+		if ds.entries[i].CustomerID == customerID {
+			ds.entries[i].ErasePersonalData()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package datasource
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+type AuditMongoModel struct {
+	ID         string             `bson:"_id"`
+	CustomerID int                `bson:"customer_id"`
+	Action     string             `bson:"action"`
+	Actor      string             `bson:"actor,omitempty"`
+	ActorRole  string             `bson:"actor_role,omitempty"`
+	RequestID  string             `bson:"request_id,omitempty"`
+	Changes    []AuditChangeModel `bson:"changes"`
+	OccurredAt time.Time          `bson:"occurred_at"`
+}
+
+func (m AuditMongoModel) toEntity() *entity.AuditEntry {
+	return &entity.AuditEntry{
+		ID:         m.ID,
+		CustomerID: m.CustomerID,
+		Action:     entity.AuditAction(m.Action),
+		Actor:      m.Actor,
+		ActorRole:  entity.Role(m.ActorRole),
+		RequestID:  m.RequestID,
+		Changes:    toAuditChanges(m.Changes),
+		OccurredAt: m.OccurredAt,
+	}
+}
+
+// auditMongoDataSource stores the audit entries in the customer_audit collection, only inserting them
+// and rewriting their changes to erase personal data
+type auditMongoDataSource struct {
+	db *database.MongoDatabase
+}
+
+func NewAuditMongoDataSource(db *database.MongoDatabase) port.AuditDataSource {
+	return &auditMongoDataSource{
+		db: db,
+	}
+}
+
+func (ds *auditMongoDataSource) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	startTime := time.Now()
+
+	model := AuditMongoModel{
+		ID:         uuid.NewString(),
+		CustomerID: entry.CustomerID,
+		Action:     string(entry.Action),
+		Actor:      entry.Actor,
+		ActorRole:  string(entry.ActorRole),
+		RequestID:  entry.RequestID,
+		Changes:    toAuditChangeModels(entry.Changes),
+		OccurredAt: entry.OccurredAt,
+	}
+	_, err := ds.db.Database.Collection(database.MongoAuditCollection).InsertOne(ctx, model)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "AppendAuditEntry", database.MongoAuditCollection, duration, err)
+
+	if err != nil {
+		return err
+	}
+	entry.ID = model.ID
+	return nil
+}
+
+func (ds *auditMongoDataSource) FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	startTime := time.Now()
+
+	entries, err := ds.find(ctx, customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindAuditEntriesByCustomerID", database.MongoAuditCollection, duration, err)
+
+	return entries, err
+}
+
+// EraseCustomer rewrites the changes of the entries that recorded personal data, one entry at a time
+func (ds *auditMongoDataSource) EraseCustomer(ctx context.Context, customerID int) error {
+	startTime := time.Now()
+
+	err := ds.erase(ctx, customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "EraseAuditEntries", database.MongoAuditCollection, duration, err)
+
+	return err
+}
+
+func (ds *auditMongoDataSource) erase(ctx context.Context, customerID int) error {
+	entries, err := ds.find(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	collection := ds.db.Database.Collection(database.MongoAuditCollection)
+	for _, entry := range entries {
+		if !entry.ErasePersonalData() {
+			continue
+		}
+
+		update := bson.M{"$set": bson.M{"changes": toAuditChangeModels(entry.Changes)}}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": entry.ID}, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ds *auditMongoDataSource) find(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := ds.db.Database.Collection(database.MongoAuditCollection).Find(ctx, bson.M{"customer_id": customerID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []AuditMongoModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*entity.AuditEntry, len(models))
+	for i, model := range models {
+		entries[i] = model.toEntity()
+	}
+	return entries, nil
+}
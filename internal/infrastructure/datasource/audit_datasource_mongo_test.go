@@ -0,0 +1,27 @@
+package datasource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+)
+
+func TestAuditMongoDataSourceConformance(t *testing.T) {
+	skipMongoIntegrationTests(t)
+
+	db := newTestMongoDatabase(t)
+	defer db.Close(context.Background())
+
+	suite.Run(t, NewAuditDataSourceConformanceTestSuite(func() port.AuditDataSource {
+		_, err := db.Database.Collection(database.MongoAuditCollection).DeleteMany(context.Background(), bson.M{})
+		require.NoError(t, err, "Failed to clear %s", database.MongoAuditCollection)
+		return datasource.NewAuditMongoDataSource(db)
+	}))
+}
@@ -0,0 +1,137 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+const (
+	auditTable   = "customer_audit"
+	auditColumns = "id, customer_id, action, actor, actor_role, request_id, changes, occurred_at"
+)
+
+// auditPostgresDataSource stores the audit entries in the customer_audit table, where a trigger
+// refuses to delete them or to update anything but their changes
+type auditPostgresDataSource struct {
+	db *database.PostgresDatabase
+}
+
+func NewAuditPostgresDataSource(db *database.PostgresDatabase) port.AuditDataSource {
+	return &auditPostgresDataSource{
+		db: db,
+	}
+}
+
+func (ds *auditPostgresDataSource) Append(ctx context.Context, entry *entity.AuditEntry) error {
+	startTime := time.Now()
+
+	id := uuid.NewString()
+	changes, err := json.Marshal(toAuditChangeModels(entry.Changes))
+	if err == nil {
+		_, err = ds.db.DB.ExecContext(ctx,
+			"INSERT INTO customer_audit ("+auditColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			id, entry.CustomerID, string(entry.Action), entry.Actor, string(entry.ActorRole), entry.RequestID,
+			string(changes), entry.OccurredAt)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "AppendAuditEntry", auditTable, duration, err)
+
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+	return nil
+}
+
+func (ds *auditPostgresDataSource) FindByCustomerID(ctx context.Context, customerID int) ([]*entity.AuditEntry, error) {
+	startTime := time.Now()
+
+	entries, err := queryAuditEntries(ctx, ds.db.DB, "SELECT "+auditColumns+" FROM customer_audit WHERE customer_id = $1 ORDER BY occurred_at, id", customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindAuditEntriesByCustomerID", auditTable, duration, err)
+
+	return entries, err
+}
+
+// EraseCustomer rewrites the changes of the entries that recorded personal data in a single transaction,
+// locking the entries so an erasure running at the same time waits for it
+func (ds *auditPostgresDataSource) EraseCustomer(ctx context.Context, customerID int) error {
+	startTime := time.Now()
+
+	err := ds.erase(ctx, customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "EraseAuditEntries", auditTable, duration, err)
+
+	return err
+}
+
+func (ds *auditPostgresDataSource) erase(ctx context.Context, customerID int) error {
+	tx, err := ds.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	entries, err := queryAuditEntries(ctx, tx, "SELECT "+auditColumns+" FROM customer_audit WHERE customer_id = $1 FOR UPDATE", customerID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.ErasePersonalData() {
+			continue
+		}
+
+		changes, err := json.Marshal(toAuditChangeModels(entry.Changes))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE customer_audit SET changes = $1 WHERE id = $2", string(changes), entry.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// auditQueryer runs the audit queries on the database or in a transaction
+type auditQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryAuditEntries(ctx context.Context, db auditQueryer, query string, args ...any) ([]*entity.AuditEntry, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*entity.AuditEntry, 0)
+	for rows.Next() {
+		var entry entity.AuditEntry
+		var action, actorRole string
+		var changes []byte
+		if err := rows.Scan(&entry.ID, &entry.CustomerID, &action, &entry.Actor, &actorRole, &entry.RequestID,
+			&changes, &entry.OccurredAt); err != nil {
+			return nil, err
+		}
+
+		var models []AuditChangeModel
+		if err := json.Unmarshal(changes, &models); err != nil {
+			return nil, err
+		}
+		entry.Action, entry.ActorRole, entry.Changes = entity.AuditAction(action), entity.Role(actorRole), toAuditChanges(models)
+		entries = append(entries, &entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,55 @@
+package datasource_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+)
+
+func TestAuditPostgresDataSourceConformance(t *testing.T) {
+	skipPostgresIntegrationTests(t)
+
+	db := newTestPostgresDatabase(t)
+	defer db.Close(context.Background())
+
+	suite.Run(t, NewAuditDataSourceConformanceTestSuite(func() port.AuditDataSource {
+		truncateTestPostgresAudit(t, db)
+		return datasource.NewAuditPostgresDataSource(db)
+	}))
+}
+
+func TestAuditPostgresDataSource_AppendOnly(t *testing.T) {
+	skipPostgresIntegrationTests(t)
+
+	db := newTestPostgresDatabase(t)
+	defer db.Close(context.Background())
+	truncateTestPostgresAudit(t, db)
+
+	entry := &entity.AuditEntry{CustomerID: 1, Action: entity.AuditActionCreate, OccurredAt: time.Now()}
+	require.NoError(t, datasource.NewAuditPostgresDataSource(db).Append(context.Background(), entry))
+
+	_, err := db.DB.Exec("UPDATE customer_audit SET actor = 'someone' WHERE id = $1", entry.ID)
+	assert.Error(t, err)
+	_, err = db.DB.Exec("DELETE FROM customer_audit WHERE id = $1", entry.ID)
+	assert.Error(t, err)
+
+	// Only the changes can be rewritten, to erase the personal data of an anonymized customer
+	_, err = db.DB.Exec("UPDATE customer_audit SET changes = '[]' WHERE id = $1", entry.ID)
+	assert.NoError(t, err)
+}
+
+// truncateTestPostgresAudit clears the entries between tests. TRUNCATE doesn't fire the row triggers
+// that keep the table append-only
+func truncateTestPostgresAudit(t *testing.T, db *database.PostgresDatabase) {
+	_, err := db.DB.Exec("TRUNCATE customer_audit")
+	require.NoError(t, err, "Failed to truncate customer_audit")
+}
@@ -0,0 +1,51 @@
+package datasource
+
+import (
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+// CustomerConsentModel is a consent of a customer, as stored by every customer data source
+type CustomerConsentModel struct {
+	Purpose       string    `json:"purpose" bson:"purpose" dynamodbav:"purpose"`
+	Granted       bool      `json:"granted" bson:"granted" dynamodbav:"granted"`
+	PolicyVersion string    `json:"policy_version" bson:"policy_version" dynamodbav:"policy_version"`
+	Channel       string    `json:"channel" bson:"channel" dynamodbav:"channel"`
+	RecordedAt    time.Time `json:"recorded_at" bson:"recorded_at" dynamodbav:"recorded_at"`
+}
+
+func toCustomerConsentModels(consents []entity.Consent) []CustomerConsentModel {
+	if len(consents) == 0 {
+		return nil
+	}
+	models := make([]CustomerConsentModel, len(consents))
+	for i, consent := range consents {
+		models[i] = CustomerConsentModel{
+			Purpose:       string(consent.Purpose),
+			Granted:       consent.Granted,
+			PolicyVersion: consent.PolicyVersion,
+			Channel:       string(consent.Channel),
+			RecordedAt:    consent.RecordedAt,
+		}
+	}
+	return models
+}
+
+// toCustomerConsents reads the stored consents, nil when the customer has none
+func toCustomerConsents(models []CustomerConsentModel) []entity.Consent {
+	if len(models) == 0 {
+		return nil
+	}
+	consents := make([]entity.Consent, len(models))
+	for i, model := range models {
+		consents[i] = entity.Consent{
+			Purpose:       entity.ConsentPurpose(model.Purpose),
+			Granted:       model.Granted,
+			PolicyVersion: model.PolicyVersion,
+			Channel:       entity.ConsentChannel(model.Channel),
+			RecordedAt:    model.RecordedAt,
+		}
+	}
+	return consents
+}
@@ -0,0 +1,555 @@
+package datasource_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// CustomerDataSourceConformanceTestSuite describes the behavior every port.CustomerDataSource
+// implementation must have. newDataSource must return an empty data source for each test
+type CustomerDataSourceConformanceTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	newDataSource func() port.CustomerDataSource
+	dataSource    port.CustomerDataSource
+}
+
+func NewCustomerDataSourceConformanceTestSuite(newDataSource func() port.CustomerDataSource) *CustomerDataSourceConformanceTestSuite {
+	return &CustomerDataSourceConformanceTestSuite{newDataSource: newDataSource}
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.dataSource = suite.newDataSource()
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) createCustomers(n int) []*entity.Customer {
+	customers := make([]*entity.Customer, n)
+	for i := range customers {
+		customers[i] = &entity.Customer{
+			Name:      fmt.Sprintf("Customer %d", i+1),
+			Email:     fmt.Sprintf("customer.%d@example.com", i+1),
+			CPF:       fmt.Sprintf("%011d", i+1),
+			Status:    entity.CustomerStatusActive,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, customers[i]))
+	}
+	return customers
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestCreateAssignsIDAndVersion() {
+	customers := suite.createCustomers(2)
+
+	assert.NotZero(suite.T(), customers[0].ID)
+	assert.Greater(suite.T(), customers[1].ID, customers[0].ID)
+	assert.Equal(suite.T(), 1, customers[0].Version)
+
+	found, err := suite.dataSource.FindByID(suite.ctx, customers[0].ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Equal(suite.T(), customers[0].Name, found.Name)
+	assert.Equal(suite.T(), customers[0].Email, found.Email)
+	assert.Equal(suite.T(), customers[0].CPF, found.CPF)
+	assert.Equal(suite.T(), 1, found.Version)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestCreateExistingIDConflicts() {
+	customers := suite.createCustomers(1)
+
+	duplicated := &entity.Customer{ID: customers[0].ID, Name: "Duplicated", Email: "dup@example.com", CPF: "99999999999"}
+	err := suite.dataSource.Create(suite.ctx, duplicated)
+
+	assert.IsType(suite.T(), &domain.ConflictError{}, err)
+	found, err := suite.dataSource.FindByID(suite.ctx, customers[0].ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), customers[0].Name, found.Name)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestCreateDuplicateCPFConflicts() {
+	customers := suite.createCustomers(1)
+
+	duplicated := &entity.Customer{Name: "Duplicated", Email: "dup@example.com", CPF: customers[0].CPF}
+	err := suite.dataSource.Create(suite.ctx, duplicated)
+
+	assert.Equal(suite.T(), domain.NewConflictError(domain.ErrCPFAlreadyExists), err)
+	found, err := suite.dataSource.FindByCPF(suite.ctx, customers[0].CPF)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Equal(suite.T(), customers[0].ID, found.ID)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestCreateDuplicateEmailConflicts() {
+	customers := suite.createCustomers(1)
+
+	duplicated := &entity.Customer{Name: "Duplicated", Email: customers[0].Email, CPF: "99999999999"}
+	err := suite.dataSource.Create(suite.ctx, duplicated)
+
+	assert.Equal(suite.T(), domain.NewConflictError(domain.ErrEmailAlreadyExists), err)
+	found, err := suite.dataSource.FindByCPF(suite.ctx, "99999999999")
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), found)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestUpdateToTakenEmailConflicts() {
+	customers := suite.createCustomers(2)
+	customer := *customers[1]
+
+	customer.Email = customers[0].Email
+	err := suite.dataSource.Update(suite.ctx, &customer)
+
+	assert.Equal(suite.T(), domain.NewConflictError(domain.ErrEmailAlreadyExists), err)
+	found, err := suite.dataSource.FindByID(suite.ctx, customers[1].ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Equal(suite.T(), customers[1].Email, found.Email)
+	assert.Equal(suite.T(), customers[1].Version, found.Version)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestChangedAndDeletedValuesAreFreed() {
+	customers := suite.createCustomers(2)
+	changed := *customers[0]
+	email := changed.Email
+
+	changed.Email = "changed@example.com"
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &changed))
+	require.NoError(suite.T(), suite.dataSource.Delete(suite.ctx, customers[1].ID, customers[1].Version))
+
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, &entity.Customer{
+		Name: "New Customer", Email: email, CPF: customers[1].CPF, Status: entity.CustomerStatusActive,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}))
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindMissingCustomer() {
+	found, err := suite.dataSource.FindByID(suite.ctx, 999999)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), found)
+
+	found, err = suite.dataSource.FindByCPF(suite.ctx, "00000000000")
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), found)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindByCPF() {
+	customers := suite.createCustomers(3)
+
+	found, err := suite.dataSource.FindByCPF(suite.ctx, customers[1].CPF)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Equal(suite.T(), customers[1].ID, found.ID)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindByIDs() {
+	customers := suite.createCustomers(3)
+
+	found, err := suite.dataSource.FindByIDs(suite.ctx, []int{customers[2].ID, 999999, customers[0].ID})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 2)
+	assert.Equal(suite.T(), customers[2].ID, found[0].ID, "the customers must follow the order of the IDs")
+	assert.Equal(suite.T(), customers[2].CPF, found[0].CPF)
+	assert.Equal(suite.T(), customers[0].ID, found[1].ID)
+
+	found, err = suite.dataSource.FindByIDs(suite.ctx, []int{999999})
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestSaveBatch() {
+	existing := suite.createCustomers(1)[0]
+
+	replaced := *existing
+	replaced.Name = "Replaced"
+	replaced.Version = existing.Version + 1
+	batch := []*entity.Customer{
+		{Name: "Batch 1", Email: "batch.1@example.com", CPF: "90000000001", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		&replaced,
+		{Name: "Batch 2", Email: "batch.2@example.com", CPF: "90000000002", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	require.NoError(suite.T(), suite.dataSource.SaveBatch(suite.ctx, batch))
+
+	assert.Greater(suite.T(), batch[0].ID, existing.ID)
+	assert.Greater(suite.T(), batch[2].ID, batch[0].ID)
+	assert.Equal(suite.T(), 1, batch[0].Version)
+	assert.Equal(suite.T(), existing.ID, batch[1].ID)
+
+	found, err := suite.dataSource.FindByIDs(suite.ctx, []int{batch[0].ID, existing.ID, batch[2].ID})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 3)
+	assert.Equal(suite.T(), "Batch 1", found[0].Name)
+	assert.Equal(suite.T(), "Replaced", found[1].Name)
+	assert.Equal(suite.T(), existing.Version+1, found[1].Version)
+	assert.Equal(suite.T(), "90000000002", found[2].CPF)
+
+	// The customers created afterwards don't reuse the IDs of the batch
+	next := &entity.Customer{Name: "Next", Email: "next@example.com", CPF: "90000000003", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, next))
+	assert.Greater(suite.T(), next.ID, batch[2].ID)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestSaveBatchChecksVersions() {
+	existing := suite.createCustomers(1)[0]
+
+	// The replacement was read before another request changed the customer
+	stale := *existing
+	stale.Version = existing.Version + 1
+	stale.Name = "Stale"
+	changed := *existing
+	changed.Name = "Changed"
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &changed))
+
+	err := suite.dataSource.SaveBatch(suite.ctx, []*entity.Customer{&stale})
+	var preconditionFailed *domain.PreconditionFailedError
+	assert.ErrorAs(suite.T(), err, &preconditionFailed)
+
+	gone := entity.Customer{ID: 999999, Name: "Gone", Email: "gone@example.com", CPF: "90000000009", Version: 2}
+	err = suite.dataSource.SaveBatch(suite.ctx, []*entity.Customer{&gone})
+	var notFound *domain.NotFoundError
+	assert.ErrorAs(suite.T(), err, &notFound)
+
+	found, err := suite.dataSource.FindByID(suite.ctx, existing.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Changed", found.Name)
+	missing, err := suite.dataSource.FindByID(suite.ctx, gone.ID)
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), missing)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestSaveBatchDuplicateCPFConflicts() {
+	existing := suite.createCustomers(1)[0]
+
+	duplicated := &entity.Customer{Name: "Duplicated", Email: "dup@example.com", CPF: existing.CPF, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	err := suite.dataSource.SaveBatch(suite.ctx, []*entity.Customer{duplicated})
+
+	assert.Equal(suite.T(), domain.NewConflictError(domain.ErrCPFAlreadyExists), err)
+	found, err := suite.dataSource.FindByCPF(suite.ctx, existing.CPF)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Equal(suite.T(), existing.ID, found.ID)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestScan() {
+	customers := suite.createCustomers(5)
+
+	scanned := make(map[int]string)
+	err := suite.dataSource.Scan(suite.ctx, 3, func(customer *entity.Customer) error {
+		scanned[customer.ID] = customer.CPF
+		return nil
+	})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), scanned, 5)
+	for _, customer := range customers {
+		assert.Equal(suite.T(), customer.CPF, scanned[customer.ID])
+	}
+
+	// An error of visit stops the scan
+	visited := 0
+	err = suite.dataSource.Scan(suite.ctx, 1, func(*entity.Customer) error {
+		visited++
+		return assert.AnError
+	})
+	assert.ErrorIs(suite.T(), err, assert.AnError)
+	assert.Equal(suite.T(), 1, visited)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindAllPagination() {
+	customers := suite.createCustomers(5)
+
+	tests := []struct {
+		name    string
+		page    int
+		limit   int
+		wantIDs []int
+	}{
+		{name: "first page", page: 1, limit: 2, wantIDs: []int{customers[0].ID, customers[1].ID}},
+		{name: "middle page", page: 2, limit: 2, wantIDs: []int{customers[2].ID, customers[3].ID}},
+		{name: "last partial page", page: 3, limit: 2, wantIDs: []int{customers[4].ID}},
+		{name: "page after the end", page: 4, limit: 2, wantIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			result, total, err := suite.dataSource.FindAll(suite.ctx, map[string]interface{}{}, tt.page, tt.limit)
+			require.NoError(suite.T(), err)
+			assert.Equal(suite.T(), int64(5), total)
+
+			ids := make([]int, len(result))
+			for i, customer := range result {
+				ids[i] = customer.ID
+			}
+			assert.Equal(suite.T(), tt.wantIDs, ids)
+		})
+	}
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindAllKeysetPagination() {
+	customers := suite.createCustomers(5)
+
+	tests := []struct {
+		name    string
+		afterID int
+		limit   int
+		wantIDs []int
+	}{
+		{name: "page after the first customer", afterID: customers[0].ID, limit: 2, wantIDs: []int{customers[1].ID, customers[2].ID}},
+		{name: "last partial page", afterID: customers[3].ID, limit: 2, wantIDs: []int{customers[4].ID}},
+		{name: "page after the last customer", afterID: customers[4].ID, limit: 2, wantIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			filters := map[string]interface{}{port.FilterAfterID: tt.afterID}
+			result, total, err := suite.dataSource.FindAll(suite.ctx, filters, 1, tt.limit)
+			require.NoError(suite.T(), err)
+			assert.Equal(suite.T(), int64(5), total)
+
+			ids := make([]int, len(result))
+			for i, customer := range result {
+				ids[i] = customer.ID
+			}
+			assert.Equal(suite.T(), tt.wantIDs, ids)
+		})
+	}
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindAllFilters() {
+	customers := suite.createCustomers(3)
+
+	result, total, err := suite.dataSource.FindAll(suite.ctx, map[string]interface{}{"name": customers[2].Name}, 1, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	require.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), customers[2].ID, result[0].ID)
+
+	result, total, err = suite.dataSource.FindAll(suite.ctx, map[string]interface{}{"name": "Nobody"}, 1, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), total)
+	assert.Empty(suite.T(), result)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestFindAllSearch() {
+	names := []string{"Maria Silva", "Maria Souza", "João Silva"}
+	customers := make([]*entity.Customer, len(names))
+	for i, name := range names {
+		customers[i] = &entity.Customer{
+			Name:  name,
+			Email: fmt.Sprintf("search.%d@example.com", i+1),
+			CPF:   fmt.Sprintf("%011d", i+100),
+		}
+		require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, customers[i]))
+	}
+
+	tests := []struct {
+		name    string
+		search  string
+		wantIDs []int
+	}{
+		{name: "single word", search: "silva", wantIDs: []int{customers[0].ID, customers[2].ID}},
+		{name: "every word must match", search: "maria silva", wantIDs: []int{customers[0].ID}},
+		{name: "ignores case", search: "SOUZA", wantIDs: []int{customers[1].ID}},
+		{name: "no match", search: "pereira", wantIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			filters := map[string]interface{}{port.FilterSearch: tt.search}
+			result, total, err := suite.dataSource.FindAll(suite.ctx, filters, 1, 10)
+			require.NoError(suite.T(), err)
+			assert.Equal(suite.T(), int64(len(tt.wantIDs)), total)
+
+			ids := make([]int, len(result))
+			for i, customer := range result {
+				ids[i] = customer.ID
+			}
+			assert.Equal(suite.T(), tt.wantIDs, ids)
+		})
+	}
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestUpdate() {
+	customers := suite.createCustomers(1)
+	customer := *customers[0]
+
+	customer.Name = "Updated"
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &customer))
+	assert.Equal(suite.T(), 2, customer.Version)
+
+	found, err := suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Updated", found.Name)
+	assert.Equal(suite.T(), 2, found.Version)
+
+	stale := *customers[0]
+	stale.Name = "Stale"
+	err = suite.dataSource.Update(suite.ctx, &stale)
+	assert.IsType(suite.T(), &domain.PreconditionFailedError{}, err)
+
+	missing := entity.Customer{ID: 999999, Name: "Missing", Version: 1}
+	err = suite.dataSource.Update(suite.ctx, &missing)
+	assert.IsType(suite.T(), &domain.NotFoundError{}, err)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestUpdateStatus() {
+	customers := suite.createCustomers(1)
+	customer := *customers[0]
+
+	found, err := suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.CustomerStatusActive, found.Status)
+
+	require.NoError(suite.T(), customer.ChangeStatus(entity.CustomerStatusBlocked, "chargeback fraud"))
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &customer))
+
+	found, err = suite.dataSource.FindByCPF(suite.ctx, customer.CPF)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Equal(suite.T(), entity.CustomerStatusBlocked, found.Status)
+	assert.Equal(suite.T(), "chargeback fraud", found.StatusReason)
+	require.NotNil(suite.T(), found.StatusChangedAt)
+	assert.WithinDuration(suite.T(), *customer.StatusChangedAt, *found.StatusChangedAt, time.Millisecond)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestAnonymize() {
+	customers := suite.createCustomers(1)
+	customer := *customers[0]
+	cpf, email := customer.CPF, customer.Email
+
+	customer.Anonymize()
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &customer))
+
+	found, err := suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.True(suite.T(), found.IsAnonymized())
+	assert.WithinDuration(suite.T(), *customer.AnonymizedAt, *found.AnonymizedAt, time.Millisecond)
+	assert.Equal(suite.T(), customer.Name, found.Name)
+	assert.Equal(suite.T(), customer.Email, found.Email)
+	assert.Equal(suite.T(), customer.CPF, found.CPF)
+
+	// The CPF and email indexes no longer hold the erased values, which another customer can take
+	found, err = suite.dataSource.FindByCPF(suite.ctx, cpf)
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), found)
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, &entity.Customer{
+		Name: "New Customer", Email: email, CPF: cpf, Status: entity.CustomerStatusActive,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}))
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestConsents() {
+	customers := suite.createCustomers(1)
+	customer := *customers[0]
+
+	require.NoError(suite.T(), customer.RecordConsent(entity.ConsentPurposeMarketing, true, "1.0", entity.ConsentChannelWeb))
+	require.NoError(suite.T(), customer.RecordConsent(entity.ConsentPurposeMarketing, false, "1.1", entity.ConsentChannelApp))
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &customer))
+
+	found, err := suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	require.Len(suite.T(), found.Consents, 2)
+	for i, consent := range found.Consents {
+		assert.Equal(suite.T(), customer.Consents[i].Purpose, consent.Purpose)
+		assert.Equal(suite.T(), customer.Consents[i].Granted, consent.Granted)
+		assert.Equal(suite.T(), customer.Consents[i].PolicyVersion, consent.PolicyVersion)
+		assert.Equal(suite.T(), customer.Consents[i].Channel, consent.Channel)
+		assert.WithinDuration(suite.T(), customer.Consents[i].RecordedAt, consent.RecordedAt, time.Millisecond)
+	}
+	assert.False(suite.T(), found.CurrentConsent(entity.ConsentPurposeMarketing).Granted)
+
+	// Updates that don't touch the consents keep them
+	found.Update("Renamed Customer", found.Email)
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, found))
+
+	found, err = suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.Len(suite.T(), found.Consents, 2)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestSoftDelete() {
+	customers := suite.createCustomers(2)
+	customer := *customers[0]
+
+	customer.Delete()
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &customer))
+
+	found, err := suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.True(suite.T(), found.IsDeleted())
+
+	result, total, err := suite.dataSource.FindAll(suite.ctx, map[string]interface{}{}, 1, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	require.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), customers[1].ID, result[0].ID)
+
+	result, total, err = suite.dataSource.FindAll(suite.ctx, map[string]interface{}{port.FilterIncludeDeleted: true}, 1, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), total)
+	assert.Len(suite.T(), result, 2)
+
+	customer.Restore()
+	require.NoError(suite.T(), suite.dataSource.Update(suite.ctx, &customer))
+
+	found, err = suite.dataSource.FindByID(suite.ctx, customer.ID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), found)
+	assert.False(suite.T(), found.IsDeleted())
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestDelete() {
+	customers := suite.createCustomers(1)
+
+	err := suite.dataSource.Delete(suite.ctx, customers[0].ID, customers[0].Version+1)
+	assert.IsType(suite.T(), &domain.PreconditionFailedError{}, err)
+
+	require.NoError(suite.T(), suite.dataSource.Delete(suite.ctx, customers[0].ID, customers[0].Version))
+	found, err := suite.dataSource.FindByID(suite.ctx, customers[0].ID)
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), found)
+
+	err = suite.dataSource.Delete(suite.ctx, customers[0].ID, customers[0].Version)
+	assert.IsType(suite.T(), &domain.NotFoundError{}, err)
+}
+
+func (suite *CustomerDataSourceConformanceTestSuite) TestConcurrentUpdatesOnlyOneWins() {
+	customers := suite.createCustomers(1)
+
+	const writers = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			customer := *customers[0]
+			customer.Name = fmt.Sprintf("Writer %d", i)
+			errs <- suite.dataSource.Update(suite.ctx, &customer)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.IsType(suite.T(), &domain.PreconditionFailedError{}, err)
+	}
+	assert.Equal(suite.T(), 1, succeeded)
+}
@@ -2,9 +2,13 @@ package datasource
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
@@ -13,26 +17,141 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// maxBatchGetKeys is the most keys DynamoDB accepts in a BatchGetItem request
+	maxBatchGetKeys = 100
+	// maxTransactWriteItems is the most actions DynamoDB accepts in a TransactWriteItems request
+	maxTransactWriteItems = 100
+	// maxBatchAttempts bounds the batch calls of a chunk while DynamoDB leaves requests unprocessed
+	maxBatchAttempts = 5
+	// lastIDAttribute is the attribute of the migrations metadata item counting the IDs given to customers
+	lastIDAttribute = "last_customer_id"
+	// createCondition keeps a new customer from overwriting a stored item
+	createCondition = "attribute_not_exists(id)"
+)
+
+var errUnprocessedKeys = errors.New("DynamoDB left customer keys unprocessed")
+
 type customerDynamoDataSource struct {
-	db *database.DynamoDatabase
+	db     *database.DynamoDatabase
+	client database.DynamoClient
+	// cipher encrypts the personal data of the items, nil when it's stored in plaintext
+	cipher *customerCipher
 }
 
 type CustomerDynamoModel struct {
-	ID    int    `dynamodbav:"id"`
-	CPF   string `dynamodbav:"cpf"`
-	Name  string `dynamodbav:"name"`
-	Email string `dynamodbav:"email"`
+	ID        int        `dynamodbav:"id"`
+	CPF       string     `dynamodbav:"cpf"`
+	Name      string     `dynamodbav:"name,omitempty"`
+	Email     string     `dynamodbav:"email"`
+	Version   int        `dynamodbav:"version"`
+	DeletedAt *time.Time `dynamodbav:"deleted_at,omitempty"`
+	// PurgeAt is the TTL of a deleted customer, in epoch seconds
+	PurgeAt         int64      `dynamodbav:"purge_at,omitempty"`
+	Status          string     `dynamodbav:"status,omitempty"`
+	StatusReason    string     `dynamodbav:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `dynamodbav:"status_changed_at,omitempty"`
+	AnonymizedAt    *time.Time `dynamodbav:"anonymized_at,omitempty"`
+	// Consents are kept on the item, with their history
+	Consents []CustomerConsentModel `dynamodbav:"consents,omitempty"`
+	// With the field-level encryption, the name, email and CPF are sealed with the data key, which is stored
+	// wrapped by the master key, and the email and CPF attributes hold their blind indexes
+	EncryptedName  []byte `dynamodbav:"name_enc,omitempty"`
+	EncryptedEmail []byte `dynamodbav:"email_enc,omitempty"`
+	EncryptedCPF   []byte `dynamodbav:"cpf_enc,omitempty"`
+	DataKey        []byte `dynamodbav:"data_key,omitempty"`
+}
+
+func (m CustomerDynamoModel) toEntity() *entity.Customer {
+	return &entity.Customer{
+		ID:              m.ID,
+		CPF:             m.CPF,
+		Name:            m.Name,
+		Email:           m.Email,
+		Version:         m.Version,
+		DeletedAt:       m.DeletedAt,
+		Status:          storedStatus(m.Status),
+		StatusReason:    m.StatusReason,
+		StatusChangedAt: m.StatusChangedAt,
+		AnonymizedAt:    m.AnonymizedAt,
+		Consents:        toCustomerConsents(m.Consents),
+	}
+}
+
+// newModel returns the item of a customer. A deleted customer is purged by the table TTL after the
+// retention period, unless the retention is zero
+func (ds *customerDynamoDataSource) newModel(customer *entity.Customer) CustomerDynamoModel {
+	return CustomerDynamoModel{
+		ID:              customer.ID,
+		CPF:             customer.CPF,
+		Name:            customer.Name,
+		Email:           customer.Email,
+		Version:         customer.Version,
+		DeletedAt:       customer.DeletedAt,
+		PurgeAt:         ds.purgeAt(customer),
+		Status:          string(customer.Status),
+		StatusReason:    customer.StatusReason,
+		StatusChangedAt: customer.StatusChangedAt,
+		AnonymizedAt:    customer.AnonymizedAt,
+		Consents:        toCustomerConsentModels(customer.Consents),
+	}
 }
 
+// purgeAt is the TTL of a customer, zero when it isn't deleted or the deleted customers are kept
+func (ds *customerDynamoDataSource) purgeAt(customer *entity.Customer) int64 {
+	if customer.DeletedAt == nil || ds.db.DeletedRetention <= 0 {
+		return 0
+	}
+	return customer.DeletedAt.Add(ds.db.DeletedRetention).Unix()
+}
+
+// encryptedModel returns the item of a customer, with the personal data encrypted when the field-level
+// encryption is enabled
+func (ds *customerDynamoDataSource) encryptedModel(ctx context.Context, customer *entity.Customer) (CustomerDynamoModel, error) {
+	model := ds.newModel(customer)
+	if ds.cipher == nil {
+		return model, nil
+	}
+	err := ds.cipher.encrypt(ctx, &model)
+	return model, err
+}
+
+// unmarshalModel reads an item, decrypting its personal data
+func (ds *customerDynamoDataSource) unmarshalModel(ctx context.Context, item map[string]types.AttributeValue) (CustomerDynamoModel, error) {
+	var model CustomerDynamoModel
+	if err := attributevalue.UnmarshalMap(item, &model); err != nil {
+		return model, err
+	}
+	if ds.cipher == nil {
+		return model, nil
+	}
+	err := ds.cipher.decrypt(ctx, &model)
+	return model, err
+}
+
+// NewCustomerDynamoDataSource calls DynamoDB through the resilient client, so throttled or failed calls are
+// retried, and the calls fail fast with a ServiceUnavailableError while the table is unavailable. With a key
+// provider in the database the name, email and CPF of the customers are encrypted
 func NewCustomerDynamoDataSource(db *database.DynamoDatabase) port.CustomerDataSource {
-	return &customerDynamoDataSource{
-		db: db,
+	ds := &customerDynamoDataSource{
+		db:     db,
+		client: db.ResilientClient(),
+	}
+	if db.KeyProvider != nil {
+		ds.cipher = newCustomerCipher(db.KeyProvider, db.DataKeyTTL)
 	}
+	return ds
 }
 
 func (ds *customerDynamoDataSource) FindByID(ctx context.Context, id int) (*entity.Customer, error) {
+	// The migrations metadata item shares the table, but isn't a customer
+	if id == database.MigrationsMetadataID {
+		return nil, nil
+	}
+
 	startTime := time.Now()
 
 	input := &dynamodb.GetItemInput{
@@ -42,7 +161,7 @@ func (ds *customerDynamoDataSource) FindByID(ctx context.Context, id int) (*enti
 		},
 	}
 
-	result, err := ds.db.Client.GetItem(ctx, input)
+	result, err := ds.client.GetItem(ctx, input)
 
 	duration := time.Since(startTime)
 	ds.db.LogOperation(ctx, "FindByID", ds.db.TableName, duration, err)
@@ -55,73 +174,160 @@ func (ds *customerDynamoDataSource) FindByID(ctx context.Context, id int) (*enti
 		return nil, nil
 	}
 
-	var customerModel CustomerDynamoModel
-	err = attributevalue.UnmarshalMap(result.Item, &customerModel)
+	customerModel, err := ds.unmarshalModel(ctx, result.Item)
 	if err != nil {
 		return nil, err
 	}
 
-	return &entity.Customer{
-		ID:    customerModel.ID,
-		CPF:   customerModel.CPF,
-		Name:  customerModel.Name,
-		Email: customerModel.Email,
-	}, nil
+	return customerModel.toEntity(), nil
 }
 
-func (ds *customerDynamoDataSource) FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error) {
+func (ds *customerDynamoDataSource) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
 	startTime := time.Now()
 
-	// Use GSI for CPF lookup
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String(ds.db.TableName),
-		IndexName:              aws.String("cpf-index"),
-		KeyConditionExpression: aws.String("cpf = :cpf"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":cpf": &types.AttributeValueMemberS{Value: cpf},
-		},
-		Limit: aws.Int32(1),
+	keys := make([]map[string]types.AttributeValue, 0, len(ids))
+	for _, id := range ids {
+		// The migrations metadata item shares the table, but isn't a customer
+		if id == database.MigrationsMetadataID {
+			continue
+		}
+		keys = append(keys, map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id)},
+		})
 	}
 
-	result, err := ds.db.Client.Query(ctx, input)
+	var items []map[string]types.AttributeValue
+	var err error
+	for start := 0; start < len(keys) && err == nil; start += maxBatchGetKeys {
+		var chunk []map[string]types.AttributeValue
+		chunk, err = ds.batchGet(ctx, keys[start:min(start+maxBatchGetKeys, len(keys))], false)
+		items = append(items, chunk...)
+	}
 
 	duration := time.Since(startTime)
-	ds.db.LogOperation(ctx, "FindByCPF", ds.db.TableName, duration, err)
+	ds.db.LogOperation(ctx, "FindByIDs", ds.db.TableName, duration, err)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.Items) == 0 {
-		return nil, nil
+	customers := make([]*entity.Customer, 0, len(items))
+	for _, item := range items {
+		customerModel, err := ds.unmarshalModel(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customerModel.toEntity())
 	}
+	return inIDOrder(customers, ids), nil
+}
 
-	var customerModel CustomerDynamoModel
-	err = attributevalue.UnmarshalMap(result.Items[0], &customerModel)
+// batchGet reads up to 100 keys, calling BatchGetItem again with the keys DynamoDB left unprocessed,
+// which happens when the batch is throttled or exceeds the response size
+func (ds *customerDynamoDataSource) batchGet(ctx context.Context, keys []map[string]types.AttributeValue, consistent bool) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	requestItems := map[string]types.KeysAndAttributes{
+		ds.db.TableName: {Keys: keys, ConsistentRead: aws.Bool(consistent)},
+	}
+
+	for attempt := 1; ; attempt++ {
+		output, err := ds.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: requestItems})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, output.Responses[ds.db.TableName]...)
+
+		if len(output.UnprocessedKeys[ds.db.TableName].Keys) == 0 {
+			return items, nil
+		}
+		if attempt >= maxBatchAttempts {
+			return nil, domain.NewServiceUnavailableError(errUnprocessedKeys)
+		}
+		requestItems = output.UnprocessedKeys
+		if err := database.Sleep(ctx, ds.db.Resilience.Backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (ds *customerDynamoDataSource) FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error) {
+	startTime := time.Now()
+
+	item, err := ds.findByCPF(ctx, cpf)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByCPF", ds.db.TableName, duration, err)
+
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	customerModel, err := ds.unmarshalModel(ctx, item)
 	if err != nil {
 		return nil, err
 	}
 
-	return &entity.Customer{
-		ID:    customerModel.ID,
-		CPF:   customerModel.CPF,
-		Name:  customerModel.Name,
-		Email: customerModel.Email,
-	}, nil
+	return customerModel.toEntity(), nil
+}
+
+// findByCPF queries the CPF index. With the field-level encryption the index has the blind index of the CPF,
+// and the plaintext CPF is queried next, for the items written before the encryption was enabled
+func (ds *customerDynamoDataSource) findByCPF(ctx context.Context, cpf string) (map[string]types.AttributeValue, error) {
+	values := []string{cpf}
+	if ds.cipher != nil {
+		blindIndex, err := ds.cipher.blindIndex(ctx, "cpf", cpf)
+		if err != nil {
+			return nil, err
+		}
+		values = []string{blindIndex, cpf}
+	}
+
+	for _, value := range values {
+		// Use GSI for CPF lookup
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(ds.db.TableName),
+			IndexName:              aws.String(database.CustomersCPFIndex),
+			KeyConditionExpression: aws.String("cpf = :cpf"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":cpf": &types.AttributeValueMemberS{Value: value},
+			},
+			Limit: aws.Int32(1),
+		}
+
+		result, err := ds.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Items) > 0 {
+			return result.Items[0], nil
+		}
+	}
+	return nil, nil
 }
 
+// FindAll scans the whole table on every call, whatever the page, limit and after_id, so its cost and
+// latency grow with the number of customers. The scan stops once it read more than ListScanLimit items,
+// and the listing fails instead of reading the rest
 func (ds *customerDynamoDataSource) FindAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*entity.Customer, int64, error) {
 	startTime := time.Now()
 
-	// For simplicity, we'll scan the table (not optimal for large datasets)
-	// In production, consider implementing pagination tokens
+	filters, afterID, err := splitAfterID(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	filters, search := splitSearch(filters)
+	filters, includeDeleted := splitIncludeDeleted(filters)
+
+	// A scan has no order and a limit applied before the filter, so every matching item is read
+	// and the page is cut after sorting them by ID. Filter expressions are case-sensitive,
+	// so the name search is applied to the scanned items. Encrypted attributes can't be filtered
+	// by DynamoDB either, so with the field-level encryption every filter is applied after decrypting
 	input := &dynamodb.ScanInput{
 		TableName: aws.String(ds.db.TableName),
-		Limit:     aws.Int32(int32(limit)),
 	}
 
 	// Add filters if provided
-	if len(filters) > 0 {
+	if len(filters) > 0 && ds.cipher == nil {
 		filterExpression := ""
 		expressionAttributeValues := make(map[string]types.AttributeValue)
 		expressionAttributeNames := make(map[string]string)
@@ -145,7 +351,7 @@ func (ds *customerDynamoDataSource) FindAll(ctx context.Context, filters map[str
 		}
 	}
 
-	result, err := ds.db.Client.Scan(ctx, input)
+	items, err := ds.scanUpTo(ctx, input, ds.db.ListScanLimit)
 
 	duration := time.Since(startTime)
 	ds.db.LogOperation(ctx, "FindAll", ds.db.TableName, duration, err)
@@ -154,149 +360,638 @@ func (ds *customerDynamoDataSource) FindAll(ctx context.Context, filters map[str
 		return nil, 0, err
 	}
 
-	customers := make([]*entity.Customer, len(result.Items))
-	for i, item := range result.Items {
-		var customerModel CustomerDynamoModel
-		err = attributevalue.UnmarshalMap(item, &customerModel)
+	customers := make([]*entity.Customer, 0, len(items))
+	for _, item := range items {
+		customerModel, err := ds.unmarshalModel(ctx, item)
 		if err != nil {
 			return nil, 0, err
 		}
 
-		customers[i] = &entity.Customer{
-			ID:    customerModel.ID,
-			CPF:   customerModel.CPF,
-			Name:  customerModel.Name,
-			Email: customerModel.Email,
+		if customerModel.ID == database.MigrationsMetadataID || (!includeDeleted && customerModel.DeletedAt != nil) ||
+			!matchesSearch(customerModel.Name, search) {
+			continue
+		}
+		customer := customerModel.toEntity()
+		if ds.cipher == nil || matchesFilters(customer, filters) {
+			customers = append(customers, customer)
 		}
 	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].ID < customers[j].ID })
 
-	// For total count, we need another scan operation (simplified approach)
-	countInput := &dynamodb.ScanInput{
-		TableName: aws.String(ds.db.TableName),
-		Select:    types.SelectCount,
+	return pageOf(customers, afterID, page, limit), int64(len(customers)), nil
+}
+
+// scanAll follows the scan pagination, since each call returns at most 1 MB of items
+func (ds *customerDynamoDataSource) scanAll(ctx context.Context, input *dynamodb.ScanInput) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(ds.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, output.Items...)
 	}
+	return items, nil
+}
 
-	if input.FilterExpression != nil {
-		countInput.FilterExpression = input.FilterExpression
-		countInput.ExpressionAttributeValues = input.ExpressionAttributeValues
-		countInput.ExpressionAttributeNames = input.ExpressionAttributeNames
+// scanUpTo follows the scan pagination like scanAll, but each call is limited to the items left to reach
+// limit, and reading one more item than limit fails with an InvalidInputError. Zero doesn't limit the scan
+func (ds *customerDynamoDataSource) scanUpTo(ctx context.Context, input *dynamodb.ScanInput, limit int) ([]map[string]types.AttributeValue, error) {
+	if limit <= 0 {
+		return ds.scanAll(ctx, input)
 	}
 
-	countResult, err := ds.db.Client.Scan(ctx, countInput)
-	if err != nil {
-		return customers, int64(len(customers)), nil // Return partial result
+	var items []map[string]types.AttributeValue
+	scanned := 0
+	for {
+		input.Limit = aws.Int32(int32(limit - scanned + 1))
+		output, err := ds.client.Scan(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		scanned += int(output.ScannedCount)
+		if scanned > limit {
+			return nil, domain.NewInvalidInputError(domain.ErrListScanLimitExceeded)
+		}
+		items = append(items, output.Items...)
+		if output.LastEvaluatedKey == nil {
+			return items, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
 	}
+}
+
+// Scan reads the table with parallel segment scans, each following its own pages. The pages are visited
+// from the calling goroutine as they arrive, so a slow visit slows the scans down instead of piling up items
+func (ds *customerDynamoDataSource) Scan(ctx context.Context, segments int, visit func(*entity.Customer) error) error {
+	startTime := time.Now()
 
-	return customers, int64(countResult.Count), nil
+	err := ds.scanSegments(ctx, max(segments, 1), visit)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Scan", ds.db.TableName, duration, err)
+
+	return err
 }
 
-func (ds *customerDynamoDataSource) getNextID(ctx context.Context) (int, error) {
-	// Scan table to find the highest ID
-	input := &dynamodb.ScanInput{
-		TableName:            aws.String(ds.db.TableName),
-		ProjectionExpression: aws.String("id"),
+func (ds *customerDynamoDataSource) scanSegments(ctx context.Context, segments int, visit func(*entity.Customer) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	pages := make(chan []map[string]types.AttributeValue, segments)
+	for segment := 0; segment < segments; segment++ {
+		group.Go(func() error {
+			paginator := dynamodb.NewScanPaginator(ds.client, &dynamodb.ScanInput{
+				TableName:     aws.String(ds.db.TableName),
+				Segment:       aws.Int32(int32(segment)),
+				TotalSegments: aws.Int32(int32(segments)),
+			})
+			for paginator.HasMorePages() {
+				output, err := paginator.NextPage(groupCtx)
+				if err != nil {
+					return err
+				}
+				select {
+				case pages <- output.Items:
+				case <-groupCtx.Done():
+					return groupCtx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	go func() {
+		_ = group.Wait()
+		close(pages)
+	}()
+
+	// After a failed visit the scans are cancelled, and their pages drained until they stop
+	var visitErr error
+	for items := range pages {
+		if visitErr == nil {
+			visitErr = ds.visitItems(ctx, items, visit)
+			if visitErr != nil {
+				cancel()
+			}
+		}
+	}
+	if visitErr != nil {
+		return visitErr
 	}
+	return group.Wait()
+}
 
-	result, err := ds.db.Client.Scan(ctx, input)
+// visitItems visits the customers of scanned items, skipping the migrations metadata item
+func (ds *customerDynamoDataSource) visitItems(ctx context.Context, items []map[string]types.AttributeValue, visit func(*entity.Customer) error) error {
+	for _, item := range items {
+		customerModel, err := ds.unmarshalModel(ctx, item)
+		if err != nil {
+			return err
+		}
+		if customerModel.ID == database.MigrationsMetadataID {
+			continue
+		}
+		if err := visit(customerModel.toEntity()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reserveIDs increments the ID counter by n and returns the first of the n reserved IDs. The first
+// reservation seeds the counter with the highest stored ID, which is the only scan of the table
+func (ds *customerDynamoDataSource) reserveIDs(ctx context.Context, n int) (int, error) {
+	for seeded := false; ; seeded = true {
+		output, err := ds.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(ds.db.TableName),
+			Key:                       metadataKey(),
+			UpdateExpression:          aws.String("SET #last = #last + :n"),
+			ConditionExpression:       aws.String("attribute_exists(#last)"),
+			ExpressionAttributeNames:  map[string]string{"#last": lastIDAttribute},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":n": numberValue(n)},
+			ReturnValues:              types.ReturnValueAllNew,
+		})
+
+		var conditionFailed *types.ConditionalCheckFailedException
+		switch {
+		case errors.As(err, &conditionFailed) && !seeded:
+			if err := ds.seedIDs(ctx); err != nil {
+				return 0, err
+			}
+			continue
+		case err != nil:
+			return 0, err
+		}
+
+		var lastID int
+		if err := attributevalue.Unmarshal(output.Attributes[lastIDAttribute], &lastID); err != nil {
+			return 0, err
+		}
+		return lastID - n + 1, nil
+	}
+}
+
+// seedIDs starts the ID counter at the highest stored ID, unless another request started it first
+func (ds *customerDynamoDataSource) seedIDs(ctx context.Context) error {
+	items, err := ds.scanAll(ctx, &dynamodb.ScanInput{
+		TableName:            aws.String(ds.db.TableName),
+		ProjectionExpression: aws.String("id"),
+	})
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	maxID := 0
-	for _, item := range result.Items {
+	for _, item := range items {
 		var customerModel CustomerDynamoModel
-		err = attributevalue.UnmarshalMap(item, &customerModel)
-		if err != nil {
-			continue
-		}
-		if customerModel.ID > maxID {
-			maxID = customerModel.ID
+		if err := attributevalue.UnmarshalMap(item, &customerModel); err != nil {
+			return err
 		}
+		maxID = max(maxID, customerModel.ID)
 	}
 
-	return maxID + 1, nil
+	_, err = ds.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(ds.db.TableName),
+		Key:                       metadataKey(),
+		UpdateExpression:          aws.String("SET #last = :max"),
+		ConditionExpression:       aws.String("attribute_not_exists(#last)"),
+		ExpressionAttributeNames:  map[string]string{"#last": lastIDAttribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":max": numberValue(maxID)},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+// advanceID moves the ID counter past a chosen ID, so the next reserved IDs don't collide with it. A counter
+// not seeded yet is left alone, the seeding scan finds the ID
+func (ds *customerDynamoDataSource) advanceID(ctx context.Context, id int) error {
+	_, err := ds.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(ds.db.TableName),
+		Key:                       metadataKey(),
+		UpdateExpression:          aws.String("SET #last = :id"),
+		ConditionExpression:       aws.String("#last < :id"),
+		ExpressionAttributeNames:  map[string]string{"#last": lastIDAttribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":id": numberValue(id)},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+func metadataKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"id": numberValue(database.MigrationsMetadataID)}
+}
+
+func numberValue(n int) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", n)}
 }
 
 func (ds *customerDynamoDataSource) Create(ctx context.Context, customer *entity.Customer) error {
 	startTime := time.Now()
 
+	err := ds.create(ctx, customer)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Create", ds.db.TableName, duration, err)
+
+	return err
+}
+
+// create puts the customer item in a transaction with the claims of its CPF and email
+func (ds *customerDynamoDataSource) create(ctx context.Context, customer *entity.Customer) error {
+	var err error
 	if customer.ID == 0 {
-		nextID, err := ds.getNextID(ctx)
-		if err != nil {
-			return err
-		}
-		customer.ID = nextID
+		customer.ID, err = ds.reserveIDs(ctx, 1)
+	} else {
+		err = ds.advanceID(ctx, customer.ID)
 	}
-
-	customerModel := CustomerDynamoModel{
-		ID:    customer.ID,
-		CPF:   customer.CPF,
-		Name:  customer.Name,
-		Email: customer.Email,
+	if err != nil {
+		return err
 	}
 
-	item, err := attributevalue.MarshalMap(customerModel)
+	customer.Version = 1
+	actions, err := ds.putActions(ctx, customer, transactCreate, nil)
 	if err != nil {
 		return err
 	}
+	return ds.transact(ctx, actions)
+}
 
-	input := &dynamodb.PutItemInput{
-		TableName:           aws.String(ds.db.TableName),
-		Item:                item,
-		ConditionExpression: aws.String("attribute_not_exists(id)"), // Prevent overwrite
+// putActions returns the put of the customer item, with its create or version condition, followed by the
+// claims and releases of its unique values. previous is the stored item replaced by the put
+func (ds *customerDynamoDataSource) putActions(ctx context.Context, customer *entity.Customer, kind transactKind, previous *CustomerDynamoModel) ([]transactAction, error) {
+	model, err := ds.encryptedModel(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+	item, err := attributevalue.MarshalMap(model)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = ds.db.Client.PutItem(ctx, input)
+	put := &types.Put{
+		TableName:                           aws.String(ds.db.TableName),
+		Item:                                item,
+		ConditionExpression:                 aws.String(createCondition),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+	if kind == transactReplace {
+		put.ConditionExpression = aws.String(versionCondition(customer.Version - 1))
+		put.ExpressionAttributeNames = map[string]string{"#version": "version"}
+		put.ExpressionAttributeValues = map[string]types.AttributeValue{":version": numberValue(customer.Version - 1)}
+	}
+
+	actions := []transactAction{{kind: kind, write: types.TransactWriteItem{Put: put}}}
+	return append(actions, ds.uniquenessActions(customer.ID, previous, &model)...), nil
+}
+
+// SaveBatch writes the customers with TransactWriteItems, each with the claims of its CPF and email, in
+// chunks of up to 100 actions. The new customers receive consecutive IDs reserved from the counter and
+// can't overwrite a stored item, and the others carry the version condition of Update. A chunk is written
+// whole or not at all, but a failing chunk leaves the previous ones written
+func (ds *customerDynamoDataSource) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	startTime := time.Now()
+
+	err := ds.saveBatch(ctx, customers)
 
 	duration := time.Since(startTime)
-	ds.db.LogOperation(ctx, "Create", ds.db.TableName, duration, err)
+	ds.db.LogOperation(ctx, "SaveBatch", ds.db.TableName, duration, err)
 
 	return err
 }
 
+func (ds *customerDynamoDataSource) saveBatch(ctx context.Context, customers []*entity.Customer) error {
+	created := 0
+	for _, customer := range customers {
+		if customer.ID == 0 {
+			created++
+		}
+	}
+	previous, err := ds.storedModels(ctx, customers)
+	if err != nil {
+		return err
+	}
+	nextID := 0
+	if created > 0 {
+		firstID, err := ds.reserveIDs(ctx, created)
+		if err != nil {
+			return err
+		}
+		nextID = firstID
+	}
+
+	// A transaction can't write an item twice, so a value repeated in the batch fails it up front
+	uniqueKeys := make(map[string]bool)
+	var chunk []transactAction
+	for _, customer := range customers {
+		kind := transactReplace
+		if customer.ID == 0 {
+			customer.ID = nextID
+			customer.Version = 1
+			nextID++
+			kind = transactCreate
+		}
+
+		actions, err := ds.putActions(ctx, customer, kind, previous[customer.ID])
+		if err != nil {
+			return err
+		}
+		for _, action := range actions[1:] {
+			if uniqueKeys[action.key] {
+				return domain.NewConflictError(action.message)
+			}
+			uniqueKeys[action.key] = true
+		}
+
+		if len(chunk)+len(actions) > maxTransactWriteItems {
+			if err := ds.transact(ctx, chunk); err != nil {
+				return err
+			}
+			chunk = nil
+		}
+		chunk = append(chunk, actions...)
+	}
+	if len(chunk) == 0 {
+		return nil
+	}
+	return ds.transact(ctx, chunk)
+}
+
+// storedModels reads the stored items of the customers having an ID, whose unique values they replace
+func (ds *customerDynamoDataSource) storedModels(ctx context.Context, customers []*entity.Customer) (map[int]*CustomerDynamoModel, error) {
+	models := make(map[int]*CustomerDynamoModel)
+	if ds.db.UniquenessTableName == "" {
+		return models, nil
+	}
+
+	var keys []map[string]types.AttributeValue
+	for _, customer := range customers {
+		if customer.ID != 0 {
+			keys = append(keys, map[string]types.AttributeValue{"id": numberValue(customer.ID)})
+		}
+	}
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		items, err := ds.batchGet(ctx, keys[start:min(start+maxBatchGetKeys, len(keys))], true)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			var model CustomerDynamoModel
+			if err := attributevalue.UnmarshalMap(item, &model); err != nil {
+				return nil, err
+			}
+			models[model.ID] = &model
+		}
+	}
+	return models, nil
+}
+
 func (ds *customerDynamoDataSource) Update(ctx context.Context, customer *entity.Customer) error {
 	startTime := time.Now()
 
+	err := ds.update(ctx, customer)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Update", ds.db.TableName, duration, err)
+
+	return err
+}
+
+func (ds *customerDynamoDataSource) update(ctx context.Context, customer *entity.Customer) error {
+	model, err := ds.encryptedModel(ctx, customer)
+	if err != nil {
+		return err
+	}
+
+	nextVersion := customer.Version + 1
 	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(ds.db.TableName),
 		Key: map[string]types.AttributeValue{
 			"id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", customer.ID)},
 		},
-		UpdateExpression: aws.String("SET #name = :name, email = :email, cpf = :cpf"),
 		ExpressionAttributeNames: map[string]string{
-			"#name": "name", // 'name' is a reserved keyword in DynamoDB
+			"#name":    "name", // 'name' and 'status' are reserved keywords in DynamoDB
+			"#version": "version",
+			"#status":  "status",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":name":  &types.AttributeValueMemberS{Value: customer.Name},
-			":email": &types.AttributeValueMemberS{Value: customer.Email},
-			":cpf":   &types.AttributeValueMemberS{Value: customer.CPF},
+			":email":        &types.AttributeValueMemberS{Value: model.Email},
+			":cpf":          &types.AttributeValueMemberS{Value: model.CPF},
+			":version":      &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", customer.Version)},
+			":next_version": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", nextVersion)},
+			":status":       &types.AttributeValueMemberS{Value: string(storedStatus(string(customer.Status)))},
 		},
-		ConditionExpression: aws.String("attribute_exists(id)"), // Ensure item exists
+		ConditionExpression:                 aws.String(versionCondition(customer.Version)),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 	}
 
-	_, err := ds.db.Client.UpdateItem(ctx, input)
+	sets := []string{"email = :email", "cpf = :cpf", "#version = :next_version", "#status = :status"}
+	removes := []string{}
+	// An encrypted item has no plaintext name
+	if model.Name != "" {
+		sets = append(sets, "#name = :name")
+		input.ExpressionAttributeValues[":name"] = &types.AttributeValueMemberS{Value: model.Name}
+	} else {
+		removes = append(removes, "#name")
+	}
+	setOrRemove := func(attribute string, value types.AttributeValue) {
+		if value == nil {
+			removes = append(removes, attribute)
+			return
+		}
+		sets = append(sets, attribute+" = :"+attribute)
+		input.ExpressionAttributeValues[":"+attribute] = value
+	}
 
-	duration := time.Since(startTime)
-	ds.db.LogOperation(ctx, "Update", ds.db.TableName, duration, err)
+	var statusReason types.AttributeValue
+	if customer.StatusReason != "" {
+		statusReason = &types.AttributeValueMemberS{Value: customer.StatusReason}
+	}
+	setOrRemove("status_reason", statusReason)
 
-	return err
+	// Writing an item written before the field-level encryption was enabled encrypts it
+	setOrRemove("name_enc", binaryAttribute(model.EncryptedName))
+	setOrRemove("email_enc", binaryAttribute(model.EncryptedEmail))
+	setOrRemove("cpf_enc", binaryAttribute(model.EncryptedCPF))
+	setOrRemove("data_key", binaryAttribute(model.DataKey))
+
+	statusChangedAt, err := marshalTime(customer.StatusChangedAt)
+	if err != nil {
+		return err
+	}
+	setOrRemove("status_changed_at", statusChangedAt)
+
+	anonymizedAt, err := marshalTime(customer.AnonymizedAt)
+	if err != nil {
+		return err
+	}
+	setOrRemove("anonymized_at", anonymizedAt)
+
+	var consents types.AttributeValue
+	if len(customer.Consents) > 0 {
+		if consents, err = attributevalue.Marshal(toCustomerConsentModels(customer.Consents)); err != nil {
+			return err
+		}
+	}
+	setOrRemove("consents", consents)
+
+	// Deleting sets the TTL of the customer, and restoring removes it
+	deletedAt, err := marshalTime(customer.DeletedAt)
+	if err != nil {
+		return err
+	}
+	setOrRemove("deleted_at", deletedAt)
+
+	var purgeAt types.AttributeValue
+	if seconds := ds.purgeAt(customer); seconds > 0 {
+		purgeAt = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", seconds)}
+	}
+	setOrRemove(database.CustomersPurgeAtAttribute, purgeAt)
+
+	update := "SET " + strings.Join(sets, ", ")
+	if len(removes) > 0 {
+		update += " REMOVE " + strings.Join(removes, ", ")
+	}
+	input.UpdateExpression = aws.String(update)
+
+	claims, err := ds.changedUniqueValues(ctx, customer.ID, customer.Version, &model)
+	if err != nil {
+		return err
+	}
+	if len(claims) == 0 {
+		if _, err := ds.client.UpdateItem(ctx, input); err != nil {
+			return conditionalCheckError(err)
+		}
+	} else {
+		write := types.TransactWriteItem{Update: &types.Update{
+			TableName:                           input.TableName,
+			Key:                                 input.Key,
+			UpdateExpression:                    input.UpdateExpression,
+			ConditionExpression:                 input.ConditionExpression,
+			ExpressionAttributeNames:            input.ExpressionAttributeNames,
+			ExpressionAttributeValues:           input.ExpressionAttributeValues,
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		}}
+		actions := append([]transactAction{{kind: transactReplace, write: write}}, claims...)
+		if err := ds.transact(ctx, actions); err != nil {
+			return err
+		}
+	}
+
+	customer.Version = nextVersion
+	return nil
+}
+
+// changedUniqueValues returns the claims and releases of the unique values a write of the stored customer
+// changes into the current model, nil for a removed customer. The stored customer must still have the
+// version read by the caller, as the values compared come from it
+func (ds *customerDynamoDataSource) changedUniqueValues(ctx context.Context, id, version int, current *CustomerDynamoModel) ([]transactAction, error) {
+	if ds.db.UniquenessTableName == "" {
+		return nil, nil
+	}
+
+	stored, err := ds.storedModel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	if stored.Version != version {
+		return nil, domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+	return ds.uniquenessActions(id, stored, current), nil
+}
+
+// binaryAttribute returns the attribute of an optional binary value, nil when it's empty
+func binaryAttribute(value []byte) types.AttributeValue {
+	if len(value) == 0 {
+		return nil
+	}
+	return &types.AttributeValueMemberB{Value: value}
+}
+
+// marshalTime returns the attribute of an optional time, nil when it isn't set
+func marshalTime(t *time.Time) (types.AttributeValue, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return attributevalue.Marshal(*t)
 }
 
-func (ds *customerDynamoDataSource) Delete(ctx context.Context, id int) error {
+func (ds *customerDynamoDataSource) Delete(ctx context.Context, id int, version int) error {
 	startTime := time.Now()
 
+	err := ds.delete(ctx, id, version)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Delete", ds.db.TableName, duration, err)
+
+	return err
+}
+
+// delete removes the customer item, releasing its CPF and email in the same transaction
+func (ds *customerDynamoDataSource) delete(ctx context.Context, id int, version int) error {
 	input := &dynamodb.DeleteItemInput{
 		TableName: aws.String(ds.db.TableName),
 		Key: map[string]types.AttributeValue{
 			"id": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", id)},
 		},
-		ConditionExpression: aws.String("attribute_exists(id)"), // Ensure item exists
+		ConditionExpression: aws.String(versionCondition(version)),
+		ExpressionAttributeNames: map[string]string{
+			"#version": "version",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", version)},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 	}
 
-	_, err := ds.db.Client.DeleteItem(ctx, input)
+	releases, err := ds.changedUniqueValues(ctx, id, version, nil)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		_, err := ds.client.DeleteItem(ctx, input)
+		return conditionalCheckError(err)
+	}
 
-	duration := time.Since(startTime)
-	ds.db.LogOperation(ctx, "Delete", ds.db.TableName, duration, err)
+	write := types.TransactWriteItem{Delete: &types.Delete{
+		TableName:                           input.TableName,
+		Key:                                 input.Key,
+		ConditionExpression:                 input.ConditionExpression,
+		ExpressionAttributeNames:            input.ExpressionAttributeNames,
+		ExpressionAttributeValues:           input.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}}
+	return ds.transact(ctx, append([]transactAction{{kind: transactReplace, write: write}}, releases...))
+}
 
-	return err
+// versionCondition only lets a write through while the item still has the version read by the caller.
+// Items written before the version attribute existed are read as version 0
+func versionCondition(version int) string {
+	if version == 0 {
+		return "attribute_exists(id) AND (attribute_not_exists(#version) OR #version = :version)"
+	}
+	return "attribute_exists(id) AND #version = :version"
+}
+
+// conditionalCheckError translates a failed version condition into a domain error: the item is either
+// gone or was changed by another request since it was read
+func conditionalCheckError(err error) error {
+	var conditionFailed *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionFailed) {
+		return err
+	}
+	if conditionFailed.Item == nil {
+		return domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	return domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
 }
@@ -0,0 +1,750 @@
+package datasource_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database/dynamotest"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/keyprovider"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+const (
+	fakeCustomersTable  = "customers"
+	fakeUniquenessTable = "customer-uniqueness"
+)
+
+func newFakeCustomerDynamoDataSource(client *dynamotest.FakeClient, resilience database.ResilienceOptions) port.CustomerDataSource {
+	l := logger.NewLogger(&config.Config{Environment: "test"})
+	db := newFakeCustomerDynamoDatabase(client, l)
+	db.Resilience = resilience
+	return datasource.NewCustomerDynamoDataSource(db)
+}
+
+// newFakeCustomerDynamoDatabase returns a database of the customers and their uniqueness items on the client
+func newFakeCustomerDynamoDatabase(client *dynamotest.FakeClient, l *logger.Logger) *database.DynamoDatabase {
+	client.SetHashKey(fakeUniquenessTable, database.UniquenessKeyAttribute)
+	db := database.NewDynamoItemDatabase(client, fakeCustomersTable, l)
+	db.UniquenessTableName = fakeUniquenessTable
+	return db
+}
+
+func fakeCustomerItem(id, version string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberN{Value: id},
+		"cpf":     &types.AttributeValueMemberS{Value: "12345678900"},
+		"name":    &types.AttributeValueMemberS{Value: "John Doe"},
+		"email":   &types.AttributeValueMemberS{Value: "john.doe@example.com"},
+		"version": &types.AttributeValueMemberN{Value: version},
+	}
+}
+
+func TestCustomerDynamoDataSourceFakeConformance(t *testing.T) {
+	suite.Run(t, NewCustomerDataSourceConformanceTestSuite(func() port.CustomerDataSource {
+		return newFakeCustomerDynamoDataSource(dynamotest.NewFakeClient(), database.ResilienceOptions{})
+	}))
+}
+
+func TestCustomerDynamoDataSource_ReadErrors(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		operation string
+		item      map[string]types.AttributeValue
+		read      func(ds port.CustomerDataSource) error
+	}{
+		{
+			name:      "should return the error of FindByID",
+			operation: dynamotest.OperationGetItem,
+			read: func(ds port.CustomerDataSource) error {
+				_, err := ds.FindByID(ctx, 1)
+				return err
+			},
+		},
+		{
+			name:      "should return the error of FindByCPF",
+			operation: dynamotest.OperationQuery,
+			read: func(ds port.CustomerDataSource) error {
+				_, err := ds.FindByCPF(ctx, "12345678900")
+				return err
+			},
+		},
+		{
+			name:      "should return the error of FindByIDs",
+			operation: dynamotest.OperationBatchGetItem,
+			read: func(ds port.CustomerDataSource) error {
+				_, err := ds.FindByIDs(ctx, []int{1})
+				return err
+			},
+		},
+		{
+			name:      "should return the error of FindAll",
+			operation: dynamotest.OperationScan,
+			read: func(ds port.CustomerDataSource) error {
+				_, _, err := ds.FindAll(ctx, map[string]interface{}{}, 1, 10)
+				return err
+			},
+		},
+		{
+			name:      "should return the error of the next ID scan",
+			operation: dynamotest.OperationScan,
+			read: func(ds port.CustomerDataSource) error {
+				return ds.Create(ctx, &entity.Customer{Name: "John Doe", CPF: "12345678900"})
+			},
+		},
+		{
+			name:      "should return the error of Create",
+			operation: dynamotest.OperationTransactWriteItems,
+			read: func(ds port.CustomerDataSource) error {
+				return ds.Create(ctx, &entity.Customer{Name: "John Doe", CPF: "12345678900"})
+			},
+		},
+		{
+			name:      "should return the error of Update",
+			operation: dynamotest.OperationUpdateItem,
+			item:      fakeCustomerItem("1", "1"),
+			read: func(ds port.CustomerDataSource) error {
+				return ds.Update(ctx, &entity.Customer{ID: 1, Name: "John Doe", CPF: "12345678900", Email: "john.doe@example.com", Version: 1})
+			},
+		},
+		{
+			name:      "should return the error of an Update changing the email",
+			operation: dynamotest.OperationTransactWriteItems,
+			item:      fakeCustomerItem("1", "1"),
+			read: func(ds port.CustomerDataSource) error {
+				return ds.Update(ctx, &entity.Customer{ID: 1, Name: "John Doe", CPF: "12345678900", Version: 1})
+			},
+		},
+		{
+			name:      "should return the error of Delete",
+			operation: dynamotest.OperationTransactWriteItems,
+			item:      fakeCustomerItem("1", "1"),
+			read: func(ds port.CustomerDataSource) error {
+				return ds.Delete(ctx, 1, 1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamotest.NewFakeClient()
+			if tt.item != nil {
+				client.Put(fakeCustomersTable, tt.item)
+			}
+			client.FailAlways(tt.operation, failure)
+			ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+			err := tt.read(ds)
+
+			assert.ErrorIs(t, err, failure)
+			assert.Len(t, client.CallsOf(tt.operation), 1, "a failure that isn't retryable must not be retried")
+		})
+	}
+}
+
+func TestCustomerDynamoDataSource_ConditionalWrites(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		items   []map[string]types.AttributeValue
+		write   func(ds port.CustomerDataSource) error
+		wantErr any
+	}{
+		{
+			name:  "should return conflict when the ID is taken",
+			items: []map[string]types.AttributeValue{fakeCustomerItem("7", "1")},
+			write: func(ds port.CustomerDataSource) error {
+				return ds.Create(ctx, &entity.Customer{ID: 7, CPF: "12345678900"})
+			},
+			wantErr: &domain.ConflictError{},
+		},
+		{
+			name:    "should return not found when updating a missing customer",
+			write:   func(ds port.CustomerDataSource) error { return ds.Update(ctx, &entity.Customer{ID: 1, Version: 1}) },
+			wantErr: &domain.NotFoundError{},
+		},
+		{
+			name:    "should return precondition failed when updating a modified customer",
+			items:   []map[string]types.AttributeValue{fakeCustomerItem("1", "2")},
+			write:   func(ds port.CustomerDataSource) error { return ds.Update(ctx, &entity.Customer{ID: 1, Version: 1}) },
+			wantErr: &domain.PreconditionFailedError{},
+		},
+		{
+			name:    "should return not found when deleting a missing customer",
+			write:   func(ds port.CustomerDataSource) error { return ds.Delete(ctx, 1, 1) },
+			wantErr: &domain.NotFoundError{},
+		},
+		{
+			name:    "should return precondition failed when deleting a modified customer",
+			items:   []map[string]types.AttributeValue{fakeCustomerItem("1", "2")},
+			write:   func(ds port.CustomerDataSource) error { return ds.Delete(ctx, 1, 1) },
+			wantErr: &domain.PreconditionFailedError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamotest.NewFakeClient()
+			for _, item := range tt.items {
+				client.Put(fakeCustomersTable, item)
+			}
+			ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+			err := tt.write(ds)
+
+			assert.IsType(t, tt.wantErr, err)
+			assert.Equal(t, tt.items, nilIfEmpty(client.Items(fakeCustomersTable)), "a failed write must not change the table")
+		})
+	}
+}
+
+func TestCustomerDynamoDataSource_InvalidItems(t *testing.T) {
+	ctx := context.Background()
+	client := dynamotest.NewFakeClient()
+	item := fakeCustomerItem("1", "1")
+	item["version"] = &types.AttributeValueMemberS{Value: "one"}
+	client.Put(fakeCustomersTable, item)
+	ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+	_, err := ds.FindByID(ctx, 1)
+	assert.Error(t, err)
+
+	_, _, err = ds.FindAll(ctx, map[string]interface{}{}, 1, 10)
+	assert.Error(t, err)
+
+	_, err = ds.FindByCPF(ctx, "12345678900")
+	assert.Error(t, err)
+}
+
+func TestCustomerDynamoDataSource_Throttling(t *testing.T) {
+	ctx := context.Background()
+	throttled := &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException"}
+	resilience := database.ResilienceOptions{MaxAttempts: 2, RetryBaseDelay: time.Millisecond, RetryMaxDelay: time.Millisecond}
+
+	t.Run("should retry a throttled read", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("1", "1"))
+		client.FailNext(dynamotest.OperationGetItem, throttled)
+		ds := newFakeCustomerDynamoDataSource(client, resilience)
+
+		customer, err := ds.FindByID(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, customer.ID)
+		assert.Len(t, client.CallsOf(dynamotest.OperationGetItem), 2)
+	})
+
+	t.Run("should return service unavailable when every attempt is throttled", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("1", "1"))
+		client.FailAlways(dynamotest.OperationUpdateItem, throttled)
+		ds := newFakeCustomerDynamoDataSource(client, resilience)
+
+		err := ds.Update(ctx, &entity.Customer{ID: 1, CPF: "12345678900", Email: "john.doe@example.com", Version: 1})
+
+		var unavailable *domain.ServiceUnavailableError
+		assert.ErrorAs(t, err, &unavailable)
+		assert.Len(t, client.CallsOf(dynamotest.OperationUpdateItem), 2)
+	})
+}
+
+func TestCustomerDynamoDataSource_FindByIDs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should read more than 100 IDs in chunks", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ids := make([]int, 0, 150)
+		for id := 150; id >= 1; id-- {
+			client.Put(fakeCustomersTable, fakeCustomerItem(strconv.Itoa(id), "1"))
+			ids = append(ids, id)
+		}
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		customers, err := ds.FindByIDs(ctx, ids)
+
+		require.NoError(t, err)
+		require.Len(t, customers, 150)
+		assert.Equal(t, 150, customers[0].ID)
+		assert.Equal(t, 1, customers[149].ID)
+		assert.Len(t, client.CallsOf(dynamotest.OperationBatchGetItem), 2)
+	})
+
+	t.Run("should retry the unprocessed keys", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		for _, id := range []string{"1", "2", "3"} {
+			client.Put(fakeCustomersTable, fakeCustomerItem(id, "1"))
+		}
+		client.LimitBatch(2)
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		customers, err := ds.FindByIDs(ctx, []int{3, 2, 1})
+
+		require.NoError(t, err)
+		require.Len(t, customers, 3)
+		assert.Equal(t, []int{3, 2, 1}, []int{customers[0].ID, customers[1].ID, customers[2].ID})
+		calls := client.CallsOf(dynamotest.OperationBatchGetItem)
+		require.Len(t, calls, 2)
+		retried := calls[1].Input.(*dynamodb.BatchGetItemInput)
+		assert.Len(t, retried.RequestItems[fakeCustomersTable].Keys, 1, "only the unprocessed key must be requested again")
+	})
+
+	t.Run("should return service unavailable when keys stay unprocessed", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.LimitBatch(1)
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		ids := []int{1, 2, 3, 4, 5, 6, 7}
+		_, err := ds.FindByIDs(ctx, ids)
+
+		var unavailable *domain.ServiceUnavailableError
+		assert.ErrorAs(t, err, &unavailable)
+		assert.Len(t, client.CallsOf(dynamotest.OperationBatchGetItem), 5)
+	})
+
+	t.Run("should skip the migrations metadata item", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("0", "1"))
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		customers, err := ds.FindByIDs(ctx, []int{database.MigrationsMetadataID})
+
+		require.NoError(t, err)
+		assert.Empty(t, customers)
+		assert.Empty(t, client.CallsOf(dynamotest.OperationBatchGetItem))
+	})
+}
+
+func TestCustomerDynamoDataSource_SaveBatch(t *testing.T) {
+	ctx := context.Background()
+
+	imported := 0
+	newCustomers := func(n int) []*entity.Customer {
+		customers := make([]*entity.Customer, n)
+		for i := range customers {
+			imported++
+			customers[i] = &entity.Customer{Name: "Imported", Email: fmt.Sprintf("imported%d@example.com", imported), CPF: fmt.Sprintf("%011d", imported)}
+		}
+		return customers
+	}
+
+	t.Run("should write more than 100 customers in chunks with consecutive IDs", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("7", "1"))
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+		customers := newCustomers(130)
+
+		err := ds.SaveBatch(ctx, customers)
+
+		require.NoError(t, err)
+		assert.Equal(t, 8, customers[0].ID)
+		assert.Equal(t, 137, customers[129].ID)
+		assert.Len(t, fakeCustomerItems(client), 131)
+		assert.Len(t, client.Items(fakeUniquenessTable), 260)
+		assert.Len(t, client.CallsOf(dynamotest.OperationTransactWriteItems), 4, "a customer and its CPF and email claims take 3 actions")
+	})
+
+	t.Run("should reserve the next IDs from the counter, scanning the table only once", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("7", "1"))
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		require.NoError(t, ds.SaveBatch(ctx, newCustomers(2)))
+		customers := newCustomers(3)
+		require.NoError(t, ds.SaveBatch(ctx, customers))
+		created := &entity.Customer{CPF: "99999999999"}
+		require.NoError(t, ds.Create(ctx, created))
+
+		assert.Equal(t, []int{10, 11, 12}, []int{customers[0].ID, customers[1].ID, customers[2].ID})
+		assert.Equal(t, 13, created.ID)
+		assert.Len(t, client.CallsOf(dynamotest.OperationScan), 1)
+	})
+
+	t.Run("should move the counter past a chosen ID", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+		require.NoError(t, ds.SaveBatch(ctx, newCustomers(1)))
+
+		require.NoError(t, ds.Create(ctx, &entity.Customer{ID: 50, CPF: "99999999999"}))
+		customers := newCustomers(1)
+		require.NoError(t, ds.SaveBatch(ctx, customers))
+
+		assert.Equal(t, 51, customers[0].ID)
+	})
+
+	t.Run("should return conflict when a new customer would overwrite a stored one", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, map[string]types.AttributeValue{
+			"id":               &types.AttributeValueMemberN{Value: "0"},
+			"last_customer_id": &types.AttributeValueMemberN{Value: "0"},
+		})
+		client.Put(fakeCustomersTable, fakeCustomerItem("2", "1"))
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		err := ds.SaveBatch(ctx, newCustomers(2))
+
+		var conflict *domain.ConflictError
+		assert.ErrorAs(t, err, &conflict)
+		assert.Len(t, fakeCustomerItems(client), 1, "a failed chunk must not be written")
+	})
+
+	tests := []struct {
+		name    string
+		items   []map[string]types.AttributeValue
+		wantErr any
+	}{
+		{
+			name:    "should return precondition failed when a replaced customer was changed",
+			items:   []map[string]types.AttributeValue{fakeCustomerItem("1", "3")},
+			wantErr: &domain.PreconditionFailedError{},
+		},
+		{
+			name:    "should return not found when a replaced customer is gone",
+			wantErr: &domain.NotFoundError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamotest.NewFakeClient()
+			for _, item := range tt.items {
+				client.Put(fakeCustomersTable, item)
+			}
+			ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+			batch := append(newCustomers(1), &entity.Customer{ID: 1, Version: 3, CPF: "12345678900"})
+
+			err := ds.SaveBatch(ctx, batch)
+
+			assert.IsType(t, tt.wantErr, err)
+			assert.Equal(t, tt.items, nilIfEmpty(fakeCustomerItems(client)), "a failed chunk must not be written")
+		})
+	}
+}
+
+func TestCustomerDynamoDataSource_Uniqueness(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should take over the values of a purged customer", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+		client.Put(fakeUniquenessTable, map[string]types.AttributeValue{
+			database.UniquenessKeyAttribute: &types.AttributeValueMemberS{Value: "cpf#12345678900"},
+			"customer_id":                   &types.AttributeValueMemberN{Value: "5"},
+		})
+
+		customer := &entity.Customer{Name: "John Doe", Email: "john.doe@example.com", CPF: "12345678900"}
+		err := ds.Create(ctx, customer)
+
+		require.NoError(t, err)
+		require.Len(t, client.Items(fakeUniquenessTable), 2)
+		for _, item := range client.Items(fakeUniquenessTable) {
+			assert.Equal(t, &types.AttributeValueMemberN{Value: strconv.Itoa(customer.ID)}, item["customer_id"])
+		}
+	})
+
+	t.Run("should return conflict when a batch repeats a value", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		err := ds.SaveBatch(ctx, []*entity.Customer{
+			{Name: "John Doe", Email: "john.doe@example.com", CPF: "12345678900"},
+			{Name: "Jane Doe", Email: "john.doe@example.com", CPF: "98765432100"},
+		})
+
+		assert.Equal(t, domain.NewConflictError(domain.ErrEmailAlreadyExists), err)
+		assert.Empty(t, fakeCustomerItems(client))
+		assert.Empty(t, client.CallsOf(dynamotest.OperationTransactWriteItems))
+	})
+
+	t.Run("should not reserve the values without a uniqueness table", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		db := database.NewDynamoItemDatabase(client, fakeCustomersTable, logger.NewLogger(&config.Config{Environment: "test"}))
+		ds := datasource.NewCustomerDynamoDataSource(db)
+
+		require.NoError(t, ds.Create(ctx, &entity.Customer{Name: "John Doe", CPF: "12345678900"}))
+		require.NoError(t, ds.Create(ctx, &entity.Customer{Name: "Jane Doe", CPF: "12345678900"}))
+
+		assert.Len(t, fakeCustomerItems(client), 2)
+	})
+}
+
+func TestCustomerDynamoDataSource_Scan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should scan every segment and skip the migrations metadata item", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("0", "1"))
+		for id := 1; id <= 40; id++ {
+			client.Put(fakeCustomersTable, fakeCustomerItem(strconv.Itoa(id), "1"))
+		}
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+		ids := make(map[int]bool)
+		err := ds.Scan(ctx, 4, func(customer *entity.Customer) error {
+			ids[customer.ID] = true
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, ids, 40)
+		assert.False(t, ids[database.MigrationsMetadataID])
+		calls := client.CallsOf(dynamotest.OperationScan)
+		require.Len(t, calls, 4)
+		segments := make(map[int32]bool)
+		for _, call := range calls {
+			input := call.Input.(*dynamodb.ScanInput)
+			assert.Equal(t, int32(4), aws.ToInt32(input.TotalSegments))
+			segments[aws.ToInt32(input.Segment)] = true
+		}
+		assert.Len(t, segments, 4)
+	})
+
+	t.Run("should fail when a segment fails", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("1", "1"))
+		client.FailAlways(dynamotest.OperationScan, errors.New("boom"))
+		ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{MaxAttempts: 1})
+
+		err := ds.Scan(ctx, 2, func(*entity.Customer) error { return nil })
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCustomerDynamoDataSource_ListScanLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		listScanLimit int
+		wantErr       bool
+		wantScans     int
+	}{
+		{name: "should list the customers within the limit", listScanLimit: 3, wantScans: 1},
+		{name: "should stop scanning once the limit is exceeded", listScanLimit: 1, wantErr: true, wantScans: 1},
+		{name: "should scan every customer without a limit", listScanLimit: 0, wantScans: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := dynamotest.NewFakeClient()
+			for _, id := range []string{"1", "2", "3"} {
+				client.Put(fakeCustomersTable, fakeCustomerItem(id, "1"))
+			}
+			db := newFakeCustomerDynamoDatabase(client, logger.NewLogger(&config.Config{Environment: "test"}))
+			db.ListScanLimit = tt.listScanLimit
+			ds := datasource.NewCustomerDynamoDataSource(db)
+
+			customers, total, err := ds.FindAll(ctx, map[string]interface{}{}, 1, 2)
+
+			assert.Len(t, client.CallsOf(dynamotest.OperationScan), tt.wantScans)
+			if tt.wantErr {
+				assert.Equal(t, domain.NewInvalidInputError(domain.ErrListScanLimitExceeded), err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), total)
+			require.Len(t, customers, 2)
+			assert.Equal(t, 1, customers[0].ID)
+		})
+	}
+}
+
+func TestCustomerDynamoDataSource_RecordedRequests(t *testing.T) {
+	ctx := context.Background()
+	client := dynamotest.NewFakeClient()
+	client.Put(fakeCustomersTable, fakeCustomerItem("1", "1"))
+	ds := newFakeCustomerDynamoDataSource(client, database.ResilienceOptions{})
+
+	_, err := ds.FindByCPF(ctx, "12345678900")
+	require.NoError(t, err)
+
+	calls := client.CallsOf(dynamotest.OperationQuery)
+	require.Len(t, calls, 1)
+	input := calls[0].Input.(*dynamodb.QueryInput)
+	assert.Equal(t, database.CustomersCPFIndex, aws.ToString(input.IndexName), "the CPF lookup must use the index")
+	assert.Equal(t, fakeCustomersTable, aws.ToString(input.TableName))
+}
+
+// fakeCustomerItems returns the customer items of the table, without the migrations metadata item
+func fakeCustomerItems(client *dynamotest.FakeClient) []map[string]types.AttributeValue {
+	var items []map[string]types.AttributeValue
+	for _, item := range client.Items(fakeCustomersTable) {
+		if id, ok := item["id"].(*types.AttributeValueMemberN); ok && id.Value == strconv.Itoa(database.MigrationsMetadataID) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func nilIfEmpty(items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+func TestCustomerDynamoDataSource_PurgeAt(t *testing.T) {
+	ctx := context.Background()
+	l := logger.NewLogger(&config.Config{Environment: "test"})
+	client := dynamotest.NewFakeClient()
+	client.Put(fakeCustomersTable, fakeCustomerItem("1", "1"))
+	db := database.NewDynamoItemDatabase(client, fakeCustomersTable, l)
+	db.DeletedRetention = 24 * time.Hour
+	ds := datasource.NewCustomerDynamoDataSource(db)
+
+	customer, err := ds.FindByID(ctx, 1)
+	require.NoError(t, err)
+
+	customer.Delete()
+	require.NoError(t, ds.Update(ctx, customer))
+	items := client.Items(fakeCustomersTable)
+	require.Len(t, items, 1)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: strconv.FormatInt(customer.DeletedAt.Add(24*time.Hour).Unix(), 10)},
+		items[0][database.CustomersPurgeAtAttribute])
+	assert.Contains(t, items[0], "deleted_at")
+
+	customer.Restore()
+	require.NoError(t, ds.Update(ctx, customer))
+	items = client.Items(fakeCustomersTable)
+	require.Len(t, items, 1)
+	assert.NotContains(t, items[0], database.CustomersPurgeAtAttribute, "a restored customer must not be purged")
+	assert.NotContains(t, items[0], "deleted_at")
+}
+
+// newFakeKeyProvider returns a file key provider with a random master key
+func newFakeKeyProvider(t *testing.T) port.KeyProvider {
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "master.key")
+	require.NoError(t, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(masterKey)), 0o600))
+	keys, err := keyprovider.NewFileKeyProvider(path)
+	require.NoError(t, err)
+	return keys
+}
+
+func newFakeEncryptedCustomerDynamoDataSource(client *dynamotest.FakeClient, keys port.KeyProvider) port.CustomerDataSource {
+	db := newFakeCustomerDynamoDatabase(client, logger.NewLogger(&config.Config{Environment: "test"}))
+	db.KeyProvider = keys
+	db.DataKeyTTL = time.Minute
+	return datasource.NewCustomerDynamoDataSource(db)
+}
+
+func TestCustomerDynamoDataSourceEncryptedFakeConformance(t *testing.T) {
+	keys := newFakeKeyProvider(t)
+	suite.Run(t, NewCustomerDataSourceConformanceTestSuite(func() port.CustomerDataSource {
+		return newFakeEncryptedCustomerDynamoDataSource(dynamotest.NewFakeClient(), keys)
+	}))
+}
+
+func TestCustomerDynamoDataSource_Encryption(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should store no personal data in plaintext", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeEncryptedCustomerDynamoDataSource(client, newFakeKeyProvider(t))
+
+		customer := &entity.Customer{Name: "John Doe", Email: "john.doe@example.com", CPF: "12345678900"}
+		require.NoError(t, ds.Create(ctx, customer))
+		customer.Name = "John Smith"
+		require.NoError(t, ds.Update(ctx, customer))
+
+		items := fakeCustomerItems(client)
+		require.Len(t, items, 1)
+		assert.NotContains(t, items[0], "name")
+		for _, attribute := range []string{"name_enc", "email_enc", "cpf_enc", "data_key"} {
+			assert.Contains(t, items[0], attribute)
+		}
+		for attribute, value := range items[0] {
+			for _, plaintext := range []string{"John", "john.doe@example.com", "12345678900"} {
+				assert.NotContains(t, fmt.Sprint(value), plaintext, "attribute %s", attribute)
+			}
+		}
+
+		found, err := ds.FindByCPF(ctx, "12345678900")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "John Smith", found.Name)
+		assert.Equal(t, "john.doe@example.com", found.Email)
+		assert.Equal(t, "12345678900", found.CPF)
+
+		customers, total, err := ds.FindAll(ctx, map[string]interface{}{"email": "john.doe@example.com"}, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "John Smith", customers[0].Name)
+	})
+
+	t.Run("should read and encrypt the items written in plaintext", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.Put(fakeCustomersTable, fakeCustomerItem("1", "1"))
+		ds := newFakeEncryptedCustomerDynamoDataSource(client, newFakeKeyProvider(t))
+
+		customer, err := ds.FindByCPF(ctx, "12345678900")
+		require.NoError(t, err)
+		require.NotNil(t, customer)
+		assert.Equal(t, "John Doe", customer.Name)
+
+		require.NoError(t, ds.Update(ctx, customer))
+		items := fakeCustomerItems(client)
+		require.Len(t, items, 1)
+		assert.NotContains(t, items[0], "name")
+		assert.Contains(t, items[0], "data_key")
+
+		customer, err = ds.FindByCPF(ctx, "12345678900")
+		require.NoError(t, err)
+		require.NotNil(t, customer)
+		assert.Equal(t, "John Doe", customer.Name)
+	})
+
+	t.Run("should not decrypt a ciphertext copied to another customer", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		ds := newFakeEncryptedCustomerDynamoDataSource(client, newFakeKeyProvider(t))
+		require.NoError(t, ds.SaveBatch(ctx, []*entity.Customer{
+			{Name: "John Doe", Email: "john.doe@example.com", CPF: "12345678900"},
+			{Name: "Jane Doe", Email: "jane.doe@example.com", CPF: "98765432100"},
+		}))
+
+		items := fakeCustomerItems(client)
+		require.Len(t, items, 2)
+		item := items[1]
+		item["name_enc"] = items[0]["name_enc"]
+		client.Put(fakeCustomersTable, item)
+
+		_, err := ds.FindByID(ctx, 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail when the data key can't be decrypted", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		require.NoError(t, newFakeEncryptedCustomerDynamoDataSource(client, newFakeKeyProvider(t)).Create(ctx,
+			&entity.Customer{Name: "John Doe", Email: "john.doe@example.com", CPF: "12345678900"}))
+
+		_, err := newFakeEncryptedCustomerDynamoDataSource(client, newFakeKeyProvider(t)).FindByID(ctx, 1)
+		assert.Error(t, err)
+	})
+}
@@ -31,9 +31,10 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) SetupSuite() {
 	suite.ctx = context.Background()
 
 	cfg := &config.Config{
-		DynamoTableName: getTestDynamoTableName(),
-		DynamoRegion:    getTestDynamoRegion(),
-		Environment:     "test",
+		DynamoTableName:     getTestDynamoTableName(),
+		UniquenessTableName: getTestDynamoTableName() + "-uniqueness",
+		DynamoRegion:        getTestDynamoRegion(),
+		Environment:         "test",
 	}
 
 	l := logger.NewLogger(cfg)
@@ -93,77 +94,24 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) deleteAndRecreateTest
 }
 
 func (suite *CustomerDynamoDataSourceIntegrationTestSuite) createTestTableIfNotExists() {
-	// Check if table exists
-	_, err := suite.db.Client.DescribeTable(suite.ctx, &dynamodb.DescribeTableInput{
-		TableName: aws.String(suite.db.TableName),
-	})
-
-	if err != nil {
-		// Table doesn't exist, create it
-		_, err = suite.db.Client.CreateTable(suite.ctx, &dynamodb.CreateTableInput{
-			TableName: aws.String(suite.db.TableName),
-			KeySchema: []types.KeySchemaElement{
-				{
-					AttributeName: aws.String("id"),
-					KeyType:       types.KeyTypeHash,
-				},
-			},
-			AttributeDefinitions: []types.AttributeDefinition{
-				{
-					AttributeName: aws.String("id"),
-					AttributeType: types.ScalarAttributeTypeN,
-				},
-				{
-					AttributeName: aws.String("cpf"),
-					AttributeType: types.ScalarAttributeTypeS,
-				},
-			},
-			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
-				{
-					IndexName: aws.String("cpf-index"),
-					KeySchema: []types.KeySchemaElement{
-						{
-							AttributeName: aws.String("cpf"),
-							KeyType:       types.KeyTypeHash,
-						},
-					},
-					Projection: &types.Projection{
-						ProjectionType: types.ProjectionTypeAll,
-					},
-					ProvisionedThroughput: &types.ProvisionedThroughput{
-						ReadCapacityUnits:  aws.Int64(5),
-						WriteCapacityUnits: aws.Int64(5),
-					},
-				},
-			},
-			ProvisionedThroughput: &types.ProvisionedThroughput{
-				ReadCapacityUnits:  aws.Int64(5),
-				WriteCapacityUnits: aws.Int64(5),
-			},
-		})
+	ctx, cancel := context.WithTimeout(suite.ctx, 30*time.Second)
+	defer cancel()
 
-		if err != nil {
-			suite.T().Logf("Warning: Could not create test table: %v", err)
-		} else {
-			// Wait for the table to be active
-			waiter := dynamodb.NewTableExistsWaiter(suite.db.Client)
-			err = waiter.Wait(suite.ctx, &dynamodb.DescribeTableInput{
-				TableName: aws.String(suite.db.TableName),
-			}, 30*time.Second, func(o *dynamodb.TableExistsWaiterOptions) {
-				o.MaxDelay = 30 * time.Second
-				o.MinDelay = 2 * time.Second
-			})
-			if err != nil {
-				suite.T().Logf("Warning: Table creation wait failed: %v", err)
-			}
-		}
-	}
+	err := suite.db.EnsureTable(ctx, database.CustomersTableSchema(suite.db.TableName))
+	require.NoError(suite.T(), err, "Failed to create test table")
+	err = suite.db.EnsureTable(ctx, database.UniquenessTableSchema(suite.db.UniquenessTableName))
+	require.NoError(suite.T(), err, "Failed to create test uniqueness table")
 }
 
 func (suite *CustomerDynamoDataSourceIntegrationTestSuite) clearTestTable() {
+	suite.clearTable(suite.db.TableName, "id")
+	suite.clearTable(suite.db.UniquenessTableName, database.UniquenessKeyAttribute)
+}
+
+func (suite *CustomerDynamoDataSourceIntegrationTestSuite) clearTable(tableName, keyAttribute string) {
 	// Scan and delete all items
 	scanInput := &dynamodb.ScanInput{
-		TableName: aws.String(suite.db.TableName),
+		TableName: aws.String(tableName),
 	}
 
 	result, err := suite.db.Client.Scan(suite.ctx, scanInput)
@@ -173,11 +121,11 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) clearTestTable() {
 	}
 
 	for _, item := range result.Items {
-		if id, exists := item["id"]; exists {
+		if key, exists := item[keyAttribute]; exists {
 			_, err := suite.db.Client.DeleteItem(suite.ctx, &dynamodb.DeleteItemInput{
-				TableName: aws.String(suite.db.TableName),
+				TableName: aws.String(tableName),
 				Key: map[string]types.AttributeValue{
-					"id": id,
+					keyAttribute: key,
 				},
 			})
 			if err != nil {
@@ -188,6 +136,26 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) clearTestTable() {
 }
 
 func TestCustomerDynamoDataSourceIntegrationTestSuite(t *testing.T) {
+	skipDynamoIntegrationTests(t)
+
+	suite.Run(t, new(CustomerDynamoDataSourceIntegrationTestSuite))
+}
+
+func TestCustomerDynamoDataSourceConformance(t *testing.T) {
+	skipDynamoIntegrationTests(t)
+
+	integration := new(CustomerDynamoDataSourceIntegrationTestSuite)
+	integration.SetT(t)
+	integration.SetupSuite()
+	defer integration.TearDownSuite()
+
+	suite.Run(t, NewCustomerDataSourceConformanceTestSuite(func() port.CustomerDataSource {
+		integration.clearTestTable()
+		return integration.dataSource
+	}))
+}
+
+func skipDynamoIntegrationTests(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
 	}
@@ -201,8 +169,6 @@ func TestCustomerDynamoDataSourceIntegrationTestSuite(t *testing.T) {
 	if getTestDynamoEndpoint() == "" {
 		t.Skip("Skipping DynamoDB integration tests - no endpoint configured")
 	}
-
-	suite.Run(t, new(CustomerDynamoDataSourceIntegrationTestSuite))
 }
 
 func getTestDynamoTableName() string {
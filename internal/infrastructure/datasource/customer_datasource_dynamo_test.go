@@ -279,8 +279,16 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) TestDynamoUpdate() {
 				assert.Equal(t, "Updated Name", updated.Name)
 				assert.Equal(t, "updated@example.com", updated.Email)
 				assert.Equal(t, "123.456.789-00", updated.CPF)
+				assert.Equal(t, 2, updated.Version)
 			},
 		},
+		{
+			name: "should fail to update with a stale version",
+			updateData: func(c *entity.Customer) {
+				c.Name = "Stale Name"
+			},
+			wantErr: true,
+		},
 		{
 			name: "should fail to update with non-existent ID",
 			updateData: func(c *entity.Customer) {
@@ -329,11 +337,19 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) TestDynamoDelete() {
 	tests := []struct {
 		name    string
 		id      int
+		version int
 		wantErr bool
 	}{
+		{
+			name:    "should fail to delete customer with a stale version",
+			id:      customer.ID,
+			version: customer.Version + 1,
+			wantErr: true,
+		},
 		{
 			name:    "should delete existing customer",
 			id:      customer.ID,
+			version: customer.Version,
 			wantErr: false,
 		},
 		{
@@ -345,7 +361,7 @@ func (suite *CustomerDynamoDataSourceIntegrationTestSuite) TestDynamoDelete() {
 
 	for _, tt := range tests {
 		suite.T().Run(tt.name, func(t *testing.T) {
-			err := suite.dataSource.Delete(suite.ctx, tt.id)
+			err := suite.dataSource.Delete(suite.ctx, tt.id, tt.version)
 
 			if tt.wantErr {
 				assert.Error(t, err)
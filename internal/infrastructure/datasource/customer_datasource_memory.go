@@ -0,0 +1,259 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// customerMemoryDataSource keeps the customers in memory, with the same semantics of the DynamoDB
+// data source. It is meant for tests and local runs, the data is lost when the process exits
+type customerMemoryDataSource struct {
+	mu        sync.RWMutex
+	customers map[int]entity.Customer
+	lastID    int
+}
+
+func NewCustomerMemoryDataSource() port.CustomerDataSource {
+	return &customerMemoryDataSource{
+		customers: make(map[int]entity.Customer),
+	}
+}
+
+func (ds *customerMemoryDataSource) FindByID(_ context.Context, id int) (*entity.Customer, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	customer, ok := ds.customers[id]
+	if !ok {
+		return nil, nil
+	}
+	return &customer, nil
+}
+
+func (ds *customerMemoryDataSource) FindByIDs(_ context.Context, ids []int) ([]*entity.Customer, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	customers := make([]*entity.Customer, 0, len(ids))
+	for _, id := range ids {
+		if customer, ok := ds.customers[id]; ok {
+			customers = append(customers, &customer)
+		}
+	}
+	return customers, nil
+}
+
+func (ds *customerMemoryDataSource) FindByCPF(_ context.Context, cpf string) (*entity.Customer, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	for _, customer := range ds.sorted() {
+		if customer.CPF == cpf {
+			return customer, nil
+		}
+	}
+	return nil, nil
+}
+
+func (ds *customerMemoryDataSource) FindAll(_ context.Context, filters map[string]interface{}, page, limit int) ([]*entity.Customer, int64, error) {
+	filters, afterID, err := splitAfterID(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	filters, search := splitSearch(filters)
+	filters, includeDeleted := splitIncludeDeleted(filters)
+
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	matches := make([]*entity.Customer, 0, len(ds.customers))
+	for _, customer := range ds.sorted() {
+		if (includeDeleted || !customer.IsDeleted()) && matchesFilters(customer, filters) && matchesSearch(customer.Name, search) {
+			matches = append(matches, customer)
+		}
+	}
+
+	return pageOf(matches, afterID, page, limit), int64(len(matches)), nil
+}
+
+func (ds *customerMemoryDataSource) Create(_ context.Context, customer *entity.Customer) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if customer.ID == 0 {
+		customer.ID = ds.lastID + 1
+	}
+	if _, exists := ds.customers[customer.ID]; exists {
+		return domain.NewConflictError(domain.ErrConflict)
+	}
+	if err := ds.checkUnique(customer); err != nil {
+		return err
+	}
+	if customer.ID > ds.lastID {
+		ds.lastID = customer.ID
+	}
+
+	customer.Version = 1
+	ds.customers[customer.ID] = *customer
+	return nil
+}
+
+func (ds *customerMemoryDataSource) SaveBatch(_ context.Context, customers []*entity.Customer) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	// Every customer is checked before writing, so a failing batch leaves the customers unchanged
+	batch := make([]entity.Customer, 0, len(customers))
+	for _, customer := range customers {
+		if customer.ID != 0 {
+			stored, exists := ds.customers[customer.ID]
+			if !exists {
+				return domain.NewNotFoundError(domain.ErrNotFound)
+			}
+			if stored.Version != customer.Version-1 {
+				return domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+			}
+		}
+		if err := ds.checkUnique(customer); err != nil {
+			return err
+		}
+		if err := checkUniqueAmong(customer, batch); err != nil {
+			return err
+		}
+		batch = append(batch, *customer)
+	}
+
+	for _, customer := range customers {
+		if customer.ID == 0 {
+			ds.lastID++
+			customer.ID = ds.lastID
+			customer.Version = 1
+		}
+		ds.customers[customer.ID] = *customer
+	}
+	return nil
+}
+
+// Scan visits a snapshot of the customers, so visit may change them
+func (ds *customerMemoryDataSource) Scan(ctx context.Context, _ int, visit func(*entity.Customer) error) error {
+	ds.mu.RLock()
+	customers := ds.sorted()
+	ds.mu.RUnlock()
+
+	for _, customer := range customers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := visit(customer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ds *customerMemoryDataSource) Update(_ context.Context, customer *entity.Customer) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	stored, exists := ds.customers[customer.ID]
+	if !exists {
+		return domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	if stored.Version != customer.Version {
+		return domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+	if err := ds.checkUnique(customer); err != nil {
+		return err
+	}
+
+	customer.Version++
+	stored.Name = customer.Name
+	stored.Email = customer.Email
+	stored.CPF = customer.CPF
+	stored.Version = customer.Version
+	stored.UpdatedAt = customer.UpdatedAt
+	stored.DeletedAt = customer.DeletedAt
+	stored.Status = customer.Status
+	stored.StatusReason = customer.StatusReason
+	stored.StatusChangedAt = customer.StatusChangedAt
+	stored.AnonymizedAt = customer.AnonymizedAt
+	stored.Consents = slices.Clone(customer.Consents)
+	ds.customers[customer.ID] = stored
+	return nil
+}
+
+func (ds *customerMemoryDataSource) Delete(_ context.Context, id int, version int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	stored, exists := ds.customers[id]
+	if !exists {
+		return domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	if stored.Version != version {
+		return domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+	}
+
+	delete(ds.customers, id)
+	return nil
+}
+
+// checkUnique fails when another stored customer has the CPF or email of the customer, the caller must
+// hold the lock
+func (ds *customerMemoryDataSource) checkUnique(customer *entity.Customer) error {
+	others := make([]entity.Customer, 0, len(ds.customers))
+	for id, stored := range ds.customers {
+		if id != customer.ID {
+			others = append(others, stored)
+		}
+	}
+	return checkUniqueAmong(customer, others)
+}
+
+// checkUniqueAmong fails when one of the others has the CPF or email of the customer. Empty values are
+// shared, as with the uniqueness items of the DynamoDB data source
+func checkUniqueAmong(customer *entity.Customer, others []entity.Customer) error {
+	for _, other := range others {
+		if customer.CPF != "" && other.CPF == customer.CPF {
+			return domain.NewConflictError(domain.ErrCPFAlreadyExists)
+		}
+		if customer.Email != "" && strings.EqualFold(other.Email, customer.Email) {
+			return domain.NewConflictError(domain.ErrEmailAlreadyExists)
+		}
+	}
+	return nil
+}
+
+// sorted returns copies of the customers ordered by ID, the caller must hold the lock
+func (ds *customerMemoryDataSource) sorted() []*entity.Customer {
+	customers := make([]*entity.Customer, 0, len(ds.customers))
+	for _, customer := range ds.customers {
+		customers = append(customers, &customer)
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].ID < customers[j].ID })
+	return customers
+}
+
+// matchesFilters applies the equality filters used by FindAll, keyed by the stored attribute name
+func matchesFilters(customer *entity.Customer, filters map[string]interface{}) bool {
+	attributes := map[string]string{
+		"name":  customer.Name,
+		"email": customer.Email,
+		"cpf":   customer.CPF,
+	}
+	for key, value := range filters {
+		attribute, ok := attributes[key]
+		if !ok || attribute != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
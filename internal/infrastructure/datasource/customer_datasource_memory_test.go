@@ -0,0 +1,31 @@
+package datasource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+)
+
+func TestCustomerMemoryDataSourceConformance(t *testing.T) {
+	suite.Run(t, NewCustomerDataSourceConformanceTestSuite(func() port.CustomerDataSource {
+		return datasource.NewCustomerMemoryDataSource()
+	}))
+}
+
+func TestCustomerMemoryDataSource_EmailInAnotherCase(t *testing.T) {
+	ctx := context.Background()
+	dataSource := datasource.NewCustomerMemoryDataSource()
+	require.NoError(t, dataSource.Create(ctx, &entity.Customer{Name: "John Doe", Email: "john.doe@example.com", CPF: "12345678909"}))
+
+	err := dataSource.Create(ctx, &entity.Customer{Name: "Jane Doe", Email: "John.Doe@Example.com", CPF: "98765432100"})
+
+	assert.Equal(t, domain.NewConflictError(domain.ErrEmailAlreadyExists), err)
+}
@@ -0,0 +1,511 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+// mongoCustomersCounter is the counters document holding the last customer ID
+const mongoCustomersCounter = "customers"
+
+// mongoFilterFields are the FindAll equality filters, mapped to their document fields
+var mongoFilterFields = map[string]string{
+	"name":  "name",
+	"email": "email",
+	"cpf":   "cpf",
+}
+
+// mongoConflictMessages explains which unique index a write violated
+var mongoConflictMessages = map[string]string{
+	"_id_":                            domain.ErrConflict,
+	database.MongoCustomersCPFIndex:   domain.ErrCPFAlreadyExists,
+	database.MongoCustomersEmailIndex: domain.ErrEmailAlreadyExists,
+}
+
+type CustomerMongoModel struct {
+	ID              int        `bson:"_id"`
+	Name            string     `bson:"name"`
+	Email           string     `bson:"email"`
+	CPF             string     `bson:"cpf"`
+	Version         int        `bson:"version"`
+	CreatedAt       time.Time  `bson:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at"`
+	DeletedAt       *time.Time `bson:"deleted_at,omitempty"`
+	Status          string     `bson:"status,omitempty"`
+	StatusReason    string     `bson:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `bson:"status_changed_at,omitempty"`
+	AnonymizedAt    *time.Time `bson:"anonymized_at,omitempty"`
+	// Consents are kept on the document, with their history
+	Consents []CustomerConsentModel `bson:"consents,omitempty"`
+}
+
+func (m CustomerMongoModel) toEntity() *entity.Customer {
+	return &entity.Customer{
+		ID:              m.ID,
+		Name:            m.Name,
+		Email:           m.Email,
+		CPF:             m.CPF,
+		Version:         m.Version,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+		DeletedAt:       m.DeletedAt,
+		Status:          storedStatus(m.Status),
+		StatusReason:    m.StatusReason,
+		StatusChangedAt: m.StatusChangedAt,
+		AnonymizedAt:    m.AnonymizedAt,
+		Consents:        toCustomerConsents(m.Consents),
+	}
+}
+
+type customerMongoDataSource struct {
+	db *database.MongoDatabase
+}
+
+func NewCustomerMongoDataSource(db *database.MongoDatabase) port.CustomerDataSource {
+	return &customerMongoDataSource{
+		db: db,
+	}
+}
+
+func (ds *customerMongoDataSource) customers() *mongo.Collection {
+	return ds.db.Database.Collection(database.MongoCustomersCollection)
+}
+
+func (ds *customerMongoDataSource) FindByID(ctx context.Context, id int) (*entity.Customer, error) {
+	startTime := time.Now()
+
+	customer, err := ds.findOne(ctx, bson.M{"_id": id})
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByID", database.MongoCustomersCollection, duration, err)
+
+	return customer, err
+}
+
+func (ds *customerMongoDataSource) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
+	startTime := time.Now()
+
+	customers, err := ds.find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find())
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByIDs", database.MongoCustomersCollection, duration, err)
+
+	if err != nil {
+		return nil, err
+	}
+	return inIDOrder(customers, ids), nil
+}
+
+func (ds *customerMongoDataSource) FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error) {
+	startTime := time.Now()
+
+	customer, err := ds.findOne(ctx, bson.M{"cpf": cpf})
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByCPF", database.MongoCustomersCollection, duration, err)
+
+	return customer, err
+}
+
+func (ds *customerMongoDataSource) findOne(ctx context.Context, filter bson.M) (*entity.Customer, error) {
+	var model CustomerMongoModel
+	err := ds.customers().FindOne(ctx, filter).Decode(&model)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.toEntity(), nil
+}
+
+func (ds *customerMongoDataSource) FindAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*entity.Customer, int64, error) {
+	startTime := time.Now()
+
+	filters, afterID, err := splitAfterID(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	filters, search := splitSearch(filters)
+	filters, includeDeleted := splitIncludeDeleted(filters)
+
+	filter, err := mongoFilter(filters, search)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !includeDeleted {
+		// Matches the documents without the field too
+		filter["deleted_at"] = nil
+	}
+
+	total, err := ds.customers().CountDocuments(ctx, filter)
+	if err != nil {
+		ds.db.LogOperation(ctx, "FindAll", database.MongoCustomersCollection, time.Since(startTime), err)
+		return nil, 0, err
+	}
+
+	// Keyset pagination seeks past the last ID of the previous page through the _id index,
+	// while offset pagination skips the documents of the previous pages
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if afterID > 0 {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+	if limit > 0 {
+		findOptions.SetLimit(int64(limit))
+		if afterID == 0 && page > 1 {
+			findOptions.SetSkip(int64((page - 1) * limit))
+		}
+	}
+
+	customers, err := ds.find(ctx, filter, findOptions)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindAll", database.MongoCustomersCollection, duration, err)
+
+	if err != nil {
+		return nil, 0, err
+	}
+	return customers, total, nil
+}
+
+func (ds *customerMongoDataSource) find(ctx context.Context, filter bson.M, findOptions *options.FindOptions) ([]*entity.Customer, error) {
+	cursor, err := ds.customers().Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []CustomerMongoModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+
+	customers := make([]*entity.Customer, len(models))
+	for i, model := range models {
+		customers[i] = model.toEntity()
+	}
+	return customers, nil
+}
+
+func (ds *customerMongoDataSource) Create(ctx context.Context, customer *entity.Customer) error {
+	startTime := time.Now()
+
+	var err error
+	if customer.ID == 0 {
+		customer.ID, err = ds.nextID(ctx)
+	} else {
+		err = ds.advanceID(ctx, customer.ID)
+	}
+
+	if err == nil {
+		customer.Version = 1
+		_, err = ds.customers().InsertOne(ctx, CustomerMongoModel{
+			ID:        customer.ID,
+			Name:      customer.Name,
+			Email:     customer.Email,
+			CPF:       customer.CPF,
+			Version:   customer.Version,
+			CreatedAt: customer.CreatedAt,
+			UpdatedAt: customer.UpdatedAt,
+			Status:    string(storedStatus(string(customer.Status))),
+		})
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Create", database.MongoCustomersCollection, duration, err)
+
+	return mongoError(err)
+}
+
+// nextID increments the customers counter, creating it on the first customer
+func (ds *customerMongoDataSource) nextID(ctx context.Context) (int, error) {
+	return ds.reserveIDs(ctx, 1)
+}
+
+// reserveIDs increments the customers counter by n and returns the first of the n reserved IDs
+func (ds *customerMongoDataSource) reserveIDs(ctx context.Context, n int) (int, error) {
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err := ds.db.Database.Collection(database.MongoCountersCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": mongoCustomersCounter},
+		bson.M{"$inc": bson.M{"seq": n}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	return counter.Seq - n + 1, err
+}
+
+// advanceID moves the customers counter past a chosen ID, so the next generated IDs don't collide with it
+func (ds *customerMongoDataSource) advanceID(ctx context.Context, id int) error {
+	_, err := ds.db.Database.Collection(database.MongoCountersCollection).UpdateOne(ctx,
+		bson.M{"_id": mongoCustomersCounter},
+		bson.M{"$max": bson.M{"seq": id}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// SaveBatch replaces the customers having an ID one at a time, each with the version check of Update, then
+// inserts the new ones with an ordered bulk write. It stops at the first failing customer, leaving the
+// earlier ones written
+func (ds *customerMongoDataSource) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	startTime := time.Now()
+
+	err := ds.saveBatch(ctx, customers)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "SaveBatch", database.MongoCustomersCollection, duration, err)
+
+	return mongoError(err)
+}
+
+func (ds *customerMongoDataSource) saveBatch(ctx context.Context, customers []*entity.Customer) error {
+	created := 0
+	for _, customer := range customers {
+		if customer.ID == 0 {
+			created++
+			continue
+		}
+
+		result, err := ds.customers().ReplaceOne(ctx,
+			bson.M{"_id": customer.ID, "version": customer.Version - 1},
+			newCustomerMongoModel(customer),
+		)
+		if err == nil {
+			err = ds.checkMatched(ctx, result.MatchedCount, customer.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if created == 0 {
+		return nil
+	}
+
+	nextID, err := ds.reserveIDs(ctx, created)
+	if err != nil {
+		return err
+	}
+	models := make([]mongo.WriteModel, 0, created)
+	for _, customer := range customers {
+		if customer.ID != 0 {
+			continue
+		}
+		customer.ID = nextID
+		customer.Version = 1
+		nextID++
+		models = append(models, mongo.NewInsertOneModel().SetDocument(newCustomerMongoModel(customer)))
+	}
+
+	_, err = ds.customers().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	return err
+}
+
+func newCustomerMongoModel(customer *entity.Customer) CustomerMongoModel {
+	return CustomerMongoModel{
+		ID:              customer.ID,
+		Name:            customer.Name,
+		Email:           customer.Email,
+		CPF:             customer.CPF,
+		Version:         customer.Version,
+		CreatedAt:       customer.CreatedAt,
+		UpdatedAt:       customer.UpdatedAt,
+		DeletedAt:       customer.DeletedAt,
+		Status:          string(storedStatus(string(customer.Status))),
+		StatusReason:    customer.StatusReason,
+		StatusChangedAt: customer.StatusChangedAt,
+		AnonymizedAt:    customer.AnonymizedAt,
+		Consents:        toCustomerConsentModels(customer.Consents),
+	}
+}
+
+// Scan reads the customers through a single cursor, which fetches them in batches
+func (ds *customerMongoDataSource) Scan(ctx context.Context, _ int, visit func(*entity.Customer) error) error {
+	startTime := time.Now()
+
+	err := ds.scan(ctx, visit)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Scan", database.MongoCustomersCollection, duration, err)
+
+	return err
+}
+
+func (ds *customerMongoDataSource) scan(ctx context.Context, visit func(*entity.Customer) error) error {
+	cursor, err := ds.customers().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var model CustomerMongoModel
+		if err := cursor.Decode(&model); err != nil {
+			return err
+		}
+		if err := visit(model.toEntity()); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (ds *customerMongoDataSource) Update(ctx context.Context, customer *entity.Customer) error {
+	startTime := time.Now()
+
+	set := bson.M{
+		"name":       customer.Name,
+		"email":      customer.Email,
+		"cpf":        customer.CPF,
+		"updated_at": customer.UpdatedAt,
+		"status":     string(storedStatus(string(customer.Status))),
+	}
+	unset := bson.M{}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	// The TTL index only expires the documents having the field
+	if customer.DeletedAt != nil {
+		set["deleted_at"] = *customer.DeletedAt
+	} else {
+		unset["deleted_at"] = ""
+	}
+	if customer.StatusReason != "" {
+		set["status_reason"] = customer.StatusReason
+	} else {
+		unset["status_reason"] = ""
+	}
+	if customer.StatusChangedAt != nil {
+		set["status_changed_at"] = *customer.StatusChangedAt
+	} else {
+		unset["status_changed_at"] = ""
+	}
+	if customer.AnonymizedAt != nil {
+		set["anonymized_at"] = *customer.AnonymizedAt
+	} else {
+		unset["anonymized_at"] = ""
+	}
+	if len(customer.Consents) > 0 {
+		set["consents"] = toCustomerConsentModels(customer.Consents)
+	} else {
+		unset["consents"] = ""
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := ds.customers().UpdateOne(ctx, bson.M{"_id": customer.ID, "version": customer.Version}, update)
+	if err == nil {
+		err = ds.checkMatched(ctx, result.MatchedCount, customer.ID)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Update", database.MongoCustomersCollection, duration, err)
+
+	if err != nil {
+		return mongoError(err)
+	}
+
+	customer.Version++
+	return nil
+}
+
+func (ds *customerMongoDataSource) Delete(ctx context.Context, id int, version int) error {
+	startTime := time.Now()
+
+	result, err := ds.customers().DeleteOne(ctx, bson.M{"_id": id, "version": version})
+	if err == nil {
+		err = ds.checkMatched(ctx, result.DeletedCount, id)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Delete", database.MongoCustomersCollection, duration, err)
+
+	return err
+}
+
+// checkMatched tells apart why a conditional write matched no document: the customer is either
+// gone or was changed by another request since it was read
+func (ds *customerMongoDataSource) checkMatched(ctx context.Context, matched int64, id int) error {
+	if matched > 0 {
+		return nil
+	}
+
+	count, err := ds.customers().CountDocuments(ctx, bson.M{"_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	return domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+}
+
+// mongoFilter builds the query of the FindAll equality filters and name search. Every search
+// word is quoted, so the text index matches the documents having all of them
+func mongoFilter(filters map[string]interface{}, search []string) (bson.M, error) {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filter := bson.M{}
+	for _, key := range keys {
+		field, ok := mongoFilterFields[key]
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter %q", key)
+		}
+		filter[field] = fmt.Sprint(filters[key])
+	}
+
+	if len(search) > 0 {
+		phrases := make([]string, len(search))
+		for i, term := range search {
+			phrases[i] = `"` + strings.ReplaceAll(term, `"`, "") + `"`
+		}
+		filter["$text"] = bson.M{"$search": strings.Join(phrases, " ")}
+	}
+	return filter, nil
+}
+
+// mongoError translates duplicate key errors into conflict errors
+func mongoError(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	var writeErrors []mongo.WriteError
+	var writeErr mongo.WriteException
+	var bulkWriteErr mongo.BulkWriteException
+	switch {
+	case errors.As(err, &writeErr):
+		writeErrors = writeErr.WriteErrors
+	case errors.As(err, &bulkWriteErr):
+		for _, e := range bulkWriteErr.WriteErrors {
+			writeErrors = append(writeErrors, e.WriteError)
+		}
+	default:
+		return err
+	}
+
+	message := domain.ErrConflict
+	for _, e := range writeErrors {
+		for index, indexMessage := range mongoConflictMessages {
+			if strings.Contains(e.Message, "index: "+index+" ") {
+				message = indexMessage
+			}
+		}
+	}
+	return domain.NewConflictError(message)
+}
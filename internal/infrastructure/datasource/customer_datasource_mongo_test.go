@@ -0,0 +1,197 @@
+package datasource_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+type CustomerMongoDataSourceIntegrationTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	db         *database.MongoDatabase
+	dataSource port.CustomerDataSource
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.db = newTestMongoDatabase(suite.T())
+	suite.dataSource = datasource.NewCustomerMongoDataSource(suite.db)
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		_ = suite.db.Close(suite.ctx)
+	}
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) SetupTest() {
+	clearTestMongoCustomers(suite.T(), suite.db)
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) TestEnsureIndexesIsIdempotent() {
+	assert.NoError(suite.T(), suite.db.EnsureIndexes(suite.ctx))
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) TestEnsureIndexesFollowsRetention() {
+	defer func(retention time.Duration) {
+		suite.db.DeletedRetention = retention
+		require.NoError(suite.T(), suite.db.EnsureIndexes(suite.ctx))
+	}(suite.db.DeletedRetention)
+
+	ttlIndex := func() *mongo.IndexSpecification {
+		specifications, err := suite.db.Database.Collection(database.MongoCustomersCollection).Indexes().ListSpecifications(suite.ctx)
+		require.NoError(suite.T(), err)
+		for _, specification := range specifications {
+			if specification.Name == database.MongoCustomersDeletedTTLIndex {
+				return specification
+			}
+		}
+		return nil
+	}
+
+	for _, retention := range []time.Duration{24 * time.Hour, 48 * time.Hour} {
+		suite.db.DeletedRetention = retention
+		require.NoError(suite.T(), suite.db.EnsureIndexes(suite.ctx))
+		index := ttlIndex()
+		require.NotNil(suite.T(), index)
+		assert.Equal(suite.T(), int32(retention/time.Second), *index.ExpireAfterSeconds)
+	}
+
+	suite.db.DeletedRetention = 0
+	require.NoError(suite.T(), suite.db.EnsureIndexes(suite.ctx))
+	assert.Nil(suite.T(), ttlIndex(), "a zero retention must keep the deleted customers")
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) TestUniqueIndexes() {
+	customer := &entity.Customer{
+		Name:      "John Doe",
+		Email:     "john.doe@example.com",
+		CPF:       "12345678901",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, customer))
+
+	tests := []struct {
+		name        string
+		customer    *entity.Customer
+		wantMessage string
+	}{
+		{
+			name:        "should reject a duplicated cpf",
+			customer:    &entity.Customer{Name: "Jane Doe", Email: "jane.doe@example.com", CPF: customer.CPF},
+			wantMessage: domain.ErrCPFAlreadyExists,
+		},
+		{
+			name:        "should reject a duplicated email",
+			customer:    &entity.Customer{Name: "Jane Doe", Email: customer.Email, CPF: "10987654321"},
+			wantMessage: domain.ErrEmailAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := suite.dataSource.Create(suite.ctx, tt.customer)
+			assert.IsType(suite.T(), &domain.ConflictError{}, err)
+			assert.EqualError(suite.T(), err, tt.wantMessage)
+		})
+	}
+
+	other := &entity.Customer{Name: "Jane Doe", Email: "jane.doe@example.com", CPF: "10987654321"}
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, other))
+	other.Email = customer.Email
+	err := suite.dataSource.Update(suite.ctx, other)
+	assert.IsType(suite.T(), &domain.ConflictError{}, err)
+}
+
+func (suite *CustomerMongoDataSourceIntegrationTestSuite) TestSearchCombinesWithFilters() {
+	for i, name := range []string{"Ana Maria Costa", "Ana Costa"} {
+		customer := &entity.Customer{Name: name, Email: name + "@example.com", CPF: string(rune('1'+i)) + "0000000000"}
+		require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, customer))
+	}
+
+	filters := map[string]interface{}{"name": "Ana Costa", port.FilterSearch: "costa ana"}
+	result, total, err := suite.dataSource.FindAll(suite.ctx, filters, 1, 10)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	require.Len(suite.T(), result, 1)
+	assert.Equal(suite.T(), "Ana Costa", result[0].Name)
+}
+
+func TestCustomerMongoDataSourceIntegrationTestSuite(t *testing.T) {
+	skipMongoIntegrationTests(t)
+
+	suite.Run(t, new(CustomerMongoDataSourceIntegrationTestSuite))
+}
+
+func TestCustomerMongoDataSourceConformance(t *testing.T) {
+	skipMongoIntegrationTests(t)
+
+	db := newTestMongoDatabase(t)
+	defer db.Close(context.Background())
+
+	suite.Run(t, NewCustomerDataSourceConformanceTestSuite(func() port.CustomerDataSource {
+		clearTestMongoCustomers(t, db)
+		return datasource.NewCustomerMongoDataSource(db)
+	}))
+}
+
+func newTestMongoDatabase(t *testing.T) *database.MongoDatabase {
+	cfg := &config.Config{
+		Environment:      "test",
+		MongoURI:         os.Getenv("TEST_MONGO_URI"),
+		MongoDatabase:    getTestMongoDatabase(),
+		MongoTimeout:     10 * time.Second,
+		MongoMaxPoolSize: 10,
+	}
+
+	db, err := database.NewMongoConnection(cfg, logger.NewLogger(cfg))
+	require.NoError(t, err, "Failed to connect to test MongoDB")
+	require.NoError(t, db.EnsureIndexes(context.Background()), "Failed to create test MongoDB indexes")
+	return db
+}
+
+func clearTestMongoCustomers(t *testing.T, db *database.MongoDatabase) {
+	ctx := context.Background()
+	for _, collection := range []string{database.MongoCustomersCollection, database.MongoCountersCollection} {
+		_, err := db.Database.Collection(collection).DeleteMany(ctx, bson.M{})
+		require.NoError(t, err, "Failed to clear %s", collection)
+	}
+}
+
+func skipMongoIntegrationTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	if os.Getenv("COVERAGE_MODE") == "true" || os.Getenv("SKIP_INTEGRATION_TESTS") == "true" {
+		t.Skip("Skipping integration tests in coverage mode")
+	}
+
+	if os.Getenv("TEST_MONGO_URI") == "" {
+		t.Skip("Skipping MongoDB integration tests - no TEST_MONGO_URI configured")
+	}
+}
+
+func getTestMongoDatabase() string {
+	if name := os.Getenv("TEST_MONGO_DATABASE"); name != "" {
+		return name
+	}
+	return "tc4_customer_service_test"
+}
@@ -0,0 +1,444 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+const (
+	customersTable   = "customers"
+	customersColumns = "id, name, email, cpf, version, created_at, updated_at, deleted_at, status, status_reason, status_changed_at, anonymized_at, consents"
+
+	pgUniqueViolation = "23505"
+)
+
+// postgresFilterColumns are the FindAll equality filters, mapped to their columns
+var postgresFilterColumns = map[string]string{
+	"name":  "name",
+	"email": "email",
+	"cpf":   "cpf",
+}
+
+// postgresConflictMessages explains which unique constraint a write violated
+var postgresConflictMessages = map[string]string{
+	"customers_pkey":      domain.ErrConflict,
+	"customers_cpf_key":   domain.ErrCPFAlreadyExists,
+	"customers_email_key": domain.ErrEmailAlreadyExists,
+}
+
+type customerPostgresDataSource struct {
+	db *database.PostgresDatabase
+}
+
+func NewCustomerPostgresDataSource(db *database.PostgresDatabase) port.CustomerDataSource {
+	return &customerPostgresDataSource{
+		db: db,
+	}
+}
+
+func (ds *customerPostgresDataSource) FindByID(ctx context.Context, id int) (*entity.Customer, error) {
+	startTime := time.Now()
+
+	row := ds.db.DB.QueryRowContext(ctx, "SELECT "+customersColumns+" FROM customers WHERE id = $1", id)
+	customer, err := scanCustomer(row)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByID", customersTable, duration, err)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return customer, err
+}
+
+func (ds *customerPostgresDataSource) FindByIDs(ctx context.Context, ids []int) ([]*entity.Customer, error) {
+	startTime := time.Now()
+
+	customers, err := ds.query(ctx, "SELECT "+customersColumns+" FROM customers WHERE id = ANY($1)", ids)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByIDs", customersTable, duration, err)
+
+	if err != nil {
+		return nil, err
+	}
+	return inIDOrder(customers, ids), nil
+}
+
+func (ds *customerPostgresDataSource) FindByCPF(ctx context.Context, cpf string) (*entity.Customer, error) {
+	startTime := time.Now()
+
+	row := ds.db.DB.QueryRowContext(ctx, "SELECT "+customersColumns+" FROM customers WHERE cpf = $1", cpf)
+	customer, err := scanCustomer(row)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindByCPF", customersTable, duration, err)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return customer, err
+}
+
+func (ds *customerPostgresDataSource) FindAll(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*entity.Customer, int64, error) {
+	startTime := time.Now()
+
+	filters, afterID, err := splitAfterID(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	filters, search := splitSearch(filters)
+	filters, includeDeleted := splitIncludeDeleted(filters)
+
+	where, args, err := postgresWhere(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !includeDeleted {
+		where += appendCondition(where, "deleted_at IS NULL")
+	}
+	if len(search) > 0 {
+		args = append(args, strings.Join(search, " "))
+		where += appendCondition(where, fmt.Sprintf("to_tsvector('simple', name) @@ plainto_tsquery('simple', $%d)", len(args)))
+	}
+
+	var total int64
+	if err := ds.db.DB.QueryRowContext(ctx, "SELECT count(*) FROM customers"+where, args...).Scan(&total); err != nil {
+		ds.db.LogOperation(ctx, "FindAll", customersTable, time.Since(startTime), err)
+		return nil, 0, err
+	}
+
+	// Keyset pagination seeks past the last ID of the previous page through the primary key,
+	// while offset pagination skips the rows of the previous pages
+	query := "SELECT " + customersColumns + " FROM customers" + where
+	if afterID > 0 {
+		args = append(args, afterID)
+		query += appendCondition(where, fmt.Sprintf("id > $%d", len(args)))
+	}
+	query += " ORDER BY id"
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+		if afterID == 0 && page > 1 {
+			args = append(args, (page-1)*limit)
+			query += fmt.Sprintf(" OFFSET $%d", len(args))
+		}
+	}
+
+	customers, err := ds.query(ctx, query, args...)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "FindAll", customersTable, duration, err)
+
+	if err != nil {
+		return nil, 0, err
+	}
+	return customers, total, nil
+}
+
+func (ds *customerPostgresDataSource) Create(ctx context.Context, customer *entity.Customer) error {
+	startTime := time.Now()
+
+	customer.Version = 1
+	var err error
+	if customer.ID == 0 {
+		err = ds.db.DB.QueryRowContext(ctx,
+			`INSERT INTO customers (name, email, cpf, version, created_at, updated_at, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			customer.Name, customer.Email, customer.CPF, customer.Version, customer.CreatedAt, customer.UpdatedAt,
+			storedStatus(string(customer.Status)),
+		).Scan(&customer.ID)
+	} else {
+		err = ds.createWithID(ctx, customer)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Create", customersTable, duration, err)
+
+	return postgresError(err)
+}
+
+// createWithID inserts a customer with a chosen ID and moves the ID sequence past it,
+// so the next generated IDs don't collide with it
+func (ds *customerPostgresDataSource) createWithID(ctx context.Context, customer *entity.Customer) error {
+	tx, err := ds.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO customers (id, name, email, cpf, version, created_at, updated_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		customer.ID, customer.Name, customer.Email, customer.CPF, customer.Version, customer.CreatedAt, customer.UpdatedAt,
+		storedStatus(string(customer.Status)),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`SELECT setval(pg_get_serial_sequence('customers', 'id'), GREATEST((SELECT max(id) FROM customers), 1))`)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveBatch writes the batch in a single transaction, so a failing customer leaves the table unchanged.
+// The replacements are conditional updates, like Update
+func (ds *customerPostgresDataSource) SaveBatch(ctx context.Context, customers []*entity.Customer) error {
+	startTime := time.Now()
+
+	err := ds.saveBatch(ctx, customers)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "SaveBatch", customersTable, duration, err)
+
+	return postgresError(err)
+}
+
+func (ds *customerPostgresDataSource) saveBatch(ctx context.Context, customers []*entity.Customer) error {
+	tx, err := ds.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, customer := range customers {
+		consents, err := marshalPostgresConsents(customer.Consents)
+		if err != nil {
+			return err
+		}
+		if customer.ID == 0 {
+			customer.Version = 1
+			err = tx.QueryRowContext(ctx,
+				`INSERT INTO customers (name, email, cpf, version, created_at, updated_at, status)
+				VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+				customer.Name, customer.Email, customer.CPF, customer.Version, customer.CreatedAt, customer.UpdatedAt,
+				storedStatus(string(customer.Status)),
+			).Scan(&customer.ID)
+		} else {
+			var result sql.Result
+			result, err = tx.ExecContext(ctx,
+				`UPDATE customers SET name = $1, email = $2, cpf = $3, version = $4, updated_at = $5, deleted_at = $6,
+				status = $7, status_reason = $8, status_changed_at = $9, anonymized_at = $10, consents = $11
+				WHERE id = $12 AND version = $13`,
+				customer.Name, customer.Email, customer.CPF, customer.Version, customer.UpdatedAt, customer.DeletedAt,
+				storedStatus(string(customer.Status)), customer.StatusReason, customer.StatusChangedAt,
+				customer.AnonymizedAt, consents, customer.ID, customer.Version-1,
+			)
+			if err == nil {
+				err = ds.checkAffected(ctx, result, customer.ID)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (ds *customerPostgresDataSource) Update(ctx context.Context, customer *entity.Customer) error {
+	startTime := time.Now()
+
+	consents, err := marshalPostgresConsents(customer.Consents)
+	if err != nil {
+		return err
+	}
+
+	result, err := ds.db.DB.ExecContext(ctx,
+		`UPDATE customers SET name = $1, email = $2, cpf = $3, version = version + 1, updated_at = $4, deleted_at = $5,
+		status = $6, status_reason = $7, status_changed_at = $8, anonymized_at = $9, consents = $10
+		WHERE id = $11 AND version = $12`,
+		customer.Name, customer.Email, customer.CPF, customer.UpdatedAt, customer.DeletedAt,
+		storedStatus(string(customer.Status)), customer.StatusReason, customer.StatusChangedAt, customer.AnonymizedAt,
+		consents, customer.ID, customer.Version,
+	)
+	if err == nil {
+		err = ds.checkAffected(ctx, result, customer.ID)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Update", customersTable, duration, err)
+
+	if err != nil {
+		return postgresError(err)
+	}
+
+	customer.Version++
+	return nil
+}
+
+func (ds *customerPostgresDataSource) Delete(ctx context.Context, id int, version int) error {
+	startTime := time.Now()
+
+	result, err := ds.db.DB.ExecContext(ctx, "DELETE FROM customers WHERE id = $1 AND version = $2", id, version)
+	if err == nil {
+		err = ds.checkAffected(ctx, result, id)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Delete", customersTable, duration, err)
+
+	return err
+}
+
+// checkAffected tells apart why a conditional write changed no row: the customer is either
+// gone or was changed by another request since it was read
+func (ds *customerPostgresDataSource) checkAffected(ctx context.Context, result sql.Result, id int) error {
+	affected, err := result.RowsAffected()
+	if err != nil || affected > 0 {
+		return err
+	}
+
+	var exists bool
+	if err := ds.db.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM customers WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	return domain.NewPreconditionFailedError(domain.ErrVersionMismatch)
+}
+
+// Scan reads the customers through a single cursor, which streams the rows without holding them in memory
+func (ds *customerPostgresDataSource) Scan(ctx context.Context, _ int, visit func(*entity.Customer) error) error {
+	startTime := time.Now()
+
+	err := ds.scan(ctx, visit)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "Scan", customersTable, duration, err)
+
+	return err
+}
+
+func (ds *customerPostgresDataSource) scan(ctx context.Context, visit func(*entity.Customer) error) error {
+	rows, err := ds.db.DB.QueryContext(ctx, "SELECT "+customersColumns+" FROM customers ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return err
+		}
+		if err := visit(customer); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (ds *customerPostgresDataSource) query(ctx context.Context, query string, args ...any) ([]*entity.Customer, error) {
+	rows, err := ds.db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]*entity.Customer, 0)
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return customers, rows.Err()
+}
+
+// postgresWhere builds the WHERE clause of the FindAll equality filters
+func postgresWhere(filters map[string]interface{}) (string, []any, error) {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]string, 0, len(keys))
+	args := make([]any, 0, len(keys))
+	for _, key := range keys {
+		column, ok := postgresFilterColumns[key]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported filter %q", key)
+		}
+		args = append(args, fmt.Sprint(filters[key]))
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+func appendCondition(where, condition string) string {
+	if where == "" {
+		return " WHERE " + condition
+	}
+	return " AND " + condition
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCustomer(row rowScanner) (*entity.Customer, error) {
+	var customer entity.Customer
+	var status string
+	var consents []byte
+	err := row.Scan(&customer.ID, &customer.Name, &customer.Email, &customer.CPF,
+		&customer.Version, &customer.CreatedAt, &customer.UpdatedAt, &customer.DeletedAt,
+		&status, &customer.StatusReason, &customer.StatusChangedAt, &customer.AnonymizedAt, &consents)
+	if err != nil {
+		return nil, err
+	}
+	customer.Status = storedStatus(status)
+
+	var models []CustomerConsentModel
+	if err := json.Unmarshal(consents, &models); err != nil {
+		return nil, err
+	}
+	customer.Consents = toCustomerConsents(models)
+	return &customer, nil
+}
+
+// marshalPostgresConsents encodes the consents of a customer for their JSONB column, an empty array when it has none
+func marshalPostgresConsents(consents []entity.Consent) (string, error) {
+	models := toCustomerConsentModels(consents)
+	if models == nil {
+		models = []CustomerConsentModel{}
+	}
+	encoded, err := json.Marshal(models)
+	return string(encoded), err
+}
+
+// postgresError translates unique constraint violations into conflict errors
+func postgresError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		message, ok := postgresConflictMessages[pgErr.ConstraintName]
+		if !ok {
+			message = domain.ErrConflict
+		}
+		return domain.NewConflictError(message)
+	}
+	return err
+}
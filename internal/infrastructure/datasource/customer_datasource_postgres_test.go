@@ -0,0 +1,171 @@
+package datasource_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+type CustomerPostgresDataSourceIntegrationTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	db         *database.PostgresDatabase
+	dataSource port.CustomerDataSource
+}
+
+func (suite *CustomerPostgresDataSourceIntegrationTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.db = newTestPostgresDatabase(suite.T())
+	suite.dataSource = datasource.NewCustomerPostgresDataSource(suite.db)
+}
+
+func (suite *CustomerPostgresDataSourceIntegrationTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		_ = suite.db.Close(suite.ctx)
+	}
+}
+
+func (suite *CustomerPostgresDataSourceIntegrationTestSuite) SetupTest() {
+	truncateTestPostgresCustomers(suite.T(), suite.db)
+}
+
+func (suite *CustomerPostgresDataSourceIntegrationTestSuite) TestMigrateIsIdempotent() {
+	assert.NoError(suite.T(), suite.db.Migrate(suite.ctx))
+}
+
+func (suite *CustomerPostgresDataSourceIntegrationTestSuite) TestUniqueConstraints() {
+	customer := &entity.Customer{
+		Name:      "John Doe",
+		Email:     "john.doe@example.com",
+		CPF:       "12345678901",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, customer))
+
+	tests := []struct {
+		name        string
+		customer    *entity.Customer
+		wantMessage string
+	}{
+		{
+			name:        "should reject a duplicated cpf",
+			customer:    &entity.Customer{Name: "Jane Doe", Email: "jane.doe@example.com", CPF: customer.CPF},
+			wantMessage: domain.ErrCPFAlreadyExists,
+		},
+		{
+			name:        "should reject a duplicated email",
+			customer:    &entity.Customer{Name: "Jane Doe", Email: customer.Email, CPF: "10987654321"},
+			wantMessage: domain.ErrEmailAlreadyExists,
+		},
+		{
+			name:        "should reject a duplicated email in another case",
+			customer:    &entity.Customer{Name: "Jane Doe", Email: strings.ToUpper(customer.Email), CPF: "10987654321"},
+			wantMessage: domain.ErrEmailAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := suite.dataSource.Create(suite.ctx, tt.customer)
+			assert.IsType(suite.T(), &domain.ConflictError{}, err)
+			assert.EqualError(suite.T(), err, tt.wantMessage)
+		})
+	}
+
+	other := &entity.Customer{Name: "Jane Doe", Email: "jane.doe@example.com", CPF: "10987654321"}
+	require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, other))
+	other.Email = customer.Email
+	err := suite.dataSource.Update(suite.ctx, other)
+	assert.IsType(suite.T(), &domain.ConflictError{}, err)
+}
+
+func (suite *CustomerPostgresDataSourceIntegrationTestSuite) TestPurgeDeleted() {
+	customers := make([]*entity.Customer, 3)
+	for i := range customers {
+		customers[i] = &entity.Customer{Name: "John Doe", Email: fmt.Sprintf("john.%d@example.com", i), CPF: fmt.Sprintf("%011d", i+1)}
+		require.NoError(suite.T(), suite.dataSource.Create(suite.ctx, customers[i]))
+	}
+	expired, recent := customers[0], customers[1]
+	_, err := suite.db.DB.ExecContext(suite.ctx, "UPDATE customers SET deleted_at = $1 WHERE id = $2", time.Now().Add(-48*time.Hour), expired.ID)
+	require.NoError(suite.T(), err)
+	_, err = suite.db.DB.ExecContext(suite.ctx, "UPDATE customers SET deleted_at = $1 WHERE id = $2", time.Now().Add(-time.Hour), recent.ID)
+	require.NoError(suite.T(), err)
+
+	suite.db.DeletedRetention = 24 * time.Hour
+	defer func() { suite.db.DeletedRetention = 0 }()
+	purged, err := suite.db.PurgeDeleted(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), purged)
+
+	found, err := suite.dataSource.FindByIDs(suite.ctx, []int{expired.ID, recent.ID, customers[2].ID})
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), found, 2)
+	assert.Equal(suite.T(), recent.ID, found[0].ID)
+	assert.Equal(suite.T(), customers[2].ID, found[1].ID)
+}
+
+func TestCustomerPostgresDataSourceIntegrationTestSuite(t *testing.T) {
+	skipPostgresIntegrationTests(t)
+
+	suite.Run(t, new(CustomerPostgresDataSourceIntegrationTestSuite))
+}
+
+func TestCustomerPostgresDataSourceConformance(t *testing.T) {
+	skipPostgresIntegrationTests(t)
+
+	db := newTestPostgresDatabase(t)
+	defer db.Close(context.Background())
+
+	suite.Run(t, NewCustomerDataSourceConformanceTestSuite(func() port.CustomerDataSource {
+		truncateTestPostgresCustomers(t, db)
+		return datasource.NewCustomerPostgresDataSource(db)
+	}))
+}
+
+func newTestPostgresDatabase(t *testing.T) *database.PostgresDatabase {
+	cfg := &config.Config{
+		Environment:          "test",
+		PostgresURL:          os.Getenv("TEST_POSTGRES_URL"),
+		PostgresMaxOpenConns: 5,
+	}
+
+	db, err := database.NewPostgresConnection(cfg, logger.NewLogger(cfg))
+	require.NoError(t, err, "Failed to connect to test PostgreSQL")
+	require.NoError(t, db.Migrate(context.Background()), "Failed to migrate test PostgreSQL")
+	return db
+}
+
+func truncateTestPostgresCustomers(t *testing.T, db *database.PostgresDatabase) {
+	_, err := db.DB.Exec("TRUNCATE customers RESTART IDENTITY")
+	require.NoError(t, err, "Failed to truncate customers")
+}
+
+func skipPostgresIntegrationTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	if os.Getenv("COVERAGE_MODE") == "true" || os.Getenv("SKIP_INTEGRATION_TESTS") == "true" {
+		t.Skip("Skipping integration tests in coverage mode")
+	}
+
+	if os.Getenv("TEST_POSTGRES_URL") == "" {
+		t.Skip("Skipping PostgreSQL integration tests - no TEST_POSTGRES_URL configured")
+	}
+}
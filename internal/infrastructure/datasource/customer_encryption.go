@@ -0,0 +1,227 @@
+package datasource
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// dataKeyCacheSize bounds the unwrapped data keys kept in memory to read the items
+const dataKeyCacheSize = 256
+
+var errInvalidCiphertext = errors.New("invalid ciphertext of an encrypted attribute")
+
+// customerCipher encrypts the name, email and CPF of the customer items with envelope encryption. Each value
+// is sealed with AES-256-GCM under a data key and bound to the customer and attribute, and the item keeps the
+// data key wrapped by the master key. The email and CPF attributes keep a keyed HMAC of the value instead
+// (blind index), so the CPF index and the equality lookups still work without decrypting. The audit and
+// idempotency items seal the copies of the personal data they keep with sealField
+type customerCipher struct {
+	keys port.KeyProvider
+	// ttl is how long a data key seals the writes, so the key provider isn't called on every write
+	ttl time.Duration
+
+	mu      sync.Mutex
+	current *customerDataKey
+	// dataKeys caches the unwrapped data keys by their wrapped form
+	dataKeys *lru.Cache[string, cipher.AEAD]
+}
+
+type customerDataKey struct {
+	aead      cipher.AEAD
+	wrapped   []byte
+	expiresAt time.Time
+}
+
+func newCustomerCipher(keys port.KeyProvider, ttl time.Duration) *customerCipher {
+	dataKeys, _ := lru.New[string, cipher.AEAD](dataKeyCacheSize)
+	return &customerCipher{keys: keys, ttl: ttl, dataKeys: dataKeys}
+}
+
+// encrypt replaces the name, email and CPF of an item with their ciphertexts and blind indexes
+func (c *customerCipher) encrypt(ctx context.Context, m *CustomerDynamoModel) error {
+	key, err := c.currentKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	if m.EncryptedName, err = seal(key.aead, additionalData(m.ID, "name"), m.Name); err != nil {
+		return err
+	}
+	if m.EncryptedEmail, err = seal(key.aead, additionalData(m.ID, "email"), m.Email); err != nil {
+		return err
+	}
+	if m.EncryptedCPF, err = seal(key.aead, additionalData(m.ID, "cpf"), m.CPF); err != nil {
+		return err
+	}
+	if m.Email, err = c.blindIndex(ctx, "email", m.Email); err != nil {
+		return err
+	}
+	if m.CPF, err = c.blindIndex(ctx, "cpf", m.CPF); err != nil {
+		return err
+	}
+	m.Name = ""
+	m.DataKey = key.wrapped
+	return nil
+}
+
+// decrypt restores the name, email and CPF of an item. Items written before the encryption was enabled
+// have no data key, and are read as they are until their next write encrypts them
+func (c *customerCipher) decrypt(ctx context.Context, m *CustomerDynamoModel) error {
+	if len(m.DataKey) == 0 {
+		return nil
+	}
+
+	aead, err := c.dataKey(ctx, m.DataKey)
+	if err != nil {
+		return err
+	}
+
+	if m.Name, err = open(aead, additionalData(m.ID, "name"), m.EncryptedName); err != nil {
+		return err
+	}
+	if m.Email, err = open(aead, additionalData(m.ID, "email"), m.EncryptedEmail); err != nil {
+		return err
+	}
+	if m.CPF, err = open(aead, additionalData(m.ID, "cpf"), m.EncryptedCPF); err != nil {
+		return err
+	}
+	m.EncryptedName, m.EncryptedEmail, m.EncryptedCPF, m.DataKey = nil, nil, nil, nil
+	return nil
+}
+
+// sealField seals a value of another item under the current data key, bound to its scope, like
+// "audit/<entry ID>/changes". It returns the ciphertext and the wrapped data key the item keeps next to it
+func (c *customerCipher) sealField(ctx context.Context, scope, value string) ([]byte, []byte, error) {
+	key, err := c.currentKey(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sealed, err := seal(key.aead, []byte(scope), value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sealed, key.wrapped, nil
+}
+
+// openField restores a value sealed by sealField with the same scope
+func (c *customerCipher) openField(ctx context.Context, scope string, wrapped, sealed []byte) (string, error) {
+	aead, err := c.dataKey(ctx, wrapped)
+	if err != nil {
+		return "", err
+	}
+	return open(aead, []byte(scope), sealed)
+}
+
+// blindIndex returns the HMAC-SHA256 of a value of the attribute, empty for an empty value. Equal values
+// have equal indexes, which is what lets them be looked up, and also what they reveal
+func (c *customerCipher) blindIndex(ctx context.Context, attribute, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	key, err := c.keys.BlindIndexKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(attribute))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// currentKey returns the data key of the writes, generating another one when it expires
+func (c *customerCipher) currentKey(ctx context.Context) (*customerDataKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current != nil && time.Now().Before(c.current.expiresAt) {
+		return c.current, nil
+	}
+
+	plaintext, wrapped, err := c.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate a data key: %w", err)
+	}
+	aead, err := newDataKeyAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	c.dataKeys.Add(string(wrapped), aead)
+	c.current = &customerDataKey{aead: aead, wrapped: wrapped, expiresAt: time.Now().Add(c.ttl)}
+	return c.current, nil
+}
+
+// dataKey returns the unwrapped data key of an item
+func (c *customerCipher) dataKey(ctx context.Context, wrapped []byte) (cipher.AEAD, error) {
+	if aead, ok := c.dataKeys.Get(string(wrapped)); ok {
+		return aead, nil
+	}
+
+	plaintext, err := c.keys.DecryptDataKey(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt a data key: %w", err)
+	}
+	aead, err := newDataKeyAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	c.dataKeys.Add(string(wrapped), aead)
+	return aead, nil
+}
+
+func newDataKeyAEAD(plaintext []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds a ciphertext to its customer and attribute, so it can't be copied to another one
+func additionalData(id int, attribute string) []byte {
+	return []byte(fmt.Sprintf("customer/%d/%s", id, attribute))
+}
+
+// seal returns the random nonce followed by the ciphertext of a value, bound to the additional data, nil for
+// an empty value
+func seal(aead cipher.AEAD, aad []byte, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, []byte(value), aad), nil
+}
+
+func open(aead cipher.AEAD, aad []byte, sealed []byte) (string, error) {
+	if len(sealed) == 0 {
+		return "", nil
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errInvalidCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return "", errInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,12 @@
+package datasource
+
+import "github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+
+// storedStatus reads the status of a stored customer. The customers stored before the statuses were
+// added have none, and are active
+func storedStatus(status string) entity.CustomerStatus {
+	if status == "" {
+		return entity.CustomerStatusActive
+	}
+	return entity.CustomerStatus(status)
+}
@@ -0,0 +1,234 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The CPF and email of a customer are reserved by the items of the uniqueness table, keyed by the attribute
+// and its stored value, which is the blind index with the field-level encryption. The items are written in
+// the same transaction as the customer item, so two customers can never hold the same value
+
+// uniqueAttributes are the attributes no two customers may share, with the error of a value already taken
+var uniqueAttributes = []struct {
+	name    string
+	message string
+}{
+	{name: "cpf", message: domain.ErrCPFAlreadyExists},
+	{name: "email", message: domain.ErrEmailAlreadyExists},
+}
+
+// uniqueOwnerCondition lets a write of a uniqueness item through when the item is free or held by the customer
+const uniqueOwnerCondition = "attribute_not_exists(unique_key) OR customer_id = :customer_id"
+
+type transactKind int
+
+const (
+	// transactCreate writes a new customer item, which fails when the ID is taken
+	transactCreate transactKind = iota
+	// transactReplace writes a stored customer item with the version condition of Update
+	transactReplace
+	// transactClaim reserves a value for the customer
+	transactClaim
+	// transactRelease frees a value the customer no longer has
+	transactRelease
+)
+
+// transactAction is a write of a customer transaction, with what its failed condition means
+type transactAction struct {
+	kind  transactKind
+	write types.TransactWriteItem
+	// key is the uniqueness item a claim or release writes
+	key string
+	// message is the error of a value held by another customer
+	message string
+	// skip drops a release of a value held by another customer, which only happens to customers written
+	// before their values were reserved
+	skip bool
+}
+
+// uniqueValue returns the stored value of a unique attribute of the model
+func (m CustomerDynamoModel) uniqueValue(attribute string) string {
+	if attribute == "cpf" {
+		return m.CPF
+	}
+	return m.Email
+}
+
+func uniqueKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		database.UniquenessKeyAttribute: &types.AttributeValueMemberS{Value: key},
+	}
+}
+
+// uniquenessActions claims the values of the current model the previous one didn't have, and releases the
+// values it no longer has. A nil previous model is a new customer, and a nil current one a removed customer
+func (ds *customerDynamoDataSource) uniquenessActions(id int, previous, current *CustomerDynamoModel) []transactAction {
+	if ds.db.UniquenessTableName == "" {
+		return nil
+	}
+
+	owner := map[string]types.AttributeValue{":customer_id": numberValue(id)}
+	var actions []transactAction
+	for _, attribute := range uniqueAttributes {
+		var before, after string
+		if previous != nil {
+			before = previous.uniqueValue(attribute.name)
+		}
+		if current != nil {
+			after = current.uniqueValue(attribute.name)
+		}
+		if before == after {
+			continue
+		}
+
+		if after != "" {
+			key := attribute.name + "#" + after
+			item := uniqueKey(key)
+			item["customer_id"] = numberValue(id)
+			actions = append(actions, transactAction{
+				kind:    transactClaim,
+				key:     key,
+				message: attribute.message,
+				write: types.TransactWriteItem{Put: &types.Put{
+					TableName:                           aws.String(ds.db.UniquenessTableName),
+					Item:                                item,
+					ConditionExpression:                 aws.String(uniqueOwnerCondition),
+					ExpressionAttributeValues:           owner,
+					ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+				}},
+			})
+		}
+		if before != "" {
+			key := attribute.name + "#" + before
+			actions = append(actions, transactAction{
+				kind:    transactRelease,
+				key:     key,
+				message: attribute.message,
+				write: types.TransactWriteItem{Delete: &types.Delete{
+					TableName:                 aws.String(ds.db.UniquenessTableName),
+					Key:                       uniqueKey(key),
+					ConditionExpression:       aws.String(uniqueOwnerCondition),
+					ExpressionAttributeValues: owner,
+				}},
+			})
+		}
+	}
+	return actions
+}
+
+// transact writes the actions in a single transaction, and translates the first failed condition into
+// the error of Create or Update. A value held by a customer that is gone, e.g. purged by the TTL, is taken
+// over and the transaction is tried again
+func (ds *customerDynamoDataSource) transact(ctx context.Context, actions []transactAction) error {
+	for attempt := 1; ; attempt++ {
+		pending := make([]*transactAction, 0, len(actions))
+		items := make([]types.TransactWriteItem, 0, len(actions))
+		for n := range actions {
+			if !actions[n].skip {
+				pending = append(pending, &actions[n])
+				items = append(items, actions[n].write)
+			}
+		}
+
+		_, err := ds.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+
+		var canceled *types.TransactionCanceledException
+		if !errors.As(err, &canceled) {
+			return err
+		}
+
+		retry := false
+		for n, reason := range canceled.CancellationReasons {
+			if aws.ToString(reason.Code) != "ConditionalCheckFailed" || n >= len(pending) {
+				continue
+			}
+
+			action := pending[n]
+			switch action.kind {
+			case transactCreate:
+				return domain.NewConflictError(domain.ErrConflict)
+			case transactReplace:
+				return conditionalCheckError(&types.ConditionalCheckFailedException{Item: reason.Item})
+			case transactRelease:
+				action.skip = true
+			case transactClaim:
+				holder, err := ds.heldBy(ctx, reason.Item)
+				if err != nil {
+					return err
+				}
+				if holder != 0 {
+					return domain.NewConflictError(action.message)
+				}
+				takeOver(action, reason.Item)
+			}
+			retry = true
+		}
+		if !retry || attempt >= maxBatchAttempts {
+			return err
+		}
+	}
+}
+
+// heldBy returns the customer holding a uniqueness item, or 0 when that customer is gone
+func (ds *customerDynamoDataSource) heldBy(ctx context.Context, item map[string]types.AttributeValue) (int, error) {
+	var holder struct {
+		CustomerID int `dynamodbav:"customer_id"`
+	}
+	if err := attributevalue.UnmarshalMap(item, &holder); err != nil {
+		return 0, err
+	}
+
+	output, err := ds.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(ds.db.TableName),
+		Key:                  map[string]types.AttributeValue{"id": numberValue(holder.CustomerID)},
+		ProjectionExpression: aws.String("id"),
+		ConsistentRead:       aws.Bool(true),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if output.Item == nil {
+		return 0, nil
+	}
+	return holder.CustomerID, nil
+}
+
+// takeOver lets a claim replace the item of a customer that is gone, as long as nobody claimed it since
+func takeOver(action *transactAction, item map[string]types.AttributeValue) {
+	put := *action.write.Put
+	put.ConditionExpression = aws.String(uniqueOwnerCondition + " OR customer_id = :gone")
+	put.ExpressionAttributeValues = map[string]types.AttributeValue{
+		":customer_id": put.ExpressionAttributeValues[":customer_id"],
+		":gone":        item["customer_id"],
+	}
+	action.write.Put = &put
+}
+
+// storedModel reads the attributes of a stored customer item the writes compare, nil when it doesn't exist
+func (ds *customerDynamoDataSource) storedModel(ctx context.Context, id int) (*CustomerDynamoModel, error) {
+	output, err := ds.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:                aws.String(ds.db.TableName),
+		Key:                      map[string]types.AttributeValue{"id": numberValue(id)},
+		ProjectionExpression:     aws.String("id, cpf, email, #version"),
+		ExpressionAttributeNames: map[string]string{"#version": "version"},
+		ConsistentRead:           aws.Bool(true),
+	})
+	if err != nil || output.Item == nil {
+		return nil, err
+	}
+
+	var model CustomerDynamoModel
+	if err := attributevalue.UnmarshalMap(output.Item, &model); err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
@@ -0,0 +1,76 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// DataSources are the data sources of the storage selected by DATASOURCE
+type DataSources struct {
+	Customer    port.CustomerDataSource
+	Audit       port.AuditDataSource
+	Idempotency port.IdempotencyDataSource
+}
+
+// NewDataSources connects to the storage selected by cfg.DataSource, applying the Postgres migrations and
+// the MongoDB indexes. A non-empty dynamoEndpoint replaces the DynamoDB endpoint, e.g. with DynamoDB Local
+func NewDataSources(ctx context.Context, cfg *config.Config, l *logger.Logger, dynamoEndpoint string) (*DataSources, error) {
+	switch cfg.DataSource {
+	case config.DataSourceMemory:
+		l.Warn("Using the in-memory data source, customers are lost when the process stops")
+		return &DataSources{
+			Customer:    NewCustomerMemoryDataSource(),
+			Audit:       NewAuditMemoryDataSource(),
+			Idempotency: NewIdempotencyMemoryDataSource(),
+		}, nil
+	case config.DataSourceDynamoDB:
+		var db *database.DynamoDatabase
+		var err error
+		if dynamoEndpoint != "" {
+			db, err = database.NewDynamoTestConnection(cfg, l, dynamoEndpoint)
+		} else {
+			db, err = database.NewDynamoConnection(cfg, l)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &DataSources{
+			Customer:    NewCustomerDynamoDataSource(db),
+			Audit:       NewAuditDynamoDataSource(db, cfg.AuditTableName),
+			Idempotency: NewIdempotencyDynamoDataSource(db, cfg.IdempotencyTableName),
+		}, nil
+	case config.DataSourcePostgres:
+		db, err := database.NewPostgresConnection(cfg, l)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Migrate(ctx); err != nil {
+			return nil, err
+		}
+		return &DataSources{
+			Customer:    NewCustomerPostgresDataSource(db),
+			Audit:       NewAuditPostgresDataSource(db),
+			Idempotency: NewIdempotencyPostgresDataSource(db),
+		}, nil
+	case config.DataSourceMongo:
+		db, err := database.NewMongoConnection(cfg, l)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.EnsureIndexes(ctx); err != nil {
+			return nil, err
+		}
+		return &DataSources{
+			Customer:    NewCustomerMongoDataSource(db),
+			Audit:       NewAuditMongoDataSource(db),
+			Idempotency: NewIdempotencyMongoDataSource(db),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported DATASOURCE %q", cfg.DataSource)
+	}
+}
@@ -0,0 +1,37 @@
+package datasource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+func TestNewDataSources(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should build the data sources selected by DATASOURCE", func(t *testing.T) {
+		cfg := &config.Config{Environment: "test", DataSource: config.DataSourceMemory}
+
+		dataSources, err := datasource.NewDataSources(ctx, cfg, logger.NewLogger(cfg), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, datasource.NewCustomerMemoryDataSource(), dataSources.Customer)
+		assert.Equal(t, datasource.NewAuditMemoryDataSource(), dataSources.Audit)
+		assert.Equal(t, datasource.NewIdempotencyMemoryDataSource(), dataSources.Idempotency)
+	})
+
+	t.Run("should fail with an unsupported DATASOURCE", func(t *testing.T) {
+		cfg := &config.Config{Environment: "test", DataSource: "cassandra"}
+
+		dataSources, err := datasource.NewDataSources(ctx, cfg, logger.NewLogger(cfg), "")
+
+		assert.EqualError(t, err, `unsupported DATASOURCE "cassandra"`)
+		assert.Nil(t, dataSources)
+	})
+}
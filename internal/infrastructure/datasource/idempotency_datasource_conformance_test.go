@@ -0,0 +1,178 @@
+package datasource_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// IdempotencyDataSourceConformanceTestSuite describes the behavior every port.IdempotencyDataSource
+// implementation must have. newDataSource must return an empty data source for each test
+type IdempotencyDataSourceConformanceTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	newDataSource func() port.IdempotencyDataSource
+	dataSource    port.IdempotencyDataSource
+}
+
+func NewIdempotencyDataSourceConformanceTestSuite(newDataSource func() port.IdempotencyDataSource) *IdempotencyDataSourceConformanceTestSuite {
+	return &IdempotencyDataSourceConformanceTestSuite{newDataSource: newDataSource}
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.dataSource = suite.newDataSource()
+}
+
+// pending returns a reservation of the key by the lease, expiring in ttl. The stores keep the expiration
+// to the second
+func pending(key, leaseID string, ttl time.Duration) *entity.IdempotencyRecord {
+	return &entity.IdempotencyRecord{
+		Key:         key,
+		RequestHash: "hash-1",
+		LeaseID:     leaseID,
+		ExpiresAt:   time.Now().Add(ttl).Truncate(time.Second),
+	}
+}
+
+// complete stores the response of a reservation
+func (suite *IdempotencyDataSourceConformanceTestSuite) complete(record *entity.IdempotencyRecord, customerID int) {
+	existing, err := suite.dataSource.Reserve(suite.ctx, record)
+	require.NoError(suite.T(), err)
+	require.Nil(suite.T(), existing)
+
+	record.Completed = true
+	record.StatusCode = 201
+	record.Headers = map[string]string{"Location": "/customers/1"}
+	record.Body = `{"id":1}`
+	record.CustomerID = customerID
+	require.NoError(suite.T(), suite.dataSource.Complete(suite.ctx, record))
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestReserveReturnsThePendingRecord() {
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-1", time.Hour))
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), existing, "first reservation should store the record")
+
+	existing, err = suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), existing, "second reservation should return the pending record")
+	assert.False(suite.T(), existing.Completed)
+	assert.Equal(suite.T(), "hash-1", existing.RequestHash)
+	assert.Equal(suite.T(), "lease-1", existing.LeaseID)
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestReserveRetriedByTheSameLease() {
+	record := pending("key-1", "lease-1", time.Hour)
+	_, err := suite.dataSource.Reserve(suite.ctx, record)
+	require.NoError(suite.T(), err)
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, record)
+
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), existing, "a retried call should find its own reservation")
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestReserveTakesAnExpiredKeyOver() {
+	_, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-1", -time.Minute))
+	require.NoError(suite.T(), err)
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), existing, "expired keys should be reserved again")
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestCompleteStoresTheResponse() {
+	record := pending("key-1", "lease-1", time.Hour)
+	suite.complete(record, 1)
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), existing)
+	assert.True(suite.T(), existing.Completed)
+	assert.Equal(suite.T(), 201, existing.StatusCode)
+	assert.Equal(suite.T(), map[string]string{"Location": "/customers/1"}, existing.Headers)
+	assert.Equal(suite.T(), `{"id":1}`, existing.Body)
+	assert.Equal(suite.T(), 1, existing.CustomerID)
+	assert.True(suite.T(), record.ExpiresAt.Equal(existing.ExpiresAt))
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestCompleteFailsOnceTheLeaseIsLost() {
+	expired := pending("key-1", "lease-1", -time.Minute)
+	_, err := suite.dataSource.Reserve(suite.ctx, expired)
+	require.NoError(suite.T(), err)
+	_, err = suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+	require.NoError(suite.T(), err)
+
+	expired.Completed = true
+	err = suite.dataSource.Complete(suite.ctx, expired)
+
+	assert.Equal(suite.T(), domain.NewConflictError(domain.ErrIdempotencyLeaseLost), err)
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-3", time.Hour))
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), existing)
+	assert.False(suite.T(), existing.Completed)
+	assert.Equal(suite.T(), "lease-2", existing.LeaseID)
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestReleaseRemovesThePendingReservation() {
+	record := pending("key-1", "lease-1", time.Hour)
+	_, err := suite.dataSource.Reserve(suite.ctx, record)
+	require.NoError(suite.T(), err)
+
+	require.NoError(suite.T(), suite.dataSource.Release(suite.ctx, record))
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+	require.NoError(suite.T(), err)
+	assert.Nil(suite.T(), existing, "released keys should be reserved again")
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestReleaseKeepsACompletedRecord() {
+	record := pending("key-1", "lease-1", time.Hour)
+	suite.complete(record, 1)
+
+	require.NoError(suite.T(), suite.dataSource.Release(suite.ctx, record))
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), existing)
+	assert.True(suite.T(), existing.Completed)
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestReleaseKeepsTheReservationOfAnotherLease() {
+	expired := pending("key-1", "lease-1", -time.Minute)
+	_, err := suite.dataSource.Reserve(suite.ctx, expired)
+	require.NoError(suite.T(), err)
+	_, err = suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-2", time.Hour))
+	require.NoError(suite.T(), err)
+
+	require.NoError(suite.T(), suite.dataSource.Release(suite.ctx, expired))
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, pending("key-1", "lease-3", time.Hour))
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), existing)
+	assert.Equal(suite.T(), "lease-2", existing.LeaseID)
+}
+
+func (suite *IdempotencyDataSourceConformanceTestSuite) TestPurgeCustomerRemovesOnlyItsRecords() {
+	suite.complete(pending("key-1", "lease-1", time.Hour), 1)
+	suite.complete(pending("key-2", "lease-2", time.Hour), 1)
+	suite.complete(pending("key-3", "lease-3", time.Hour), 2)
+
+	require.NoError(suite.T(), suite.dataSource.PurgeCustomer(suite.ctx, 1))
+
+	for key, purged := range map[string]bool{"key-1": true, "key-2": true, "key-3": false} {
+		existing, err := suite.dataSource.Reserve(suite.ctx, pending(key, "lease-4", time.Hour))
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), purged, existing == nil, key)
+	}
+}
@@ -0,0 +1,263 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type idempotencyDynamoDataSource struct {
+	db        *database.DynamoDatabase
+	client    database.DynamoClient
+	tableName string
+	// cipher encrypts the stored responses, nil when they're stored in plaintext
+	cipher *customerCipher
+}
+
+// IdempotencyDynamoModel is an item of the idempotency table. The table must have the DynamoDB TTL
+// enabled on expires_at, so expired keys are eventually removed. With the field-level encryption the
+// body, which presents the personal data of the created customer, is stored encrypted in body_enc
+type IdempotencyDynamoModel struct {
+	Key           string            `dynamodbav:"idempotency_key"`
+	RequestHash   string            `dynamodbav:"request_hash"`
+	LeaseID       string            `dynamodbav:"lease_id,omitempty"`
+	Completed     bool              `dynamodbav:"completed"`
+	StatusCode    int               `dynamodbav:"status_code,omitempty"`
+	Headers       map[string]string `dynamodbav:"headers,omitempty"`
+	Body          string            `dynamodbav:"body,omitempty"`
+	EncryptedBody []byte            `dynamodbav:"body_enc,omitempty"`
+	DataKey       []byte            `dynamodbav:"data_key,omitempty"`
+	CustomerID    int               `dynamodbav:"customer_id,omitempty"`
+	ExpiresAt     int64             `dynamodbav:"expires_at"`
+}
+
+func (m IdempotencyDynamoModel) toEntity() *entity.IdempotencyRecord {
+	return &entity.IdempotencyRecord{
+		Key:         m.Key,
+		RequestHash: m.RequestHash,
+		LeaseID:     m.LeaseID,
+		Completed:   m.Completed,
+		StatusCode:  m.StatusCode,
+		Headers:     m.Headers,
+		Body:        m.Body,
+		CustomerID:  m.CustomerID,
+		ExpiresAt:   time.Unix(m.ExpiresAt, 0),
+	}
+}
+
+func toIdempotencyDynamoModel(record *entity.IdempotencyRecord) IdempotencyDynamoModel {
+	return IdempotencyDynamoModel{
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		LeaseID:     record.LeaseID,
+		Completed:   record.Completed,
+		StatusCode:  record.StatusCode,
+		Headers:     record.Headers,
+		Body:        record.Body,
+		CustomerID:  record.CustomerID,
+		ExpiresAt:   record.ExpiresAt.Unix(),
+	}
+}
+
+// NewIdempotencyDynamoDataSource stores the records in the table, calling DynamoDB through the resilient
+// client. With a key provider in the database the stored responses are encrypted
+func NewIdempotencyDynamoDataSource(db *database.DynamoDatabase, tableName string) port.IdempotencyDataSource {
+	ds := &idempotencyDynamoDataSource{
+		db:        db,
+		client:    db.ResilientClient(),
+		tableName: tableName,
+	}
+	if db.KeyProvider != nil {
+		ds.cipher = newCustomerCipher(db.KeyProvider, db.DataKeyTTL)
+	}
+	return ds
+}
+
+// bodyScope binds the encrypted body to its key
+func bodyScope(key string) string {
+	return "idempotency/" + key + "/body"
+}
+
+// marshalRecord returns the item of a record, with its body encrypted when the field-level encryption
+// is enabled
+func (ds *idempotencyDynamoDataSource) marshalRecord(ctx context.Context, record *entity.IdempotencyRecord) (map[string]types.AttributeValue, error) {
+	model := toIdempotencyDynamoModel(record)
+	if ds.cipher != nil && model.Body != "" {
+		var err error
+		if model.EncryptedBody, model.DataKey, err = ds.cipher.sealField(ctx, bodyScope(model.Key), model.Body); err != nil {
+			return nil, err
+		}
+		model.Body = ""
+	}
+	return attributevalue.MarshalMap(model)
+}
+
+// unmarshalRecord reads an item, decrypting its body
+func (ds *idempotencyDynamoDataSource) unmarshalRecord(ctx context.Context, item map[string]types.AttributeValue) (*entity.IdempotencyRecord, error) {
+	var model IdempotencyDynamoModel
+	if err := attributevalue.UnmarshalMap(item, &model); err != nil {
+		return nil, err
+	}
+	if ds.cipher != nil && len(model.DataKey) > 0 {
+		var err error
+		if model.Body, err = ds.cipher.openField(ctx, bodyScope(model.Key), model.DataKey, model.EncryptedBody); err != nil {
+			return nil, err
+		}
+	}
+	return model.toEntity(), nil
+}
+
+func (ds *idempotencyDynamoDataSource) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	startTime := time.Now()
+
+	item, err := ds.marshalRecord(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	// DynamoDB removes expired items in the background, so they may still be there
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(ds.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(idempotency_key) OR expires_at <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", startTime.Unix())},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	_, err = ds.client.PutItem(ctx, input)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "ReserveIdempotencyKey", ds.tableName, duration, err)
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) && conditionFailed.Item != nil {
+		existing, err := ds.unmarshalRecord(ctx, conditionFailed.Item)
+		// A retried call finds the pending reservation of its first attempt
+		if err == nil && !existing.Completed && existing.LeaseID == record.LeaseID {
+			return nil, nil
+		}
+		return existing, err
+	}
+
+	return nil, err
+}
+
+func (ds *idempotencyDynamoDataSource) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	startTime := time.Now()
+
+	item, err := ds.marshalRecord(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	// Only the request holding the lease writes its response. A retried call after its first attempt
+	// succeeded writes the same response again
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(ds.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("lease_id = :lease"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lease": &types.AttributeValueMemberS{Value: record.LeaseID},
+		},
+	}
+
+	_, err = ds.client.PutItem(ctx, input)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "CompleteIdempotencyKey", ds.tableName, duration, err)
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return domain.NewConflictError(domain.ErrIdempotencyLeaseLost)
+	}
+	return err
+}
+
+func (ds *idempotencyDynamoDataSource) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	startTime := time.Now()
+
+	// A completed record and the reservation of another request must survive, so only the pending
+	// reservation of the lease is removed
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(ds.tableName),
+		Key: map[string]types.AttributeValue{
+			"idempotency_key": &types.AttributeValueMemberS{Value: record.Key},
+		},
+		ConditionExpression: aws.String("completed = :false AND lease_id = :lease"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":false": &types.AttributeValueMemberBOOL{Value: false},
+			":lease": &types.AttributeValueMemberS{Value: record.LeaseID},
+		},
+	}
+
+	_, err := ds.client.DeleteItem(ctx, input)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "ReleaseIdempotencyKey", ds.tableName, duration, err)
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+// PurgeCustomer queries the customer index for the records of the customer and removes them one by one
+func (ds *idempotencyDynamoDataSource) PurgeCustomer(ctx context.Context, customerID int) error {
+	startTime := time.Now()
+
+	err := ds.purgeCustomer(ctx, customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "PurgeIdempotencyKeys", ds.tableName, duration, err)
+
+	return err
+}
+
+func (ds *idempotencyDynamoDataSource) purgeCustomer(ctx context.Context, customerID int) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ds.tableName),
+		IndexName:              aws.String(database.IdempotencyCustomerIndex),
+		KeyConditionExpression: aws.String("customer_id = :customer_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":customer_id": &types.AttributeValueMemberN{Value: strconv.Itoa(customerID)},
+		},
+		ProjectionExpression: aws.String("idempotency_key"),
+	}
+
+	for {
+		output, err := ds.client.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range output.Items {
+			_, err := ds.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName: aws.String(ds.tableName),
+				Key:       map[string]types.AttributeValue{"idempotency_key": item["idempotency_key"]},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,181 @@
+package datasource_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database/dynamotest"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+const fakeIdempotencyTable = "idempotency-keys"
+
+func newFakeIdempotencyDataSource() (port.IdempotencyDataSource, *dynamotest.FakeClient) {
+	client := dynamotest.NewFakeClient()
+	client.SetHashKey(fakeIdempotencyTable, "idempotency_key")
+	db := database.NewDynamoItemDatabase(client, fakeCustomersTable, logger.NewLogger(&config.Config{Environment: "test"}))
+	return datasource.NewIdempotencyDynamoDataSource(db, fakeIdempotencyTable), client
+}
+
+func TestIdempotencyDynamoDataSourceConformance_Fake(t *testing.T) {
+	suite.Run(t, NewIdempotencyDataSourceConformanceTestSuite(func() port.IdempotencyDataSource {
+		ds, _ := newFakeIdempotencyDataSource()
+		return ds
+	}))
+}
+
+func TestIdempotencyDynamoDataSource_Fake(t *testing.T) {
+	ctx := context.Background()
+	record := func(hash string, expiresAt time.Time) *entity.IdempotencyRecord {
+		return &entity.IdempotencyRecord{Key: "key-1", RequestHash: hash, LeaseID: "lease-" + hash, ExpiresAt: expiresAt.Truncate(time.Second)}
+	}
+	// reserveAndComplete stores a completed record, like a request that reserved the key and then completed it
+	reserveAndComplete := func(t *testing.T, ds port.IdempotencyDataSource, completed *entity.IdempotencyRecord) {
+		existing, err := ds.Reserve(ctx, completed)
+		require.NoError(t, err)
+		require.Nil(t, existing)
+		completed.Completed = true
+		require.NoError(t, ds.Complete(ctx, completed))
+	}
+
+	t.Run("should return the existing reservation", func(t *testing.T) {
+		ds, _ := newFakeIdempotencyDataSource()
+		first := record("hash-1", time.Now().Add(time.Hour))
+
+		existing, err := ds.Reserve(ctx, first)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+
+		existing, err = ds.Reserve(ctx, record("hash-2", time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.Equal(t, "hash-1", existing.RequestHash)
+	})
+
+	t.Run("should take over an expired reservation", func(t *testing.T) {
+		ds, _ := newFakeIdempotencyDataSource()
+		_, err := ds.Reserve(ctx, record("hash-1", time.Now().Add(-time.Minute)))
+		require.NoError(t, err)
+
+		existing, err := ds.Reserve(ctx, record("hash-2", time.Now().Add(time.Hour)))
+
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+
+	t.Run("should keep a completed record on release", func(t *testing.T) {
+		ds, client := newFakeIdempotencyDataSource()
+		completed := record("hash-1", time.Now().Add(time.Hour))
+		reserveAndComplete(t, ds, completed)
+
+		require.NoError(t, ds.Release(ctx, completed))
+
+		assert.Len(t, client.Items(fakeIdempotencyTable), 1)
+	})
+
+	t.Run("should complete and release only the reservation of the lease", func(t *testing.T) {
+		ds, client := newFakeIdempotencyDataSource()
+		expired := record("hash-1", time.Now().Add(-time.Minute))
+		_, err := ds.Reserve(ctx, expired)
+		require.NoError(t, err)
+		takenOver := record("hash-1", time.Now().Add(time.Hour))
+		takenOver.LeaseID = "lease-2"
+		_, err = ds.Reserve(ctx, takenOver)
+		require.NoError(t, err)
+
+		expired.Completed = true
+		assert.Equal(t, domain.NewConflictError(domain.ErrIdempotencyLeaseLost), ds.Complete(ctx, expired))
+		require.NoError(t, ds.Release(ctx, expired))
+
+		items := client.Items(fakeIdempotencyTable)
+		require.Len(t, items, 1)
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "lease-2"}, items[0]["lease_id"])
+		assert.Equal(t, &types.AttributeValueMemberBOOL{Value: false}, items[0]["completed"])
+	})
+
+	t.Run("should purge the records of a customer only", func(t *testing.T) {
+		ds, client := newFakeIdempotencyDataSource()
+		for key, customerID := range map[string]int{"key-1": 1, "key-2": 1, "key-3": 2, "key-4": 0} {
+			completed := record("hash-1", time.Now().Add(time.Hour))
+			completed.Key, completed.CustomerID = key, customerID
+			reserveAndComplete(t, ds, completed)
+		}
+
+		require.NoError(t, ds.PurgeCustomer(ctx, 1))
+
+		keys := make([]string, 0)
+		for _, item := range client.Items(fakeIdempotencyTable) {
+			keys = append(keys, item["idempotency_key"].(*types.AttributeValueMemberS).Value)
+		}
+		assert.ElementsMatch(t, []string{"key-3", "key-4"}, keys)
+		calls := client.CallsOf(dynamotest.OperationQuery)
+		if assert.Len(t, calls, 1) {
+			assert.Equal(t, database.IdempotencyCustomerIndex, aws.ToString(calls[0].Input.(*dynamodb.QueryInput).IndexName))
+		}
+	})
+
+	t.Run("should return the errors of the table", func(t *testing.T) {
+		ds, client := newFakeIdempotencyDataSource()
+		failure := errors.New("connection reset")
+		client.FailAlways(dynamotest.OperationPutItem, failure)
+		client.FailAlways(dynamotest.OperationDeleteItem, failure)
+
+		_, err := ds.Reserve(ctx, record("hash-1", time.Now().Add(time.Hour)))
+		assert.ErrorIs(t, err, failure)
+		assert.ErrorIs(t, ds.Complete(ctx, record("hash-1", time.Now().Add(time.Hour))), failure)
+		assert.ErrorIs(t, ds.Release(ctx, record("hash-1", time.Now().Add(time.Hour))), failure)
+	})
+}
+
+func TestIdempotencyDynamoDataSource_Encryption(t *testing.T) {
+	ctx := context.Background()
+	body := `{"id":1,"name":"John Doe","email":"john@example.com","cpf":"12345678900"}`
+
+	t.Run("should store the responses encrypted", func(t *testing.T) {
+		client := dynamotest.NewFakeClient()
+		client.SetHashKey(fakeIdempotencyTable, "idempotency_key")
+		db := database.NewDynamoItemDatabase(client, fakeCustomersTable, logger.NewLogger(&config.Config{Environment: "test"}))
+		db.KeyProvider = newFakeKeyProvider(t)
+		db.DataKeyTTL = time.Minute
+		ds := datasource.NewIdempotencyDynamoDataSource(db, fakeIdempotencyTable)
+
+		completed := &entity.IdempotencyRecord{Key: "key-1", RequestHash: "hash-1", LeaseID: "lease-1", StatusCode: 201,
+			Body: body, CustomerID: 1, ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second)}
+		_, err := ds.Reserve(ctx, completed)
+		require.NoError(t, err)
+		completed.Completed = true
+		require.NoError(t, ds.Complete(ctx, completed))
+
+		items := client.Items(fakeIdempotencyTable)
+		require.Len(t, items, 1)
+		assert.NotContains(t, items[0], "body")
+		assert.Contains(t, items[0], "body_enc")
+		for attribute, value := range items[0] {
+			for _, plaintext := range []string{"John", "john@example.com", "12345678900"} {
+				assert.NotContains(t, fmt.Sprint(value), plaintext, "attribute %s", attribute)
+			}
+		}
+
+		existing, err := ds.Reserve(ctx, &entity.IdempotencyRecord{Key: "key-1", RequestHash: "hash-1", ExpiresAt: completed.ExpiresAt})
+		require.NoError(t, err)
+		require.NotNil(t, existing)
+		assert.Equal(t, body, existing.Body)
+		assert.Equal(t, 1, existing.CustomerID)
+	})
+}
@@ -0,0 +1,117 @@
+package datasource_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+const testIdempotencyTableName = "tc4-customer-service-test-idempotency-keys"
+
+type IdempotencyDynamoDataSourceIntegrationTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	db         *database.DynamoDatabase
+	dataSource port.IdempotencyDataSource
+}
+
+func (suite *IdempotencyDynamoDataSourceIntegrationTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+
+	cfg := &config.Config{
+		DynamoTableName: getTestDynamoTableName(),
+		DynamoRegion:    getTestDynamoRegion(),
+		Environment:     "test",
+	}
+
+	var err error
+	suite.db, err = database.NewDynamoTestConnection(cfg, logger.NewLogger(cfg), getTestDynamoEndpoint())
+	require.NoError(suite.T(), err, "Failed to connect to test DynamoDB")
+
+	suite.dataSource = datasource.NewIdempotencyDynamoDataSource(suite.db, testIdempotencyTableName)
+
+	_, _ = suite.db.Client.DeleteTable(suite.ctx, &dynamodb.DeleteTableInput{TableName: aws.String(testIdempotencyTableName)})
+	err = suite.db.EnsureTable(suite.ctx, database.IdempotencyTableSchema(testIdempotencyTableName))
+	require.NoError(suite.T(), err, "Failed to create idempotency table")
+}
+
+func (suite *IdempotencyDynamoDataSourceIntegrationTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		_, _ = suite.db.Client.DeleteTable(suite.ctx, &dynamodb.DeleteTableInput{TableName: aws.String(testIdempotencyTableName)})
+	}
+}
+
+func (suite *IdempotencyDynamoDataSourceIntegrationTestSuite) TestReserveCompleteAndRelease() {
+	t := suite.T()
+	record := &entity.IdempotencyRecord{
+		Key:         "key-1",
+		RequestHash: "hash-1",
+		LeaseID:     "lease-1",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	retry := *record
+	retry.LeaseID = "lease-2"
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, record)
+	require.NoError(t, err)
+	assert.Nil(t, existing, "first reservation should store the record")
+
+	existing, err = suite.dataSource.Reserve(suite.ctx, &retry)
+	require.NoError(t, err)
+	require.NotNil(t, existing, "second reservation should return the pending record")
+	assert.False(t, existing.Completed)
+	assert.Equal(t, "hash-1", existing.RequestHash)
+
+	record.Completed = true
+	record.StatusCode = 201
+	record.Headers = map[string]string{"Location": "/customers/1"}
+	record.Body = `{"id":1}`
+	require.NoError(t, suite.dataSource.Complete(suite.ctx, record))
+
+	// Completed records can't be released
+	require.NoError(t, suite.dataSource.Release(suite.ctx, record))
+
+	existing, err = suite.dataSource.Reserve(suite.ctx, &retry)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.True(t, existing.Completed)
+	assert.Equal(t, 201, existing.StatusCode)
+	assert.Equal(t, "/customers/1", existing.Headers["Location"])
+	assert.Equal(t, `{"id":1}`, existing.Body)
+}
+
+func (suite *IdempotencyDynamoDataSourceIntegrationTestSuite) TestReserveExpiredKey() {
+	t := suite.T()
+	expired := &entity.IdempotencyRecord{Key: "key-2", RequestHash: "old", LeaseID: "lease-1", ExpiresAt: time.Now().Add(-time.Minute)}
+	_, err := suite.dataSource.Reserve(suite.ctx, expired)
+	require.NoError(t, err)
+
+	existing, err := suite.dataSource.Reserve(suite.ctx, &entity.IdempotencyRecord{
+		Key:         "key-2",
+		RequestHash: "new",
+		LeaseID:     "lease-2",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, existing, "expired keys should be reserved again")
+}
+
+func TestIdempotencyDynamoDataSourceIntegrationTestSuite(t *testing.T) {
+	skipDynamoIntegrationTests(t)
+
+	suite.Run(t, new(IdempotencyDynamoDataSourceIntegrationTestSuite))
+}
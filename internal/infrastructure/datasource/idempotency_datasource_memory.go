@@ -0,0 +1,79 @@
+package datasource
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// idempotencyMemoryDataSource keeps the idempotency records in memory, next to the customers of the
+// memory data source. The records are lost when the process exits
+type idempotencyMemoryDataSource struct {
+	mu      sync.Mutex
+	records map[string]entity.IdempotencyRecord
+}
+
+func NewIdempotencyMemoryDataSource() port.IdempotencyDataSource {
+	return &idempotencyMemoryDataSource{
+		records: make(map[string]entity.IdempotencyRecord),
+	}
+}
+
+// copyIdempotencyRecord keeps the stored records apart from the ones of the callers
+func copyIdempotencyRecord(record entity.IdempotencyRecord) entity.IdempotencyRecord {
+	record.Headers = maps.Clone(record.Headers)
+	return record
+}
+
+func (ds *idempotencyMemoryDataSource) Reserve(_ context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if stored, ok := ds.records[record.Key]; ok && !stored.Expired(time.Now()) {
+		// A retried call finds the pending reservation of its first attempt
+		if !stored.Completed && stored.LeaseID == record.LeaseID {
+			return nil, nil
+		}
+		existing := copyIdempotencyRecord(stored)
+		return &existing, nil
+	}
+
+	ds.records[record.Key] = copyIdempotencyRecord(*record)
+	return nil, nil
+}
+
+func (ds *idempotencyMemoryDataSource) Complete(_ context.Context, record *entity.IdempotencyRecord) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if stored, ok := ds.records[record.Key]; !ok || stored.LeaseID != record.LeaseID {
+		return domain.NewConflictError(domain.ErrIdempotencyLeaseLost)
+	}
+	ds.records[record.Key] = copyIdempotencyRecord(*record)
+	return nil
+}
+
+func (ds *idempotencyMemoryDataSource) Release(_ context.Context, record *entity.IdempotencyRecord) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if stored, ok := ds.records[record.Key]; ok && !stored.Completed && stored.LeaseID == record.LeaseID {
+		delete(ds.records, record.Key)
+	}
+	return nil
+}
+
+func (ds *idempotencyMemoryDataSource) PurgeCustomer(_ context.Context, customerID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	maps.DeleteFunc(ds.records, func(_ string, stored entity.IdempotencyRecord) bool {
+		return stored.CustomerID == customerID
+	})
+	return nil
+}
@@ -0,0 +1,16 @@
+package datasource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+)
+
+func TestIdempotencyMemoryDataSourceConformance(t *testing.T) {
+	suite.Run(t, NewIdempotencyDataSourceConformanceTestSuite(func() port.IdempotencyDataSource {
+		return datasource.NewIdempotencyMemoryDataSource()
+	}))
+}
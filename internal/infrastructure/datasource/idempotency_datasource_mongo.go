@@ -0,0 +1,152 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+// IdempotencyMongoModel is a document of the idempotency_keys collection, keyed by the idempotency key.
+// A TTL index on expires_at eventually removes the expired keys
+type IdempotencyMongoModel struct {
+	Key         string            `bson:"_id"`
+	RequestHash string            `bson:"request_hash"`
+	LeaseID     string            `bson:"lease_id"`
+	Completed   bool              `bson:"completed"`
+	StatusCode  int               `bson:"status_code,omitempty"`
+	Headers     map[string]string `bson:"headers,omitempty"`
+	Body        string            `bson:"body,omitempty"`
+	CustomerID  int               `bson:"customer_id,omitempty"`
+	ExpiresAt   time.Time         `bson:"expires_at"`
+}
+
+func (m IdempotencyMongoModel) toEntity() *entity.IdempotencyRecord {
+	return &entity.IdempotencyRecord{
+		Key:         m.Key,
+		RequestHash: m.RequestHash,
+		LeaseID:     m.LeaseID,
+		Completed:   m.Completed,
+		StatusCode:  m.StatusCode,
+		Headers:     m.Headers,
+		Body:        m.Body,
+		CustomerID:  m.CustomerID,
+		ExpiresAt:   m.ExpiresAt,
+	}
+}
+
+func toIdempotencyMongoModel(record *entity.IdempotencyRecord) IdempotencyMongoModel {
+	return IdempotencyMongoModel{
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		LeaseID:     record.LeaseID,
+		Completed:   record.Completed,
+		StatusCode:  record.StatusCode,
+		Headers:     record.Headers,
+		Body:        record.Body,
+		CustomerID:  record.CustomerID,
+		ExpiresAt:   record.ExpiresAt,
+	}
+}
+
+type idempotencyMongoDataSource struct {
+	db *database.MongoDatabase
+}
+
+func NewIdempotencyMongoDataSource(db *database.MongoDatabase) port.IdempotencyDataSource {
+	return &idempotencyMongoDataSource{
+		db: db,
+	}
+}
+
+func (ds *idempotencyMongoDataSource) collection() *mongo.Collection {
+	return ds.db.Database.Collection(database.MongoIdempotencyCollection)
+}
+
+func (ds *idempotencyMongoDataSource) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	startTime := time.Now()
+
+	existing, err := ds.reserve(ctx, record)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "ReserveIdempotencyKey", database.MongoIdempotencyCollection, duration, err)
+
+	return existing, err
+}
+
+func (ds *idempotencyMongoDataSource) reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	for {
+		// The TTL index removes expired documents in the background, so an expired one is replaced, and the
+		// upsert of a key whose document hasn't expired fails with a duplicate key
+		filter := bson.M{"_id": record.Key, "expires_at": bson.M{"$lte": time.Now()}}
+		_, err := ds.collection().ReplaceOne(ctx, filter, toIdempotencyMongoModel(record), options.Replace().SetUpsert(true))
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+
+		var existing IdempotencyMongoModel
+		err = ds.collection().FindOne(ctx, bson.M{"_id": record.Key}).Decode(&existing)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// The record was released or purged in the meantime, so the key is free again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// A retried call finds the pending reservation of its first attempt
+		if !existing.Completed && existing.LeaseID == record.LeaseID {
+			return nil, nil
+		}
+		return existing.toEntity(), nil
+	}
+}
+
+// Complete replaces the document of the lease with the response, failing when another request took it over
+func (ds *idempotencyMongoDataSource) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	startTime := time.Now()
+
+	result, err := ds.collection().ReplaceOne(ctx, bson.M{"_id": record.Key, "lease_id": record.LeaseID}, toIdempotencyMongoModel(record))
+	if err == nil && result.MatchedCount == 0 {
+		err = domain.NewConflictError(domain.ErrIdempotencyLeaseLost)
+	}
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "CompleteIdempotencyKey", database.MongoIdempotencyCollection, duration, err)
+
+	return err
+}
+
+// Release removes the pending document of the lease, keeping a completed one and the reservation of another
+// request
+func (ds *idempotencyMongoDataSource) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	startTime := time.Now()
+
+	_, err := ds.collection().DeleteOne(ctx, bson.M{"_id": record.Key, "lease_id": record.LeaseID, "completed": false})
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "ReleaseIdempotencyKey", database.MongoIdempotencyCollection, duration, err)
+
+	return err
+}
+
+func (ds *idempotencyMongoDataSource) PurgeCustomer(ctx context.Context, customerID int) error {
+	startTime := time.Now()
+
+	_, err := ds.collection().DeleteMany(ctx, bson.M{"customer_id": customerID})
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "PurgeIdempotencyKeys", database.MongoIdempotencyCollection, duration, err)
+
+	return err
+}
@@ -0,0 +1,161 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
+)
+
+const (
+	idempotencyTable   = "idempotency_keys"
+	idempotencyColumns = "idempotency_key, request_hash, lease_id, completed, status_code, headers, body, customer_id, expires_at"
+)
+
+// idempotencyPostgresDataSource stores the records in the idempotency_keys table. Expired rows are taken
+// over by the next reservation of their key
+type idempotencyPostgresDataSource struct {
+	db *database.PostgresDatabase
+}
+
+func NewIdempotencyPostgresDataSource(db *database.PostgresDatabase) port.IdempotencyDataSource {
+	return &idempotencyPostgresDataSource{
+		db: db,
+	}
+}
+
+func (ds *idempotencyPostgresDataSource) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	startTime := time.Now()
+
+	existing, err := ds.reserve(ctx, record)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "ReserveIdempotencyKey", idempotencyTable, duration, err)
+
+	return existing, err
+}
+
+func (ds *idempotencyPostgresDataSource) reserve(ctx context.Context, record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		// The row of an expired key is overwritten, any other one is left as is
+		result, err := ds.db.DB.ExecContext(ctx,
+			"INSERT INTO idempotency_keys ("+idempotencyColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) "+
+				"ON CONFLICT (idempotency_key) DO UPDATE SET request_hash = EXCLUDED.request_hash, "+
+				"lease_id = EXCLUDED.lease_id, completed = EXCLUDED.completed, status_code = EXCLUDED.status_code, "+
+				"headers = EXCLUDED.headers, body = EXCLUDED.body, customer_id = EXCLUDED.customer_id, "+
+				"expires_at = EXCLUDED.expires_at WHERE idempotency_keys.expires_at <= $10",
+			record.Key, record.RequestHash, record.LeaseID, record.Completed, record.StatusCode, string(headers),
+			record.Body, record.CustomerID, record.ExpiresAt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		reserved, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if reserved > 0 {
+			return nil, nil
+		}
+
+		existing, err := ds.find(ctx, record.Key)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The record was released or purged in the meantime, so the key is free again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// A retried call finds the pending reservation of its first attempt
+		if !existing.Completed && existing.LeaseID == record.LeaseID {
+			return nil, nil
+		}
+		return existing, nil
+	}
+}
+
+func (ds *idempotencyPostgresDataSource) find(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	var record entity.IdempotencyRecord
+	var headers []byte
+	err := ds.db.DB.QueryRowContext(ctx, "SELECT "+idempotencyColumns+" FROM idempotency_keys WHERE idempotency_key = $1", key).
+		Scan(&record.Key, &record.RequestHash, &record.LeaseID, &record.Completed, &record.StatusCode, &headers,
+			&record.Body, &record.CustomerID, &record.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(headers, &record.Headers); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Complete writes the response on the row of the lease, failing when another request took it over
+func (ds *idempotencyPostgresDataSource) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	startTime := time.Now()
+
+	err := ds.complete(ctx, record)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "CompleteIdempotencyKey", idempotencyTable, duration, err)
+
+	return err
+}
+
+func (ds *idempotencyPostgresDataSource) complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	result, err := ds.db.DB.ExecContext(ctx,
+		"UPDATE idempotency_keys SET request_hash = $3, completed = $4, status_code = $5, headers = $6, body = $7, "+
+			"customer_id = $8, expires_at = $9 WHERE idempotency_key = $1 AND lease_id = $2",
+		record.Key, record.LeaseID, record.RequestHash, record.Completed, record.StatusCode, string(headers),
+		record.Body, record.CustomerID, record.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	completed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if completed == 0 {
+		return domain.NewConflictError(domain.ErrIdempotencyLeaseLost)
+	}
+	return nil
+}
+
+// Release removes the pending row of the lease, keeping a completed one and the reservation of another request
+func (ds *idempotencyPostgresDataSource) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	startTime := time.Now()
+
+	_, err := ds.db.DB.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND lease_id = $2 AND NOT completed",
+		record.Key, record.LeaseID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "ReleaseIdempotencyKey", idempotencyTable, duration, err)
+
+	return err
+}
+
+func (ds *idempotencyPostgresDataSource) PurgeCustomer(ctx context.Context, customerID int) error {
+	startTime := time.Now()
+
+	_, err := ds.db.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE customer_id = $1", customerID)
+
+	duration := time.Since(startTime)
+	ds.db.LogOperation(ctx, "PurgeIdempotencyKeys", idempotencyTable, duration, err)
+
+	return err
+}
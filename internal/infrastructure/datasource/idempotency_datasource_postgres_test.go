@@ -0,0 +1,25 @@
+package datasource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+)
+
+func TestIdempotencyPostgresDataSourceConformance(t *testing.T) {
+	skipPostgresIntegrationTests(t)
+
+	db := newTestPostgresDatabase(t)
+	defer db.Close(context.Background())
+
+	suite.Run(t, NewIdempotencyDataSourceConformanceTestSuite(func() port.IdempotencyDataSource {
+		_, err := db.DB.Exec("TRUNCATE idempotency_keys")
+		require.NoError(t, err, "Failed to truncate idempotency_keys")
+		return datasource.NewIdempotencyPostgresDataSource(db)
+	}))
+}
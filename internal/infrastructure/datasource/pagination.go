@@ -0,0 +1,126 @@
+package datasource
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// pageBounds returns the slice bounds of a page of a result with total items. Pages start at 1,
+// and a limit of 0 or less returns every item in a single page
+func pageBounds(total, page, limit int) (int, int) {
+	if limit <= 0 {
+		return 0, total
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageOf cuts a page of customers sorted by ID. With an afterID the page starts after that
+// customer (keyset pagination), otherwise it is the page-th page of the result (offset pagination)
+func pageOf(customers []*entity.Customer, afterID, page, limit int) []*entity.Customer {
+	if afterID > 0 {
+		start := sort.Search(len(customers), func(i int) bool { return customers[i].ID > afterID })
+		customers = customers[start:]
+		page = 1
+	}
+
+	start, end := pageBounds(len(customers), page, limit)
+	return customers[start:end]
+}
+
+// splitAfterID separates the keyset cursor from the equality filters of FindAll
+func splitAfterID(filters map[string]interface{}) (map[string]interface{}, int, error) {
+	value, ok := filters[port.FilterAfterID]
+	if !ok {
+		return filters, 0, nil
+	}
+
+	afterID, err := strconv.Atoi(fmt.Sprint(value))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid %s filter %v: %w", port.FilterAfterID, value, err)
+	}
+
+	equality := make(map[string]interface{}, len(filters)-1)
+	for key, value := range filters {
+		if key != port.FilterAfterID {
+			equality[key] = value
+		}
+	}
+	return equality, afterID, nil
+}
+
+// splitSearch separates the name search from the equality filters of FindAll, returning its words
+func splitSearch(filters map[string]interface{}) (map[string]interface{}, []string) {
+	value, ok := filters[port.FilterSearch]
+	if !ok {
+		return filters, nil
+	}
+
+	equality := make(map[string]interface{}, len(filters)-1)
+	for key, value := range filters {
+		if key != port.FilterSearch {
+			equality[key] = value
+		}
+	}
+	return equality, strings.Fields(fmt.Sprint(value))
+}
+
+// splitIncludeDeleted separates the flag keeping the deleted customers from the equality filters of FindAll
+func splitIncludeDeleted(filters map[string]interface{}) (map[string]interface{}, bool) {
+	value, ok := filters[port.FilterIncludeDeleted]
+	if !ok {
+		return filters, false
+	}
+
+	equality := make(map[string]interface{}, len(filters)-1)
+	for key, value := range filters {
+		if key != port.FilterIncludeDeleted {
+			equality[key] = value
+		}
+	}
+	return equality, value == true
+}
+
+// matchesSearch reports whether every search term is a word of the name, ignoring case
+func matchesSearch(name string, terms []string) bool {
+	words := strings.Fields(strings.ToLower(name))
+	for _, term := range terms {
+		if !slices.Contains(words, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// inIDOrder orders customers like the requested IDs, dropping the IDs that weren't found
+func inIDOrder(customers []*entity.Customer, ids []int) []*entity.Customer {
+	byID := make(map[int]*entity.Customer, len(customers))
+	for _, customer := range customers {
+		byID[customer.ID] = customer
+	}
+
+	ordered := make([]*entity.Customer, 0, len(customers))
+	for _, id := range ids {
+		if customer, ok := byID[id]; ok {
+			ordered = append(ordered, customer)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,35 @@
+// Package eventpublisher publishes the domain events of the customers
+package eventpublisher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+// EventLogMessage is the message of the log records of the events, which a CloudWatch Logs subscription
+// filter matches to forward them to the services downstream
+const EventLogMessage = "customer event"
+
+// logEventPublisher is a stand-in for a message bus: each event is written as a structured log record
+type logEventPublisher struct {
+	logger *logger.Logger
+}
+
+func NewLogEventPublisher(l *logger.Logger) port.EventPublisher {
+	return &logEventPublisher{logger: l}
+}
+
+func (p *logEventPublisher) Publish(ctx context.Context, event *entity.CustomerEvent) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, EventLogMessage,
+		slog.String("event_type", string(event.Type)),
+		slog.Int("customer_id", event.CustomerID),
+		slog.String("request_id", event.RequestID),
+		slog.String("occurred_at", event.OccurredAt.UTC().Format(time.RFC3339Nano)),
+	)
+	return nil
+}
@@ -0,0 +1,40 @@
+package eventpublisher_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
+)
+
+func TestLogEventPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := eventpublisher.NewLogEventPublisher(&logger.Logger{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+	occurredAt := time.Date(2024, 2, 9, 10, 0, 0, 0, time.UTC)
+
+	err := publisher.Publish(context.Background(), &entity.CustomerEvent{
+		Type:       entity.CustomerEventAnonymized,
+		CustomerID: 123,
+		RequestID:  "req-1",
+		OccurredAt: occurredAt,
+	})
+	require.NoError(t, err)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, eventpublisher.EventLogMessage, record["msg"])
+	assert.Equal(t, "customer.anonymized", record["event_type"])
+	assert.Equal(t, float64(123), record["customer_id"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "2024-02-09T10:00:00Z", record["occurred_at"])
+}
@@ -0,0 +1,217 @@
+// Package exporter writes the customer export files, in CSV, NDJSON or Parquet
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// Format is the encoding of an export file
+type Format string
+
+const (
+	// FormatCSV is a CSV file with a header row naming the fields
+	FormatCSV Format = "csv"
+	// FormatNDJSON has a JSON object with the fields of a customer on each line
+	FormatNDJSON Format = "ndjson"
+	// FormatParquet is a Snappy compressed Parquet file with a column for each field
+	FormatParquet Format = "parquet"
+)
+
+var errNotStarted = errors.New("the export writer was used before Begin")
+
+// FormatOf returns the format of a file from its extension: .csv, .ndjson, .jsonl or .parquet
+func FormatOf(name string) (Format, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	case ".parquet":
+		return FormatParquet, nil
+	default:
+		return "", domain.NewInvalidInputError(domain.ErrExportUnknownFormat)
+	}
+}
+
+// NewWriter returns the export writer of a format. Closing it completes the file, but doesn't close w
+func NewWriter(w io.Writer, format Format) (port.CustomerExportWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{writer: bufio.NewWriter(w)}, nil
+	case FormatParquet:
+		return &parquetWriter{output: w}, nil
+	default:
+		return nil, domain.NewInvalidInputError(domain.ErrExportUnknownFormat)
+	}
+}
+
+// fieldValue returns the value of a field of a customer, nil for the timestamps that weren't recorded
+func fieldValue(customer *entity.Customer, field dto.ExportField) any {
+	switch field {
+	case dto.ExportFieldID:
+		return customer.ID
+	case dto.ExportFieldName:
+		return customer.Name
+	case dto.ExportFieldEmail:
+		return customer.Email
+	case dto.ExportFieldCPF:
+		return customer.CPF
+	case dto.ExportFieldVersion:
+		return customer.Version
+	case dto.ExportFieldCreatedAt:
+		return timestamp(customer.CreatedAt)
+	case dto.ExportFieldUpdatedAt:
+		return timestamp(customer.UpdatedAt)
+	default:
+		return nil
+	}
+}
+
+func timestamp(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	fields []dto.ExportField
+	record []string
+}
+
+func (w *csvWriter) Begin(fields []dto.ExportField) error {
+	w.fields = fields
+	w.record = make([]string, len(fields))
+	for i, field := range fields {
+		w.record[i] = string(field)
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Write(customer *entity.Customer) error {
+	if w.fields == nil {
+		return errNotStarted
+	}
+	for i, field := range w.fields {
+		switch value := fieldValue(customer, field).(type) {
+		case int:
+			w.record[i] = strconv.Itoa(value)
+		case string:
+			w.record[i] = value
+		case time.Time:
+			w.record[i] = value.Format(time.RFC3339)
+		default:
+			w.record[i] = ""
+		}
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonWriter struct {
+	writer *bufio.Writer
+	fields []dto.ExportField
+}
+
+func (w *ndjsonWriter) Begin(fields []dto.ExportField) error {
+	w.fields = fields
+	return nil
+}
+
+// Write encodes the fields in their export order, which a map wouldn't keep
+func (w *ndjsonWriter) Write(customer *entity.Customer) error {
+	if w.fields == nil {
+		return errNotStarted
+	}
+	line := []byte{'{'}
+	for i, field := range w.fields {
+		if i > 0 {
+			line = append(line, ',')
+		}
+		value, err := json.Marshal(fieldValue(customer, field))
+		if err != nil {
+			return err
+		}
+		line = strconv.AppendQuote(line, string(field))
+		line = append(line, ':')
+		line = append(line, value...)
+	}
+	line = append(line, '}', '\n')
+	_, err := w.writer.Write(line)
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.writer.Flush()
+}
+
+type parquetWriter struct {
+	output io.Writer
+	writer *parquet.Writer
+	fields []dto.ExportField
+	row    map[string]any
+}
+
+// Begin builds the schema of the selected fields. The timestamps are optional, since not every
+// data source records them
+func (w *parquetWriter) Begin(fields []dto.ExportField) error {
+	columns := make(parquet.Group, len(fields))
+	for _, field := range fields {
+		switch field {
+		case dto.ExportFieldID, dto.ExportFieldVersion:
+			columns[string(field)] = parquet.Int(64)
+		case dto.ExportFieldCreatedAt, dto.ExportFieldUpdatedAt:
+			columns[string(field)] = parquet.Optional(parquet.Timestamp(parquet.Millisecond))
+		default:
+			columns[string(field)] = parquet.String()
+		}
+	}
+
+	w.fields = fields
+	w.row = make(map[string]any, len(fields))
+	w.writer = parquet.NewWriter(w.output, parquet.NewSchema("customer", columns), parquet.Compression(&parquet.Snappy))
+	return nil
+}
+
+func (w *parquetWriter) Write(customer *entity.Customer) error {
+	if w.writer == nil {
+		return errNotStarted
+	}
+	for _, field := range w.fields {
+		value := fieldValue(customer, field)
+		if number, ok := value.(int); ok {
+			value = int64(number)
+		}
+		w.row[string(field)] = value
+	}
+	return w.writer.Write(w.row)
+}
+
+func (w *parquetWriter) Close() error {
+	if w.writer == nil {
+		return errNotStarted
+	}
+	return w.writer.Close()
+}
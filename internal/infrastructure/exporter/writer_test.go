@@ -0,0 +1,112 @@
+package exporter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+func testCustomers() []*entity.Customer {
+	return []*entity.Customer{
+		{
+			ID:        1,
+			Name:      "Doe, John",
+			Email:     "john@example.com",
+			CPF:       "52998224725",
+			Version:   2,
+			CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			UpdatedAt: time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC),
+		},
+		{ID: 2, Name: "Jane", Email: "jane@example.com", CPF: "11122233344", Version: 1},
+	}
+}
+
+func export(t *testing.T, format Format, fields []dto.ExportField) []byte {
+	var output bytes.Buffer
+	writer, err := NewWriter(&output, format)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Begin(fields))
+	for _, customer := range testCustomers() {
+		require.NoError(t, writer.Write(customer))
+	}
+	require.NoError(t, writer.Close())
+	return output.Bytes()
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "should detect CSV files", file: "exports/customers.csv", want: FormatCSV},
+		{name: "should detect NDJSON files", file: "customers.ndjson", want: FormatNDJSON},
+		{name: "should detect JSON Lines files", file: "customers.JSONL", want: FormatNDJSON},
+		{name: "should detect Parquet files", file: "s3://exports/customers.parquet", want: FormatParquet},
+		{name: "should reject other files", file: "customers.xlsx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatOf(tt.file)
+			if tt.wantErr {
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriter_CSV(t *testing.T) {
+	got := export(t, FormatCSV, []dto.ExportField{dto.ExportFieldID, dto.ExportFieldName, dto.ExportFieldCreatedAt})
+
+	assert.Equal(t, "id,name,created_at\n1,\"Doe, John\",2025-01-02T03:04:05Z\n2,Jane,\n", string(got))
+}
+
+func TestWriter_NDJSON(t *testing.T) {
+	got := export(t, FormatNDJSON, []dto.ExportField{dto.ExportFieldEmail, dto.ExportFieldID, dto.ExportFieldUpdatedAt})
+
+	assert.Equal(t, `{"email":"john@example.com","id":1,"updated_at":"2025-02-03T04:05:06Z"}`+"\n"+
+		`{"email":"jane@example.com","id":2,"updated_at":null}`+"\n", string(got))
+}
+
+func TestWriter_Parquet(t *testing.T) {
+	got := export(t, FormatParquet, dto.ExportFields)
+
+	reader := parquet.NewReader(bytes.NewReader(got))
+	defer reader.Close()
+	assert.Equal(t, int64(2), reader.NumRows())
+
+	row := map[string]any{}
+	require.NoError(t, reader.Read(&row))
+	assert.Equal(t, int64(1), row["id"])
+	assert.Equal(t, "Doe, John", row["name"])
+	assert.Equal(t, "52998224725", row["cpf"])
+	assert.Equal(t, int64(2), row["version"])
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC).UnixMilli(), row["created_at"])
+
+	row = map[string]any{}
+	require.NoError(t, reader.Read(&row))
+	assert.Equal(t, int64(2), row["id"])
+	assert.Nil(t, row["updated_at"], "the timestamps that weren't recorded must be null")
+}
+
+func TestWriter_WriteBeforeBegin(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatNDJSON, FormatParquet} {
+		writer, err := NewWriter(&bytes.Buffer{}, format)
+		require.NoError(t, err)
+		assert.ErrorIs(t, writer.Write(testCustomers()[0]), errNotStarted, string(format))
+	}
+}
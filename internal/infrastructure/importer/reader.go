@@ -0,0 +1,136 @@
+// Package importer reads the customer import files, in CSV or NDJSON
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+// Format is the encoding of an import file
+type Format string
+
+const (
+	// FormatCSV is a CSV file with a header row naming the name, email and cpf columns, in any order
+	FormatCSV Format = "csv"
+	// FormatNDJSON has a JSON object with name, email and cpf members on each line
+	FormatNDJSON Format = "ndjson"
+)
+
+// maxLineSize is the longest NDJSON line read
+const maxLineSize = 1024 * 1024
+
+// csvColumns are the columns read from a CSV file
+var csvColumns = []string{"name", "email", "cpf"}
+
+// FormatOf returns the format of a file from its extension: .csv, .ndjson or .jsonl
+func FormatOf(name string) (Format, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", domain.NewInvalidInputError(domain.ErrImportUnknownFormat)
+	}
+}
+
+// ReadRows reads the customers of an import file. A row that can't be read is returned with its Error
+// set, so it's reported with the others, while a file that can't be read at all returns an error
+func ReadRows(r io.Reader, format Format) ([]dto.ImportCustomerRow, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatNDJSON:
+		return readNDJSON(r)
+	default:
+		return nil, domain.NewInvalidInputError(domain.ErrImportUnknownFormat)
+	}
+}
+
+func readCSV(r io.Reader) ([]dto.ImportCustomerRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []dto.ImportCustomerRow{}, nil
+	}
+	if err != nil {
+		return nil, domain.NewInvalidInputError(err.Error())
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, column := range header {
+		positions[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, column := range csvColumns {
+		if _, ok := positions[column]; !ok {
+			return nil, domain.NewInvalidInputError(domain.ErrImportMissingColumns)
+		}
+	}
+
+	rows := make([]dto.ImportCustomerRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, dto.ImportCustomerRow{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := dto.ImportCustomerRow{Line: line}
+		if len(record) != len(header) {
+			row.Error = fmt.Sprintf("expected %d fields, found %d", len(header), len(record))
+		} else {
+			row.Name = record[positions["name"]]
+			row.Email = record[positions["email"]]
+			row.CPF = record[positions["cpf"]]
+		}
+		rows = append(rows, row)
+	}
+}
+
+func readNDJSON(r io.Reader) ([]dto.ImportCustomerRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	rows := make([]dto.ImportCustomerRow, 0)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var customer struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			CPF   string `json:"cpf"`
+		}
+		row := dto.ImportCustomerRow{Line: line}
+		if err := json.Unmarshal([]byte(text), &customer); err != nil {
+			row.Error = err.Error()
+		} else {
+			row.Name, row.Email, row.CPF = customer.Name, customer.Email, customer.CPF
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
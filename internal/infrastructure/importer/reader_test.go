@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "should detect CSV files", file: "imports/customers.CSV", want: FormatCSV},
+		{name: "should detect NDJSON files", file: "customers.ndjson", want: FormatNDJSON},
+		{name: "should detect JSON Lines files", file: "customers.jsonl", want: FormatNDJSON},
+		{name: "should reject other files", file: "customers.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatOf(tt.file)
+			if tt.wantErr {
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		format  Format
+		want    []dto.ImportCustomerRow
+		wantErr bool
+	}{
+		{
+			name:   "should read the CSV columns in any order",
+			input:  "\ufeffCPF,name,email\n52998224725,John Doe,john@example.com\n\"111.222.333-44\",\"Doe, Jane\",jane@example.com\n",
+			format: FormatCSV,
+			want: []dto.ImportCustomerRow{
+				{Line: 2, Name: "John Doe", Email: "john@example.com", CPF: "52998224725"},
+				{Line: 3, Name: "Doe, Jane", Email: "jane@example.com", CPF: "111.222.333-44"},
+			},
+		},
+		{
+			name:   "should report the CSV rows that can't be read",
+			input:  "name,email,cpf\nJohn Doe,john@example.com\nJa\"ne,jane@example.com,11122233344\nJim,jim@example.com,52998224725\n",
+			format: FormatCSV,
+			want: []dto.ImportCustomerRow{
+				{Line: 2, Error: "expected 3 fields, found 2"},
+				{Line: 3, Error: `bare " in non-quoted-field`},
+				{Line: 4, Name: "Jim", Email: "jim@example.com", CPF: "52998224725"},
+			},
+		},
+		{
+			name:   "should read an empty CSV file",
+			input:  "",
+			format: FormatCSV,
+			want:   []dto.ImportCustomerRow{},
+		},
+		{
+			name:    "should fail when a CSV column is missing",
+			input:   "name,cpf\nJohn Doe,52998224725\n",
+			format:  FormatCSV,
+			wantErr: true,
+		},
+		{
+			name:   "should read NDJSON lines and skip the blank ones",
+			input:  "{\"name\":\"John Doe\",\"email\":\"john@example.com\",\"cpf\":\"52998224725\"}\n\n{\"name\":\n",
+			format: FormatNDJSON,
+			want: []dto.ImportCustomerRow{
+				{Line: 1, Name: "John Doe", Email: "john@example.com", CPF: "52998224725"},
+				{Line: 3, Error: "unexpected end of JSON input"},
+			},
+		},
+		{
+			name:    "should reject unknown formats",
+			input:   "",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadRows(strings.NewReader(tt.input), tt.format)
+			if tt.wantErr {
+				assert.IsType(t, &domain.InvalidInputError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package keyprovider holds the master keys of the field-level encryption of the customers
+package keyprovider
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+const (
+	// dataKeySize is the size of the AES-256 data keys
+	dataKeySize = 32
+	// wrapKeyInfo and blindIndexKeyInfo separate the keys derived from the master key
+	wrapKeyInfo       = "tc4-customer-service data key wrapping"
+	blindIndexKeyInfo = "tc4-customer-service blind index"
+)
+
+var errInvalidWrappedKey = errors.New("invalid wrapped data key")
+
+// fileKeyProvider keeps the master key in a local file, for tests and local runs. The data keys are wrapped
+// with AES-GCM and the blind index key is derived from the master key, so the file is all there is to back up
+type fileKeyProvider struct {
+	wrapper       cipher.AEAD
+	blindIndexKey []byte
+}
+
+// NewFileKeyProvider reads a master key file, which has 32 random bytes encoded in base64, like the output
+// of `openssl rand -base64 32`
+func NewFileKeyProvider(path string) (port.KeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the master key file: %w", err)
+	}
+	masterKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the master key file: %w", err)
+	}
+	return newFileKeyProvider(masterKey)
+}
+
+func newFileKeyProvider(masterKey []byte) (*fileKeyProvider, error) {
+	if len(masterKey) != dataKeySize {
+		return nil, fmt.Errorf("the master key must have %d bytes, got %d", dataKeySize, len(masterKey))
+	}
+
+	wrapKey, err := hkdf.Key(sha256.New, masterKey, nil, wrapKeyInfo, dataKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	wrapper, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	blindIndexKey, err := hkdf.Key(sha256.New, masterKey, nil, blindIndexKeyInfo, dataKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileKeyProvider{wrapper: wrapper, blindIndexKey: blindIndexKey}, nil
+}
+
+// GenerateDataKey returns a random data key, wrapped as the GCM nonce followed by the sealed key
+func (p *fileKeyProvider) GenerateDataKey(_ context.Context) ([]byte, []byte, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, p.wrapper.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return plaintext, p.wrapper.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (p *fileKeyProvider) DecryptDataKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < p.wrapper.NonceSize() {
+		return nil, errInvalidWrappedKey
+	}
+	nonce, sealed := wrapped[:p.wrapper.NonceSize()], wrapped[p.wrapper.NonceSize():]
+	plaintext, err := p.wrapper.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, errInvalidWrappedKey
+	}
+	return plaintext, nil
+}
+
+func (p *fileKeyProvider) BlindIndexKey(_ context.Context) ([]byte, error) {
+	return p.blindIndexKey, nil
+}
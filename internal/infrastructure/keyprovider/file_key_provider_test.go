@@ -0,0 +1,74 @@
+package keyprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "master.key")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestFileKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	masterKey := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+	t.Run("should unwrap the data keys it generates", func(t *testing.T) {
+		keys, err := NewFileKeyProvider(writeKeyFile(t, masterKey+"\n"))
+		require.NoError(t, err)
+
+		plaintext, wrapped, err := keys.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		assert.Len(t, plaintext, dataKeySize)
+		assert.NotContains(t, string(wrapped), string(plaintext))
+
+		unwrapped, err := keys.DecryptDataKey(ctx, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, unwrapped)
+	})
+
+	t.Run("should derive the same blind index key from the same master key", func(t *testing.T) {
+		first, err := NewFileKeyProvider(writeKeyFile(t, masterKey))
+		require.NoError(t, err)
+		second, err := NewFileKeyProvider(writeKeyFile(t, masterKey))
+		require.NoError(t, err)
+
+		firstKey, err := first.BlindIndexKey(ctx)
+		require.NoError(t, err)
+		secondKey, err := second.BlindIndexKey(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, firstKey, secondKey)
+		assert.NotEqual(t, []byte("0123456789abcdef0123456789abcdef"), firstKey)
+	})
+
+	t.Run("should not unwrap a data key of another master key", func(t *testing.T) {
+		keys, err := NewFileKeyProvider(writeKeyFile(t, masterKey))
+		require.NoError(t, err)
+		other, err := NewFileKeyProvider(writeKeyFile(t, base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))))
+		require.NoError(t, err)
+
+		_, wrapped, err := keys.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		_, err = other.DecryptDataKey(ctx, wrapped)
+		assert.ErrorIs(t, err, errInvalidWrappedKey)
+		_, err = keys.DecryptDataKey(ctx, wrapped[:4])
+		assert.ErrorIs(t, err, errInvalidWrappedKey)
+	})
+
+	t.Run("should reject invalid master key files", func(t *testing.T) {
+		_, err := NewFileKeyProvider(filepath.Join(t.TempDir(), "missing.key"))
+		assert.Error(t, err)
+		_, err = NewFileKeyProvider(writeKeyFile(t, "not base64!"))
+		assert.Error(t, err)
+		_, err = NewFileKeyProvider(writeKeyFile(t, base64.StdEncoding.EncodeToString([]byte("short"))))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,46 @@
+package keyprovider
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var (
+	errKMSKeyIDIsMandatory         = errors.New("KMS_KEY_ID is mandatory with the kms key provider")
+	errKMSBlindIndexKeyIsMandatory = errors.New("KMS_BLIND_INDEX_KEY is mandatory with the kms key provider")
+	errKeyFileIsMandatory          = errors.New("PII_KEY_FILE is mandatory with the file key provider")
+)
+
+// NewKeyProvider returns the key provider selected by PII_KEY_PROVIDER, nil when the personal data isn't
+// encrypted
+func NewKeyProvider(cfg *config.Config, awsCfg aws.Config) (port.KeyProvider, error) {
+	switch cfg.PIIKeyProvider {
+	case config.KeyProviderNone, "":
+		return nil, nil
+	case config.KeyProviderFile:
+		if cfg.PIIKeyFile == "" {
+			return nil, errKeyFileIsMandatory
+		}
+		return NewFileKeyProvider(cfg.PIIKeyFile)
+	case config.KeyProviderKMS:
+		if cfg.KMSKeyID == "" {
+			return nil, errKMSKeyIDIsMandatory
+		}
+		if cfg.KMSBlindIndexKey == "" {
+			return nil, errKMSBlindIndexKeyIsMandatory
+		}
+		wrappedBlindIndexKey, err := base64.StdEncoding.DecodeString(cfg.KMSBlindIndexKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode KMS_BLIND_INDEX_KEY: %w", err)
+		}
+		return NewKMSKeyProvider(awsCfg, cfg.KMSKeyID, wrappedBlindIndexKey, cfg.KMSEndpoint), nil
+	default:
+		return nil, fmt.Errorf("unknown PII_KEY_PROVIDER %q", cfg.PIIKeyProvider)
+	}
+}
@@ -0,0 +1,57 @@
+package keyprovider
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
+)
+
+func TestNewKeyProvider(t *testing.T) {
+	keyFile := writeKeyFile(t, base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")))
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		want    any
+		wantErr bool
+	}{
+		{name: "should not encrypt without a key provider", cfg: config.Config{PIIKeyProvider: config.KeyProviderNone}},
+		{name: "should read the master key file", cfg: config.Config{PIIKeyProvider: config.KeyProviderFile, PIIKeyFile: keyFile}, want: &fileKeyProvider{}},
+		{name: "should require the master key file", cfg: config.Config{PIIKeyProvider: config.KeyProviderFile}, wantErr: true},
+		{
+			name: "should use the KMS key",
+			cfg: config.Config{PIIKeyProvider: config.KeyProviderKMS, KMSKeyID: "alias/customers",
+				KMSBlindIndexKey: base64.StdEncoding.EncodeToString([]byte("wrapped"))},
+			want: &kmsKeyProvider{},
+		},
+		{name: "should require the KMS key", cfg: config.Config{PIIKeyProvider: config.KeyProviderKMS}, wantErr: true},
+		{
+			name:    "should require the blind index key",
+			cfg:     config.Config{PIIKeyProvider: config.KeyProviderKMS, KMSKeyID: "alias/customers"},
+			wantErr: true,
+		},
+		{name: "should reject unknown key providers", cfg: config.Config{PIIKeyProvider: "vault"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeyProvider(&tt.cfg, aws.Config{Region: "us-east-1"})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, keys)
+				return
+			}
+			assert.IsType(t, tt.want, keys)
+		})
+	}
+}
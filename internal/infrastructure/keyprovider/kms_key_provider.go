@@ -0,0 +1,169 @@
+package keyprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+const (
+	// kmsEncryptionContextKey binds the data keys to the service: KMS only decrypts a key with the
+	// encryption context it was generated with, and logs it in CloudTrail
+	kmsEncryptionContextKey   = "service"
+	kmsEncryptionContextValue = "tc4-customer-service"
+	kmsRequestTimeout         = 5 * time.Second
+)
+
+// kmsKeyProvider calls the JSON API of AWS KMS, with requests signed with the credentials of the AWS
+// configuration. The master key never leaves KMS: the data keys are generated and unwrapped by it
+type kmsKeyProvider struct {
+	httpClient  *http.Client
+	signer      *v4.Signer
+	credentials aws.CredentialsProvider
+	region      string
+	endpoint    string
+	keyID       string
+
+	// wrappedBlindIndexKey is a data key generated once by the operators, unwrapped on the first use
+	wrappedBlindIndexKey []byte
+	blindIndexKeyMu      sync.Mutex
+	blindIndexKey        []byte
+}
+
+// NewKMSKeyProvider uses the KMS key keyID, in the region of the AWS configuration. An empty endpoint
+// calls the regional endpoint of KMS
+func NewKMSKeyProvider(awsCfg aws.Config, keyID string, wrappedBlindIndexKey []byte, endpoint string) port.KeyProvider {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", awsCfg.Region)
+	}
+	return &kmsKeyProvider{
+		httpClient:           &http.Client{Timeout: kmsRequestTimeout},
+		signer:               v4.NewSigner(),
+		credentials:          awsCfg.Credentials,
+		region:               awsCfg.Region,
+		endpoint:             endpoint,
+		keyID:                keyID,
+		wrappedBlindIndexKey: wrappedBlindIndexKey,
+	}
+}
+
+type kmsGenerateDataKeyRequest struct {
+	KeyId             string            `json:"KeyId"`
+	KeySpec           string            `json:"KeySpec"`
+	EncryptionContext map[string]string `json:"EncryptionContext"`
+}
+
+type kmsDecryptRequest struct {
+	KeyId             string            `json:"KeyId"`
+	CiphertextBlob    []byte            `json:"CiphertextBlob"`
+	EncryptionContext map[string]string `json:"EncryptionContext"`
+}
+
+// kmsDataKeyResponse has the fields of both responses. The blobs are base64 in JSON, as []byte is encoded
+type kmsDataKeyResponse struct {
+	CiphertextBlob []byte `json:"CiphertextBlob"`
+	Plaintext      []byte `json:"Plaintext"`
+}
+
+type kmsErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+func (p *kmsKeyProvider) encryptionContext() map[string]string {
+	return map[string]string{kmsEncryptionContextKey: kmsEncryptionContextValue}
+}
+
+func (p *kmsKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	var response kmsDataKeyResponse
+	err := p.call(ctx, "GenerateDataKey", kmsGenerateDataKeyRequest{
+		KeyId:             p.keyID,
+		KeySpec:           "AES_256",
+		EncryptionContext: p.encryptionContext(),
+	}, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.Plaintext, response.CiphertextBlob, nil
+}
+
+func (p *kmsKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var response kmsDataKeyResponse
+	err := p.call(ctx, "Decrypt", kmsDecryptRequest{
+		KeyId:             p.keyID,
+		CiphertextBlob:    wrapped,
+		EncryptionContext: p.encryptionContext(),
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Plaintext, nil
+}
+
+// BlindIndexKey unwraps the configured blind index key once. A failed call is retried on the next use
+func (p *kmsKeyProvider) BlindIndexKey(ctx context.Context) ([]byte, error) {
+	p.blindIndexKeyMu.Lock()
+	defer p.blindIndexKeyMu.Unlock()
+
+	if p.blindIndexKey == nil {
+		key, err := p.DecryptDataKey(ctx, p.wrappedBlindIndexKey)
+		if err != nil {
+			return nil, err
+		}
+		p.blindIndexKey = key
+	}
+	return p.blindIndexKey, nil
+}
+
+// call sends a signed request to an action of the KMS API
+func (p *kmsKeyProvider) call(ctx context.Context, action string, input, output any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+
+	credentials, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve the AWS credentials: %w", err)
+	}
+	payloadHash := sha256.Sum256(body)
+	if err := p.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(payloadHash[:]), "kms", p.region, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s failed: %w", action, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var kmsErr kmsErrorResponse
+		_ = json.Unmarshal(respBody, &kmsErr)
+		return fmt.Errorf("KMS %s failed with status %d: %s %s", action, resp.StatusCode, kmsErr.Type, kmsErr.Message)
+	}
+	return json.Unmarshal(respBody, output)
+}
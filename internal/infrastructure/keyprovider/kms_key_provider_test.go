@@ -0,0 +1,100 @@
+package keyprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeKMS answers the KMS actions with fixed keys, after checking the request is signed
+func newFakeKMS(t *testing.T, decrypts *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/kms/aws4_request")
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "alias/customers", body["KeyId"])
+		assert.Equal(t, map[string]any{"service": "tc4-customer-service"}, body["EncryptionContext"])
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			assert.Equal(t, "AES_256", body["KeySpec"])
+			_ = json.NewEncoder(w).Encode(kmsDataKeyResponse{CiphertextBlob: []byte("wrapped"), Plaintext: []byte("plaintext")})
+		case "TrentService.Decrypt":
+			decrypts.Add(1)
+			if body["CiphertextBlob"] != base64.StdEncoding.EncodeToString([]byte("wrapped")) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"InvalidCiphertextException","message":"invalid"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(kmsDataKeyResponse{Plaintext: []byte("plaintext")})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func newTestKMSKeyProvider(endpoint string, wrappedBlindIndexKey []byte) *kmsKeyProvider {
+	awsCfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+	return NewKMSKeyProvider(awsCfg, "alias/customers", wrappedBlindIndexKey, endpoint).(*kmsKeyProvider)
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	var decrypts atomic.Int32
+	server := newFakeKMS(t, &decrypts)
+	defer server.Close()
+
+	t.Run("should generate and decrypt data keys", func(t *testing.T) {
+		keys := newTestKMSKeyProvider(server.URL, nil)
+
+		plaintext, wrapped, err := keys.GenerateDataKey(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("plaintext"), plaintext)
+		assert.Equal(t, []byte("wrapped"), wrapped)
+
+		unwrapped, err := keys.DecryptDataKey(ctx, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("plaintext"), unwrapped)
+	})
+
+	t.Run("should return the KMS errors", func(t *testing.T) {
+		keys := newTestKMSKeyProvider(server.URL, nil)
+
+		_, err := keys.DecryptDataKey(ctx, []byte("tampered"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "InvalidCiphertextException")
+	})
+
+	t.Run("should decrypt the blind index key once", func(t *testing.T) {
+		decrypts.Store(0)
+		keys := newTestKMSKeyProvider(server.URL, []byte("wrapped"))
+
+		for range 3 {
+			key, err := keys.BlindIndexKey(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("plaintext"), key)
+		}
+		assert.Equal(t, int32(1), decrypts.Load())
+	})
+
+	t.Run("should use the regional endpoint by default", func(t *testing.T) {
+		keys := newTestKMSKeyProvider("", nil)
+		assert.Equal(t, "https://kms.us-east-1.amazonaws.com/", keys.endpoint)
+	})
+}
@@ -27,7 +27,8 @@ func NewLogger(cfg *config.Config) *Logger {
 			}})
 	}
 
+	// Both handlers write the request bodies and parameters, so the personal data is masked before them
 	return &Logger{
-		Logger: slog.New(handler),
+		Logger: slog.New(NewRedactingHandler(handler, cfg.LogRedactKeys)),
 	}
 }
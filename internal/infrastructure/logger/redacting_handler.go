@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
+)
+
+// redactedValue replaces the values of the redacted keys and the JWTs
+const redactedValue = "***"
+
+var (
+	jwtPattern   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cpfPattern   = regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`)
+
+	errTrailingJSON = errors.New("trailing data after the JSON value")
+)
+
+// RedactingHandler masks the personal data and secrets of the log records before they reach the handler:
+// the CPFs, emails and JWTs found in any string, and the values of the redacted keys. Groups, maps, structs
+// and JSON strings, like request bodies, are redacted by their nested keys and values too
+type RedactingHandler struct {
+	handler slog.Handler
+	keys    map[string]bool
+}
+
+// NewRedactingHandler wraps a handler, redacting the values of the keys, which are matched ignoring case
+func NewRedactingHandler(handler slog.Handler, keys []string) *RedactingHandler {
+	redactedKeys := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redactedKeys[strings.ToLower(key)] = true
+	}
+	return &RedactingHandler{handler: handler, keys: redactedKeys}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RedactingHandler{handler: h.handler.WithAttrs(h.redactAttrs(attrs)), keys: h.keys}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{handler: h.handler.WithGroup(name), keys: h.keys}
+}
+
+func (h *RedactingHandler) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, h.redactAttr(a))
+	}
+	return redacted
+}
+
+func (h *RedactingHandler) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if h.keys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.redactString(a.Value.String()))
+	case slog.KindGroup:
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(h.redactAttrs(a.Value.Group())...)}
+	case slog.KindAny:
+		return slog.Any(a.Key, h.redactAny(a.Value.Any()))
+	default:
+		return a
+	}
+}
+
+// redactAny redacts a value through its JSON representation, which is how the handlers write it
+func (h *RedactingHandler) redactAny(value any) any {
+	switch v := value.(type) {
+	case error:
+		return h.redactString(v.Error())
+	case []byte:
+		return h.redactString(string(v))
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		// A value that can't be inspected isn't logged
+		return redactedValue
+	}
+	decoded, err := decodeJSON(b)
+	if err != nil {
+		return redactedValue
+	}
+	return h.redactJSON(decoded)
+}
+
+// redactString masks the CPFs, emails and JWTs of a string. A JSON string is redacted by its keys too
+func (h *RedactingHandler) redactString(s string) string {
+	if trimmed := strings.TrimSpace(s); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if decoded, err := decodeJSON([]byte(trimmed)); err == nil {
+			if redacted, err := encodeJSON(h.redactJSON(decoded)); err == nil {
+				return redacted
+			}
+		}
+	}
+
+	s = jwtPattern.ReplaceAllString(s, redactedValue)
+	s = emailPattern.ReplaceAllStringFunc(s, entity.MaskEmail)
+	return cpfPattern.ReplaceAllStringFunc(s, entity.MaskCPF)
+}
+
+func (h *RedactingHandler) redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if h.keys[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = h.redactJSON(item)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = h.redactJSON(item)
+		}
+		return v
+	case string:
+		return h.redactString(v)
+	case json.Number:
+		// A CPF sent as a number
+		if cpfPattern.MatchString(v.String()) {
+			return entity.MaskCPF(v.String())
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// decodeJSON keeps the numbers as they were written, so large IDs don't lose precision
+func decodeJSON(b []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errTrailingJSON
+	}
+	return decoded, nil
+}
+
+// encodeJSON writes a redacted JSON string back without escaping its HTML characters, as it was logged
+func encodeJSON(value any) (string, error) {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
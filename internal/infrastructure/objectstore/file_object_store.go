@@ -0,0 +1,101 @@
+// Package objectstore keeps the objects of the imports and exports
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
+)
+
+// fileObjectStore is a local filesystem stand-in for S3: the object key of a bucket is the file
+// root/bucket/key
+type fileObjectStore struct {
+	root string
+}
+
+func NewFileObjectStore(root string) port.ObjectStore {
+	return &fileObjectStore{root: root}
+}
+
+func (s *fileObjectStore) Open(_ context.Context, bucket, key string) (io.ReadCloser, error) {
+	name, err := s.path(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	return file, err
+}
+
+func (s *fileObjectStore) Put(_ context.Context, bucket, key string, body []byte) error {
+	name, err := s.path(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, body, 0o644)
+}
+
+// Create writes the object to a temporary file of its directory, renamed to the object when closed, so
+// an aborted object never replaces the stored one
+func (s *fileObjectStore) Create(_ context.Context, bucket, key string) (port.ObjectWriter, error) {
+	name, err := s.path(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return CreateFile(name)
+}
+
+// CreateFile returns a writer of a local file, written like the objects of the store
+func CreateFile(name string) (port.ObjectWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &objectWriter{File: file, name: name}, nil
+}
+
+// objectWriter stores the object of its temporary file when closed
+type objectWriter struct {
+	*os.File
+	name string
+}
+
+func (w *objectWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		_ = os.Remove(w.File.Name())
+		return err
+	}
+	return os.Rename(w.File.Name(), w.name)
+}
+
+func (w *objectWriter) Abort() error {
+	_ = w.File.Close()
+	return os.Remove(w.File.Name())
+}
+
+// path maps an object to its file, rejecting the buckets and keys that would leave the root
+func (s *fileObjectStore) path(bucket, key string) (string, error) {
+	name := filepath.Join(s.root, bucket, filepath.FromSlash(key))
+	relative, err := filepath.Rel(s.root, name)
+	if err != nil || bucket == "" || key == "" || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", domain.NewInvalidInputError(domain.ErrInvalidParam)
+	}
+	return name, nil
+}
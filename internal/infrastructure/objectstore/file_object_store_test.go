@@ -0,0 +1,70 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+)
+
+func TestFileObjectStore(t *testing.T) {
+	store := NewFileObjectStore(t.TempDir())
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "imports", "2026/customers.csv", []byte("name,email,cpf\n")))
+
+	object, err := store.Open(ctx, "imports", "2026/customers.csv")
+	require.NoError(t, err)
+	defer object.Close()
+	body, err := io.ReadAll(object)
+	assert.NoError(t, err)
+	assert.Equal(t, "name,email,cpf\n", string(body))
+
+	_, err = store.Open(ctx, "imports", "missing.csv")
+	assert.IsType(t, &domain.NotFoundError{}, err)
+
+	_, err = store.Open(ctx, "imports", "../../etc/passwd")
+	assert.IsType(t, &domain.InvalidInputError{}, err)
+
+	err = store.Put(ctx, "..", "customers.csv", []byte{})
+	assert.IsType(t, &domain.InvalidInputError{}, err)
+}
+
+func TestFileObjectStore_Create(t *testing.T) {
+	root := t.TempDir()
+	store := NewFileObjectStore(root)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "exports", "customers.csv", []byte("previous")))
+
+	// An aborted object keeps the stored one
+	aborted, err := store.Create(ctx, "exports", "customers.csv")
+	require.NoError(t, err)
+	_, err = aborted.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, aborted.Abort())
+
+	object, err := store.Create(ctx, "exports", "customers.csv")
+	require.NoError(t, err)
+	_, err = object.Write([]byte("id,name\n"))
+	require.NoError(t, err)
+
+	stored, err := os.ReadFile(filepath.Join(root, "exports", "customers.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(stored), "the object must only be stored when closed")
+
+	require.NoError(t, object.Close())
+	stored, err = os.ReadFile(filepath.Join(root, "exports", "customers.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n", string(stored))
+
+	files, err := os.ReadDir(filepath.Join(root, "exports"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "the temporary files must be removed")
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,6 +20,13 @@ type JwtService struct {
 	expiration time.Duration
 }
 
+// tokenClaims are the registered claims and the role of the subject. Tokens without a role,
+// issued before roles existed, are customer tokens
+type tokenClaims struct {
+	Role entity.Role `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
 func NewJWTService(cfg *config.Config) *JwtService {
 	return &JwtService{
 		secretKey:  []byte(cfg.JWTSecret),
@@ -26,20 +36,29 @@ func NewJWTService(cfg *config.Config) *JwtService {
 	}
 }
 
+// GenerateToken issues the token of a customer
 func (s *JwtService) GenerateToken(userIdentifier string) (string, string, int64, error) {
+	return s.IssueToken(entity.Principal{Subject: userIdentifier, Role: entity.RoleCustomer})
+}
+
+// IssueToken issues a token of any role, like the admin tokens of the operators
+func (s *JwtService) IssueToken(principal entity.Principal) (string, string, int64, error) {
 	expiresAt := time.Now().Add(s.expiration)
 	jwtTokenId := uuid.New().String()
 
-	tokenClaims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(expiresAt),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Issuer:    s.issuer,
-		Subject:   userIdentifier,
-		ID:        jwtTokenId,
-		Audience:  s.audience,
+	claims := tokenClaims{
+		Role: principal.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    s.issuer,
+			Subject:   principal.Subject,
+			ID:        jwtTokenId,
+			Audience:  s.audience,
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := token.SignedString(s.secretKey)
 	if err != nil {
 		return "", "", 0, err
@@ -47,3 +66,27 @@ func (s *JwtService) GenerateToken(userIdentifier string) (string, string, int64
 
 	return "Bearer", signedToken, expiresAt.UnixMilli(), nil
 }
+
+func (s *JwtService) ValidateToken(token string) (*entity.Principal, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.secretKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(s.audience[0]),
+		jwt.WithExpirationRequired(),
+	)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, domain.NewUnauthorizedError(domain.ErrExpiredToken)
+	}
+	if err != nil {
+		return nil, domain.NewUnauthorizedError(domain.ErrInvalidToken)
+	}
+
+	role := claims.Role
+	if role == "" {
+		role = entity.RoleCustomer
+	}
+	return &entity.Principal{Subject: claims.Subject, Role: role}, nil
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
 )
 
@@ -113,3 +115,97 @@ func TestJwtService_GenerateToken_InvalidSecretKey(t *testing.T) {
 	assert.NotEmpty(t, token)
 	assert.Greater(t, expiresAt, time.Now().UnixMilli())
 }
+
+func TestJwtService_ValidateToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:     "test-secret-key",
+		JWTExpiration: time.Hour,
+		JWTIssuer:     "test-issuer",
+		JWTAudience:   "test-audience",
+	}
+	service := NewJWTService(cfg)
+
+	_, customerToken, _, err := service.GenerateToken("42")
+	assert.NoError(t, err)
+	_, adminToken, _, err := service.IssueToken(entity.Principal{Subject: "ops", Role: entity.RoleAdmin})
+	assert.NoError(t, err)
+
+	otherIssuer := NewJWTService(&config.Config{JWTSecret: cfg.JWTSecret, JWTExpiration: time.Hour, JWTIssuer: "other", JWTAudience: cfg.JWTAudience})
+	_, otherIssuerToken, _, err := otherIssuer.GenerateToken("42")
+	assert.NoError(t, err)
+
+	otherSecret := NewJWTService(&config.Config{JWTSecret: "other-secret", JWTExpiration: time.Hour, JWTIssuer: cfg.JWTIssuer, JWTAudience: cfg.JWTAudience})
+	_, otherSecretToken, _, err := otherSecret.GenerateToken("42")
+	assert.NoError(t, err)
+
+	expired := NewJWTService(&config.Config{JWTSecret: cfg.JWTSecret, JWTExpiration: -time.Minute, JWTIssuer: cfg.JWTIssuer, JWTAudience: cfg.JWTAudience})
+	_, expiredToken, _, err := expired.GenerateToken("42")
+	assert.NoError(t, err)
+
+	legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "42",
+		Issuer:    cfg.JWTIssuer,
+		Audience:  jwt.ClaimStrings{cfg.JWTAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString([]byte(cfg.JWTSecret))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *entity.Principal
+		wantErr string
+	}{
+		{
+			name:  "should read a customer token",
+			token: customerToken,
+			want:  &entity.Principal{Subject: "42", Role: entity.RoleCustomer},
+		},
+		{
+			name:  "should read an admin token",
+			token: adminToken,
+			want:  &entity.Principal{Subject: "ops", Role: entity.RoleAdmin},
+		},
+		{
+			name:  "should read a token without role as a customer token",
+			token: legacyToken,
+			want:  &entity.Principal{Subject: "42", Role: entity.RoleCustomer},
+		},
+		{
+			name:    "should reject an expired token",
+			token:   expiredToken,
+			wantErr: domain.ErrExpiredToken,
+		},
+		{
+			name:    "should reject a token of another issuer",
+			token:   otherIssuerToken,
+			wantErr: domain.ErrInvalidToken,
+		},
+		{
+			name:    "should reject a token signed with another secret",
+			token:   otherSecretToken,
+			wantErr: domain.ErrInvalidToken,
+		},
+		{
+			name:    "should reject a malformed token",
+			token:   "not-a-token",
+			wantErr: domain.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := service.ValidateToken(tt.token)
+
+			if tt.wantErr != "" {
+				var unauthorized *domain.UnauthorizedError
+				assert.ErrorAs(t, err, &unauthorized)
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, principal)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, principal)
+		})
+	}
+}
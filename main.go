@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/aws/lambda"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/cli"
 )
 
 func init() {
@@ -9,5 +13,10 @@ func init() {
 }
 
 func main() {
+	// The lambda runtime starts the binary without arguments, any argument is a maintenance command
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	lambda.StartLambda()
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/controller"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/gateway"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/port"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/core/usecase"
@@ -25,6 +26,7 @@ import (
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/config"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/database"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/eventpublisher"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/logger"
 	"github.com/FIAP-SOAT-G20/tc4-customer-service/internal/infrastructure/service"
 )
@@ -115,27 +117,30 @@ func theCustomerServiceIsRunning() error {
 
 	testCtx.logger = logger.NewLogger(cfg)
 
-	// Setup test database
+	// Without a DynamoDB endpoint, the scenarios run against the in-memory data source
 	testEndpoint := os.Getenv("TEST_DYNAMODB_ENDPOINT")
-	if testEndpoint == "" {
-		testEndpoint = "http://localhost:8000" // default for local testing
-	}
-	dynamoDb, err := database.NewDynamoTestConnection(cfg, testCtx.logger, testEndpoint)
-	if err != nil {
-		fmt.Printf("Skipping BDD tests: DynamoDB not available: %v\n", err)
-		os.Exit(0)
-	}
+	if os.Getenv("DATASOURCE") == config.DataSourceMemory || testEndpoint == "" {
+		testCtx.customerDataSource = datasource.NewCustomerMemoryDataSource()
+	} else {
+		dynamoDb, err := database.NewDynamoTestConnection(cfg, testCtx.logger, testEndpoint)
+		if err != nil {
+			fmt.Printf("Skipping BDD tests: DynamoDB not available: %v\n", err)
+			os.Exit(0)
+		}
 
-	testCtx.dynamoDb = dynamoDb
+		testCtx.dynamoDb = dynamoDb
 
-	// Create test table if it doesn't exist (delete and recreate to ensure correct schema)
-	deleteAndRecreateTestTable(dynamoDb)
+		// Create test table if it doesn't exist (delete and recreate to ensure correct schema)
+		deleteAndRecreateTestTable(dynamoDb)
+		testCtx.customerDataSource = datasource.NewCustomerDynamoDataSource(dynamoDb)
+	}
 
 	// Setup dependencies
 	jwtService := service.NewJWTService(cfg)
-	testCtx.customerDataSource = datasource.NewCustomerDynamoDataSource(dynamoDb)
 	testCtx.customerGateway = gateway.NewCustomerGateway(testCtx.customerDataSource)
-	testCtx.customerUseCase = usecase.NewCustomerUseCase(testCtx.customerGateway)
+	// The scenarios don't read the audit entries, so they are kept in memory with any data source
+	testCtx.customerUseCase = usecase.NewCustomerUseCase(testCtx.customerGateway,
+		gateway.NewAuditGateway(datasource.NewAuditMemoryDataSource()), eventpublisher.NewLogEventPublisher(logger.NewLogger(cfg)))
 	testCtx.customerController = controller.NewCustomerController(testCtx.customerUseCase)
 	testCtx.jsonPresenter = presenter.NewCustomerJsonPresenter()
 	testCtx.jwtPresenter = presenter.NewCustomerJwtTokenPresenter(jwtService)
@@ -147,7 +152,7 @@ func aCustomerExistsWithEmail(email string) error {
 	customerRequest := request.CustomerRequest{
 		Name:  "Test Customer",
 		Email: email,
-		CPF:   "12345678901",
+		CPF:   "12345678909",
 	}
 
 	return createTestCustomer(customerRequest)
@@ -158,7 +163,7 @@ func aCustomerExistsWithID(id string) error {
 	customerRequest := request.CustomerRequest{
 		Name:  "Test Customer",
 		Email: "test@example.com",
-		CPF:   "12345678901",
+		CPF:   "12345678909",
 	}
 
 	input := customerRequest.ToCreateCustomerInput()
@@ -344,9 +349,9 @@ func sendLambdaRequest(method, resource string, body interface{}, pathParams map
 	case method == "POST":
 		testCtx.response, err = handleTestPostRequest(ctx, request)
 	case method == "PUT":
-		testCtx.response, err = handleTestPutRequest(ctx, request)
+		testCtx.response, err = handleTestPutRequest(entity.ContextWithPrincipal(ctx, bddAdmin), request)
 	case method == "DELETE":
-		testCtx.response, err = handleTestDeleteRequest(ctx, request)
+		testCtx.response, err = handleTestDeleteRequest(entity.ContextWithPrincipal(ctx, bddAdmin), request)
 	default:
 		return fmt.Errorf("unsupported method: %s", method)
 	}
@@ -370,8 +375,8 @@ func handleTestAuthRequest(ctx context.Context, req events.APIGatewayProxyReques
 		return events.APIGatewayProxyResponse{StatusCode: 400, Body: `{"message": "CPF is required"}`}, nil
 	}
 
-	input := dto.GetCustomerByCPFInput{CPF: customerRequest.CPF}
-	resp, err := testCtx.customerController.GetByCPF(ctx, testCtx.jwtPresenter, input)
+	input := dto.AuthenticateCustomerInput{CPF: customerRequest.CPF}
+	resp, err := testCtx.customerController.Authenticate(ctx, testCtx.jwtPresenter, input)
 	if err != nil {
 		return events.APIGatewayProxyResponse{StatusCode: 401, Body: `{"message": "Invalid credentials"}`}, nil
 	}
@@ -437,6 +442,9 @@ func handleTestPostRequest(ctx context.Context, req events.APIGatewayProxyReques
 	return events.APIGatewayProxyResponse{StatusCode: 201, Body: string(resp)}, nil
 }
 
+// bddAdmin is the caller of the updates and deletions, which only the customer themself or an admin can do
+var bddAdmin = &entity.Principal{Subject: "bdd", Role: entity.RoleAdmin}
+
 func handleTestPutRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	customerID, hasID := req.PathParameters["id"]
 	if !hasID {
@@ -684,72 +692,11 @@ func deleteAndRecreateTestTable(db *database.DynamoDatabase) {
 
 // createTestTableIfNotExists creates the DynamoDB table for tests if it doesn't exist
 func createTestTableIfNotExists(db *database.DynamoDatabase) {
-	ctx := context.Background()
-
-	// Check if table exists
-	_, err := db.Client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
-		TableName: aws.String(db.TableName),
-	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	if err != nil {
-		// Table doesn't exist, create it
-		_, err = db.Client.CreateTable(ctx, &dynamodb.CreateTableInput{
-			TableName: aws.String(db.TableName),
-			KeySchema: []types.KeySchemaElement{
-				{
-					AttributeName: aws.String("id"),
-					KeyType:       types.KeyTypeHash,
-				},
-			},
-			AttributeDefinitions: []types.AttributeDefinition{
-				{
-					AttributeName: aws.String("id"),
-					AttributeType: types.ScalarAttributeTypeN,
-				},
-				{
-					AttributeName: aws.String("cpf"),
-					AttributeType: types.ScalarAttributeTypeS,
-				},
-			},
-			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
-				{
-					IndexName: aws.String("cpf-index"),
-					KeySchema: []types.KeySchemaElement{
-						{
-							AttributeName: aws.String("cpf"),
-							KeyType:       types.KeyTypeHash,
-						},
-					},
-					Projection: &types.Projection{
-						ProjectionType: types.ProjectionTypeAll,
-					},
-					ProvisionedThroughput: &types.ProvisionedThroughput{
-						ReadCapacityUnits:  aws.Int64(5),
-						WriteCapacityUnits: aws.Int64(5),
-					},
-				},
-			},
-			ProvisionedThroughput: &types.ProvisionedThroughput{
-				ReadCapacityUnits:  aws.Int64(5),
-				WriteCapacityUnits: aws.Int64(5),
-			},
-		})
-
-		if err != nil {
-			fmt.Printf("Warning: Could not create test table: %v\n", err)
-		} else {
-			// Wait for the table to be active
-			waiter := dynamodb.NewTableExistsWaiter(db.Client)
-			err = waiter.Wait(ctx, &dynamodb.DescribeTableInput{
-				TableName: aws.String(db.TableName),
-			}, 30*time.Second, func(o *dynamodb.TableExistsWaiterOptions) {
-				o.MaxDelay = 30 * time.Second
-				o.MinDelay = 2 * time.Second
-			})
-			if err != nil {
-				fmt.Printf("Warning: Table creation wait failed: %v\n", err)
-			}
-		}
+	if err := db.EnsureTable(ctx, database.CustomersTableSchema(db.TableName)); err != nil {
+		fmt.Printf("Warning: Could not create test table: %v\n", err)
 	}
 }
 
@@ -7,13 +7,13 @@ Feature: Customer Authentication
     Given the customer service is running
 
   Scenario: Successful customer authentication
-    Given a customer exists with CPF "12345678901"
-    When I send an authentication request with CPF "12345678901"
+    Given a customer exists with CPF "12345678909"
+    When I send an authentication request with CPF "12345678909"
     Then I should receive a response with status 200
     And the response should contain a valid JWT token
 
   Scenario: Failed authentication with invalid CPF
-    Given a customer exists with CPF "12345678901"
+    Given a customer exists with CPF "12345678909"
     When I send an authentication request with CPF "98765432100"
     Then I should receive a response with status 401
     And the response should contain an error message "Invalid credentials"
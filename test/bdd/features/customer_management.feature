@@ -10,7 +10,7 @@ Feature: Customer Management
     When I send a request to create a customer with the following details:
       | name  | John Doe         |
       | email | john@example.com |
-      | cpf   | 12345678901      |
+      | cpf   | 12345678909      |
     Then I should receive a response with status 201
     And the response should contain the customer ID
     And the response should contain customer details
@@ -32,8 +32,8 @@ Feature: Customer Management
     And the response should contain an error message "Invalid customer ID"
 
   Scenario: Get customer by CPF
-    Given a customer exists with CPF "12345678901"
-    When I send a request to get customer with CPF "12345678901"
+    Given a customer exists with CPF "12345678909"
+    When I send a request to get customer with CPF "12345678909"
     Then I should receive a response with status 200
     And the response should contain customer details
 
@@ -45,7 +45,7 @@ Feature: Customer Management
   Scenario: List all customers
     Given the following customers exist:
       | name     | email            | cpf         |
-      | John Doe | john@example.com | 12345678901 |
+      | John Doe | john@example.com | 12345678909 |
       | Jane Doe | jane@example.com | 98765432100 |
     When I send a request to list all customers
     Then I should receive a response with status 200